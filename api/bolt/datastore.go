@@ -10,9 +10,14 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/bolt/customtemplate"
 	"github.com/portainer/portainer/api/bolt/dockerhub"
+	"github.com/portainer/portainer/api/bolt/dockerpolicy"
+	"github.com/portainer/portainer/api/bolt/edgeasynccommand"
+	"github.com/portainer/portainer/api/bolt/edgedevice"
 	"github.com/portainer/portainer/api/bolt/edgegroup"
 	"github.com/portainer/portainer/api/bolt/edgejob"
+	"github.com/portainer/portainer/api/bolt/edgejobrun"
 	"github.com/portainer/portainer/api/bolt/edgestack"
+	"github.com/portainer/portainer/api/bolt/edgestackstatushistory"
 	"github.com/portainer/portainer/api/bolt/endpoint"
 	"github.com/portainer/portainer/api/bolt/endpointgroup"
 	"github.com/portainer/portainer/api/bolt/endpointrelation"
@@ -20,15 +25,19 @@ import (
 	"github.com/portainer/portainer/api/bolt/extension"
 	"github.com/portainer/portainer/api/bolt/internal"
 	"github.com/portainer/portainer/api/bolt/migrator"
+	"github.com/portainer/portainer/api/bolt/prunepolicy"
+	"github.com/portainer/portainer/api/bolt/prunepolicyrun"
 	"github.com/portainer/portainer/api/bolt/registry"
 	"github.com/portainer/portainer/api/bolt/resourcecontrol"
 	"github.com/portainer/portainer/api/bolt/role"
 	"github.com/portainer/portainer/api/bolt/schedule"
 	"github.com/portainer/portainer/api/bolt/settings"
+	"github.com/portainer/portainer/api/bolt/snapshothistory"
 	"github.com/portainer/portainer/api/bolt/stack"
 	"github.com/portainer/portainer/api/bolt/tag"
 	"github.com/portainer/portainer/api/bolt/team"
 	"github.com/portainer/portainer/api/bolt/teammembership"
+	"github.com/portainer/portainer/api/bolt/tunnelevent"
 	"github.com/portainer/portainer/api/bolt/tunnelserver"
 	"github.com/portainer/portainer/api/bolt/user"
 	"github.com/portainer/portainer/api/bolt/version"
@@ -37,38 +46,48 @@ import (
 )
 
 const (
-	databaseFileName = "portainer.db"
+	// DatabaseFileName represents the name of the BoltDB database file inside the data folder
+	DatabaseFileName = "portainer.db"
 )
 
 // Store defines the implementation of portainer.DataStore using
 // BoltDB as the storage system.
 type Store struct {
-	path                    string
-	connection              *internal.DbConnection
-	isNew                   bool
-	fileService             portainer.FileService
-	CustomTemplateService   *customtemplate.Service
-	DockerHubService        *dockerhub.Service
-	EdgeGroupService        *edgegroup.Service
-	EdgeJobService          *edgejob.Service
-	EdgeStackService        *edgestack.Service
-	EndpointGroupService    *endpointgroup.Service
-	EndpointService         *endpoint.Service
-	EndpointRelationService *endpointrelation.Service
-	ExtensionService        *extension.Service
-	RegistryService         *registry.Service
-	ResourceControlService  *resourcecontrol.Service
-	RoleService             *role.Service
-	ScheduleService         *schedule.Service
-	SettingsService         *settings.Service
-	StackService            *stack.Service
-	TagService              *tag.Service
-	TeamMembershipService   *teammembership.Service
-	TeamService             *team.Service
-	TunnelServerService     *tunnelserver.Service
-	UserService             *user.Service
-	VersionService          *version.Service
-	WebhookService          *webhook.Service
+	path                          string
+	connection                    *internal.DbConnection
+	isNew                         bool
+	fileService                   portainer.FileService
+	CustomTemplateService         *customtemplate.Service
+	DockerPolicyService           *dockerpolicy.Service
+	DockerHubService              *dockerhub.Service
+	EdgeAsyncCommandService       *edgeasynccommand.Service
+	EdgeDeviceService             *edgedevice.Service
+	EdgeGroupService              *edgegroup.Service
+	EdgeJobService                *edgejob.Service
+	EdgeJobRunService             *edgejobrun.Service
+	EdgeStackService              *edgestack.Service
+	EdgeStackStatusHistoryService *edgestackstatushistory.Service
+	EndpointGroupService          *endpointgroup.Service
+	EndpointService               *endpoint.Service
+	EndpointRelationService       *endpointrelation.Service
+	ExtensionService              *extension.Service
+	PrunePolicyService            *prunepolicy.Service
+	PrunePolicyRunService         *prunepolicyrun.Service
+	RegistryService               *registry.Service
+	ResourceControlService        *resourcecontrol.Service
+	RoleService                   *role.Service
+	ScheduleService               *schedule.Service
+	SettingsService               *settings.Service
+	SnapshotHistoryService        *snapshothistory.Service
+	StackService                  *stack.Service
+	TagService                    *tag.Service
+	TeamMembershipService         *teammembership.Service
+	TeamService                   *team.Service
+	TunnelServerService           *tunnelserver.Service
+	TunnelEventService            *tunnelevent.Service
+	UserService                   *user.Service
+	VersionService                *version.Service
+	WebhookService                *webhook.Service
 }
 
 func (store *Store) edition() portainer.SoftwareEdition {
@@ -88,7 +107,7 @@ func NewStore(storePath string, fileService portainer.FileService) (*Store, erro
 		connection:  &internal.DbConnection{},
 	}
 
-	databasePath := path.Join(storePath, databaseFileName)
+	databasePath := path.Join(storePath, DatabaseFileName)
 	databaseFileExists, err := fileService.FileExists(databasePath)
 	if err != nil {
 		return nil, err
@@ -103,7 +122,7 @@ func NewStore(storePath string, fileService portainer.FileService) (*Store, erro
 
 // Open opens and initializes the BoltDB database.
 func (store *Store) Open() error {
-	databasePath := path.Join(store.path, databaseFileName)
+	databasePath := path.Join(store.path, DatabaseFileName)
 	db, err := bolt.Open(databasePath, 0600, &bolt.Options{Timeout: 1 * time.Second})
 	if err != nil {
 		return err
@@ -151,6 +170,12 @@ func (store *Store) MigrateData(force bool) error {
 	}
 
 	if version < portainer.DBVersion {
+		backupPath, err := store.backupBeforeMigration(version)
+		if err != nil {
+			log.Printf("An error occurred while creating the pre-migration backup: %s\n", err)
+			return err
+		}
+
 		migratorParams := &migrator.Parameters{
 			DB:                      store.connection.DB,
 			DatabaseVersion:         version,
@@ -178,6 +203,19 @@ func (store *Store) MigrateData(force bool) error {
 		err = migrator.Migrate()
 		if err != nil {
 			log.Printf("An error occurred during database migration: %s\n", err)
+
+			if version < MinRollbackDBVersion {
+				log.Printf("The pre-migration backup %s was not restored as the migration may have renamed the stack folders.\n", backupPath)
+				return err
+			}
+
+			restoreErr := store.restoreBackup(backupPath)
+			if restoreErr != nil {
+				log.Printf("An error occurred while restoring the pre-migration backup %s: %s\n", backupPath, restoreErr)
+				return err
+			}
+
+			log.Printf("Database restored to version %v from %s.\n", version, backupPath)
 			return err
 		}
 	}
@@ -0,0 +1,20 @@
+package bolt_test
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/bolttest"
+	"github.com/portainer/portainer/api/internal/datastoretest"
+)
+
+func TestStoreServices(t *testing.T) {
+	datastoretest.RunServiceTests(t, func(t *testing.T) (portainer.DataStore, func()) {
+		store, teardown, err := bolttest.NewTestStore(true)
+		if err != nil {
+			t.Fatalf("failed creating test store: %v", err)
+		}
+
+		return store, teardown
+	})
+}
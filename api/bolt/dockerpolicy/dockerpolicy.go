@@ -0,0 +1,95 @@
+package dockerpolicy
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/internal"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "docker_policies"
+)
+
+// Service represents a service for managing Docker policy data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateBucket(connection, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// DockerPolicies returns an array containing all the Docker policies.
+func (service *Service) DockerPolicies() ([]portainer.DockerPolicy, error) {
+	var policies = make([]portainer.DockerPolicy, 0)
+
+	err := service.connection.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var policy portainer.DockerPolicy
+			err := internal.UnmarshalObject(v, &policy)
+			if err != nil {
+				return err
+			}
+			policies = append(policies, policy)
+		}
+
+		return nil
+	})
+
+	return policies, err
+}
+
+// DockerPolicy returns a Docker policy by ID.
+func (service *Service) DockerPolicy(ID portainer.DockerPolicyID) (*portainer.DockerPolicy, error) {
+	var policy portainer.DockerPolicy
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, BucketName, identifier, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// CreateDockerPolicy creates a new Docker policy.
+func (service *Service) CreateDockerPolicy(policy *portainer.DockerPolicy) error {
+	return service.connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		id, _ := bucket.NextSequence()
+		policy.ID = portainer.DockerPolicyID(id)
+
+		data, err := internal.MarshalObject(policy)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(internal.Itob(int(policy.ID)), data)
+	})
+}
+
+// UpdateDockerPolicy updates a Docker policy.
+func (service *Service) UpdateDockerPolicy(ID portainer.DockerPolicyID, policy *portainer.DockerPolicy) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, BucketName, identifier, policy)
+}
+
+// DeleteDockerPolicy deletes a Docker policy.
+func (service *Service) DeleteDockerPolicy(ID portainer.DockerPolicyID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, BucketName, identifier)
+}
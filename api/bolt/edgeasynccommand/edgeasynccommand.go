@@ -0,0 +1,102 @@
+package edgeasynccommand
+
+import (
+	"math"
+
+	"github.com/boltdb/bolt"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/internal"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "edge_async_commands"
+)
+
+// Service represents a service for managing Edge async command data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateBucket(connection, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// commandKey returns the key of a command, made of the endpoint identifier followed by the command
+// identifier so that the commands of an endpoint are stored next to each other, in creation order.
+func commandKey(endpointID portainer.EndpointID, ID int) []byte {
+	return append(internal.Itob(int(endpointID)), internal.Itob(ID)...)
+}
+
+// EdgeAsyncCommands returns the async commands of an endpoint, ordered by creation.
+func (service *Service) EdgeAsyncCommands(endpointID portainer.EndpointID) ([]portainer.EdgeAsyncCommand, error) {
+	var commands = make([]portainer.EdgeAsyncCommand, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, BucketName, commandKey(endpointID, 0), commandKey(endpointID, math.MaxInt64), func(data []byte) error {
+		var command portainer.EdgeAsyncCommand
+		err := internal.UnmarshalObject(data, &command)
+		if err != nil {
+			return err
+		}
+		commands = append(commands, command)
+
+		return nil
+	})
+
+	return commands, err
+}
+
+// EdgeAsyncCommand returns an async command of an endpoint by ID.
+func (service *Service) EdgeAsyncCommand(endpointID portainer.EndpointID, ID int) (*portainer.EdgeAsyncCommand, error) {
+	var command portainer.EdgeAsyncCommand
+
+	err := internal.GetObject(service.connection, BucketName, commandKey(endpointID, ID), &command)
+	if err != nil {
+		return nil, err
+	}
+
+	return &command, nil
+}
+
+// CreateEdgeAsyncCommand assigns an identifier to an async command and saves it.
+func (service *Service) CreateEdgeAsyncCommand(command *portainer.EdgeAsyncCommand) error {
+	return service.connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		command.ID = int(id)
+
+		data, err := internal.MarshalObject(command)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(commandKey(command.EndpointID, command.ID), data)
+	})
+}
+
+// UpdateEdgeAsyncCommand saves an async command.
+func (service *Service) UpdateEdgeAsyncCommand(command *portainer.EdgeAsyncCommand) error {
+	return internal.UpdateObject(service.connection, BucketName, commandKey(command.EndpointID, command.ID), command)
+}
+
+// DeleteEdgeAsyncCommand deletes an async command of an endpoint.
+func (service *Service) DeleteEdgeAsyncCommand(endpointID portainer.EndpointID, ID int) error {
+	return internal.DeleteObject(service.connection, BucketName, commandKey(endpointID, ID))
+}
+
+// DeleteEdgeAsyncCommands deletes the async commands of an endpoint up to the command identified by maxID (inclusive).
+func (service *Service) DeleteEdgeAsyncCommands(endpointID portainer.EndpointID, maxID int) error {
+	return internal.DeleteObjectsInRange(service.connection, BucketName, commandKey(endpointID, 0), commandKey(endpointID, maxID))
+}
@@ -0,0 +1,125 @@
+package edgedevice
+
+import (
+	"github.com/boltdb/bolt"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/bolt/internal"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "edge_devices"
+)
+
+// Service represents a service for managing edge device data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateBucket(connection, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// EdgeDevices returns an array containing all the edge devices.
+func (service *Service) EdgeDevices() ([]portainer.EdgeDevice, error) {
+	var edgeDevices = make([]portainer.EdgeDevice, 0)
+
+	err := service.connection.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var edgeDevice portainer.EdgeDevice
+			err := internal.UnmarshalObject(v, &edgeDevice)
+			if err != nil {
+				return err
+			}
+			edgeDevices = append(edgeDevices, edgeDevice)
+		}
+
+		return nil
+	})
+
+	return edgeDevices, err
+}
+
+// EdgeDevice returns an edge device by ID.
+func (service *Service) EdgeDevice(ID portainer.EdgeDeviceID) (*portainer.EdgeDevice, error) {
+	var edgeDevice portainer.EdgeDevice
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, BucketName, identifier, &edgeDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &edgeDevice, nil
+}
+
+// EdgeDeviceByEdgeID returns an edge device by the EdgeID of its agent.
+func (service *Service) EdgeDeviceByEdgeID(edgeID string) (*portainer.EdgeDevice, error) {
+	var edgeDevice *portainer.EdgeDevice
+
+	err := service.connection.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+		cursor := bucket.Cursor()
+
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var device portainer.EdgeDevice
+			err := internal.UnmarshalObject(v, &device)
+			if err != nil {
+				return err
+			}
+
+			if device.EdgeID == edgeID {
+				edgeDevice = &device
+				break
+			}
+		}
+
+		if edgeDevice == nil {
+			return errors.ErrObjectNotFound
+		}
+		return nil
+	})
+
+	return edgeDevice, err
+}
+
+// CreateEdgeDevice assigns an ID to a new edge device and saves it.
+func (service *Service) CreateEdgeDevice(edgeDevice *portainer.EdgeDevice) error {
+	return service.connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		id, _ := bucket.NextSequence()
+		edgeDevice.ID = portainer.EdgeDeviceID(id)
+
+		data, err := internal.MarshalObject(edgeDevice)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(internal.Itob(int(edgeDevice.ID)), data)
+	})
+}
+
+// UpdateEdgeDevice updates an edge device.
+func (service *Service) UpdateEdgeDevice(ID portainer.EdgeDeviceID, edgeDevice *portainer.EdgeDevice) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, BucketName, identifier, edgeDevice)
+}
+
+// DeleteEdgeDevice deletes an edge device.
+func (service *Service) DeleteEdgeDevice(ID portainer.EdgeDeviceID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, BucketName, identifier)
+}
@@ -0,0 +1,96 @@
+package edgejobrun
+
+import (
+	"math"
+
+	"github.com/boltdb/bolt"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/internal"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "edge_job_runs"
+)
+
+// Service represents a service for managing Edge job run data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateBucket(connection, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// runKey returns the key of a run, made of the Edge job and endpoint identifiers followed by the run
+// identifier so that the runs of an Edge job on an endpoint are stored next to each other, in creation order.
+func runKey(edgeJobID portainer.EdgeJobID, endpointID portainer.EndpointID, ID int) []byte {
+	key := append(internal.Itob(int(edgeJobID)), internal.Itob(int(endpointID))...)
+	return append(key, internal.Itob(ID)...)
+}
+
+// EdgeJobRuns returns the runs of an Edge job on every endpoint, ordered by endpoint and creation.
+func (service *Service) EdgeJobRuns(edgeJobID portainer.EdgeJobID) ([]portainer.EdgeJobRun, error) {
+	return service.runsInRange(runKey(edgeJobID, 0, 0), runKey(edgeJobID, math.MaxInt64, math.MaxInt64))
+}
+
+// EdgeJobEndpointRuns returns the runs of an Edge job on an endpoint, ordered by creation.
+func (service *Service) EdgeJobEndpointRuns(edgeJobID portainer.EdgeJobID, endpointID portainer.EndpointID) ([]portainer.EdgeJobRun, error) {
+	return service.runsInRange(runKey(edgeJobID, endpointID, 0), runKey(edgeJobID, endpointID, math.MaxInt64))
+}
+
+func (service *Service) runsInRange(min, max []byte) ([]portainer.EdgeJobRun, error) {
+	var runs = make([]portainer.EdgeJobRun, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, BucketName, min, max, func(data []byte) error {
+		var run portainer.EdgeJobRun
+		err := internal.UnmarshalObject(data, &run)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+
+		return nil
+	})
+
+	return runs, err
+}
+
+// CreateEdgeJobRun assigns an identifier to a run and saves it.
+func (service *Service) CreateEdgeJobRun(run *portainer.EdgeJobRun) error {
+	return service.connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		run.ID = int(id)
+
+		data, err := internal.MarshalObject(run)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(runKey(run.EdgeJobID, run.EndpointID, run.ID), data)
+	})
+}
+
+// DeleteEdgeJobEndpointRuns deletes the runs of an Edge job on an endpoint
+// up to the run identified by maxID (inclusive).
+func (service *Service) DeleteEdgeJobEndpointRuns(edgeJobID portainer.EdgeJobID, endpointID portainer.EndpointID, maxID int) error {
+	return internal.DeleteObjectsInRange(service.connection, BucketName, runKey(edgeJobID, endpointID, 0), runKey(edgeJobID, endpointID, maxID))
+}
+
+// DeleteEdgeJobRuns deletes the runs of an Edge job on every endpoint.
+func (service *Service) DeleteEdgeJobRuns(edgeJobID portainer.EdgeJobID) error {
+	return internal.DeleteObjectsInRange(service.connection, BucketName, runKey(edgeJobID, 0, 0), runKey(edgeJobID, math.MaxInt64, math.MaxInt64))
+}
@@ -41,6 +41,29 @@ func (service *Service) EndpointRelation(endpointID portainer.EndpointID) (*port
 	return &endpointRelation, nil
 }
 
+// EndpointRelations returns an array containing all the endpoint relations.
+func (service *Service) EndpointRelations() ([]portainer.EndpointRelation, error) {
+	var endpointRelations = make([]portainer.EndpointRelation, 0)
+
+	err := service.connection.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var endpointRelation portainer.EndpointRelation
+			err := internal.UnmarshalObject(v, &endpointRelation)
+			if err != nil {
+				return err
+			}
+			endpointRelations = append(endpointRelations, endpointRelation)
+		}
+
+		return nil
+	})
+
+	return endpointRelations, err
+}
+
 // CreateEndpointRelation saves endpointRelation
 func (service *Service) CreateEndpointRelation(endpointRelation *portainer.EndpointRelation) error {
 	return service.connection.Update(func(tx *bolt.Tx) error {
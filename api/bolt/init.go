@@ -45,6 +45,21 @@ func (store *Store) Init() error {
 			EdgeAgentCheckinInterval: portainer.DefaultEdgeAgentCheckinIntervalInSeconds,
 			TemplatesURL:             portainer.DefaultTemplatesURL,
 			UserSessionTimeout:       portainer.DefaultUserSessionTimeout,
+
+			SnapshotHistoryRetention:          portainer.DefaultSnapshotHistoryRetention,
+			SnapshotHistoryDownsampleAfter:    portainer.DefaultSnapshotHistoryDownsampleAfter,
+			SnapshotHistoryDownsampleInterval: portainer.DefaultSnapshotHistoryDownsampleInterval,
+
+			EdgeStackStatusHistoryRetention:  portainer.DefaultEdgeStackStatusHistoryRetention,
+			EdgeStackStatusHistoryMaxEntries: portainer.DefaultEdgeStackStatusHistoryMaxEntries,
+
+			EdgeKeyRotationGracePeriod: portainer.DefaultEdgeKeyRotationGracePeriod,
+
+			EdgeSnapshotPushInterval: portainer.DefaultEdgeSnapshotPushInterval,
+			EdgeTunnelMinPort:        portainer.DefaultEdgeTunnelMinPort,
+			EdgeTunnelMaxPort:        portainer.DefaultEdgeTunnelMaxPort,
+
+			DockerProxyCacheTTL: portainer.DefaultDockerProxyCacheTTL,
 		}
 
 		err = store.SettingsService.UpdateSettings(defaultSettings)
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"bytes"
 	"encoding/binary"
 
 	"github.com/boltdb/bolt"
@@ -55,6 +56,23 @@ func GetObject(connection *DbConnection, bucketName string, key []byte, object i
 	return UnmarshalObject(data, object)
 }
 
+// ForEachObjectInRange is a generic function that calls fn with the raw content of every object
+// whose key is between fromKey and toKey (inclusive), ordered by key.
+func ForEachObjectInRange(connection *DbConnection, bucketName string, fromKey, toKey []byte, fn func(data []byte) error) error {
+	return connection.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(bucketName)).Cursor()
+
+		for k, v := cursor.Seek(fromKey); k != nil && bytes.Compare(k, toKey) <= 0; k, v = cursor.Next() {
+			err := fn(v)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // UpdateObject is a generic function used to update an object inside a bolt database.
 func UpdateObject(connection *DbConnection, bucketName string, key []byte, object interface{}) error {
 	return connection.Update(func(tx *bolt.Tx) error {
@@ -82,6 +100,30 @@ func DeleteObject(connection *DbConnection, bucketName string, key []byte) error
 	})
 }
 
+// DeleteObjectsInRange is a generic function used to delete every object whose key is between
+// fromKey and toKey (inclusive).
+func DeleteObjectsInRange(connection *DbConnection, bucketName string, fromKey, toKey []byte) error {
+	return connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		cursor := bucket.Cursor()
+
+		// keys are collected first as deleting through the cursor would skip entries
+		keys := make([][]byte, 0)
+		for k, _ := cursor.Seek(fromKey); k != nil && bytes.Compare(k, toKey) <= 0; k, _ = cursor.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+
+		for _, k := range keys {
+			err := bucket.Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // GetNextIdentifier is a generic function that returns the specified bucket identifier incremented by 1.
 func GetNextIdentifier(connection *DbConnection, bucketName string) int {
 	var identifier int
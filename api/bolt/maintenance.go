@@ -0,0 +1,268 @@
+package bolt
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+)
+
+const compactionTransactionSize = 65536
+
+type (
+	// MigrationReport describes the changes a migration would apply to the database.
+	MigrationReport struct {
+		FromVersion int
+		ToVersion   int
+		Buckets     []BucketChanges
+	}
+
+	// BucketChanges represents the number of keys created, updated and deleted inside a bucket.
+	BucketChanges struct {
+		Name    string
+		Created int
+		Updated int
+		Deleted int
+	}
+
+	// dryRunFileService prevents migrations from moving files on disk.
+	dryRunFileService struct {
+		portainer.FileService
+	}
+)
+
+func (service dryRunFileService) Rename(oldPath, newPath string) error {
+	return nil
+}
+
+// Compact rewrites the database file stored inside storePath, reclaiming the space
+// left free by deleted data. The database must not be opened by another process.
+func Compact(storePath string) error {
+	databasePath := path.Join(storePath, DatabaseFileName)
+	compactedPath := databasePath + ".compact"
+
+	src, err := bolt.Open(databasePath, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+
+	dst, err := bolt.Open(compactedPath, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		src.Close()
+		return err
+	}
+
+	err = copyDatabase(src, dst)
+	dst.Close()
+	src.Close()
+	if err != nil {
+		os.Remove(compactedPath)
+		return err
+	}
+
+	return os.Rename(compactedPath, databasePath)
+}
+
+// copyDatabase copies every bucket of src into dst, committing every compactionTransactionSize keys
+// to keep memory usage bounded.
+func copyDatabase(src, dst *bolt.DB) error {
+	return src.View(func(srcTx *bolt.Tx) error {
+		return srcTx.ForEach(func(name []byte, srcBucket *bolt.Bucket) error {
+			dstTx, err := dst.Begin(true)
+			if err != nil {
+				return err
+			}
+
+			dstBucket, err := dstTx.CreateBucketIfNotExists(name)
+			if err != nil {
+				dstTx.Rollback()
+				return err
+			}
+			dstBucket.FillPercent = 1.0
+
+			count := 0
+			err = srcBucket.ForEach(func(k, v []byte) error {
+				if count == compactionTransactionSize {
+					err := dstTx.Commit()
+					if err != nil {
+						return err
+					}
+
+					dstTx, err = dst.Begin(true)
+					if err != nil {
+						return err
+					}
+
+					dstBucket = dstTx.Bucket(name)
+					dstBucket.FillPercent = 1.0
+					count = 0
+				}
+				count++
+
+				return dstBucket.Put(k, v)
+			})
+			if err != nil {
+				dstTx.Rollback()
+				return err
+			}
+
+			err = dstBucket.SetSequence(srcBucket.Sequence())
+			if err != nil {
+				dstTx.Rollback()
+				return err
+			}
+
+			return dstTx.Commit()
+		})
+	})
+}
+
+// MigrationDryRun runs the pending migrations against a copy of the database stored inside storePath
+// and reports the changes they would apply. Neither the database nor the files managed by fileService are modified.
+func MigrationDryRun(storePath string, fileService portainer.FileService) (*MigrationReport, error) {
+	databasePath := path.Join(storePath, DatabaseFileName)
+
+	copyPath, err := ioutil.TempDir("", "portainer-migration")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(copyPath)
+
+	err = copyFile(databasePath, path.Join(copyPath, DatabaseFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := readDatabase(databasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := NewStore(copyPath, dryRunFileService{FileService: fileService})
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrationReport{ToVersion: portainer.DBVersion}
+
+	report.FromVersion, err = store.VersionService.DBVersion()
+	if err == errors.ErrObjectNotFound {
+		report.FromVersion = 0
+	} else if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	err = store.MigrateData(false)
+	store.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := readDatabase(path.Join(copyPath, DatabaseFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	report.Buckets = compareDatabases(before, after)
+
+	return report, nil
+}
+
+// readDatabase returns the content of every bucket of a database.
+func readDatabase(databasePath string) (map[string]map[string][]byte, error) {
+	db, err := bolt.Open(databasePath, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	content := make(map[string]map[string][]byte)
+
+	err = db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			values := make(map[string][]byte)
+			content[string(name)] = values
+
+			return bucket.ForEach(func(k, v []byte) error {
+				values[string(k)] = append([]byte(nil), v...)
+				return nil
+			})
+		})
+	})
+
+	return content, err
+}
+
+func compareDatabases(before, after map[string]map[string][]byte) []BucketChanges {
+	names := make(map[string]bool)
+	for name := range before {
+		names[name] = true
+	}
+	for name := range after {
+		names[name] = true
+	}
+
+	changes := make([]BucketChanges, 0)
+	for name := range names {
+		bucketChanges := BucketChanges{Name: name}
+
+		for key, value := range after[name] {
+			previous, ok := before[name][key]
+			if !ok {
+				bucketChanges.Created++
+			} else if !bytes.Equal(previous, value) {
+				bucketChanges.Updated++
+			}
+		}
+
+		for key := range before[name] {
+			if _, ok := after[name][key]; !ok {
+				bucketChanges.Deleted++
+			}
+		}
+
+		if bucketChanges.Created+bucketChanges.Updated+bucketChanges.Deleted > 0 {
+			changes = append(changes, bucketChanges)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,83 @@
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/registry"
+	"github.com/portainer/portainer/api/bolt/version"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceTestStore(t *testing.T) (string, portainer.FileService, *Store) {
+	storePath, err := ioutil.TempDir("", "boltdb")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(storePath) })
+
+	fileService, err := filesystem.NewService(storePath, "")
+	require.NoError(t, err)
+
+	store, err := NewStore(storePath, fileService)
+	require.NoError(t, err)
+	require.NoError(t, store.Open())
+	require.NoError(t, store.Init())
+
+	return storePath, fileService, store
+}
+
+func TestCompact(t *testing.T) {
+	storePath, fileService, store := newMaintenanceTestStore(t)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, store.Tag().CreateTag(&portainer.Tag{Name: "tag"}))
+	}
+	for i := 1; i <= 90; i++ {
+		require.NoError(t, store.Tag().DeleteTag(portainer.TagID(i)))
+	}
+	require.NoError(t, store.Close())
+
+	require.NoError(t, Compact(storePath))
+
+	store, err := NewStore(storePath, fileService)
+	require.NoError(t, err)
+	require.NoError(t, store.Open())
+	defer store.Close()
+
+	tags, err := store.Tag().Tags()
+	require.NoError(t, err)
+	assert.Len(t, tags, 10)
+
+	require.NoError(t, store.Tag().CreateTag(&portainer.Tag{Name: "tag"}))
+	tag, err := store.Tag().Tag(101)
+	require.NoError(t, err, "bucket sequence should be preserved")
+	assert.Equal(t, "tag", tag.Name)
+}
+
+func TestMigrationDryRun(t *testing.T) {
+	storePath, fileService, store := newMaintenanceTestStore(t)
+
+	require.NoError(t, store.Registry().CreateRegistry(&portainer.Registry{Name: "registry"}))
+	require.NoError(t, store.Version().StoreDBVersion(31))
+	require.NoError(t, store.Close())
+
+	report, err := MigrationDryRun(storePath, fileService)
+	require.NoError(t, err)
+
+	assert.Equal(t, 31, report.FromVersion)
+	assert.Equal(t, portainer.DBVersion, report.ToVersion)
+	assert.Contains(t, report.Buckets, BucketChanges{Name: registry.BucketName, Updated: 1})
+	assert.Contains(t, report.Buckets, BucketChanges{Name: version.BucketName, Updated: 1})
+
+	store, err = NewStore(storePath, fileService)
+	require.NoError(t, err)
+	require.NoError(t, store.Open())
+	defer store.Close()
+
+	dbVersion, err := store.Version().DBVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 31, dbVersion, "the database should not be migrated")
+}
@@ -1,71 +1,68 @@
 package migrator
 
 import (
+	"fmt"
+
 	"github.com/boltdb/bolt"
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/bolt/dockerhub"
-	"github.com/portainer/portainer/api/bolt/endpoint"
-	"github.com/portainer/portainer/api/bolt/endpointgroup"
-	"github.com/portainer/portainer/api/bolt/endpointrelation"
 	"github.com/portainer/portainer/api/bolt/extension"
 	plog "github.com/portainer/portainer/api/bolt/log"
-	"github.com/portainer/portainer/api/bolt/registry"
-	"github.com/portainer/portainer/api/bolt/resourcecontrol"
-	"github.com/portainer/portainer/api/bolt/role"
 	"github.com/portainer/portainer/api/bolt/schedule"
-	"github.com/portainer/portainer/api/bolt/settings"
-	"github.com/portainer/portainer/api/bolt/stack"
-	"github.com/portainer/portainer/api/bolt/tag"
-	"github.com/portainer/portainer/api/bolt/teammembership"
-	"github.com/portainer/portainer/api/bolt/user"
-	"github.com/portainer/portainer/api/bolt/version"
 	"github.com/portainer/portainer/api/internal/authorization"
 )
 
 var migrateLog = plog.NewScopedLog("bolt, migrate")
 
+// MinDataStoreDBVersion is the oldest database version that can be migrated without a BoltDB database.
+// The migrations to the previous versions read BoltDB buckets or rely on BoltDB specific services,
+// the migrations from this version only go through the portainer.DataStore services.
+const MinDataStoreDBVersion = 32
+
 type (
 	// Migrator defines a service to migrate data after a Portainer version update.
 	Migrator struct {
 		currentDBVersion        int
 		db                      *bolt.DB
-		endpointGroupService    *endpointgroup.Service
-		endpointService         *endpoint.Service
-		endpointRelationService *endpointrelation.Service
+		endpointGroupService    portainer.EndpointGroupService
+		endpointService         portainer.EndpointService
+		endpointRelationService portainer.EndpointRelationService
 		extensionService        *extension.Service
-		registryService         *registry.Service
-		resourceControlService  *resourcecontrol.Service
-		roleService             *role.Service
+		registryService         portainer.RegistryService
+		resourceControlService  portainer.ResourceControlService
+		roleService             portainer.RoleService
 		scheduleService         *schedule.Service
-		settingsService         *settings.Service
-		stackService            *stack.Service
-		tagService              *tag.Service
-		teamMembershipService   *teammembership.Service
-		userService             *user.Service
-		versionService          *version.Service
+		settingsService         portainer.SettingsService
+		stackService            portainer.StackService
+		tagService              portainer.TagService
+		teamMembershipService   portainer.TeamMembershipService
+		userService             portainer.UserService
+		versionService          portainer.VersionService
 		fileService             portainer.FileService
 		authorizationService    *authorization.Service
 		dockerhubService        *dockerhub.Service
 	}
 
 	// Parameters represents the required parameters to create a new Migrator instance.
+	// DB, ExtensionService, ScheduleService and DockerhubService are only required to migrate
+	// a database older than MinDataStoreDBVersion.
 	Parameters struct {
 		DB                      *bolt.DB
 		DatabaseVersion         int
-		EndpointGroupService    *endpointgroup.Service
-		EndpointService         *endpoint.Service
-		EndpointRelationService *endpointrelation.Service
+		EndpointGroupService    portainer.EndpointGroupService
+		EndpointService         portainer.EndpointService
+		EndpointRelationService portainer.EndpointRelationService
 		ExtensionService        *extension.Service
-		RegistryService         *registry.Service
-		ResourceControlService  *resourcecontrol.Service
-		RoleService             *role.Service
+		RegistryService         portainer.RegistryService
+		ResourceControlService  portainer.ResourceControlService
+		RoleService             portainer.RoleService
 		ScheduleService         *schedule.Service
-		SettingsService         *settings.Service
-		StackService            *stack.Service
-		TagService              *tag.Service
-		TeamMembershipService   *teammembership.Service
-		UserService             *user.Service
-		VersionService          *version.Service
+		SettingsService         portainer.SettingsService
+		StackService            portainer.StackService
+		TagService              portainer.TagService
+		TeamMembershipService   portainer.TeamMembershipService
+		UserService             portainer.UserService
+		VersionService          portainer.VersionService
 		FileService             portainer.FileService
 		AuthorizationService    *authorization.Service
 		DockerhubService        *dockerhub.Service
@@ -99,6 +96,10 @@ func NewMigrator(parameters *Parameters) *Migrator {
 
 // Migrate checks the database version and migrate the existing data to the most recent data model.
 func (m *Migrator) Migrate() error {
+	if m.db == nil && m.currentDBVersion < MinDataStoreDBVersion {
+		return fmt.Errorf("unable to migrate a database older than version %d without BoltDB", MinDataStoreDBVersion)
+	}
+
 	// Portainer < 1.12
 	if m.currentDBVersion < 1 {
 		err := m.updateAdminUserToDBVersion1()
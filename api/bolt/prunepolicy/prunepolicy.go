@@ -0,0 +1,95 @@
+package prunepolicy
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/internal"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "prune_policies"
+)
+
+// Service represents a service for managing prune policy data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateBucket(connection, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// PrunePolicies returns an array containing all the prune policies.
+func (service *Service) PrunePolicies() ([]portainer.PrunePolicy, error) {
+	var policies = make([]portainer.PrunePolicy, 0)
+
+	err := service.connection.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		cursor := bucket.Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			var policy portainer.PrunePolicy
+			err := internal.UnmarshalObject(v, &policy)
+			if err != nil {
+				return err
+			}
+			policies = append(policies, policy)
+		}
+
+		return nil
+	})
+
+	return policies, err
+}
+
+// PrunePolicy returns a prune policy by ID.
+func (service *Service) PrunePolicy(ID portainer.PrunePolicyID) (*portainer.PrunePolicy, error) {
+	var policy portainer.PrunePolicy
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, BucketName, identifier, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// CreatePrunePolicy creates a new prune policy.
+func (service *Service) CreatePrunePolicy(policy *portainer.PrunePolicy) error {
+	return service.connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		id, _ := bucket.NextSequence()
+		policy.ID = portainer.PrunePolicyID(id)
+
+		data, err := internal.MarshalObject(policy)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(internal.Itob(int(policy.ID)), data)
+	})
+}
+
+// UpdatePrunePolicy updates a prune policy.
+func (service *Service) UpdatePrunePolicy(ID portainer.PrunePolicyID, policy *portainer.PrunePolicy) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, BucketName, identifier, policy)
+}
+
+// DeletePrunePolicy deletes a prune policy.
+func (service *Service) DeletePrunePolicy(ID portainer.PrunePolicyID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, BucketName, identifier)
+}
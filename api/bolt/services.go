@@ -4,22 +4,31 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/bolt/customtemplate"
 	"github.com/portainer/portainer/api/bolt/dockerhub"
+	"github.com/portainer/portainer/api/bolt/dockerpolicy"
+	"github.com/portainer/portainer/api/bolt/edgeasynccommand"
+	"github.com/portainer/portainer/api/bolt/edgedevice"
 	"github.com/portainer/portainer/api/bolt/edgegroup"
 	"github.com/portainer/portainer/api/bolt/edgejob"
+	"github.com/portainer/portainer/api/bolt/edgejobrun"
 	"github.com/portainer/portainer/api/bolt/edgestack"
+	"github.com/portainer/portainer/api/bolt/edgestackstatushistory"
 	"github.com/portainer/portainer/api/bolt/endpoint"
 	"github.com/portainer/portainer/api/bolt/endpointgroup"
 	"github.com/portainer/portainer/api/bolt/endpointrelation"
 	"github.com/portainer/portainer/api/bolt/extension"
+	"github.com/portainer/portainer/api/bolt/prunepolicy"
+	"github.com/portainer/portainer/api/bolt/prunepolicyrun"
 	"github.com/portainer/portainer/api/bolt/registry"
 	"github.com/portainer/portainer/api/bolt/resourcecontrol"
 	"github.com/portainer/portainer/api/bolt/role"
 	"github.com/portainer/portainer/api/bolt/schedule"
 	"github.com/portainer/portainer/api/bolt/settings"
+	"github.com/portainer/portainer/api/bolt/snapshothistory"
 	"github.com/portainer/portainer/api/bolt/stack"
 	"github.com/portainer/portainer/api/bolt/tag"
 	"github.com/portainer/portainer/api/bolt/team"
 	"github.com/portainer/portainer/api/bolt/teammembership"
+	"github.com/portainer/portainer/api/bolt/tunnelevent"
 	"github.com/portainer/portainer/api/bolt/tunnelserver"
 	"github.com/portainer/portainer/api/bolt/user"
 	"github.com/portainer/portainer/api/bolt/version"
@@ -39,6 +48,12 @@ func (store *Store) initServices() error {
 	}
 	store.CustomTemplateService = customTemplateService
 
+	dockerPolicyService, err := dockerpolicy.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.DockerPolicyService = dockerPolicyService
+
 	dockerhubService, err := dockerhub.NewService(store.connection)
 	if err != nil {
 		return err
@@ -51,6 +66,24 @@ func (store *Store) initServices() error {
 	}
 	store.EdgeStackService = edgeStackService
 
+	edgeStackStatusHistoryService, err := edgestackstatushistory.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeStackStatusHistoryService = edgeStackStatusHistoryService
+
+	edgeAsyncCommandService, err := edgeasynccommand.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeAsyncCommandService = edgeAsyncCommandService
+
+	edgeDeviceService, err := edgedevice.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeDeviceService = edgeDeviceService
+
 	edgeGroupService, err := edgegroup.NewService(store.connection)
 	if err != nil {
 		return err
@@ -63,6 +96,12 @@ func (store *Store) initServices() error {
 	}
 	store.EdgeJobService = edgeJobService
 
+	edgeJobRunService, err := edgejobrun.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeJobRunService = edgeJobRunService
+
 	endpointgroupService, err := endpointgroup.NewService(store.connection)
 	if err != nil {
 		return err
@@ -87,6 +126,18 @@ func (store *Store) initServices() error {
 	}
 	store.ExtensionService = extensionService
 
+	prunePolicyService, err := prunepolicy.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.PrunePolicyService = prunePolicyService
+
+	prunePolicyRunService, err := prunepolicyrun.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.PrunePolicyRunService = prunePolicyRunService
+
 	registryService, err := registry.NewService(store.connection)
 	if err != nil {
 		return err
@@ -105,6 +156,12 @@ func (store *Store) initServices() error {
 	}
 	store.SettingsService = settingsService
 
+	snapshotHistoryService, err := snapshothistory.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.SnapshotHistoryService = snapshotHistoryService
+
 	stackService, err := stack.NewService(store.connection)
 	if err != nil {
 		return err
@@ -135,6 +192,12 @@ func (store *Store) initServices() error {
 	}
 	store.TunnelServerService = tunnelServerService
 
+	tunnelEventService, err := tunnelevent.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.TunnelEventService = tunnelEventService
+
 	userService, err := user.NewService(store.connection)
 	if err != nil {
 		return err
@@ -167,6 +230,21 @@ func (store *Store) CustomTemplate() portainer.CustomTemplateService {
 	return store.CustomTemplateService
 }
 
+// DockerPolicy gives access to the DockerPolicy data management layer
+func (store *Store) DockerPolicy() portainer.DockerPolicyService {
+	return store.DockerPolicyService
+}
+
+// EdgeAsyncCommand gives access to the EdgeAsyncCommand data management layer
+func (store *Store) EdgeAsyncCommand() portainer.EdgeAsyncCommandService {
+	return store.EdgeAsyncCommandService
+}
+
+// EdgeDevice gives access to the EdgeDevice data management layer
+func (store *Store) EdgeDevice() portainer.EdgeDeviceService {
+	return store.EdgeDeviceService
+}
+
 // EdgeGroup gives access to the EdgeGroup data management layer
 func (store *Store) EdgeGroup() portainer.EdgeGroupService {
 	return store.EdgeGroupService
@@ -177,11 +255,21 @@ func (store *Store) EdgeJob() portainer.EdgeJobService {
 	return store.EdgeJobService
 }
 
+// EdgeJobRun gives access to the EdgeJobRun data management layer
+func (store *Store) EdgeJobRun() portainer.EdgeJobRunService {
+	return store.EdgeJobRunService
+}
+
 // EdgeStack gives access to the EdgeStack data management layer
 func (store *Store) EdgeStack() portainer.EdgeStackService {
 	return store.EdgeStackService
 }
 
+// EdgeStackStatusHistory gives access to the EdgeStackStatusHistory data management layer
+func (store *Store) EdgeStackStatusHistory() portainer.EdgeStackStatusHistoryService {
+	return store.EdgeStackStatusHistoryService
+}
+
 // Endpoint gives access to the Endpoint data management layer
 func (store *Store) Endpoint() portainer.EndpointService {
 	return store.EndpointService
@@ -197,6 +285,16 @@ func (store *Store) EndpointRelation() portainer.EndpointRelationService {
 	return store.EndpointRelationService
 }
 
+// PrunePolicy gives access to the PrunePolicy data management layer
+func (store *Store) PrunePolicy() portainer.PrunePolicyService {
+	return store.PrunePolicyService
+}
+
+// PrunePolicyRun gives access to the PrunePolicyRun data management layer
+func (store *Store) PrunePolicyRun() portainer.PrunePolicyRunService {
+	return store.PrunePolicyRunService
+}
+
 // Registry gives access to the Registry data management layer
 func (store *Store) Registry() portainer.RegistryService {
 	return store.RegistryService
@@ -217,6 +315,11 @@ func (store *Store) Settings() portainer.SettingsService {
 	return store.SettingsService
 }
 
+// SnapshotHistory gives access to the SnapshotHistory data management layer
+func (store *Store) SnapshotHistory() portainer.SnapshotHistoryService {
+	return store.SnapshotHistoryService
+}
+
 // Stack gives access to the Stack data management layer
 func (store *Store) Stack() portainer.StackService {
 	return store.StackService
@@ -242,6 +345,11 @@ func (store *Store) TunnelServer() portainer.TunnelServerService {
 	return store.TunnelServerService
 }
 
+// TunnelEvent gives access to the TunnelEvent data management layer
+func (store *Store) TunnelEvent() portainer.TunnelEventService {
+	return store.TunnelEventService
+}
+
 // User gives access to the User data management layer
 func (store *Store) User() portainer.UserService {
 	return store.UserService
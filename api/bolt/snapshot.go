@@ -0,0 +1,116 @@
+package bolt
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+)
+
+// MinRollbackDBVersion is the oldest database version a snapshot can be restored to.
+// The migration to version 12 renames the project folders of the stacks, which a database snapshot does not restore.
+const MinRollbackDBVersion = 12
+
+// BackupFileName returns the name of the snapshot taken before migrating a database from version.
+func BackupFileName(version int) string {
+	return fmt.Sprintf("%s.v%d.bak", DatabaseFileName, version)
+}
+
+// backupBeforeMigration copies the database to a snapshot stamped with its current version
+// so that it can be restored if the migration fails or if Portainer is downgraded.
+// A previous snapshot of the same version is replaced.
+func (store *Store) backupBeforeMigration(version int) (string, error) {
+	backupPath := path.Join(store.path, BackupFileName(version))
+
+	backup, err := os.OpenFile(backupPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	err = store.BackupTo(backup)
+	if err != nil {
+		backup.Close()
+		os.Remove(backupPath)
+		return "", err
+	}
+
+	err = backup.Close()
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("Database snapshot created before migration: %s\n", backupPath)
+
+	return backupPath, nil
+}
+
+// restoreBackup replaces the database with a snapshot and re-opens the store.
+func (store *Store) restoreBackup(backupPath string) error {
+	err := store.Close()
+	if err != nil {
+		return err
+	}
+
+	err = replaceFile(backupPath, path.Join(store.path, DatabaseFileName))
+	if err != nil {
+		return err
+	}
+
+	return store.Open()
+}
+
+// RollbackToVersion restores the snapshot taken before the last migration from version.
+// The database must not be opened by another process.
+func RollbackToVersion(storePath string, version int) error {
+	if version < MinRollbackDBVersion {
+		return fmt.Errorf("unable to roll back to version %d, the migration to version %d renamed the stack folders on disk", version, MinRollbackDBVersion)
+	}
+
+	backupPath := path.Join(storePath, BackupFileName(version))
+
+	_, err := os.Stat(backupPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("unable to find a database snapshot for version %d", version)
+	} else if err != nil {
+		return err
+	}
+
+	return replaceFile(backupPath, path.Join(storePath, DatabaseFileName))
+}
+
+// replaceFile atomically replaces a file with a copy of another one: the copy is written and synced
+// to a temporary file of the same folder, then renamed over the destination.
+// The destination is left untouched when the copy fails.
+func replaceFile(sourcePath, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	temporary, err := ioutil.TempFile(path.Dir(destinationPath), path.Base(destinationPath)+".restore-*")
+	if err != nil {
+		return err
+	}
+	temporaryPath := temporary.Name()
+
+	_, err = io.Copy(temporary, source)
+	if err == nil {
+		err = temporary.Sync()
+	}
+	if err != nil {
+		temporary.Close()
+		os.Remove(temporaryPath)
+		return err
+	}
+
+	err = temporary.Close()
+	if err != nil {
+		os.Remove(temporaryPath)
+		return err
+	}
+
+	return os.Rename(temporaryPath, destinationPath)
+}
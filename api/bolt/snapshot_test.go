@@ -0,0 +1,108 @@
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateData_CreatesSnapshot(t *testing.T) {
+	storePath, fileService, store := newMaintenanceTestStore(t)
+
+	require.NoError(t, store.Version().StoreDBVersion(31))
+	require.NoError(t, store.Close())
+
+	store, err := NewStore(storePath, fileService)
+	require.NoError(t, err)
+	require.NoError(t, store.Open())
+	require.NoError(t, store.MigrateData(false))
+
+	dbVersion, err := store.Version().DBVersion()
+	require.NoError(t, err)
+	assert.Equal(t, portainer.DBVersion, dbVersion)
+	require.NoError(t, store.Close())
+
+	_, err = os.Stat(path.Join(storePath, BackupFileName(31)))
+	require.NoError(t, err, "a snapshot of version 31 should exist")
+
+	require.NoError(t, RollbackToVersion(storePath, 31))
+
+	store, err = NewStore(storePath, fileService)
+	require.NoError(t, err)
+	require.NoError(t, store.Open())
+	defer store.Close()
+
+	dbVersion, err = store.Version().DBVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 31, dbVersion)
+}
+
+func TestRestoreBackup(t *testing.T) {
+	_, _, store := newMaintenanceTestStore(t)
+	defer store.Close()
+
+	require.NoError(t, store.Tag().CreateTag(&portainer.Tag{Name: "before"}))
+
+	backupPath, err := store.backupBeforeMigration(31)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Tag().CreateTag(&portainer.Tag{Name: "after"}))
+	require.NoError(t, store.restoreBackup(backupPath))
+
+	tags, err := store.Tag().Tags()
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Equal(t, "before", tags[0].Name)
+}
+
+func TestRollbackToVersion_MissingSnapshot(t *testing.T) {
+	storePath, _, store := newMaintenanceTestStore(t)
+	require.NoError(t, store.Close())
+
+	assert.Error(t, RollbackToVersion(storePath, 12))
+}
+
+func TestRollbackToVersion_RefusesVersionsWithRenamedStackFolders(t *testing.T) {
+	storePath, _, store := newMaintenanceTestStore(t)
+	require.NoError(t, store.Close())
+
+	backupPath := path.Join(storePath, BackupFileName(MinRollbackDBVersion-1))
+	require.NoError(t, ioutil.WriteFile(backupPath, []byte("snapshot"), 0600))
+
+	assert.Error(t, RollbackToVersion(storePath, MinRollbackDBVersion-1))
+
+	content, err := ioutil.ReadFile(path.Join(storePath, DatabaseFileName))
+	require.NoError(t, err)
+	assert.NotEqual(t, "snapshot", string(content), "the database should be left untouched")
+}
+
+func TestReplaceFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sourcePath := path.Join(dir, "source")
+	destinationPath := path.Join(dir, "destination")
+	require.NoError(t, ioutil.WriteFile(sourcePath, []byte("new"), 0600))
+	require.NoError(t, ioutil.WriteFile(destinationPath, []byte("old content"), 0600))
+
+	require.NoError(t, replaceFile(sourcePath, destinationPath))
+
+	content, err := ioutil.ReadFile(destinationPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "the temporary file should have been renamed")
+
+	assert.Error(t, replaceFile(path.Join(dir, "missing"), destinationPath))
+	content, err = ioutil.ReadFile(destinationPath)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(content), "the destination should be left untouched when the copy fails")
+}
@@ -0,0 +1,81 @@
+package snapshothistory
+
+import (
+	"math"
+
+	"github.com/boltdb/bolt"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/internal"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "snapshot_history"
+)
+
+// Service represents a service for managing endpoint snapshot history data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateBucket(connection, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// entryKey returns the key of an entry, made of the endpoint identifier followed by the entry time
+// so that the entries of an endpoint are stored next to each other, ordered by time.
+// The key ends with a sequence number as several entries of an endpoint can be recorded within the same second.
+func entryKey(endpointID portainer.EndpointID, time int64, sequence int) []byte {
+	key := append(internal.Itob(int(endpointID)), internal.Itob(int(time))...)
+	return append(key, internal.Itob(sequence)...)
+}
+
+// SnapshotHistory returns the history entries of an endpoint between from and to (inclusive), ordered by time.
+func (service *Service) SnapshotHistory(endpointID portainer.EndpointID, from, to int64) ([]portainer.SnapshotHistoryEntry, error) {
+	var entries = make([]portainer.SnapshotHistoryEntry, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, BucketName, entryKey(endpointID, from, 0), entryKey(endpointID, to, math.MaxInt64), func(data []byte) error {
+		var entry portainer.SnapshotHistoryEntry
+		err := internal.UnmarshalObject(data, &entry)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// CreateSnapshotHistoryEntry saves a history entry.
+func (service *Service) CreateSnapshotHistoryEntry(entry *portainer.SnapshotHistoryEntry) error {
+	return service.connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		sequence, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		data, err := internal.MarshalObject(entry)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(entryKey(entry.EndpointID, entry.Time, int(sequence)), data)
+	})
+}
+
+// DeleteSnapshotHistory deletes the history entries of an endpoint between from and to (inclusive).
+func (service *Service) DeleteSnapshotHistory(endpointID portainer.EndpointID, from, to int64) error {
+	return internal.DeleteObjectsInRange(service.connection, BucketName, entryKey(endpointID, from, 0), entryKey(endpointID, to, math.MaxInt64))
+}
@@ -0,0 +1,80 @@
+package tunnelevent
+
+import (
+	"math"
+
+	"github.com/boltdb/bolt"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/internal"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "tunnel_events"
+)
+
+// Service represents a service for managing tunnel event data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateBucket(connection, BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// eventKey returns the key of an event, made of the endpoint identifier followed by the event
+// identifier so that the events of an endpoint are stored next to each other, in creation order.
+func eventKey(endpointID portainer.EndpointID, ID int) []byte {
+	return append(internal.Itob(int(endpointID)), internal.Itob(ID)...)
+}
+
+// TunnelEvents returns the tunnel events of an endpoint, ordered by creation.
+func (service *Service) TunnelEvents(endpointID portainer.EndpointID) ([]portainer.TunnelEvent, error) {
+	var events = make([]portainer.TunnelEvent, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, BucketName, eventKey(endpointID, 0), eventKey(endpointID, math.MaxInt64), func(data []byte) error {
+		var event portainer.TunnelEvent
+		err := internal.UnmarshalObject(data, &event)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+
+		return nil
+	})
+
+	return events, err
+}
+
+// CreateTunnelEvent assigns an identifier to a tunnel event and saves it.
+func (service *Service) CreateTunnelEvent(event *portainer.TunnelEvent) error {
+	return service.connection.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(BucketName))
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		event.ID = int(id)
+
+		data, err := internal.MarshalObject(event)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(eventKey(event.EndpointID, event.ID), data)
+	})
+}
+
+// DeleteTunnelEvents deletes the tunnel events of an endpoint up to the event identified by maxID (inclusive).
+func (service *Service) DeleteTunnelEvents(endpointID portainer.EndpointID, maxID int) error {
+	return internal.DeleteObjectsInRange(service.connection, BucketName, eventKey(endpointID, 0), eventKey(endpointID, maxID))
+}
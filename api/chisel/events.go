@@ -0,0 +1,52 @@
+package chisel
+
+import (
+	"log"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// recordTunnelEvent stores the transition of a tunnel from its previous status, when the status changed
+func (service *Service) recordTunnelEvent(endpointID portainer.EndpointID, previousStatus string, tunnel *portainer.TunnelDetails, reason string) {
+	if previousStatus == tunnel.Status {
+		return
+	}
+
+	service.createTunnelEvent(endpointID, previousStatus, tunnel.Status, tunnel.Port, reason)
+}
+
+// createTunnelEvent stores a tunnel transition and deletes the oldest events of the endpoint.
+// Failures are only logged as they must not affect the tunnel.
+func (service *Service) createTunnelEvent(endpointID portainer.EndpointID, previousStatus, status string, port int, reason string) {
+	event := &portainer.TunnelEvent{
+		EndpointID:     endpointID,
+		PreviousStatus: previousStatus,
+		Status:         status,
+		Port:           port,
+		Reason:         reason,
+		Time:           time.Now().Unix(),
+	}
+
+	err := service.dataStore.TunnelEvent().CreateTunnelEvent(event)
+	if err != nil {
+		log.Printf("[WARN] [chisel,events] [endpoint_id: %d] [message: unable to store tunnel event] [error: %s]", endpointID, err)
+		return
+	}
+
+	events, err := service.dataStore.TunnelEvent().TunnelEvents(endpointID)
+	if err != nil {
+		log.Printf("[WARN] [chisel,events] [endpoint_id: %d] [message: unable to retrieve tunnel events] [error: %s]", endpointID, err)
+		return
+	}
+
+	if len(events) <= portainer.DefaultTunnelEventsMaxEntries {
+		return
+	}
+
+	maxID := events[len(events)-portainer.DefaultTunnelEventsMaxEntries-1].ID
+	err = service.dataStore.TunnelEvent().DeleteTunnelEvents(endpointID, maxID)
+	if err != nil {
+		log.Printf("[WARN] [chisel,events] [endpoint_id: %d] [message: unable to delete old tunnel events] [error: %s]", endpointID, err)
+	}
+}
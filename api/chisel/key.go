@@ -10,12 +10,21 @@ import (
 // GenerateEdgeKey will generate a key that can be used by an Edge agent to register with a Portainer instance.
 // The key represents the following data in this particular format:
 // portainer_instance_url|tunnel_server_addr|tunnel_server_fingerprint|endpoint_ID
+// A key with an endpoint identifier of 0 is a fleet key, shared by the agents enrolling through the waiting room.
+// The secrets of rotated keys and of the fleet key are never part of the key, agents present them in a dedicated header.
 // The key returned by this function is a base64 encoded version of the data.
 func (service *Service) GenerateEdgeKey(url, host string, endpointIdentifier int) string {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	return generateEdgeKey(url, fmt.Sprintf("%s:%s", host, service.serverPort), service.serverFingerprint, endpointIdentifier)
+}
+
+func generateEdgeKey(url, tunnelServerAddr, fingerprint string, endpointIdentifier int) string {
 	keyInformation := []string{
 		url,
-		fmt.Sprintf("%s:%s", host, service.serverPort),
-		service.serverFingerprint,
+		tunnelServerAddr,
+		fingerprint,
 		strconv.Itoa(endpointIdentifier),
 	}
 
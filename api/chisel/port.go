@@ -0,0 +1,136 @@
+package chisel
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// tunnelPortRange returns the range of ports that can be allocated to the tunnels.
+// Settings created before the range was configurable use the dynamic port range, 49152 to 65535.
+func tunnelPortRange(settings *portainer.Settings) (int, int) {
+	minPort, maxPort := settings.EdgeTunnelMinPort, settings.EdgeTunnelMaxPort
+	if minPort == 0 {
+		minPort = portainer.DefaultEdgeTunnelMinPort
+	}
+	if maxPort == 0 {
+		maxPort = portainer.DefaultEdgeTunnelMaxPort
+	}
+	return minPort, maxPort
+}
+
+// allocatePort returns the port to use for the tunnel of the endpoint, portMu must be held.
+// The port persisted with the endpoint is reused when it is still inside the tunnel port range, is not used by
+// an open tunnel and can be bound, so that the port of an endpoint stays the same across tunnels and restarts.
+// Otherwise, a free port of the range is picked, starting at a random offset, and persisted with the endpoint.
+// The ports persisted with the other endpoints are only a preference: they are avoided while other ports are
+// free, so that a range smaller than the number of endpoints still serves the open tunnels.
+func (service *Service) allocatePort(endpoint *portainer.Endpoint) (int, error) {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return 0, err
+	}
+	minPort, maxPort := tunnelPortRange(settings)
+
+	reservedPorts := service.reservedPorts(endpoint.ID)
+
+	port := endpoint.EdgeTunnelPort
+	if port >= minPort && port <= maxPort && !reservedPorts[port] && isPortBindable(port) {
+		return port, nil
+	}
+
+	preferredPorts, err := service.preferredPorts(endpoint.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	port = 0
+	size := maxPort - minPort + 1
+	offset := rand.Intn(size)
+	for _, avoidPreferredPorts := range []bool{true, false} {
+		for i := 0; i < size && port == 0; i++ {
+			candidate := minPort + (offset+i)%size
+			if !reservedPorts[candidate] && !(avoidPreferredPorts && preferredPorts[candidate]) && isPortBindable(candidate) {
+				port = candidate
+			}
+		}
+	}
+
+	if port == 0 {
+		return 0, fmt.Errorf("unable to allocate a tunnel port, all the ports of the range %d-%d are either used by open tunnels or in use on the host", minPort, maxPort)
+	}
+
+	if endpoint.EdgeTunnelPort != 0 {
+		log.Printf("[INFO] [chisel,port] [endpoint_id: %d] [message: tunnel port changed] [previous_port: %d] [port: %d]", endpoint.ID, endpoint.EdgeTunnelPort, port)
+	}
+
+	err = service.persistPort(endpoint.ID, port)
+	if err != nil {
+		return 0, err
+	}
+	endpoint.EdgeTunnelPort = port
+
+	return port, nil
+}
+
+// persistPort persists the tunnel port of the endpoint, portMu must be held. The endpoint is read again
+// as it may have been updated since the allocation started, only its tunnel port is changed.
+func (service *Service) persistPort(endpointID portainer.EndpointID, port int) error {
+	endpoint, err := service.dataStore.Endpoint().Endpoint(endpointID)
+	if err != nil {
+		return err
+	}
+
+	endpoint.EdgeTunnelPort = port
+
+	return service.dataStore.Endpoint().UpdateEndpoint(endpoint.ID, endpoint)
+}
+
+// reservedPorts returns the ports that cannot be allocated to the tunnel of the endpoint, the ports of the
+// open tunnels of the other endpoints.
+func (service *Service) reservedPorts(endpointID portainer.EndpointID) map[int]bool {
+	ports := make(map[int]bool)
+
+	for item := range service.tunnelDetailsMap.IterBuffered() {
+		tunnel := item.Val.(*portainer.TunnelDetails)
+		if tunnel.Port != 0 && item.Key != strconv.Itoa(int(endpointID)) {
+			ports[tunnel.Port] = true
+		}
+	}
+
+	return ports
+}
+
+// preferredPorts returns the ports persisted with the other endpoints, which they reuse when their tunnel is opened.
+func (service *Service) preferredPorts(endpointID portainer.EndpointID) (map[int]bool, error) {
+	ports := make(map[int]bool)
+
+	endpoints, err := service.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.ID != endpointID && endpoint.EdgeTunnelPort != 0 {
+			ports[endpoint.EdgeTunnelPort] = true
+		}
+	}
+
+	return ports, nil
+}
+
+// isPortBindable verifies that the reverse tunnel of an agent will be able to listen on the port,
+// the remote of a tunnel listens on every interface of the host
+func isPortBindable(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+
+	listener.Close()
+	return true
+}
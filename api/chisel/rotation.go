@@ -0,0 +1,288 @@
+package chisel
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/dchest/uniuri"
+	chserver "github.com/jpillora/chisel/server"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+// TunnelServerFingerprints returns the fingerprint of the tunnel server key and, while a rotation
+// of the key is pending, the fingerprint of the key replacing it.
+func (service *Service) TunnelServerFingerprints() (string, string) {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
+	return service.serverFingerprint, service.nextServerFingerprint
+}
+
+// RotateEdgeKey generates a new secret for the edge key of an endpoint. The edge key itself is unchanged, the agent
+// presents the secret in the X-PortainerAgent-EdgeKeySecret header. The previous secret is still accepted for the grace
+// period, unless the endpoint was revoked. The updated endpoint is persisted and returned.
+func (service *Service) RotateEdgeKey(endpointID portainer.EndpointID, gracePeriod time.Duration) (*portainer.Endpoint, error) {
+	endpoint, err := service.dataStore.Endpoint().Endpoint(endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint.Type != portainer.EdgeAgentOnDockerEnvironment && endpoint.Type != portainer.EdgeAgentOnKubernetesEnvironment {
+		return nil, errors.New("the endpoint is not an Edge endpoint")
+	}
+
+	secret := uniuri.NewLen(32)
+	edge.RotateEdgeCredentials(&endpoint.EdgeCredentials, secret, gracePeriod, time.Now())
+
+	err = service.dataStore.Endpoint().UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// RotateTunnelServerKey generates a new private key seed for the tunnel server. The fingerprint of the new key is
+// distributed to the edge agents at check-in until the end of the grace period, when the tunnel server restarts
+// with the new key. Established tunnels are closed by the restart and opened again on demand.
+func (service *Service) RotateTunnelServerKey(gracePeriod time.Duration) error {
+	keySeed := uniuri.NewLen(16)
+
+	nextServer, err := chserver.NewServer(&chserver.Config{KeySeed: keySeed})
+	if err != nil {
+		return err
+	}
+
+	info, err := service.dataStore.TunnelServer().Info()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	info.NextPrivateKeySeed = keySeed
+	info.NextPrivateKeySeedActivation = now.Add(gracePeriod).Unix()
+
+	err = service.dataStore.TunnelServer().UpdateInfo(info)
+	if err != nil {
+		return err
+	}
+
+	service.mu.Lock()
+	service.nextServerFingerprint = nextServer.GetFingerprint()
+	service.mu.Unlock()
+
+	if gracePeriod > 0 {
+		return nil
+	}
+
+	return service.activateNextPrivateKeySeed(now)
+}
+
+// rotateKeys activates the pending private key seed of the tunnel server and applies the scheduled rotations
+func (service *Service) rotateKeys() {
+	now := time.Now()
+
+	err := service.activateNextPrivateKeySeed(now)
+	if err != nil {
+		log.Printf("[ERROR] [chisel,rotation] [message: unable to activate the next tunnel server key] [error: %s]", err)
+	}
+
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		log.Printf("[ERROR] [chisel,rotation] [message: unable to retrieve the settings] [error: %s]", err)
+		return
+	}
+
+	gracePeriod := parseDuration(settings.EdgeKeyRotationGracePeriod)
+	if gracePeriod == 0 {
+		gracePeriod = parseDuration(portainer.DefaultEdgeKeyRotationGracePeriod)
+	}
+
+	interval := parseDuration(settings.TunnelServerKeyRotationInterval)
+	if interval > 0 {
+		info, err := service.dataStore.TunnelServer().Info()
+		if err != nil {
+			log.Printf("[ERROR] [chisel,rotation] [message: unable to retrieve the tunnel server information] [error: %s]", err)
+		} else if info.NextPrivateKeySeed == "" && now.Sub(time.Unix(info.RotationDate, 0)) >= interval {
+			err := service.RotateTunnelServerKey(gracePeriod)
+			if err != nil {
+				log.Printf("[ERROR] [chisel,rotation] [message: unable to rotate the tunnel server key] [error: %s]", err)
+			}
+		}
+	}
+
+	interval = parseDuration(settings.EdgeKeyRotationInterval)
+	if interval <= 0 {
+		return
+	}
+
+	endpoints, err := service.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		log.Printf("[ERROR] [chisel,rotation] [message: unable to retrieve the endpoints] [error: %s]", err)
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.EdgeKey == "" || endpoint.EdgeCredentials.Revoked || now.Sub(time.Unix(endpoint.EdgeCredentials.RotationDate, 0)) < interval {
+			continue
+		}
+
+		_, err := service.RotateEdgeKey(endpoint.ID, gracePeriod)
+		if err != nil {
+			log.Printf("[ERROR] [chisel,rotation] [endpoint_id: %d] [message: unable to rotate the edge key] [error: %s]", endpoint.ID, err)
+		}
+	}
+}
+
+// activateNextPrivateKeySeed replaces the private key seed of the tunnel server by the next one once its activation
+// time is reached. A running tunnel server is restarted and the edge keys are updated with the new fingerprint.
+func (service *Service) activateNextPrivateKeySeed(now time.Time) error {
+	info, err := service.dataStore.TunnelServer().Info()
+	if err == bolterrors.ErrObjectNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.NextPrivateKeySeed == "" || now.Unix() < info.NextPrivateKeySeedActivation {
+		return nil
+	}
+
+	info.PrivateKeySeed = info.NextPrivateKeySeed
+	info.RotationDate = now.Unix()
+	info.NextPrivateKeySeed = ""
+	info.NextPrivateKeySeedActivation = 0
+
+	err = service.dataStore.TunnelServer().UpdateInfo(info)
+	if err != nil {
+		return err
+	}
+
+	service.mu.Lock()
+	service.nextServerFingerprint = ""
+	err = service.restartChiselServer(info.PrivateKeySeed)
+	fingerprint := service.serverFingerprint
+	service.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] [chisel,rotation] [fingerprint: %s] [message: tunnel server key rotated]", fingerprint)
+
+	return service.updateEdgeKeysFingerprint(fingerprint)
+}
+
+// loadNextServerFingerprint computes the fingerprint of the pending private key seed
+func (service *Service) loadNextServerFingerprint() error {
+	info, err := service.dataStore.TunnelServer().Info()
+	if err != nil {
+		return err
+	}
+
+	if info.NextPrivateKeySeed == "" {
+		return nil
+	}
+
+	nextServer, err := chserver.NewServer(&chserver.Config{KeySeed: info.NextPrivateKeySeed})
+	if err != nil {
+		return err
+	}
+
+	service.mu.Lock()
+	service.nextServerFingerprint = nextServer.GetFingerprint()
+	service.mu.Unlock()
+
+	return nil
+}
+
+// restartChiselServer replaces the tunnel server by a server using a new private key, mu must be held.
+// The credentials of the tunnels only exist in the previous server, the tunnels are set back to idle.
+func (service *Service) restartChiselServer(keySeed string) error {
+	err := service.chiselServer.Close()
+	if err != nil {
+		log.Printf("[WARN] [chisel,rotation] [message: unable to stop the tunnel server] [error: %s]", err)
+	}
+
+	for item := range service.tunnelDetailsMap.IterBuffered() {
+		tunnel := item.Val.(*portainer.TunnelDetails)
+		if tunnel.Status == portainer.EdgeAgentIdle {
+			continue
+		}
+
+		endpointID, err := strconv.Atoi(item.Key)
+		if err == nil {
+			service.createTunnelEvent(portainer.EndpointID(endpointID), tunnel.Status, portainer.EdgeAgentIdle, tunnel.Port, "tunnel server key rotated")
+		}
+
+		tunnel.Status = portainer.EdgeAgentIdle
+		tunnel.Port = 0
+		tunnel.Credentials = ""
+		tunnel.OpenedAt = time.Time{}
+	}
+
+	return service.startChiselServer(keySeed)
+}
+
+// updateEdgeKeysFingerprint updates the fingerprint embedded in the edge keys and in the fleet key
+func (service *Service) updateEdgeKeysFingerprint(fingerprint string) error {
+	endpoints, err := service.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		return err
+	}
+
+	for idx := range endpoints {
+		endpoint := &endpoints[idx]
+		if endpoint.EdgeKey == "" {
+			continue
+		}
+
+		edgeKey, err := replaceKeyFingerprint(endpoint.EdgeKey, fingerprint)
+		if err != nil {
+			log.Printf("[WARN] [chisel,rotation] [endpoint_id: %d] [message: unable to update the fingerprint of the edge key] [error: %s]", endpoint.ID, err)
+			continue
+		}
+		endpoint.EdgeKey = edgeKey
+
+		err = service.dataStore.Endpoint().UpdateEndpoint(endpoint.ID, endpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	if settings.EdgeFleetKey == "" {
+		return nil
+	}
+
+	settings.EdgeFleetKey, err = replaceKeyFingerprint(settings.EdgeFleetKey, fingerprint)
+	if err != nil {
+		return err
+	}
+
+	return service.dataStore.Settings().UpdateSettings(settings)
+}
+
+func replaceKeyFingerprint(key, fingerprint string) (string, error) {
+	keyInfo, err := edge.ParseEdgeKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return generateEdgeKey(keyInfo.PortainerURL, keyInfo.TunnelServerAddr, fingerprint, int(keyInfo.EndpointID)), nil
+}
+
+func parseDuration(value string) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0
+	}
+	return duration
+}
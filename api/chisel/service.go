@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dchest/uniuri"
@@ -16,6 +17,7 @@ import (
 
 const (
 	tunnelCleanupInterval = 10 * time.Second
+	keyRotationInterval   = time.Minute
 	requiredTimeout       = 15 * time.Second
 	activeTimeout         = 4*time.Minute + 30*time.Second
 )
@@ -24,13 +26,19 @@ const (
 // It is used to start a reverse tunnel server and to manage the connection status of each tunnel
 // connected to the tunnel server.
 type Service struct {
-	serverFingerprint string
-	serverPort        string
-	tunnelDetailsMap  cmap.ConcurrentMap
-	dataStore         portainer.DataStore
-	snapshotService   portainer.SnapshotService
-	chiselServer      *chserver.Server
-	shutdownCtx       context.Context
+	// mu protects the tunnel server and its fingerprints, the server is replaced when its private key is rotated
+	mu                    sync.RWMutex
+	serverFingerprint     string
+	nextServerFingerprint string
+	// portMu serializes the allocation of the tunnel ports
+	portMu           sync.Mutex
+	serverAddr       string
+	serverPort       string
+	tunnelDetailsMap cmap.ConcurrentMap
+	dataStore        portainer.DataStore
+	snapshotService  portainer.SnapshotService
+	chiselServer     *chserver.Server
+	shutdownCtx      context.Context
 }
 
 // NewService returns a pointer to a new instance of Service
@@ -48,11 +56,40 @@ func NewService(dataStore portainer.DataStore, shutdownCtx context.Context) *Ser
 // It starts the tunnel status verification process in the background.
 // The snapshotter is used in the tunnel status verification process.
 func (service *Service) StartTunnelServer(addr, port string, snapshotService portainer.SnapshotService) error {
+	service.serverAddr = addr
+	service.serverPort = port
+
 	keySeed, err := service.retrievePrivateKeySeed()
 	if err != nil {
 		return err
 	}
 
+	service.mu.Lock()
+	err = service.startChiselServer(keySeed)
+	service.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// a seed whose activation passed while Portainer was stopped is activated right away
+	err = service.activateNextPrivateKeySeed(time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = service.loadNextServerFingerprint()
+	if err != nil {
+		return err
+	}
+
+	service.snapshotService = snapshotService
+	go service.startTunnelVerificationLoop()
+
+	return nil
+}
+
+// startChiselServer starts a tunnel server using a private key generated from the seed, mu must be held
+func (service *Service) startChiselServer(keySeed string) error {
 	config := &chserver.Config{
 		Reverse: true,
 		KeySeed: keySeed,
@@ -63,31 +100,24 @@ func (service *Service) StartTunnelServer(addr, port string, snapshotService por
 		return err
 	}
 
-	service.serverFingerprint = chiselServer.GetFingerprint()
-	service.serverPort = port
-
-	err = chiselServer.Start(addr, port)
+	err = chiselServer.Start(service.serverAddr, service.serverPort)
 	if err != nil {
 		return err
 	}
 	service.chiselServer = chiselServer
+	service.serverFingerprint = chiselServer.GetFingerprint()
 
 	// TODO: work-around Chisel default behavior.
 	// By default, Chisel will allow anyone to connect if no user exists.
 	username, password := generateRandomCredentials()
-	err = service.chiselServer.AddUser(username, password, "127.0.0.1")
-	if err != nil {
-		return err
-	}
-
-	service.snapshotService = snapshotService
-	go service.startTunnelVerificationLoop()
-
-	return nil
+	return service.chiselServer.AddUser(username, password, "127.0.0.1")
 }
 
 // StopTunnelServer stops tunnel http server
 func (service *Service) StopTunnelServer() error {
+	service.mu.RLock()
+	defer service.mu.RUnlock()
+
 	return service.chiselServer.Close()
 }
 
@@ -100,6 +130,7 @@ func (service *Service) retrievePrivateKeySeed() (string, error) {
 
 		serverInfo = &portainer.TunnelServerInfo{
 			PrivateKeySeed: keySeed,
+			RotationDate:   time.Now().Unix(),
 		}
 
 		err := service.dataStore.TunnelServer().UpdateInfo(serverInfo)
@@ -116,17 +147,21 @@ func (service *Service) retrievePrivateKeySeed() (string, error) {
 func (service *Service) startTunnelVerificationLoop() {
 	log.Printf("[DEBUG] [chisel, monitoring] [check_interval_seconds: %f] [message: starting tunnel management process]", tunnelCleanupInterval.Seconds())
 	ticker := time.NewTicker(tunnelCleanupInterval)
+	rotationTicker := time.NewTicker(keyRotationInterval)
 
 	for {
 		select {
 		case <-ticker.C:
 			service.checkTunnels()
+		case <-rotationTicker.C:
+			service.rotateKeys()
 		case <-service.shutdownCtx.Done():
 			log.Println("[DEBUG] Shutting down tunnel service")
 			if err := service.StopTunnelServer(); err != nil {
 				log.Printf("Stopped tunnel service: %s", err)
 			}
 			ticker.Stop()
+			rotationTicker.Stop()
 			return
 		}
 	}
@@ -165,16 +200,19 @@ func (service *Service) checkTunnels() {
 			}
 		}
 
-		if len(tunnel.Jobs) > 0 {
-			endpointID, err := strconv.Atoi(item.Key)
-			if err != nil {
-				log.Printf("[ERROR] [chisel,conversion] Invalid endpoint identifier (id: %s): %s", item.Key, err)
-				continue
-			}
+		endpointID, err := strconv.Atoi(item.Key)
+		if err != nil {
+			log.Printf("[ERROR] [chisel,conversion] Invalid endpoint identifier (id: %s): %s", item.Key, err)
+			continue
+		}
 
-			service.SetTunnelStatusToIdle(portainer.EndpointID(endpointID))
+		reason := fmt.Sprintf("%s state timeout", tunnel.Status)
+
+		if len(tunnel.Jobs) > 0 {
+			service.CloseTunnel(portainer.EndpointID(endpointID), reason)
 		} else {
 			service.tunnelDetailsMap.Remove(item.Key)
+			service.createTunnelEvent(portainer.EndpointID(endpointID), tunnel.Status, portainer.EdgeAgentIdle, tunnel.Port, reason)
 		}
 
 	}
@@ -0,0 +1,58 @@
+package chisel
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// TunnelDialer returns a dial function accounting the connections opened through the tunnel of an endpoint
+// and the bytes they transfer in the traffic of the tunnel.
+func (service *Service) TunnelDialer(endpointID portainer.EndpointID) func(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+
+		traffic := service.GetTunnelDetails(endpointID).Traffic
+		if traffic == nil {
+			return conn, nil
+		}
+
+		atomic.AddInt64(&traffic.Connections, 1)
+		atomic.AddInt64(&traffic.OpenConnections, 1)
+
+		return &trafficConn{Conn: conn, traffic: traffic}, nil
+	}
+}
+
+// trafficConn counts the bytes read and written on a connection
+type trafficConn struct {
+	net.Conn
+	traffic *portainer.TunnelTraffic
+	closed  int32
+}
+
+func (conn *trafficConn) Read(b []byte) (int, error) {
+	n, err := conn.Conn.Read(b)
+	atomic.AddInt64(&conn.traffic.BytesReceived, int64(n))
+	return n, err
+}
+
+func (conn *trafficConn) Write(b []byte) (int, error) {
+	n, err := conn.Conn.Write(b)
+	atomic.AddInt64(&conn.traffic.BytesSent, int64(n))
+	return n, err
+}
+
+func (conn *trafficConn) Close() error {
+	if atomic.CompareAndSwapInt32(&conn.closed, 0, 1) {
+		atomic.AddInt64(&conn.traffic.OpenConnections, -1)
+	}
+	return conn.Conn.Close()
+}
@@ -3,7 +3,6 @@ package chisel
 import (
 	"encoding/base64"
 	"fmt"
-	"math/rand"
 	"strconv"
 	"strings"
 	"time"
@@ -14,30 +13,6 @@ import (
 	portainer "github.com/portainer/portainer/api"
 )
 
-const (
-	minAvailablePort = 49152
-	maxAvailablePort = 65535
-)
-
-// getUnusedPort is used to generate an unused random port in the dynamic port range.
-// Dynamic ports (also called private ports) are 49152 to 65535.
-func (service *Service) getUnusedPort() int {
-	port := randomInt(minAvailablePort, maxAvailablePort)
-
-	for item := range service.tunnelDetailsMap.IterBuffered() {
-		tunnel := item.Val.(*portainer.TunnelDetails)
-		if tunnel.Port == port {
-			return service.getUnusedPort()
-		}
-	}
-
-	return port
-}
-
-func randomInt(min, max int) int {
-	return min + rand.Intn(max-min)
-}
-
 // GetTunnelDetails returns information about the tunnel associated to an endpoint.
 func (service *Service) GetTunnelDetails(endpointID portainer.EndpointID) *portainer.TunnelDetails {
 	key := strconv.Itoa(int(endpointID))
@@ -56,44 +31,102 @@ func (service *Service) GetTunnelDetails(endpointID portainer.EndpointID) *porta
 	}
 }
 
+// Tunnels returns a copy of the details of every tunnel, indexed by endpoint.
+func (service *Service) Tunnels() map[portainer.EndpointID]portainer.TunnelDetails {
+	tunnels := make(map[portainer.EndpointID]portainer.TunnelDetails)
+
+	for item := range service.tunnelDetailsMap.IterBuffered() {
+		endpointID, err := strconv.Atoi(item.Key)
+		if err != nil {
+			continue
+		}
+
+		tunnels[portainer.EndpointID(endpointID)] = *item.Val.(*portainer.TunnelDetails)
+	}
+
+	return tunnels
+}
+
 // SetTunnelStatusToActive update the status of the tunnel associated to the specified endpoint.
 // It sets the status to ACTIVE.
 func (service *Service) SetTunnelStatusToActive(endpointID portainer.EndpointID) {
 	tunnel := service.GetTunnelDetails(endpointID)
+	previousStatus := tunnel.Status
 	tunnel.Status = portainer.EdgeAgentActive
 	tunnel.Credentials = ""
 	tunnel.LastActivity = time.Now()
 
 	key := strconv.Itoa(int(endpointID))
 	service.tunnelDetailsMap.Set(key, tunnel)
+
+	service.recordTunnelEvent(endpointID, previousStatus, tunnel, "tunnel established")
+}
+
+// KeepTunnelAlive refreshes the activity of the tunnel associated to the specified endpoint when it is ACTIVE,
+// preventing it from being closed for inactivity. Tunnels in any other status are left untouched.
+func (service *Service) KeepTunnelAlive(endpointID portainer.EndpointID) {
+	tunnel := service.GetTunnelDetails(endpointID)
+	if tunnel.Status != portainer.EdgeAgentActive {
+		return
+	}
+
+	tunnel.LastActivity = time.Now()
+
+	key := strconv.Itoa(int(endpointID))
+	service.tunnelDetailsMap.Set(key, tunnel)
 }
 
 // SetTunnelStatusToIdle update the status of the tunnel associated to the specified endpoint.
 // It sets the status to IDLE.
 // It removes any existing credentials associated to the tunnel.
 func (service *Service) SetTunnelStatusToIdle(endpointID portainer.EndpointID) {
+	service.CloseTunnel(endpointID, "tunnel closed")
+}
+
+// CloseTunnel sets the status of the tunnel associated to the specified endpoint to IDLE and records
+// the transition with the specified reason. It removes any existing credentials associated to the tunnel.
+func (service *Service) CloseTunnel(endpointID portainer.EndpointID, reason string) {
 	tunnel := service.GetTunnelDetails(endpointID)
+	previousStatus := tunnel.Status
+	previousPort := tunnel.Port
 
 	tunnel.Status = portainer.EdgeAgentIdle
 	tunnel.Port = 0
 	tunnel.LastActivity = time.Now()
+	tunnel.OpenedAt = time.Time{}
 
 	credentials := tunnel.Credentials
 	if credentials != "" {
 		tunnel.Credentials = ""
+		service.mu.RLock()
 		service.chiselServer.DeleteUser(strings.Split(credentials, ":")[0])
+		service.mu.RUnlock()
 	}
 
 	key := strconv.Itoa(int(endpointID))
 	service.tunnelDetailsMap.Set(key, tunnel)
+
+	if previousStatus != portainer.EdgeAgentIdle {
+		service.createTunnelEvent(endpointID, previousStatus, portainer.EdgeAgentIdle, previousPort, reason)
+	}
 }
 
 // SetTunnelStatusToRequired update the status of the tunnel associated to the specified endpoint.
 // It sets the status to REQUIRED.
-// If no port is currently associated to the tunnel, it will associate a random unused port to the tunnel
+// If no port is currently associated to the tunnel, it will associate the port of the endpoint, or an unused
+// port of the tunnel port range, to the tunnel
 // and generate temporary credentials that can be used to establish a reverse tunnel on that port.
 // Credentials are encrypted using the Edge ID associated to the endpoint.
 func (service *Service) SetTunnelStatusToRequired(endpointID portainer.EndpointID) error {
+	return service.OpenTunnel(endpointID, "tunnel requested")
+}
+
+// OpenTunnel sets the status of the tunnel associated to the specified endpoint to REQUIRED, as
+// SetTunnelStatusToRequired does, and records the transition with the specified reason.
+func (service *Service) OpenTunnel(endpointID portainer.EndpointID, reason string) error {
+	service.portMu.Lock()
+	defer service.portMu.Unlock()
+
 	tunnel := service.GetTunnelDetails(endpointID)
 
 	if tunnel.Port == 0 {
@@ -102,13 +135,23 @@ func (service *Service) SetTunnelStatusToRequired(endpointID portainer.EndpointI
 			return err
 		}
 
+		port, err := service.allocatePort(endpoint)
+		if err != nil {
+			return err
+		}
+
+		previousStatus := tunnel.Status
 		tunnel.Status = portainer.EdgeAgentManagementRequired
-		tunnel.Port = service.getUnusedPort()
+		tunnel.Port = port
 		tunnel.LastActivity = time.Now()
+		tunnel.OpenedAt = tunnel.LastActivity
+		tunnel.Traffic = &portainer.TunnelTraffic{}
 
 		username, password := generateRandomCredentials()
 		authorizedRemote := fmt.Sprintf("^R:0.0.0.0:%d$", tunnel.Port)
+		service.mu.RLock()
 		err = service.chiselServer.AddUser(username, password, authorizedRemote)
+		service.mu.RUnlock()
 		if err != nil {
 			return err
 		}
@@ -121,6 +164,8 @@ func (service *Service) SetTunnelStatusToRequired(endpointID portainer.EndpointI
 
 		key := strconv.Itoa(int(endpointID))
 		service.tunnelDetailsMap.Set(key, tunnel)
+
+		service.recordTunnelEvent(endpointID, previousStatus, tunnel, reason)
 	}
 
 	return nil
@@ -17,11 +17,29 @@ import (
 // Service implements the CLIService interface
 type Service struct{}
 
+const (
+	// CommandServe is the default command, it starts the Portainer server
+	CommandServe = "serve"
+	// CommandDatastoreCheck verifies the referential integrity of the datastore
+	CommandDatastoreCheck = "datastore check"
+	// CommandDatastoreCompact compacts the datastore file
+	CommandDatastoreCompact = "datastore compact"
+	// CommandDatastoreMigrateDryRun reports the changes the pending migrations would apply
+	CommandDatastoreMigrateDryRun = "datastore migrate-dry-run"
+)
+
 var (
 	errInvalidEndpointProtocol       = errors.New("Invalid endpoint protocol: Portainer only supports unix://, npipe:// or tcp://")
 	errSocketOrNamedPipeNotFound     = errors.New("Unable to locate Unix socket or named pipe")
 	errInvalidSnapshotInterval       = errors.New("Invalid snapshot interval")
+	errInvalidSnapshotConcurrency    = errors.New("Invalid snapshot concurrency, at least one worker is required")
+	errInvalidSnapshotTimeout        = errors.New("Invalid snapshot timeout")
+	errInvalidEdgeStackGitPoll       = errors.New("Invalid edge stack git poll interval")
+	errInvalidDockerEventsHistory    = errors.New("Invalid Docker events history, the history size cannot be negative")
+	errInvalidTunnelPortRange        = errors.New("Invalid tunnel port range, ports must be between 1 and 65535 and --tunnel-min-port must not exceed --tunnel-max-port")
 	errAdminPassExcludeAdminPassFile = errors.New("Cannot use --admin-password with --admin-password-file")
+	errImportBoltDBRequiresSQLite    = errors.New("Cannot use --import-boltdb without --datastore=sqlite")
+	errRollbackRequiresBoltDB        = errors.New("Cannot use --rollback-to-version with --datastore=sqlite")
 )
 
 // ParseFlags parse the CLI flags and return a portainer.Flags struct
@@ -32,8 +50,13 @@ func (*Service) ParseFlags(version string) (*portainer.CLIFlags, error) {
 		Addr:                      kingpin.Flag("bind", "Address and port to serve Portainer").Default(defaultBindAddress).Short('p').String(),
 		TunnelAddr:                kingpin.Flag("tunnel-addr", "Address to serve the tunnel server").Default(defaultTunnelServerAddress).String(),
 		TunnelPort:                kingpin.Flag("tunnel-port", "Port to serve the tunnel server").Default(defaultTunnelServerPort).String(),
+		TunnelMinPort:             kingpin.Flag("tunnel-min-port", "Lowest port allocated to the tunnels of the Edge endpoints, overrides the setting").Int(),
+		TunnelMaxPort:             kingpin.Flag("tunnel-max-port", "Highest port allocated to the tunnels of the Edge endpoints, overrides the setting").Int(),
 		Assets:                    kingpin.Flag("assets", "Path to the assets").Default(defaultAssetsDirectory).Short('a').String(),
 		Data:                      kingpin.Flag("data", "Path to the folder where the data is stored").Default(defaultDataDirectory).Short('d').String(),
+		Datastore:                 kingpin.Flag("datastore", "Storage engine used for the data, either boltdb or sqlite").Default(defaultDatastore).Enum(portainer.DatastoreBoltDB, portainer.DatastoreSQLite),
+		ImportBoltDB:              kingpin.Flag("import-boltdb", "Import the existing BoltDB database into a new SQLite database and exit").Bool(),
+		RollbackToVersion:         kingpin.Flag("rollback-to-version", "Restore the database snapshot taken before migrating from this database version and exit").Int(),
 		EndpointURL:               kingpin.Flag("host", "Endpoint URL").Short('H').String(),
 		EnableEdgeComputeFeatures: kingpin.Flag("edge-compute", "Enable Edge Compute features").Bool(),
 		NoAnalytics:               kingpin.Flag("no-analytics", "Disable Analytics in app (deprecated)").Bool(),
@@ -46,6 +69,10 @@ func (*Service) ParseFlags(version string) (*portainer.CLIFlags, error) {
 		SSLCert:                   kingpin.Flag("sslcert", "Path to the SSL certificate used to secure the Portainer instance").Default(defaultSSLCertPath).String(),
 		SSLKey:                    kingpin.Flag("sslkey", "Path to the SSL key used to secure the Portainer instance").Default(defaultSSLKeyPath).String(),
 		SnapshotInterval:          kingpin.Flag("snapshot-interval", "Duration between each endpoint snapshot job").Default(defaultSnapshotInterval).String(),
+		SnapshotConcurrency:       kingpin.Flag("snapshot-concurrency", "Maximum number of endpoints snapshotted concurrently").Default(defaultSnapshotConcurrency).Int(),
+		SnapshotTimeout:           kingpin.Flag("snapshot-timeout", "Maximum duration of the snapshot of a single endpoint").Default(defaultSnapshotTimeout).String(),
+		EdgeStackGitPollInterval:  kingpin.Flag("edge-stack-git-poll-interval", "Duration between each check of the repositories edge stacks are deployed from").Default(defaultEdgeStackGitPoll).String(),
+		DockerEventsHistory:       kingpin.Flag("docker-events-history", "Number of recent Docker events replayed to new subscribers of the events stream, 0 disables the history").Default(defaultDockerEventsHistory).Int(),
 		AdminPassword:             kingpin.Flag("admin-password", "Hashed admin password").String(),
 		AdminPasswordFile:         kingpin.Flag("admin-password-file", "Path to the file containing the password for the admin user").String(),
 		Labels:                    pairs(kingpin.Flag("hide-label", "Hide containers with a specific label in the UI").Short('l')),
@@ -53,7 +80,13 @@ func (*Service) ParseFlags(version string) (*portainer.CLIFlags, error) {
 		Templates:                 kingpin.Flag("templates", "URL to the templates definitions.").Short('t').String(),
 	}
 
-	kingpin.Parse()
+	kingpin.Command(CommandServe, "Start the Portainer server").Default()
+	datastore := kingpin.Command("datastore", "Datastore maintenance commands, the Portainer server must be stopped")
+	datastore.Command("check", "Verify the referential integrity of the datastore")
+	datastore.Command("compact", "Compact the datastore file to reclaim unused space")
+	datastore.Command("migrate-dry-run", "Run the pending migrations against a copy of the datastore and report what would change")
+
+	flags.Command = kingpin.Parse()
 
 	if !filepath.IsAbs(*flags.Assets) {
 		ex, err := os.Executable()
@@ -81,10 +114,53 @@ func (*Service) ValidateFlags(flags *portainer.CLIFlags) error {
 		return err
 	}
 
+	if *flags.SnapshotConcurrency < 1 {
+		return errInvalidSnapshotConcurrency
+	}
+
+	err = validateSnapshotTimeout(*flags.SnapshotTimeout)
+	if err != nil {
+		return err
+	}
+
+	interval, err := time.ParseDuration(*flags.EdgeStackGitPollInterval)
+	if err != nil || interval <= 0 {
+		return errInvalidEdgeStackGitPoll
+	}
+
+	if *flags.DockerEventsHistory < 0 {
+		return errInvalidDockerEventsHistory
+	}
+
+	err = validateTunnelPortRange(*flags.TunnelMinPort, *flags.TunnelMaxPort)
+	if err != nil {
+		return err
+	}
+
 	if *flags.AdminPassword != "" && *flags.AdminPasswordFile != "" {
 		return errAdminPassExcludeAdminPassFile
 	}
 
+	if *flags.ImportBoltDB && *flags.Datastore != portainer.DatastoreSQLite {
+		return errImportBoltDBRequiresSQLite
+	}
+
+	if *flags.RollbackToVersion != 0 && *flags.Datastore != portainer.DatastoreBoltDB {
+		return errRollbackRequiresBoltDB
+	}
+
+	return nil
+}
+
+func validateTunnelPortRange(minPort, maxPort int) error {
+	if minPort < 0 || minPort > 65535 || maxPort < 0 || maxPort > 65535 {
+		return errInvalidTunnelPortRange
+	}
+
+	if minPort != 0 && maxPort != 0 && minPort > maxPort {
+		return errInvalidTunnelPortRange
+	}
+
 	return nil
 }
 
@@ -123,3 +199,11 @@ func validateSnapshotInterval(snapshotInterval string) error {
 	}
 	return nil
 }
+
+func validateSnapshotTimeout(snapshotTimeout string) error {
+	timeout, err := time.ParseDuration(snapshotTimeout)
+	if err != nil || timeout <= 0 {
+		return errInvalidSnapshotTimeout
+	}
+	return nil
+}
@@ -17,4 +17,9 @@ const (
 	defaultSSLCertPath         = "/certs/portainer.crt"
 	defaultSSLKeyPath          = "/certs/portainer.key"
 	defaultSnapshotInterval    = "5m"
+	defaultSnapshotConcurrency = "10"
+	defaultSnapshotTimeout     = "30s"
+	defaultEdgeStackGitPoll    = "5m"
+	defaultDockerEventsHistory = "100"
+	defaultDatastore           = "boltdb"
 )
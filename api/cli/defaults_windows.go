@@ -15,4 +15,9 @@ const (
 	defaultSSLCertPath         = "C:\\certs\\portainer.crt"
 	defaultSSLKeyPath          = "C:\\certs\\portainer.key"
 	defaultSnapshotInterval    = "5m"
+	defaultSnapshotConcurrency = "10"
+	defaultSnapshotTimeout     = "30s"
+	defaultEdgeStackGitPoll    = "5m"
+	defaultDockerEventsHistory = "100"
+	defaultDatastore           = "boltdb"
 )
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt"
+	"github.com/portainer/portainer/api/cli"
+	"github.com/portainer/portainer/api/internal/integrity"
+	"github.com/portainer/portainer/api/sqlite"
+)
+
+var errDatastoreNotFound = errors.New("unable to find a database in the data folder")
+
+// runDatastoreCommand executes one of the offline datastore maintenance commands.
+// It returns true when the datastore is healthy or the command succeeded.
+func runDatastoreCommand(flags *portainer.CLIFlags) (bool, error) {
+	fileService := initFileService(*flags.Data)
+
+	switch flags.Command {
+	case cli.CommandDatastoreCheck:
+		return checkDataStore(*flags.Datastore, *flags.Data, fileService)
+	case cli.CommandDatastoreCompact:
+		return true, compactDataStore(*flags.Datastore, *flags.Data)
+	case cli.CommandDatastoreMigrateDryRun:
+		return true, migrationDryRun(*flags.Datastore, *flags.Data, fileService)
+	}
+
+	return false, fmt.Errorf("unknown command: %s", flags.Command)
+}
+
+func checkDataStore(datastore string, dataStorePath string, fileService portainer.FileService) (bool, error) {
+	store, err := newDataStore(datastore, dataStorePath, fileService)
+	if err != nil {
+		return false, err
+	}
+
+	if store.IsNew() {
+		return false, errDatastoreNotFound
+	}
+
+	err = store.Open()
+	if err != nil {
+		return false, err
+	}
+	defer store.Close()
+
+	issues, err := integrity.Check(store)
+	if err != nil {
+		return false, err
+	}
+
+	for _, issue := range issues {
+		log.Println(issue)
+	}
+	log.Printf("Integrity check completed, %d issue(s) found.\n", len(issues))
+
+	return len(issues) == 0, nil
+}
+
+func compactDataStore(datastore string, dataStorePath string) error {
+	compact := bolt.Compact
+	databasePath := filepath.Join(dataStorePath, bolt.DatabaseFileName)
+	if datastore == portainer.DatastoreSQLite {
+		compact = sqlite.Compact
+		databasePath = filepath.Join(dataStorePath, sqlite.DatabaseFileName)
+	}
+
+	before, err := os.Stat(databasePath)
+	if os.IsNotExist(err) {
+		return errDatastoreNotFound
+	} else if err != nil {
+		return err
+	}
+
+	err = compact(dataStorePath)
+	if err != nil {
+		return err
+	}
+
+	after, err := os.Stat(databasePath)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Datastore compacted from %d to %d bytes.\n", before.Size(), after.Size())
+
+	return nil
+}
+
+func migrationDryRun(datastore string, dataStorePath string, fileService portainer.FileService) error {
+	if datastore != portainer.DatastoreBoltDB {
+		return errors.New("migrations are only available for the BoltDB datastore")
+	}
+
+	store, err := bolt.NewStore(dataStorePath, fileService)
+	if err != nil {
+		return err
+	}
+
+	if store.IsNew() {
+		return errDatastoreNotFound
+	}
+
+	report, err := bolt.MigrationDryRun(dataStorePath, fileService)
+	if err != nil {
+		return err
+	}
+
+	if report.FromVersion >= report.ToVersion {
+		log.Printf("Database is up to date (version %d), no migration required.\n", report.FromVersion)
+		return nil
+	}
+
+	log.Printf("Migrating database from version %d to %d would apply the following changes:\n", report.FromVersion, report.ToVersion)
+	for _, bucket := range report.Buckets {
+		log.Printf("%s: %d created, %d updated, %d deleted\n", bucket.Name, bucket.Created, bucket.Updated, bucket.Deleted)
+	}
+
+	return nil
+}
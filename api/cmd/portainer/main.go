@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	wrapper "github.com/portainer/docker-compose-wrapper"
@@ -22,7 +25,10 @@ import (
 	"github.com/portainer/portainer/api/http/proxy"
 	kubeproxy "github.com/portainer/portainer/api/http/proxy/factory/kubernetes"
 	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/internal/dockerevents"
 	"github.com/portainer/portainer/api/internal/edge"
+	"github.com/portainer/portainer/api/internal/edgegit"
+	"github.com/portainer/portainer/api/internal/prune"
 	"github.com/portainer/portainer/api/internal/snapshot"
 	"github.com/portainer/portainer/api/jwt"
 	"github.com/portainer/portainer/api/kubernetes"
@@ -30,6 +36,7 @@ import (
 	"github.com/portainer/portainer/api/ldap"
 	"github.com/portainer/portainer/api/libcompose"
 	"github.com/portainer/portainer/api/oauth"
+	"github.com/portainer/portainer/api/sqlite"
 )
 
 func initCLI() *portainer.CLIFlags {
@@ -54,8 +61,15 @@ func initFileService(dataStorePath string) portainer.FileService {
 	return fileService
 }
 
-func initDataStore(dataStorePath string, fileService portainer.FileService) portainer.DataStore {
-	store, err := bolt.NewStore(dataStorePath, fileService)
+func newDataStore(datastore string, dataStorePath string, fileService portainer.FileService) (portainer.DataStore, error) {
+	if datastore == portainer.DatastoreSQLite {
+		return sqlite.NewStore(dataStorePath, fileService)
+	}
+	return bolt.NewStore(dataStorePath, fileService)
+}
+
+func initDataStore(datastore string, dataStorePath string, fileService portainer.FileService) portainer.DataStore {
+	store, err := newDataStore(datastore, dataStorePath, fileService)
 	if err != nil {
 		log.Fatalf("failed creating data store: %v", err)
 	}
@@ -77,6 +91,60 @@ func initDataStore(dataStorePath string, fileService portainer.FileService) port
 	return store
 }
 
+// importBoltDatabase migrates the BoltDB database to the current version
+// and copies its content into a new SQLite database.
+func importBoltDatabase(dataStorePath string, fileService portainer.FileService) error {
+	boltStore, err := bolt.NewStore(dataStorePath, fileService)
+	if err != nil {
+		return err
+	}
+
+	if boltStore.IsNew() {
+		return errors.New("unable to find a BoltDB database in the data folder")
+	}
+
+	sqliteStore, err := sqlite.NewStore(dataStorePath, fileService)
+	if err != nil {
+		return err
+	}
+
+	if !sqliteStore.IsNew() {
+		return errors.New("a SQLite database already exists in the data folder")
+	}
+
+	err = boltStore.Open()
+	if err != nil {
+		return err
+	}
+
+	err = boltStore.CheckCurrentEdition()
+	if err == nil {
+		err = boltStore.MigrateData(false)
+	}
+
+	closeErr := boltStore.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	err = sqliteStore.Open()
+	if err == nil {
+		err = sqliteStore.ImportBoltDatabase(filepath.Join(dataStorePath, bolt.DatabaseFileName))
+		sqliteStore.Close()
+	}
+
+	if err != nil {
+		// Remove the partially imported database so that the import can be retried
+		os.Remove(filepath.Join(dataStorePath, sqlite.DatabaseFileName))
+		return err
+	}
+
+	return nil
+}
+
 func initComposeStackManager(assetsPath string, dataStorePath string, reverseTunnelService portainer.ReverseTunnelService, proxyManager *proxy.Manager) portainer.ComposeStackManager {
 	composeWrapper, err := exec.NewComposeStackManager(assetsPath, dataStorePath, proxyManager)
 	if err != nil {
@@ -144,11 +212,11 @@ func initKubernetesClientFactory(signatureService portainer.DigitalSignatureServ
 	return kubecli.NewClientFactory(signatureService, reverseTunnelService, instanceID, dataStore)
 }
 
-func initSnapshotService(snapshotInterval string, dataStore portainer.DataStore, dockerClientFactory *docker.ClientFactory, kubernetesClientFactory *kubecli.ClientFactory, shutdownCtx context.Context) (portainer.SnapshotService, error) {
+func initSnapshotService(snapshotInterval string, snapshotConcurrency int, snapshotTimeout string, dataStore portainer.DataStore, dockerClientFactory *docker.ClientFactory, kubernetesClientFactory *kubecli.ClientFactory, shutdownCtx context.Context) (portainer.SnapshotService, error) {
 	dockerSnapshotter := docker.NewSnapshotter(dockerClientFactory)
 	kubernetesSnapshotter := kubernetes.NewSnapshotter(kubernetesClientFactory)
 
-	snapshotService, err := snapshot.NewService(snapshotInterval, dataStore, dockerSnapshotter, kubernetesSnapshotter, shutdownCtx)
+	snapshotService, err := snapshot.NewService(snapshotInterval, snapshotConcurrency, snapshotTimeout, dataStore, dockerSnapshotter, kubernetesSnapshotter, shutdownCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -185,6 +253,42 @@ func updateSettingsFromFlags(dataStore portainer.DataStore, flags *portainer.CLI
 	return dataStore.Settings().UpdateSettings(settings)
 }
 
+// updateTunnelPortRangeFromFlags persists the tunnel port range flags, they take precedence over the settings:
+// they are applied on every start and the settings updates of the range are rejected while they are set
+func updateTunnelPortRangeFromFlags(dataStore portainer.DataStore, flags *portainer.CLIFlags) error {
+	if *flags.TunnelMinPort == 0 && *flags.TunnelMaxPort == 0 {
+		return nil
+	}
+
+	settings, err := dataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	// settings created before the port range was configurable use the default range
+	if settings.EdgeTunnelMinPort == 0 {
+		settings.EdgeTunnelMinPort = portainer.DefaultEdgeTunnelMinPort
+	}
+
+	if settings.EdgeTunnelMaxPort == 0 {
+		settings.EdgeTunnelMaxPort = portainer.DefaultEdgeTunnelMaxPort
+	}
+
+	if *flags.TunnelMinPort != 0 {
+		settings.EdgeTunnelMinPort = *flags.TunnelMinPort
+	}
+
+	if *flags.TunnelMaxPort != 0 {
+		settings.EdgeTunnelMaxPort = *flags.TunnelMaxPort
+	}
+
+	if settings.EdgeTunnelMinPort > settings.EdgeTunnelMaxPort {
+		return fmt.Errorf("the tunnel port range %d-%d is invalid, the lowest port exceeds the highest port", settings.EdgeTunnelMinPort, settings.EdgeTunnelMaxPort)
+	}
+
+	return dataStore.Settings().UpdateSettings(settings)
+}
+
 func loadAndParseKeyPair(fileService portainer.FileService, signatureService portainer.DigitalSignatureService) error {
 	private, public, err := fileService.LoadKeyPair()
 	if err != nil {
@@ -354,7 +458,7 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 
 	fileService := initFileService(*flags.Data)
 
-	dataStore := initDataStore(*flags.Data, fileService)
+	dataStore := initDataStore(*flags.Datastore, *flags.Data, fileService)
 
 	if err := dataStore.CheckCurrentEdition(); err != nil {
 		log.Fatal(err)
@@ -390,12 +494,24 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	dockerClientFactory := initDockerClientFactory(digitalSignatureService, reverseTunnelService)
 	kubernetesClientFactory := initKubernetesClientFactory(digitalSignatureService, reverseTunnelService, instanceID, dataStore)
 
-	snapshotService, err := initSnapshotService(*flags.SnapshotInterval, dataStore, dockerClientFactory, kubernetesClientFactory, shutdownCtx)
+	snapshotService, err := initSnapshotService(*flags.SnapshotInterval, *flags.SnapshotConcurrency, *flags.SnapshotTimeout, dataStore, dockerClientFactory, kubernetesClientFactory, shutdownCtx)
 	if err != nil {
 		log.Fatalf("failed initializing snapshot service: %v", err)
 	}
 	snapshotService.Start()
 
+	edgeStackGitService, err := edgegit.NewService(*flags.EdgeStackGitPollInterval, dataStore, fileService, gitService, shutdownCtx)
+	if err != nil {
+		log.Fatalf("failed initializing edge stack git service: %v", err)
+	}
+	edgeStackGitService.Start()
+
+	dockerEventsService := dockerevents.NewService(*flags.DockerEventsHistory, dataStore, dockerClientFactory, reverseTunnelService, shutdownCtx)
+	dockerEventsService.Start()
+
+	pruneService := prune.NewService(dataStore, dockerClientFactory, reverseTunnelService, shutdownCtx)
+	pruneService.Start()
+
 	authorizationService := authorization.NewService(dataStore)
 	authorizationService.K8sClientFactory = kubernetesClientFactory
 
@@ -417,6 +533,11 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 		}
 	}
 
+	err = updateTunnelPortRangeFromFlags(dataStore, flags)
+	if err != nil {
+		log.Fatalf("failed updating the tunnel port range from flags: %v", err)
+	}
+
 	err = edge.LoadEdgeJobs(dataStore, reverseTunnelService)
 	if err != nil {
 		log.Fatalf("failed loading edge jobs from database: %v", err)
@@ -490,6 +611,9 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 		KubernetesTokenCacheManager: kubernetesTokenCacheManager,
 		SignatureService:            digitalSignatureService,
 		SnapshotService:             snapshotService,
+		EdgeStackGitService:         edgeStackGitService,
+		DockerEventsService:         dockerEventsService,
+		PruneService:                pruneService,
 		SSL:                         *flags.SSL,
 		SSLCert:                     *flags.SSLCert,
 		SSLKey:                      *flags.SSLKey,
@@ -497,12 +621,42 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 		KubernetesClientFactory:     kubernetesClientFactory,
 		ShutdownCtx:                 shutdownCtx,
 		ShutdownTrigger:             shutdownTrigger,
+		TunnelPortRangeFromFlags:    *flags.TunnelMinPort != 0 || *flags.TunnelMaxPort != 0,
 	}
 }
 
 func main() {
 	flags := initCLI()
 
+	if *flags.ImportBoltDB {
+		err := importBoltDatabase(*flags.Data, initFileService(*flags.Data))
+		if err != nil {
+			log.Fatalf("failed importing BoltDB database: %v", err)
+		}
+		log.Println("BoltDB database successfully imported into the SQLite database.")
+		return
+	}
+
+	if *flags.RollbackToVersion != 0 {
+		err := bolt.RollbackToVersion(*flags.Data, *flags.RollbackToVersion)
+		if err != nil {
+			log.Fatalf("failed restoring database snapshot: %v", err)
+		}
+		log.Printf("Database snapshot of version %d restored, start the matching Portainer version to complete the downgrade.\n", *flags.RollbackToVersion)
+		return
+	}
+
+	if flags.Command != cli.CommandServe {
+		ok, err := runDatastoreCommand(flags)
+		if err != nil {
+			log.Fatalf("failed running %s: %v", flags.Command, err)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
 	for {
 		server := buildServer(flags)
 		log.Printf("Starting Portainer %s on %s\n", portainer.APIVersion, *flags.Addr)
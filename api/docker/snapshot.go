@@ -24,19 +24,19 @@ func NewSnapshotter(clientFactory *ClientFactory) *Snapshotter {
 	}
 }
 
-// CreateSnapshot creates a snapshot of a specific Docker endpoint
-func (snapshotter *Snapshotter) CreateSnapshot(endpoint *portainer.Endpoint) (*portainer.DockerSnapshot, error) {
+// CreateSnapshot creates a snapshot of a specific Docker endpoint, the requests to the endpoint are cancelled with the context
+func (snapshotter *Snapshotter) CreateSnapshot(ctx context.Context, endpoint *portainer.Endpoint) (*portainer.DockerSnapshot, error) {
 	cli, err := snapshotter.clientFactory.CreateClient(endpoint, "")
 	if err != nil {
 		return nil, err
 	}
 	defer cli.Close()
 
-	return snapshot(cli, endpoint)
+	return snapshot(ctx, cli, endpoint)
 }
 
-func snapshot(cli *client.Client, endpoint *portainer.Endpoint) (*portainer.DockerSnapshot, error) {
-	_, err := cli.Ping(context.Background())
+func snapshot(ctx context.Context, cli *client.Client, endpoint *portainer.Endpoint) (*portainer.DockerSnapshot, error) {
+	_, err := cli.Ping(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -45,44 +45,44 @@ func snapshot(cli *client.Client, endpoint *portainer.Endpoint) (*portainer.Dock
 		StackCount: 0,
 	}
 
-	err = snapshotInfo(snapshot, cli)
+	err = snapshotInfo(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot engine information] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
 
 	if snapshot.Swarm {
-		err = snapshotSwarmServices(snapshot, cli)
+		err = snapshotSwarmServices(ctx, snapshot, cli)
 		if err != nil {
 			log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot Swarm services] [endpoint: %s] [err: %s]", endpoint.Name, err)
 		}
 
-		err = snapshotNodes(snapshot, cli)
+		err = snapshotNodes(ctx, snapshot, cli)
 		if err != nil {
 			log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot Swarm nodes] [endpoint: %s] [err: %s]", endpoint.Name, err)
 		}
 	}
 
-	err = snapshotContainers(snapshot, cli)
+	err = snapshotContainers(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot containers] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
 
-	err = snapshotImages(snapshot, cli)
+	err = snapshotImages(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot images] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
 
-	err = snapshotVolumes(snapshot, cli)
+	err = snapshotVolumes(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot volumes] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
 
-	err = snapshotNetworks(snapshot, cli)
+	err = snapshotNetworks(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot networks] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
 
-	err = snapshotVersion(snapshot, cli)
+	err = snapshotVersion(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [docker,snapshot] [message: unable to snapshot engine version] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
@@ -91,8 +91,8 @@ func snapshot(cli *client.Client, endpoint *portainer.Endpoint) (*portainer.Dock
 	return snapshot, nil
 }
 
-func snapshotInfo(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
-	info, err := cli.Info(context.Background())
+func snapshotInfo(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+	info, err := cli.Info(ctx)
 	if err != nil {
 		return err
 	}
@@ -105,8 +105,8 @@ func snapshotInfo(snapshot *portainer.DockerSnapshot, cli *client.Client) error
 	return nil
 }
 
-func snapshotNodes(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
-	nodes, err := cli.NodeList(context.Background(), types.NodeListOptions{})
+func snapshotNodes(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+	nodes, err := cli.NodeList(ctx, types.NodeListOptions{})
 	if err != nil {
 		return err
 	}
@@ -122,10 +122,10 @@ func snapshotNodes(snapshot *portainer.DockerSnapshot, cli *client.Client) error
 	return nil
 }
 
-func snapshotSwarmServices(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+func snapshotSwarmServices(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
 	stacks := make(map[string]struct{})
 
-	services, err := cli.ServiceList(context.Background(), types.ServiceListOptions{})
+	services, err := cli.ServiceList(ctx, types.ServiceListOptions{})
 	if err != nil {
 		return err
 	}
@@ -143,8 +143,8 @@ func snapshotSwarmServices(snapshot *portainer.DockerSnapshot, cli *client.Clien
 	return nil
 }
 
-func snapshotContainers(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
-	containers, err := cli.ContainerList(context.Background(), types.ContainerListOptions{All: true})
+func snapshotContainers(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
 	if err != nil {
 		return err
 	}
@@ -183,8 +183,8 @@ func snapshotContainers(snapshot *portainer.DockerSnapshot, cli *client.Client)
 	return nil
 }
 
-func snapshotImages(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
-	images, err := cli.ImageList(context.Background(), types.ImageListOptions{})
+func snapshotImages(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+	images, err := cli.ImageList(ctx, types.ImageListOptions{})
 	if err != nil {
 		return err
 	}
@@ -194,8 +194,8 @@ func snapshotImages(snapshot *portainer.DockerSnapshot, cli *client.Client) erro
 	return nil
 }
 
-func snapshotVolumes(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
-	volumes, err := cli.VolumeList(context.Background(), filters.Args{})
+func snapshotVolumes(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+	volumes, err := cli.VolumeList(ctx, filters.Args{})
 	if err != nil {
 		return err
 	}
@@ -205,8 +205,8 @@ func snapshotVolumes(snapshot *portainer.DockerSnapshot, cli *client.Client) err
 	return nil
 }
 
-func snapshotNetworks(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
-	networks, err := cli.NetworkList(context.Background(), types.NetworkListOptions{})
+func snapshotNetworks(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+	networks, err := cli.NetworkList(ctx, types.NetworkListOptions{})
 	if err != nil {
 		return err
 	}
@@ -214,8 +214,8 @@ func snapshotNetworks(snapshot *portainer.DockerSnapshot, cli *client.Client) er
 	return nil
 }
 
-func snapshotVersion(snapshot *portainer.DockerSnapshot, cli *client.Client) error {
-	version, err := cli.ServerVersion(context.Background())
+func snapshotVersion(ctx context.Context, snapshot *portainer.DockerSnapshot, cli *client.Client) error {
+	version, err := cli.ServerVersion(ctx)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/portainer/portainer/api/archive"
@@ -100,6 +101,55 @@ func (a *azureDownloader) downloadZipFromAzureDevOps(ctx context.Context, option
 	return zipFile.Name(), nil
 }
 
+func (a *azureDownloader) latestCommitID(ctx context.Context, options cloneOptions) (string, error) {
+	config, err := parseUrl(options.repositoryUrl)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to parse url")
+	}
+
+	commitsUrl, err := a.buildCommitsUrl(config, options.referenceName)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to build commits url")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", commitsUrl, nil)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to create a new HTTP request")
+	}
+
+	if options.username != "" || options.password != "" {
+		req.SetBasicAuth(options.username, options.password)
+	} else if config.username != "" || config.password != "" {
+		req.SetBasicAuth(config.username, config.password)
+	}
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to make an HTTP request")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get the latest commit with a status \"%v\"", res.Status)
+	}
+
+	var commits struct {
+		Value []struct {
+			CommitID string `json:"commitId"`
+		} `json:"value"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&commits)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to parse the commits response")
+	}
+
+	if len(commits.Value) == 0 {
+		return "", errors.Errorf("could not find the reference %s in the repository", options.referenceName)
+	}
+
+	return commits.Value[0].CommitID, nil
+}
+
 func parseUrl(rawUrl string) (*azureOptions, error) {
 	if strings.HasPrefix(rawUrl, "https://") || strings.HasPrefix(rawUrl, "http://") {
 		return parseHttpUrl(rawUrl)
@@ -193,6 +243,29 @@ func (a *azureDownloader) buildDownloadUrl(config *azureOptions, referenceName s
 	return u.String(), nil
 }
 
+func (a *azureDownloader) buildCommitsUrl(config *azureOptions, referenceName string) (string, error) {
+	rawUrl := fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s/commits",
+		a.baseUrl,
+		url.PathEscape(config.organisation),
+		url.PathEscape(config.project),
+		url.PathEscape(config.repository))
+	u, err := url.Parse(rawUrl)
+
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse commits url path %s", rawUrl)
+	}
+	q := u.Query()
+	if referenceName != "" {
+		q.Set("searchCriteria.itemVersion.versionType", getVersionType(referenceName))
+		q.Set("searchCriteria.itemVersion.version", formatReferenceName(referenceName))
+	}
+	q.Set("searchCriteria.$top", "1")
+	q.Set("api-version", "6.0")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
 const (
 	branchPrefix = "refs/heads/"
 	tagPrefix    = "refs/tags/"
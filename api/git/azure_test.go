@@ -27,6 +27,23 @@ func Test_buildDownloadUrl(t *testing.T) {
 	}
 }
 
+func Test_buildCommitsUrl(t *testing.T) {
+	a := NewAzureDownloader(nil)
+	u, err := a.buildCommitsUrl(&azureOptions{
+		organisation: "organisation",
+		project:      "project",
+		repository:   "repository",
+	}, "refs/tags/v1.0")
+
+	expectedUrl, _ := url.Parse("https://dev.azure.com/organisation/project/_apis/git/repositories/repository/commits?searchCriteria.itemVersion.version=v1.0&searchCriteria.itemVersion.versionType=tag&searchCriteria.$top=1&api-version=6.0")
+	actualUrl, _ := url.Parse(u)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedUrl.Host, actualUrl.Host)
+		assert.Equal(t, expectedUrl.Path, actualUrl.Path)
+		assert.Equal(t, expectedUrl.Query(), actualUrl.Query())
+	}
+}
+
 func Test_parseAzureUrl(t *testing.T) {
 	type args struct {
 		url string
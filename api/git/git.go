@@ -11,9 +11,11 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport/client"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 type cloneOptions struct {
@@ -26,6 +28,7 @@ type cloneOptions struct {
 
 type downloader interface {
 	download(ctx context.Context, dst string, opt cloneOptions) error
+	latestCommitID(ctx context.Context, opt cloneOptions) (string, error)
 }
 
 type gitClient struct {
@@ -62,6 +65,52 @@ func (c gitClient) download(ctx context.Context, dst string, opt cloneOptions) e
 	return nil
 }
 
+func (c gitClient) latestCommitID(ctx context.Context, opt cloneOptions) (string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{opt.repositoryUrl},
+	})
+
+	listOptions := &git.ListOptions{}
+	if opt.password != "" || opt.username != "" {
+		listOptions.Auth = &githttp.BasicAuth{
+			Username: opt.username,
+			Password: opt.password,
+		}
+	}
+
+	refs, err := remote.List(listOptions)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to list repository refs")
+	}
+
+	referenceName := opt.referenceName
+	if referenceName == "" {
+		referenceName = string(plumbing.HEAD)
+	}
+
+	for _, ref := range refs {
+		if ref.Name().String() != referenceName {
+			continue
+		}
+
+		if ref.Type() == plumbing.SymbolicReference {
+			referenceName = ref.Target().String()
+			break
+		}
+
+		return ref.Hash().String(), nil
+	}
+
+	for _, ref := range refs {
+		if ref.Name().String() == referenceName && ref.Type() == plumbing.HashReference {
+			return ref.Hash().String(), nil
+		}
+	}
+
+	return "", errors.Errorf("could not find the reference %s in the repository", referenceName)
+}
+
 // Service represents a service for managing Git.
 type Service struct {
 	httpsCli *http.Client
@@ -101,6 +150,23 @@ func (service *Service) CloneRepository(destination, repositoryURL, referenceNam
 	return service.cloneRepository(destination, options)
 }
 
+// LatestCommitID returns the identifier of the commit the specified reference of a git repository points to.
+// The default branch is used when the reference name is empty.
+func (service *Service) LatestCommitID(repositoryURL, referenceName, username, password string) (string, error) {
+	options := cloneOptions{
+		repositoryUrl: repositoryURL,
+		username:      username,
+		password:      password,
+		referenceName: referenceName,
+	}
+
+	if isAzureUrl(options.repositoryUrl) {
+		return service.azure.latestCommitID(context.TODO(), options)
+	}
+
+	return service.git.latestCommitID(context.TODO(), options)
+}
+
 func (service *Service) cloneRepository(destination string, options cloneOptions) error {
 	if isAzureUrl(options.repositoryUrl) {
 		return service.azure.download(context.TODO(), destination, options)
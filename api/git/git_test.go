@@ -108,6 +108,26 @@ func Test_cloneRepository(t *testing.T) {
 	assert.Equal(t, 3, getCommitHistoryLength(t, err, dir), "cloned repo has incorrect depth")
 }
 
+func Test_latestCommitID(t *testing.T) {
+	service := Service{git: gitClient{preserveGitDirectory: true}} // no need for http client since the test access the repo via file system.
+
+	repo, err := git.PlainOpen(bareRepoDir)
+	if err != nil {
+		t.Fatalf("can't open a git repo at %s with error %v", bareRepoDir, err)
+	}
+	head, err := repo.Reference("refs/heads/main", true)
+	if err != nil {
+		t.Fatalf("can't resolve the main branch with error %v", err)
+	}
+
+	id, err := service.LatestCommitID(bareRepoDir, "refs/heads/main", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash().String(), id)
+
+	_, err = service.LatestCommitID(bareRepoDir, "refs/heads/unknown", "", "")
+	assert.Error(t, err)
+}
+
 func getCommitHistoryLength(t *testing.T, err error, dir string) int {
 	repo, err := git.PlainOpen(dir)
 	if err != nil {
@@ -137,6 +157,11 @@ func (t *testDownloader) download(_ context.Context, _ string, _ cloneOptions) e
 	return nil
 }
 
+func (t *testDownloader) latestCommitID(_ context.Context, _ cloneOptions) (string, error) {
+	t.called = true
+	return "", nil
+}
+
 func Test_cloneRepository_azure(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -4,4 +4,13 @@ type RepoConfig struct {
 	URL            string
 	ReferenceName  string
 	ConfigFilePath string
+	// Credentials used to access the repository, only kept for the resources that are redeployed automatically
+	Authentication *GitAuthentication `json:"Authentication,omitempty"`
+	// Commit the resource was last deployed from
+	ConfigHash string `json:"ConfigHash,omitempty"`
+}
+
+type GitAuthentication struct {
+	Username string
+	Password string
 }
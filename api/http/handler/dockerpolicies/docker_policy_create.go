@@ -0,0 +1,113 @@
+package dockerpolicies
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+)
+
+type dockerPolicyPayload struct {
+	// Name of the policy
+	Name string `example:"production"`
+	// Description of the policy
+	Description string `example:"Restrictions of the production endpoints"`
+	// Whether the violations are only logged instead of denying the requests
+	AuditOnly bool `example:"false"`
+	// The policy applies to these endpoints, or to every endpoint when both the endpoints and the endpoint groups are empty
+	EndpointIDs []portainer.EndpointID
+	// The policy applies to the endpoints of these groups
+	EndpointGroupIDs []portainer.EndpointGroupID
+	// The policy applies to the members of these teams, or to every user when empty
+	TeamIDs []portainer.TeamID
+	Rules   portainer.DockerPolicyRules
+}
+
+func (payload *dockerPolicyPayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("Invalid Docker policy name")
+	}
+	for _, registry := range payload.Rules.AllowedRegistries {
+		if govalidator.IsNull(registry) {
+			return errors.New("Invalid allowed registry")
+		}
+	}
+	for _, label := range payload.Rules.RequiredLabels {
+		if govalidator.IsNull(label) {
+			return errors.New("Invalid required label")
+		}
+	}
+	if payload.Rules.MaxReplicas < 0 {
+		return errors.New("Invalid maximum replicas. Value must be positive")
+	}
+	return nil
+}
+
+// @id DockerPolicyCreate
+// @summary Create a new Docker policy
+// @description Create a new Docker policy, evaluated on the creation of containers and services and on the deployment of stacks.
+// @description **Access policy**: administrator
+// @tags docker_policies
+// @security jwt
+// @accept json
+// @produce json
+// @param body body dockerPolicyPayload true "Docker policy details"
+// @success 200 {object} portainer.DockerPolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 409 "Docker policy name exists"
+// @failure 500 "Server error"
+// @router /docker_policies [post]
+func (handler *Handler) dockerPolicyCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload dockerPolicyPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	handlerErr := handler.checkUniqueName(payload.Name, 0)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	policy := &portainer.DockerPolicy{
+		CreationDate: time.Now().Unix(),
+	}
+	applyPayload(policy, &payload)
+
+	err = handler.DataStore.DockerPolicy().CreateDockerPolicy(policy)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the Docker policy inside the database", err}
+	}
+
+	return response.JSON(w, policy)
+}
+
+func (handler *Handler) checkUniqueName(name string, policyID portainer.DockerPolicyID) *httperror.HandlerError {
+	policies, err := handler.DataStore.DockerPolicy().DockerPolicies()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve Docker policies from the database", err}
+	}
+
+	for _, policy := range policies {
+		if policy.Name == name && policy.ID != policyID {
+			return &httperror.HandlerError{http.StatusConflict, "This name is already associated to a Docker policy", errors.New("A Docker policy already exists with this name")}
+		}
+	}
+
+	return nil
+}
+
+func applyPayload(policy *portainer.DockerPolicy, payload *dockerPolicyPayload) {
+	policy.Name = payload.Name
+	policy.Description = payload.Description
+	policy.AuditOnly = payload.AuditOnly
+	policy.EndpointIDs = payload.EndpointIDs
+	policy.EndpointGroupIDs = payload.EndpointGroupIDs
+	policy.TeamIDs = payload.TeamIDs
+	policy.Rules = payload.Rules
+}
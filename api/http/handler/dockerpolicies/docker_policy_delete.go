@@ -0,0 +1,34 @@
+package dockerpolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id DockerPolicyDelete
+// @summary Remove a Docker policy
+// @description Remove a Docker policy.
+// @description **Access policy**: administrator
+// @tags docker_policies
+// @security jwt
+// @param id path int true "Docker policy identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Docker policy not found"
+// @failure 500 "Server error"
+// @router /docker_policies/{id} [delete]
+func (handler *Handler) dockerPolicyDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.dockerPolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	err := handler.DataStore.DockerPolicy().DeleteDockerPolicy(policy.ID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the Docker policy from the database", err}
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,49 @@
+package dockerpolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+)
+
+// @id DockerPolicyInspect
+// @summary Inspect a Docker policy
+// @description Retrieve details about a Docker policy.
+// @description **Access policy**: administrator
+// @tags docker_policies
+// @security jwt
+// @produce json
+// @param id path int true "Docker policy identifier"
+// @success 200 {object} portainer.DockerPolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Docker policy not found"
+// @failure 500 "Server error"
+// @router /docker_policies/{id} [get]
+func (handler *Handler) dockerPolicyInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.dockerPolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	return response.JSON(w, policy)
+}
+
+func (handler *Handler) dockerPolicyFromRequest(r *http.Request) (*portainer.DockerPolicy, *httperror.HandlerError) {
+	policyID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "Invalid Docker policy identifier route variable", err}
+	}
+
+	policy, err := handler.DataStore.DockerPolicy().DockerPolicy(portainer.DockerPolicyID(policyID))
+	if err == errors.ErrObjectNotFound {
+		return nil, &httperror.HandlerError{http.StatusNotFound, "Unable to find a Docker policy with the specified identifier inside the database", err}
+	} else if err != nil {
+		return nil, &httperror.HandlerError{http.StatusInternalServerError, "Unable to find a Docker policy with the specified identifier inside the database", err}
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,27 @@
+package dockerpolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id DockerPolicyList
+// @summary List Docker policies
+// @description List the Docker policies.
+// @description **Access policy**: administrator
+// @tags docker_policies
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.DockerPolicy "Success"
+// @failure 500 "Server error"
+// @router /docker_policies [get]
+func (handler *Handler) dockerPolicyList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policies, err := handler.DataStore.DockerPolicy().DockerPolicies()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve Docker policies from the database", err}
+	}
+
+	return response.JSON(w, policies)
+}
@@ -0,0 +1,52 @@
+package dockerpolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id DockerPolicyUpdate
+// @summary Update a Docker policy
+// @description Replace the scope and the rules of a Docker policy.
+// @description **Access policy**: administrator
+// @tags docker_policies
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Docker policy identifier"
+// @param body body dockerPolicyPayload true "Docker policy details"
+// @success 200 {object} portainer.DockerPolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Docker policy not found"
+// @failure 409 "Docker policy name exists"
+// @failure 500 "Server error"
+// @router /docker_policies/{id} [put]
+func (handler *Handler) dockerPolicyUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.dockerPolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	var payload dockerPolicyPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	handlerErr = handler.checkUniqueName(payload.Name, policy.ID)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	applyPayload(policy, &payload)
+
+	err = handler.DataStore.DockerPolicy().UpdateDockerPolicy(policy.ID, policy)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the Docker policy changes inside the database", err}
+	}
+
+	return response.JSON(w, policy)
+}
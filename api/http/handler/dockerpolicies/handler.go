@@ -0,0 +1,35 @@
+package dockerpolicies
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+)
+
+// Handler is the HTTP handler used to handle Docker policy operations.
+type Handler struct {
+	*mux.Router
+	DataStore portainer.DataStore
+}
+
+// NewHandler creates a handler to manage Docker policy operations.
+func NewHandler(bouncer *security.RequestBouncer) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+	h.Handle("/docker_policies",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.dockerPolicyCreate))).Methods(http.MethodPost)
+	h.Handle("/docker_policies",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.dockerPolicyList))).Methods(http.MethodGet)
+	h.Handle("/docker_policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.dockerPolicyInspect))).Methods(http.MethodGet)
+	h.Handle("/docker_policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.dockerPolicyUpdate))).Methods(http.MethodPut)
+	h.Handle("/docker_policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.dockerPolicyDelete))).Methods(http.MethodDelete)
+
+	return h
+}
@@ -0,0 +1,264 @@
+package edgedevices
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+var errDeviceNotPending = errors.New("Only pending edge devices can be approved or rejected")
+
+type edgeDeviceApprovePayload struct {
+	// Name of the endpoint created for the device, defaults to its EdgeID
+	Name string `example:"store-0042"`
+	// Endpoint group of the endpoint created for the device, defaults to the Unassigned group
+	GroupID portainer.EndpointGroupID `example:"1"`
+	// Tags of the endpoint created for the device
+	TagIDs []portainer.TagID `example:"1"`
+}
+
+func (payload *edgeDeviceApprovePayload) Validate(r *http.Request) error {
+	if payload.GroupID == 0 {
+		payload.GroupID = 1
+	}
+	if payload.TagIDs == nil {
+		payload.TagIDs = []portainer.TagID{}
+	}
+	return nil
+}
+
+// @id EdgeDeviceApprove
+// @summary Approve an edge device
+// @description Approve a pending edge device and create its endpoint. The endpoint joins the edge groups matching its tags,
+// @description the agent retrieves the endpoint the next time it enrols.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "EdgeDevice Id"
+// @param body body edgeDeviceApprovePayload true "Endpoint details"
+// @success 200 {object} portainer.EdgeDevice
+// @failure 400
+// @failure 404
+// @failure 409 Edge device is not pending
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/{id}/approve [post]
+func (handler *Handler) edgeDeviceApprove(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	edgeDeviceID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge device identifier route variable", err}
+	}
+
+	var payload edgeDeviceApprovePayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	edgeDevice, httpErr := handler.pendingEdgeDevice(portainer.EdgeDeviceID(edgeDeviceID))
+	if httpErr != nil {
+		return httpErr
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the settings from the database", err}
+	}
+
+	if payload.Name == "" {
+		payload.Name = edgeDevice.EdgeID
+	}
+
+	err = handler.approveEdgeDevice(edgeDevice, payload.Name, payload.GroupID, payload.TagIDs, settings.EdgeFleetKey)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to approve the edge device", err}
+	}
+
+	return response.JSON(w, edgeDevice)
+}
+
+// @id EdgeDeviceReject
+// @summary Reject an edge device
+// @description Reject a pending edge device, its enrolment requests are denied until the device is deleted.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @produce json
+// @param id path int true "EdgeDevice Id"
+// @success 200 {object} portainer.EdgeDevice
+// @failure 400
+// @failure 404
+// @failure 409 Edge device is not pending
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/{id}/reject [post]
+func (handler *Handler) edgeDeviceReject(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	edgeDeviceID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge device identifier route variable", err}
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	edgeDevice, httpErr := handler.pendingEdgeDevice(portainer.EdgeDeviceID(edgeDeviceID))
+	if httpErr != nil {
+		return httpErr
+	}
+
+	edgeDevice.Status = portainer.EdgeDeviceStatusRejected
+
+	err = handler.DataStore.EdgeDevice().UpdateEdgeDevice(edgeDevice.ID, edgeDevice)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the edge device changes inside the database", err}
+	}
+
+	return response.JSON(w, edgeDevice)
+}
+
+func (handler *Handler) pendingEdgeDevice(edgeDeviceID portainer.EdgeDeviceID) (*portainer.EdgeDevice, *httperror.HandlerError) {
+	edgeDevice, err := handler.DataStore.EdgeDevice().EdgeDevice(edgeDeviceID)
+	if err == bolterrors.ErrObjectNotFound {
+		return nil, &httperror.HandlerError{http.StatusNotFound, "Unable to find an edge device with the specified identifier inside the database", err}
+	} else if err != nil {
+		return nil, &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an edge device with the specified identifier inside the database", err}
+	}
+
+	if edgeDevice.Status != portainer.EdgeDeviceStatusPending {
+		return nil, &httperror.HandlerError{http.StatusConflict, "Unable to update the edge device", errDeviceNotPending}
+	}
+
+	return edgeDevice, nil
+}
+
+// applyEnrolmentRule approves or rejects a pending edge device according to an enrolment rule
+func (handler *Handler) applyEnrolmentRule(edgeDevice *portainer.EdgeDevice, rule *portainer.EdgeEnrolmentRule, fleetKey string) error {
+	if rule.Action == portainer.EdgeEnrolmentRuleReject {
+		edgeDevice.Status = portainer.EdgeDeviceStatusRejected
+		return handler.DataStore.EdgeDevice().UpdateEdgeDevice(edgeDevice.ID, edgeDevice)
+	}
+
+	groupID := rule.GroupID
+	if groupID == 0 {
+		groupID = 1
+	}
+
+	return handler.approveEdgeDevice(edgeDevice, edgeDevice.EdgeID, groupID, rule.TagIDs, fleetKey)
+}
+
+// approveEdgeDevice creates the edge endpoint of a device, bound to its EdgeID, and marks the device as approved
+func (handler *Handler) approveEdgeDevice(edgeDevice *portainer.EdgeDevice, name string, groupID portainer.EndpointGroupID, tagIDs []portainer.TagID, fleetKey string) error {
+	portainerURL, err := edge.FleetKeyURL(fleetKey)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve the Portainer URL from the fleet key: %w", err)
+	}
+
+	portainerHost, err := portainerHost(portainerURL)
+	if err != nil {
+		return err
+	}
+
+	endpointGroup, err := handler.DataStore.EndpointGroup().EndpointGroup(groupID)
+	if err != nil {
+		return fmt.Errorf("unable to find the endpoint group %d: %w", groupID, err)
+	}
+
+	tags := make([]*portainer.Tag, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		tag, err := handler.DataStore.Tag().Tag(tagID)
+		if err != nil {
+			return fmt.Errorf("unable to find the tag %d: %w", tagID, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	endpointType := portainer.EdgeAgentOnDockerEnvironment
+	if edgeDevice.Platform == portainer.AgentPlatformKubernetes {
+		endpointType = portainer.EdgeAgentOnKubernetesEnvironment
+	}
+
+	endpointID := handler.DataStore.Endpoint().GetNextIdentifier()
+	endpoint := &portainer.Endpoint{
+		ID:      portainer.EndpointID(endpointID),
+		Name:    name,
+		URL:     portainerHost,
+		Type:    endpointType,
+		GroupID: groupID,
+		TLSConfig: portainer.TLSConfiguration{
+			TLS: false,
+		},
+		UserAccessPolicies: portainer.UserAccessPolicies{},
+		TeamAccessPolicies: portainer.TeamAccessPolicies{},
+		Extensions:         []portainer.EndpointExtension{},
+		TagIDs:             tagIDs,
+		Status:             portainer.EndpointStatusUp,
+		Snapshots:          []portainer.DockerSnapshot{},
+		EdgeID:             edgeDevice.EdgeID,
+		EdgeKey:            handler.ReverseTunnelService.GenerateEdgeKey(portainerURL, portainerHost, endpointID),
+		Kubernetes:         portainer.KubernetesDefault(),
+		SecuritySettings: portainer.EndpointSecuritySettings{
+			AllowSysctlSettingForRegularUsers:         true,
+			AllowBindMountsForRegularUsers:            true,
+			AllowPrivilegedModeForRegularUsers:        true,
+			AllowHostNamespaceForRegularUsers:         true,
+			AllowContainerCapabilitiesForRegularUsers: true,
+			AllowDeviceMappingForRegularUsers:         true,
+			AllowStackManagementForRegularUsers:       true,
+		},
+	}
+
+	err = handler.DataStore.Endpoint().CreateEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		tag.Endpoints[endpoint.ID] = true
+
+		err = handler.DataStore.Tag().UpdateTag(tag.ID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return err
+	}
+
+	edgeStacks, err := handler.DataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return err
+	}
+
+	relation := &portainer.EndpointRelation{
+		EndpointID: endpoint.ID,
+		EdgeStacks: map[portainer.EdgeStackID]bool{},
+	}
+	for _, edgeStackID := range edge.EndpointRelatedEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks) {
+		relation.EdgeStacks[edgeStackID] = true
+	}
+
+	err = handler.DataStore.EndpointRelation().CreateEndpointRelation(relation)
+	if err != nil {
+		return err
+	}
+
+	edgeDevice.Status = portainer.EdgeDeviceStatusApproved
+	edgeDevice.EndpointID = endpoint.ID
+	edgeDevice.EdgeKeyDelivered = false
+
+	return handler.DataStore.EdgeDevice().UpdateEdgeDevice(edgeDevice.ID, edgeDevice)
+}
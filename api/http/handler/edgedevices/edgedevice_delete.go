@@ -0,0 +1,49 @@
+package edgedevices
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+)
+
+// @id EdgeDeviceDelete
+// @summary Delete an edge device
+// @description Remove an edge device from the waiting room, the device is registered again the next time it enrols.
+// @description The endpoint of an approved device is not removed.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @param id path int true "EdgeDevice Id"
+// @success 204
+// @failure 400
+// @failure 404
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/{id} [delete]
+func (handler *Handler) edgeDeviceDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	edgeDeviceID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge device identifier route variable", err}
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	_, err = handler.DataStore.EdgeDevice().EdgeDevice(portainer.EdgeDeviceID(edgeDeviceID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an edge device with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an edge device with the specified identifier inside the database", err}
+	}
+
+	err = handler.DataStore.EdgeDevice().DeleteEdgeDevice(portainer.EdgeDeviceID(edgeDeviceID))
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the edge device from the database", err}
+	}
+
+	return response.Empty(w)
+}
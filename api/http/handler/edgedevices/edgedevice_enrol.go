@@ -0,0 +1,172 @@
+package edgedevices
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+type edgeDeviceEnrolResponse struct {
+	// Status of the device: pending or approved
+	Status string `json:"status" example:"approved"`
+	// Endpoint of the device, once approved
+	EndpointID portainer.EndpointID `json:"endpointId,omitempty" example:"1"`
+	// Edge key of the endpoint, only sent in the first response following the approval
+	EdgeKey string `json:"edgeKey,omitempty" example:""`
+	// Secret the device must send in the X-PortainerAgent-EnrolmentSecret header of its next enrolment requests,
+	// only sent in the response to the first enrolment request
+	EnrolmentSecret string `json:"enrolmentSecret,omitempty" example:""`
+	// Interval (in seconds) at which a pending device should enrol again
+	CheckinInterval int `json:"checkin" example:"5"`
+}
+
+// @id EdgeDeviceEnrol
+// @summary Enrol an edge device
+// @description Endpoint for edge agents configured with the fleet key. An agent with an unknown EdgeID is registered
+// @description as a pending edge device, or approved/rejected by the first matching enrolment rule.
+// @description The response to the first enrolment request contains an enrolment secret that the agent must present in its next requests.
+// @description Once the device is approved, the response contains the endpoint the agent must use, the edge key is only sent once.
+// @description **Access policy**: restricted to edge agents presenting the fleet key
+// @tags edge_devices
+// @produce json
+// @param X-PortainerAgent-EdgeID header string true "EdgeID of the agent"
+// @param X-PortainerAgent-FleetKey header string true "Fleet key"
+// @param X-PortainerAgent-FleetKeySecret header string true "Secret of the fleet key"
+// @param X-PortainerAgent-EnrolmentSecret header string false "Enrolment secret issued on the first enrolment request"
+// @param Portainer-Agent-Platform header int true "Platform of the agent: 1 (Docker) or 2 (Kubernetes)"
+// @success 200 {object} edgeDeviceEnrolResponse
+// @failure 400
+// @failure 403 Enrolment disabled, invalid fleet key, invalid enrolment secret, rejected or revoked device
+// @failure 500
+// @router /edge_devices/enrol [post]
+func (handler *Handler) edgeDeviceEnrol(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the settings from the database", err}
+	}
+
+	if settings.EdgeFleetKey == "" {
+		return &httperror.HandlerError{http.StatusForbidden, "Edge device enrolment is disabled", errors.New("no fleet key is defined")}
+	}
+
+	fleetKey := r.Header.Get(portainer.PortainerAgentFleetKeyHeader)
+	fleetKeySecret := r.Header.Get(portainer.PortainerAgentFleetKeySecretHeader)
+	if !edge.ValidateFleetKey(settings.EdgeFleetKey, settings.EdgeFleetKeySecret, fleetKey, fleetKeySecret) {
+		return &httperror.HandlerError{http.StatusForbidden, "Permission denied to enrol the edge device", errors.New("invalid fleet key")}
+	}
+
+	edgeID := r.Header.Get(portainer.PortainerAgentEdgeIDHeader)
+	if edgeID == "" {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid Edge identifier", errors.New("missing Edge identifier")}
+	}
+
+	agentPlatform, err := strconv.Atoi(r.Header.Get(portainer.HTTPResponseAgentPlatform))
+	if err != nil || (portainer.AgentPlatform(agentPlatform) != portainer.AgentPlatformDocker && portainer.AgentPlatform(agentPlatform) != portainer.AgentPlatformKubernetes) {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid agent platform header", errors.New("invalid agent platform")}
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	now := time.Now().Unix()
+
+	var enrolmentSecret string
+
+	edgeDevice, err := handler.DataStore.EdgeDevice().EdgeDeviceByEdgeID(edgeID)
+	if err == bolterrors.ErrObjectNotFound {
+		enrolmentSecret = generateEnrolmentSecret()
+
+		edgeDevice = &portainer.EdgeDevice{
+			EdgeID:              edgeID,
+			Platform:            portainer.AgentPlatform(agentPlatform),
+			Status:              portainer.EdgeDeviceStatusPending,
+			CreationDate:        now,
+			EnrolmentSecretHash: edge.HashEnrolmentSecret(enrolmentSecret),
+		}
+
+		err = handler.DataStore.EdgeDevice().CreateEdgeDevice(edgeDevice)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the edge device inside the database", err}
+		}
+
+		rule := edge.MatchEnrolmentRule(settings.EdgeEnrolmentRules, edgeID)
+		if rule != nil {
+			err = handler.applyEnrolmentRule(edgeDevice, rule, settings.EdgeFleetKey)
+			if err != nil {
+				return &httperror.HandlerError{http.StatusInternalServerError, "Unable to apply the enrolment rule to the edge device", err}
+			}
+		}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find the edge device inside the database", err}
+	} else if edgeDevice.EnrolmentSecretHash == "" {
+		// the device enrolled before enrolment secrets were issued, it receives its secret on its next request
+		enrolmentSecret = generateEnrolmentSecret()
+		edgeDevice.EnrolmentSecretHash = edge.HashEnrolmentSecret(enrolmentSecret)
+	} else if !edge.ValidateEnrolmentSecret(edgeDevice, r.Header.Get(portainer.PortainerAgentEnrolmentSecretHeader)) {
+		return &httperror.HandlerError{http.StatusForbidden, "Permission denied to enrol the edge device", errors.New("invalid enrolment secret")}
+	}
+
+	edgeDevice.RemoteAddress = security.StripAddrPort(r.RemoteAddr)
+	edgeDevice.LastCheckInDate = now
+
+	var endpoint *portainer.Endpoint
+	if edgeDevice.Status == portainer.EdgeDeviceStatusApproved {
+		endpoint, err = handler.DataStore.Endpoint().Endpoint(edgeDevice.EndpointID)
+		if err == bolterrors.ErrObjectNotFound {
+			// the endpoint was removed after the approval, the device waits for a new approval
+			edgeDevice.Status = portainer.EdgeDeviceStatusPending
+			edgeDevice.EndpointID = 0
+			edgeDevice.EdgeKeyDelivered = false
+		} else if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find the endpoint of the edge device inside the database", err}
+		}
+	}
+
+	var edgeKey string
+	if edgeDevice.Status == portainer.EdgeDeviceStatusApproved && edge.CanDeliverEdgeKey(edgeDevice, endpoint) {
+		edgeKey = endpoint.EdgeKey
+		edgeDevice.EdgeKeyDelivered = true
+	}
+
+	err = handler.DataStore.EdgeDevice().UpdateEdgeDevice(edgeDevice.ID, edgeDevice)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the edge device changes inside the database", err}
+	}
+
+	switch edgeDevice.Status {
+	case portainer.EdgeDeviceStatusRejected:
+		return &httperror.HandlerError{http.StatusForbidden, "The edge device enrolment was rejected", errors.New("rejected edge device")}
+	case portainer.EdgeDeviceStatusApproved:
+		if endpoint.EdgeCredentials.Revoked {
+			return &httperror.HandlerError{http.StatusForbidden, "The edge device was revoked", edge.ErrEdgeDeviceRevoked}
+		}
+
+		return response.JSON(w, &edgeDeviceEnrolResponse{
+			Status:          "approved",
+			EndpointID:      endpoint.ID,
+			EdgeKey:         edgeKey,
+			EnrolmentSecret: enrolmentSecret,
+			CheckinInterval: settings.EdgeAgentCheckinInterval,
+		})
+	}
+
+	return response.JSON(w, &edgeDeviceEnrolResponse{
+		Status:          "pending",
+		EnrolmentSecret: enrolmentSecret,
+		CheckinInterval: settings.EdgeAgentCheckinInterval,
+	})
+}
+
+func generateEnrolmentSecret() string {
+	return base64.RawURLEncoding.EncodeToString(securecookie.GenerateRandomKey(32))
+}
@@ -0,0 +1,125 @@
+package edgedevices
+
+import (
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gorilla/securecookie"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type fleetKeyGeneratePayload struct {
+	// URL of the Portainer instance the edge agents connect to
+	URL string `example:"https://portainer.mydomain.tld" validate:"required"`
+}
+
+func (payload *fleetKeyGeneratePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.URL) || !govalidator.IsURL(payload.URL) {
+		return errors.New("Invalid Portainer URL")
+	}
+	return nil
+}
+
+type fleetKeyResponse struct {
+	// Key to configure on the edge agents of the fleet
+	FleetKey string `example:"aHR0cHM6Ly9wb3J0YWluZXIubXlkb21haW4udGxk"`
+	// Secret the edge agents of the fleet must send in the X-PortainerAgent-FleetKeySecret header
+	FleetKeySecret string `example:"0Ff9mQ2b7k1qXwZr"`
+}
+
+// @id EdgeDeviceFleetKeyGenerate
+// @summary Generate the fleet key
+// @description Generate the key shared by a fleet of edge agents to enrol without a pre-created endpoint.
+// @description Agents presenting the key with an unknown EdgeID are registered as pending edge devices.
+// @description The agents present the key in the X-PortainerAgent-FleetKey header and its secret in the X-PortainerAgent-FleetKeySecret header.
+// @description Generating a new key revokes the previous one.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @accept json
+// @produce json
+// @param body body fleetKeyGeneratePayload true "Fleet key details"
+// @success 200 {object} fleetKeyResponse
+// @failure 400
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/fleet_key [post]
+func (handler *Handler) edgeDeviceFleetKeyGenerate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload fleetKeyGeneratePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	portainerHost, err := portainerHost(payload.URL)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid Portainer URL", err}
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the settings from the database", err}
+	}
+
+	settings.EdgeFleetKey = handler.ReverseTunnelService.GenerateEdgeKey(payload.URL, portainerHost, 0)
+	settings.EdgeFleetKeySecret = base64.RawURLEncoding.EncodeToString(securecookie.GenerateRandomKey(32))
+
+	err = handler.DataStore.Settings().UpdateSettings(settings)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the settings inside the database", err}
+	}
+
+	return response.JSON(w, &fleetKeyResponse{FleetKey: settings.EdgeFleetKey, FleetKeySecret: settings.EdgeFleetKeySecret})
+}
+
+// @id EdgeDeviceFleetKeyDelete
+// @summary Revoke the fleet key
+// @description Revoke the fleet key, disabling the enrolment of edge devices. Approved devices keep their endpoint.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @success 204
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/fleet_key [delete]
+func (handler *Handler) edgeDeviceFleetKeyDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the settings from the database", err}
+	}
+
+	settings.EdgeFleetKey = ""
+	settings.EdgeFleetKeySecret = ""
+
+	err = handler.DataStore.Settings().UpdateSettings(settings)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the settings inside the database", err}
+	}
+
+	return response.Empty(w)
+}
+
+// portainerHost returns the host of the Portainer URL, used by the edge agents to reach the tunnel server
+func portainerHost(portainerURL string) (string, error) {
+	parsedURL, err := url.Parse(portainerURL)
+	if err != nil {
+		return "", err
+	}
+
+	host, _, err := net.SplitHostPort(parsedURL.Host)
+	if err != nil {
+		host = parsedURL.Host
+	}
+
+	if host == "localhost" {
+		return "", errors.New("cannot use localhost as Portainer URL")
+	}
+
+	return host, nil
+}
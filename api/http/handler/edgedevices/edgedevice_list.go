@@ -0,0 +1,47 @@
+package edgedevices
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+)
+
+// @id EdgeDeviceList
+// @summary List the edge devices
+// @description List the edge devices which enrolled with the fleet key.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @produce json
+// @param status query int false "Only list the devices with this status: 1 (pending), 2 (approved) or 3 (rejected)"
+// @success 200 {array} portainer.EdgeDevice
+// @failure 400
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices [get]
+func (handler *Handler) edgeDeviceList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	status, err := request.RetrieveNumericQueryParameter(r, "status", true)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid query parameter: status", err}
+	}
+
+	edgeDevices, err := handler.DataStore.EdgeDevice().EdgeDevices()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge devices from the database", err}
+	}
+
+	if status != 0 {
+		filteredEdgeDevices := make([]portainer.EdgeDevice, 0)
+		for _, edgeDevice := range edgeDevices {
+			if edgeDevice.Status == portainer.EdgeDeviceStatus(status) {
+				filteredEdgeDevices = append(filteredEdgeDevices, edgeDevice)
+			}
+		}
+		edgeDevices = filteredEdgeDevices
+	}
+
+	return response.JSON(w, edgeDevices)
+}
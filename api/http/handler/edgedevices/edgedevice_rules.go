@@ -0,0 +1,153 @@
+package edgedevices
+
+import (
+	"log"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+type edgeDeviceRulesUpdatePayload struct {
+	// Rules applied to the edge devices when they enrol, the first rule matching the EdgeID of a device applies
+	Rules []portainer.EdgeEnrolmentRule
+}
+
+func (payload *edgeDeviceRulesUpdatePayload) Validate(r *http.Request) error {
+	if payload.Rules == nil {
+		payload.Rules = []portainer.EdgeEnrolmentRule{}
+	}
+	return edge.ValidateEnrolmentRules(payload.Rules)
+}
+
+// @id EdgeDeviceRulesInspect
+// @summary Retrieve the enrolment rules
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.EdgeEnrolmentRule
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/rules [get]
+func (handler *Handler) edgeDeviceRulesInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the settings from the database", err}
+	}
+
+	rules := settings.EdgeEnrolmentRules
+	if rules == nil {
+		rules = []portainer.EdgeEnrolmentRule{}
+	}
+
+	return response.JSON(w, rules)
+}
+
+// @id EdgeDeviceRulesUpdate
+// @summary Update the enrolment rules
+// @description Replace the rules approving or rejecting the edge devices by EdgeID prefix when they enrol.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @accept json
+// @produce json
+// @param body body edgeDeviceRulesUpdatePayload true "Enrolment rules"
+// @success 200 {array} portainer.EdgeEnrolmentRule
+// @failure 400
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/rules [put]
+func (handler *Handler) edgeDeviceRulesUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload edgeDeviceRulesUpdatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	for _, rule := range payload.Rules {
+		if rule.Action != portainer.EdgeEnrolmentRuleApprove {
+			continue
+		}
+
+		if rule.GroupID != 0 {
+			_, err := handler.DataStore.EndpointGroup().EndpointGroup(rule.GroupID)
+			if err != nil {
+				return &httperror.HandlerError{http.StatusBadRequest, "Unable to find the endpoint group of an enrolment rule inside the database", err}
+			}
+		}
+
+		for _, tagID := range rule.TagIDs {
+			_, err := handler.DataStore.Tag().Tag(tagID)
+			if err != nil {
+				return &httperror.HandlerError{http.StatusBadRequest, "Unable to find a tag of an enrolment rule inside the database", err}
+			}
+		}
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the settings from the database", err}
+	}
+
+	settings.EdgeEnrolmentRules = payload.Rules
+
+	err = handler.DataStore.Settings().UpdateSettings(settings)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the settings inside the database", err}
+	}
+
+	return response.JSON(w, settings.EdgeEnrolmentRules)
+}
+
+// @id EdgeDeviceRulesApply
+// @summary Apply the enrolment rules to the pending edge devices
+// @description Approve or reject the pending edge devices matching an enrolment rule.
+// @description **Access policy**: administrator
+// @tags edge_devices
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.EdgeDevice "The edge devices approved or rejected"
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_devices/rules/apply [post]
+func (handler *Handler) edgeDeviceRulesApply(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the settings from the database", err}
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+
+	edgeDevices, err := handler.DataStore.EdgeDevice().EdgeDevices()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge devices from the database", err}
+	}
+
+	updatedEdgeDevices := make([]portainer.EdgeDevice, 0)
+	for idx := range edgeDevices {
+		edgeDevice := &edgeDevices[idx]
+		if edgeDevice.Status != portainer.EdgeDeviceStatusPending {
+			continue
+		}
+
+		rule := edge.MatchEnrolmentRule(settings.EdgeEnrolmentRules, edgeDevice.EdgeID)
+		if rule == nil {
+			continue
+		}
+
+		err = handler.applyEnrolmentRule(edgeDevice, rule, settings.EdgeFleetKey)
+		if err != nil {
+			log.Printf("[ERROR] [http,edge_devices] [edge_id: %s] [message: unable to apply the enrolment rule] [error: %s]", edgeDevice.EdgeID, err)
+			continue
+		}
+
+		updatedEdgeDevices = append(updatedEdgeDevices, *edgeDevice)
+	}
+
+	return response.JSON(w, updatedEdgeDevices)
+}
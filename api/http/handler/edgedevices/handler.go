@@ -0,0 +1,48 @@
+package edgedevices
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+)
+
+// Handler is the HTTP handler used to handle the edge devices waiting for approval.
+type Handler struct {
+	*mux.Router
+	// mu serializes enrolments and approvals so that a device is only associated to a single endpoint
+	mu                   sync.Mutex
+	DataStore            portainer.DataStore
+	ReverseTunnelService portainer.ReverseTunnelService
+}
+
+// NewHandler creates a handler to manage edge device operations.
+func NewHandler(bouncer *security.RequestBouncer) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+	h.Handle("/edge_devices/enrol",
+		bouncer.PublicAccess(httperror.LoggerHandler(h.edgeDeviceEnrol))).Methods(http.MethodPost)
+	h.Handle("/edge_devices/fleet_key",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceFleetKeyGenerate)))).Methods(http.MethodPost)
+	h.Handle("/edge_devices/fleet_key",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceFleetKeyDelete)))).Methods(http.MethodDelete)
+	h.Handle("/edge_devices/rules",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceRulesInspect)))).Methods(http.MethodGet)
+	h.Handle("/edge_devices/rules",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceRulesUpdate)))).Methods(http.MethodPut)
+	h.Handle("/edge_devices/rules/apply",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceRulesApply)))).Methods(http.MethodPost)
+	h.Handle("/edge_devices",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceList)))).Methods(http.MethodGet)
+	h.Handle("/edge_devices/{id}",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceDelete)))).Methods(http.MethodDelete)
+	h.Handle("/edge_devices/{id}/approve",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceApprove)))).Methods(http.MethodPost)
+	h.Handle("/edge_devices/{id}/reject",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeDeviceReject)))).Methods(http.MethodPost)
+	return h
+}
@@ -2,10 +2,31 @@ package edgegroups
 
 import (
 	"github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type endpointSetType map[portainer.EndpointID]bool
 
+// getDynamicGroupEndpoints returns the endpoints matched by the rule of a dynamic Edge group, or by its tags
+// when it has no rule
+func (handler *Handler) getDynamicGroupEndpoints(edgeGroup *portainer.EdgeGroup) ([]portainer.EndpointID, error) {
+	if edgeGroup.Rule == nil {
+		return handler.getEndpointsByTags(edgeGroup.TagIDs, edgeGroup.PartialMatch)
+	}
+
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	endpointGroups, err := handler.DataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	return edge.EdgeGroupRelatedEndpoints(edgeGroup, endpoints, endpointGroups), nil
+}
+
 func (handler *Handler) getEndpointsByTags(tagIDs []portainer.TagID, partialMatch bool) ([]portainer.EndpointID, error) {
 	if len(tagIDs) == 0 {
 		return []portainer.EndpointID{}, nil
@@ -2,6 +2,7 @@ package edgegroups
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/asaskevich/govalidator"
@@ -9,6 +10,7 @@ import (
 	"github.com/portainer/libhttp/request"
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type edgeGroupCreatePayload struct {
@@ -17,19 +19,29 @@ type edgeGroupCreatePayload struct {
 	TagIDs       []portainer.TagID
 	Endpoints    []portainer.EndpointID
 	PartialMatch bool
+	// Variables rendered into the edge stacks deployed on the endpoints of this group
+	Variables map[string]string
+	// Membership rule of a dynamic group, replacing the tag matching
+	Rule *portainer.EdgeGroupRule
 }
 
 func (payload *edgeGroupCreatePayload) Validate(r *http.Request) error {
 	if govalidator.IsNull(payload.Name) {
 		return errors.New("Invalid Edge group name")
 	}
-	if payload.Dynamic && (payload.TagIDs == nil || len(payload.TagIDs) == 0) {
-		return errors.New("TagIDs is mandatory for a dynamic Edge group")
+	if payload.Dynamic && payload.Rule == nil && (payload.TagIDs == nil || len(payload.TagIDs) == 0) {
+		return errors.New("TagIDs or Rule is mandatory for a dynamic Edge group")
+	}
+	if payload.Dynamic && payload.Rule != nil {
+		err := edge.ValidateEdgeGroupRule(payload.Rule)
+		if err != nil {
+			return fmt.Errorf("Invalid Edge group rule: %w", err)
+		}
 	}
 	if !payload.Dynamic && (payload.Endpoints == nil || len(payload.Endpoints) == 0) {
 		return errors.New("Endpoints is mandatory for a static Edge group")
 	}
-	return nil
+	return edge.ValidateVariableNames(payload.Variables)
 }
 
 // @id EdgeGroupCreate
@@ -68,10 +80,12 @@ func (handler *Handler) edgeGroupCreate(w http.ResponseWriter, r *http.Request)
 		TagIDs:       []portainer.TagID{},
 		Endpoints:    []portainer.EndpointID{},
 		PartialMatch: payload.PartialMatch,
+		Variables:    payload.Variables,
 	}
 
 	if edgeGroup.Dynamic {
 		edgeGroup.TagIDs = payload.TagIDs
+		edgeGroup.Rule = payload.Rule
 	} else {
 		endpointIDs := []portainer.EndpointID{}
 		for _, endpointID := range payload.Endpoints {
@@ -0,0 +1,93 @@
+package edgegroups
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+type edgeGroupPreviewPayload struct {
+	TagIDs       []portainer.TagID
+	PartialMatch bool
+	// Membership rule, replacing the tag matching
+	Rule *portainer.EdgeGroupRule
+}
+
+func (payload *edgeGroupPreviewPayload) Validate(r *http.Request) error {
+	if payload.Rule == nil {
+		if len(payload.TagIDs) == 0 {
+			return errors.New("TagIDs or Rule is mandatory")
+		}
+		return nil
+	}
+
+	err := edge.ValidateEdgeGroupRule(payload.Rule)
+	if err != nil {
+		return fmt.Errorf("Invalid Edge group rule: %w", err)
+	}
+
+	return nil
+}
+
+type edgeGroupPreviewEndpoint struct {
+	ID      portainer.EndpointID      `json:"Id" example:"1"`
+	Name    string                    `json:"Name" example:"store-0042"`
+	Type    portainer.EndpointType    `json:"Type" example:"4"`
+	GroupID portainer.EndpointGroupID `json:"GroupId" example:"1"`
+}
+
+// @id EdgeGroupPreview
+// @summary Preview the endpoints of a dynamic EdgeGroup
+// @description List the endpoints matched by a rule or a set of tags, without saving an Edge group
+// @tags edge_groups
+// @security jwt
+// @accept json
+// @produce json
+// @param body body edgeGroupPreviewPayload true "Membership rule or tags"
+// @success 200 {array} edgeGroupPreviewEndpoint
+// @failure 400
+// @failure 503 Edge compute features are disabled
+// @failure 500
+// @router /edge_groups/preview [post]
+func (handler *Handler) edgeGroupPreview(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload edgeGroupPreviewPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	edgeGroup := &portainer.EdgeGroup{
+		Dynamic:      true,
+		TagIDs:       payload.TagIDs,
+		PartialMatch: payload.PartialMatch,
+		Rule:         payload.Rule,
+	}
+
+	endpointIDs, err := handler.getDynamicGroupEndpoints(edgeGroup)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoints and endpoint groups for Edge group", err}
+	}
+
+	endpoints := make([]edgeGroupPreviewEndpoint, 0, len(endpointIDs))
+	for _, endpointID := range endpointIDs {
+		endpoint, err := handler.DataStore.Endpoint().Endpoint(endpointID)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoint from the database", err}
+		}
+
+		endpoints = append(endpoints, edgeGroupPreviewEndpoint{
+			ID:      endpoint.ID,
+			Name:    endpoint.Name,
+			Type:    endpoint.Type,
+			GroupID: endpoint.GroupID,
+		})
+	}
+
+	return response.JSON(w, endpoints)
+}
@@ -2,6 +2,7 @@ package edgegroups
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/asaskevich/govalidator"
@@ -19,19 +20,29 @@ type edgeGroupUpdatePayload struct {
 	TagIDs       []portainer.TagID
 	Endpoints    []portainer.EndpointID
 	PartialMatch *bool
+	// Variables rendered into the edge stacks deployed on the endpoints of this group
+	Variables map[string]string
+	// Membership rule of a dynamic group, replacing the tag matching
+	Rule *portainer.EdgeGroupRule
 }
 
 func (payload *edgeGroupUpdatePayload) Validate(r *http.Request) error {
 	if govalidator.IsNull(payload.Name) {
 		return errors.New("Invalid Edge group name")
 	}
-	if payload.Dynamic && (payload.TagIDs == nil || len(payload.TagIDs) == 0) {
-		return errors.New("TagIDs is mandatory for a dynamic Edge group")
+	if payload.Dynamic && payload.Rule == nil && (payload.TagIDs == nil || len(payload.TagIDs) == 0) {
+		return errors.New("TagIDs or Rule is mandatory for a dynamic Edge group")
+	}
+	if payload.Dynamic && payload.Rule != nil {
+		err := edge.ValidateEdgeGroupRule(payload.Rule)
+		if err != nil {
+			return fmt.Errorf("Invalid Edge group rule: %w", err)
+		}
 	}
 	if !payload.Dynamic && (payload.Endpoints == nil || len(payload.Endpoints) == 0) {
 		return errors.New("Endpoints is mandatory for a static Edge group")
 	}
-	return nil
+	return edge.ValidateVariableNames(payload.Variables)
 }
 
 // @id EgeGroupUpdate
@@ -92,8 +103,10 @@ func (handler *Handler) edgeGroupUpdate(w http.ResponseWriter, r *http.Request)
 	oldRelatedEndpoints := edge.EdgeGroupRelatedEndpoints(edgeGroup, endpoints, endpointGroups)
 
 	edgeGroup.Dynamic = payload.Dynamic
+	edgeGroup.Rule = nil
 	if edgeGroup.Dynamic {
 		edgeGroup.TagIDs = payload.TagIDs
+		edgeGroup.Rule = payload.Rule
 	} else {
 		endpointIDs := []portainer.EndpointID{}
 		for _, endpointID := range payload.Endpoints {
@@ -113,6 +126,31 @@ func (handler *Handler) edgeGroupUpdate(w http.ResponseWriter, r *http.Request)
 		edgeGroup.PartialMatch = *payload.PartialMatch
 	}
 
+	if payload.Variables != nil {
+		edgeGroup.Variables = payload.Variables
+	}
+
+	edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve Edge groups from the database", err}
+	}
+
+	for i := range edgeGroups {
+		if edgeGroups[i].ID == edgeGroup.ID {
+			edgeGroups[i] = *edgeGroup
+		}
+	}
+
+	edgeStacks, err := handler.DataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge stacks from the database", err}
+	}
+
+	err = edge.ValidateEdgeGroupEdgeStacks(edgeGroup.ID, edgeStacks, endpoints, endpointGroups, edgeGroups)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Edge group contains endpoints which cannot deploy its edge stacks", err}
+	}
+
 	err = handler.DataStore.EdgeGroup().UpdateEdgeGroup(edgeGroup.ID, edgeGroup)
 	if err != nil {
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist Edge group changes inside the database", err}
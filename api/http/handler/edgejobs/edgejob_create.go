@@ -50,6 +50,12 @@ type edgeJobCreateFromFileContentPayload struct {
 	Recurring      bool
 	Endpoints      []portainer.EndpointID
 	FileContent    string
+	// Duration in seconds after which a run is stopped, 0 means no timeout
+	Timeout int `example:"300"`
+	// Number of times a failed or timed out run is retried
+	MaxRetries int `example:"3"`
+	// Delay in seconds between two attempts of a run
+	RetryInterval int `example:"60"`
 }
 
 func (payload *edgeJobCreateFromFileContentPayload) Validate(r *http.Request) error {
@@ -73,7 +79,7 @@ func (payload *edgeJobCreateFromFileContentPayload) Validate(r *http.Request) er
 		return errors.New("Invalid script file content")
 	}
 
-	return nil
+	return validateRunPolicy(payload.Timeout, payload.MaxRetries, payload.RetryInterval)
 }
 
 func (handler *Handler) createEdgeJobFromFileContent(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
@@ -99,6 +105,9 @@ type edgeJobCreateFromFilePayload struct {
 	Recurring      bool
 	Endpoints      []portainer.EndpointID
 	File           []byte
+	Timeout        int
+	MaxRetries     int
+	RetryInterval  int
 }
 
 func (payload *edgeJobCreateFromFilePayload) Validate(r *http.Request) error {
@@ -131,7 +140,25 @@ func (payload *edgeJobCreateFromFilePayload) Validate(r *http.Request) error {
 	}
 	payload.File = file
 
-	return nil
+	timeout, err := retrieveOptionalNumericMultiPartFormValue(r, "Timeout")
+	if err != nil {
+		return errors.New("Invalid timeout")
+	}
+	payload.Timeout = timeout
+
+	maxRetries, err := retrieveOptionalNumericMultiPartFormValue(r, "MaxRetries")
+	if err != nil {
+		return errors.New("Invalid maximum retries")
+	}
+	payload.MaxRetries = maxRetries
+
+	retryInterval, err := retrieveOptionalNumericMultiPartFormValue(r, "RetryInterval")
+	if err != nil {
+		return errors.New("Invalid retry interval")
+	}
+	payload.RetryInterval = retryInterval
+
+	return validateRunPolicy(payload.Timeout, payload.MaxRetries, payload.RetryInterval)
 }
 
 func (handler *Handler) createEdgeJobFromFile(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
@@ -164,6 +191,9 @@ func (handler *Handler) createEdgeJobObjectFromFilePayload(payload *edgeJobCreat
 		Created:        time.Now().Unix(),
 		Endpoints:      endpoints,
 		Version:        1,
+		Timeout:        payload.Timeout,
+		MaxRetries:     payload.MaxRetries,
+		RetryInterval:  payload.RetryInterval,
 	}
 
 	return edgeJob
@@ -182,6 +212,9 @@ func (handler *Handler) createEdgeJobObjectFromFileContentPayload(payload *edgeJ
 		Created:        time.Now().Unix(),
 		Endpoints:      endpoints,
 		Version:        1,
+		Timeout:        payload.Timeout,
+		MaxRetries:     payload.MaxRetries,
+		RetryInterval:  payload.RetryInterval,
 	}
 
 	return edgeJob
@@ -222,6 +255,33 @@ func (handler *Handler) addAndPersistEdgeJob(edgeJob *portainer.EdgeJob, file []
 	return handler.DataStore.EdgeJob().CreateEdgeJob(edgeJob)
 }
 
+// retrieveOptionalNumericMultiPartFormValue returns the value of some form data as an integer, 0 when the form data value is not found.
+// Unlike request.RetrieveNumericMultiPartFormValue, an error is only returned when the value is found but is not an integer.
+func retrieveOptionalNumericMultiPartFormValue(r *http.Request, name string) (int, error) {
+	value, _ := request.RetrieveMultiPartFormValue(r, name, true)
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
+
+func validateRunPolicy(timeout, maxRetries, retryInterval int) error {
+	if timeout < 0 {
+		return errors.New("Invalid timeout. Value must be positive")
+	}
+
+	if maxRetries < 0 {
+		return errors.New("Invalid maximum retries. Value must be positive")
+	}
+
+	if retryInterval < 0 {
+		return errors.New("Invalid retry interval. Value must be positive")
+	}
+
+	return nil
+}
+
 func convertEndpointsToMetaObject(endpoints []portainer.EndpointID) map[portainer.EndpointID]portainer.EdgeJobEndpointMeta {
 	endpointsMap := map[portainer.EndpointID]portainer.EdgeJobEndpointMeta{}
 
@@ -0,0 +1,53 @@
+package edgejobs
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+)
+
+// @id EdgeJobRunsList
+// @summary Fetch the run history of an EdgeJob
+// @description List the runs reported by the edge agents, ordered by endpoint and by creation
+// @tags edge_jobs
+// @security jwt
+// @produce json
+// @param id path string true "EdgeJob Id"
+// @param endpointId query int false "Only list the runs on this endpoint"
+// @success 200 {array} portainer.EdgeJobRun
+// @failure 500
+// @failure 400
+// @failure 404
+// @failure 503 Edge compute features are disabled
+// @router /edge_jobs/{id}/runs [get]
+func (handler *Handler) edgeJobRunsList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	edgeJobID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid Edge job identifier route variable", err}
+	}
+
+	endpointID, _ := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+
+	edgeJob, err := handler.DataStore.EdgeJob().EdgeJob(portainer.EdgeJobID(edgeJobID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an Edge job with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an Edge job with the specified identifier inside the database", err}
+	}
+
+	var runs []portainer.EdgeJobRun
+	if endpointID != 0 {
+		runs, err = handler.DataStore.EdgeJobRun().EdgeJobEndpointRuns(edgeJob.ID, portainer.EndpointID(endpointID))
+	} else {
+		runs, err = handler.DataStore.EdgeJobRun().EdgeJobRuns(edgeJob.ID)
+	}
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the Edge job runs from the database", err}
+	}
+
+	return response.JSON(w, runs)
+}
@@ -9,23 +9,51 @@ import (
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
 	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type taskContainer struct {
 	ID         string                      `json:"Id"`
 	EndpointID portainer.EndpointID        `json:"EndpointId"`
 	LogsStatus portainer.EdgeJobLogsStatus `json:"LogsStatus"`
+	// Status of the most recent run, 0 when the job never ran on the endpoint
+	LastRunStatus portainer.EdgeJobRunStatus `json:"LastRunStatus" example:"1"`
+	// Exit code of the most recent run
+	LastExitCode int `json:"LastExitCode" example:"0"`
+	// Duration in seconds of the most recent run, retries included
+	LastDuration int64 `json:"LastDuration" example:"60"`
+	// Unix timestamp of the end of the most recent run
+	LastRunTime int64 `json:"LastRunTime" example:"1625132460"`
+	// Number of recorded runs per outcome
+	Runs      int `json:"Runs" example:"3"`
+	Succeeded int `json:"Succeeded" example:"2"`
+	Failed    int `json:"Failed" example:"1"`
+	TimedOut  int `json:"TimedOut" example:"0"`
+}
+
+type tasksSummary struct {
+	// Number of endpoints per outcome of their most recent run
+	Succeeded int `json:"Succeeded" example:"2"`
+	Failed    int `json:"Failed" example:"1"`
+	TimedOut  int `json:"TimedOut" example:"0"`
+	// Number of endpoints on which the job did not run yet
+	Pending int `json:"Pending" example:"1"`
+}
+
+type tasksListResponse struct {
+	Tasks   []taskContainer `json:"Tasks"`
+	Summary tasksSummary    `json:"Summary"`
 }
 
 // @id EdgeJobTasksList
 // @summary Fetch the list of tasks on an EdgeJob
-// @description
+// @description The outcome of the most recent run on each endpoint is aggregated in the summary of the response.
 // @tags edge_jobs
 // @security jwt
 // @accept json
 // @produce json
 // @param id path string true "EdgeJob Id"
-// @success 200 {array} taskContainer
+// @success 200 {object} tasksListResponse
 // @failure 500
 // @failure 400
 // @failure 503 Edge compute features are disabled
@@ -43,7 +71,15 @@ func (handler *Handler) edgeJobTasksList(w http.ResponseWriter, r *http.Request)
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an Edge job with the specified identifier inside the database", err}
 	}
 
+	runs, err := handler.DataStore.EdgeJobRun().EdgeJobRuns(edgeJob.ID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the Edge job runs from the database", err}
+	}
+
+	summaries := edge.SummarizeEdgeJobRuns(runs)
+
 	tasks := make([]taskContainer, 0)
+	outcomes := map[portainer.EdgeJobRunStatus]int{}
 
 	for endpointID, meta := range edgeJob.Endpoints {
 
@@ -53,8 +89,28 @@ func (handler *Handler) edgeJobTasksList(w http.ResponseWriter, r *http.Request)
 			LogsStatus: meta.LogsStatus,
 		}
 
+		if summary, ok := summaries[endpointID]; ok {
+			cronTask.LastRunStatus = summary.LastRun.Status
+			cronTask.LastExitCode = summary.LastRun.ExitCode
+			cronTask.LastDuration = summary.LastRun.EndTime - summary.LastRun.StartTime
+			cronTask.LastRunTime = summary.LastRun.EndTime
+			cronTask.Runs = summary.Runs
+			cronTask.Succeeded = summary.Succeeded
+			cronTask.Failed = summary.Failed
+			cronTask.TimedOut = summary.TimedOut
+		}
+		outcomes[cronTask.LastRunStatus]++
+
 		tasks = append(tasks, cronTask)
 	}
 
-	return response.JSON(w, tasks)
+	return response.JSON(w, tasksListResponse{
+		Tasks: tasks,
+		Summary: tasksSummary{
+			Succeeded: outcomes[portainer.EdgeJobRunSucceeded],
+			Failed:    outcomes[portainer.EdgeJobRunFailed],
+			TimedOut:  outcomes[portainer.EdgeJobRunTimedOut],
+			Pending:   outcomes[0],
+		},
+	})
 }
@@ -19,12 +19,24 @@ type edgeJobUpdatePayload struct {
 	Recurring      *bool
 	Endpoints      []portainer.EndpointID
 	FileContent    *string
+	Timeout        *int
+	MaxRetries     *int
+	RetryInterval  *int
 }
 
 func (payload *edgeJobUpdatePayload) Validate(r *http.Request) error {
 	if payload.Name != nil && !govalidator.Matches(*payload.Name, `^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`) {
 		return errors.New("Invalid Edge job name format. Allowed characters are: [a-zA-Z0-9_.-]")
 	}
+	if payload.Timeout != nil && *payload.Timeout < 0 {
+		return errors.New("Invalid timeout. Value must be positive")
+	}
+	if payload.MaxRetries != nil && *payload.MaxRetries < 0 {
+		return errors.New("Invalid maximum retries. Value must be positive")
+	}
+	if payload.RetryInterval != nil && *payload.RetryInterval < 0 {
+		return errors.New("Invalid retry interval. Value must be positive")
+	}
 	return nil
 }
 
@@ -122,6 +134,21 @@ func (handler *Handler) updateEdgeSchedule(edgeJob *portainer.EdgeJob, payload *
 		updateVersion = true
 	}
 
+	if payload.Timeout != nil {
+		edgeJob.Timeout = *payload.Timeout
+		updateVersion = true
+	}
+
+	if payload.MaxRetries != nil {
+		edgeJob.MaxRetries = *payload.MaxRetries
+		updateVersion = true
+	}
+
+	if payload.RetryInterval != nil {
+		edgeJob.RetryInterval = *payload.RetryInterval
+		updateVersion = true
+	}
+
 	if updateVersion {
 		edgeJob.Version++
 	}
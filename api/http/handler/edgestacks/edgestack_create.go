@@ -2,7 +2,9 @@ package edgestacks
 
 import (
 	"errors"
+	"log"
 	"net/http"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/filesystem"
+	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/internal/edge"
 )
 
@@ -73,7 +76,7 @@ func (handler *Handler) edgeStackCreate(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	return response.JSON(w, edgeStack)
+	return response.JSON(w, hideGitCredentials(edgeStack))
 }
 
 func (handler *Handler) createSwarmStack(method string, r *http.Request) (*portainer.EdgeStack, error) {
@@ -95,6 +98,12 @@ type swarmStackFromFileContentPayload struct {
 	StackFileContent string `example:"version: 3\n services:\n web:\n image:nginx" validate:"required"`
 	// List of identifiers of EdgeGroups
 	EdgeGroups []portainer.EdgeGroupID `example:"1"`
+	// Type of the stack file (0 - Compose file, 1 - Kubernetes manifest)
+	DeploymentType portainer.EdgeStackDeploymentType `example:"0"`
+	// Namespace the Kubernetes manifest is applied to
+	Namespace string `example:"default"`
+	// Staged rollout of the stack, the stack is released to every endpoint at once when omitted
+	Rollout *rolloutPayload
 }
 
 func (payload *swarmStackFromFileContentPayload) Validate(r *http.Request) error {
@@ -107,7 +116,13 @@ func (payload *swarmStackFromFileContentPayload) Validate(r *http.Request) error
 	if payload.EdgeGroups == nil || len(payload.EdgeGroups) == 0 {
 		return errors.New("Edge Groups are mandatory for an Edge stack")
 	}
-	return nil
+	if payload.Rollout != nil {
+		err := edge.ValidateEdgeStackRollout(payload.Rollout.Strategy, payload.Rollout.BatchSize, payload.Rollout.ErrorThreshold)
+		if err != nil {
+			return err
+		}
+	}
+	return edge.ValidateEdgeStackDeployment(payload.DeploymentType, payload.Namespace)
 }
 
 func (handler *Handler) createSwarmStackFromFileContent(r *http.Request) (*portainer.EdgeStack, error) {
@@ -122,15 +137,27 @@ func (handler *Handler) createSwarmStackFromFileContent(r *http.Request) (*porta
 		return nil, err
 	}
 
+	err = handler.validateEdgeStack([]byte(payload.StackFileContent), payload.EdgeGroups, payload.DeploymentType)
+	if err != nil {
+		return nil, err
+	}
+
 	stackID := handler.DataStore.EdgeStack().GetNextIdentifier()
 	stack := &portainer.EdgeStack{
-		ID:           portainer.EdgeStackID(stackID),
-		Name:         payload.Name,
-		EntryPoint:   filesystem.ComposeFileDefaultName,
-		CreationDate: time.Now().Unix(),
-		EdgeGroups:   payload.EdgeGroups,
-		Status:       make(map[portainer.EndpointID]portainer.EdgeStackStatus),
-		Version:      1,
+		ID:             portainer.EdgeStackID(stackID),
+		Name:           payload.Name,
+		EntryPoint:     defaultEntryPoint(payload.DeploymentType),
+		CreationDate:   time.Now().Unix(),
+		EdgeGroups:     payload.EdgeGroups,
+		Status:         make(map[portainer.EndpointID]portainer.EdgeStackStatus),
+		Version:        1,
+		DeploymentType: payload.DeploymentType,
+		Namespace:      payload.Namespace,
+	}
+
+	stack.Rollout, err = handler.newRollout(stack, payload.Rollout)
+	if err != nil {
+		return nil, err
 	}
 
 	stackFolder := strconv.Itoa(int(stack.ID))
@@ -165,6 +192,12 @@ type swarmStackFromGitRepositoryPayload struct {
 	ComposeFilePathInRepository string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// List of identifiers of EdgeGroups
 	EdgeGroups []portainer.EdgeGroupID `example:"1"`
+	// Type of the stack file (0 - Compose file, 1 - Kubernetes manifest)
+	DeploymentType portainer.EdgeStackDeploymentType `example:"0"`
+	// Namespace the Kubernetes manifest is applied to
+	Namespace string `example:"default"`
+	// Staged rollout of the stack, the stack is released to every endpoint at once when omitted
+	Rollout *rolloutPayload
 }
 
 func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) error {
@@ -178,12 +211,18 @@ func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) err
 		return errors.New("Invalid repository credentials. Username and password must be specified when authentication is enabled")
 	}
 	if govalidator.IsNull(payload.ComposeFilePathInRepository) {
-		payload.ComposeFilePathInRepository = filesystem.ComposeFileDefaultName
+		payload.ComposeFilePathInRepository = defaultEntryPoint(payload.DeploymentType)
 	}
 	if payload.EdgeGroups == nil || len(payload.EdgeGroups) == 0 {
 		return errors.New("Edge Groups are mandatory for an Edge stack")
 	}
-	return nil
+	if payload.Rollout != nil {
+		err := edge.ValidateEdgeStackRollout(payload.Rollout.Strategy, payload.Rollout.BatchSize, payload.Rollout.ErrorThreshold)
+		if err != nil {
+			return err
+		}
+	}
+	return edge.ValidateEdgeStackDeployment(payload.DeploymentType, payload.Namespace)
 }
 
 func (handler *Handler) createSwarmStackFromGitRepository(r *http.Request) (*portainer.EdgeStack, error) {
@@ -200,13 +239,15 @@ func (handler *Handler) createSwarmStackFromGitRepository(r *http.Request) (*por
 
 	stackID := handler.DataStore.EdgeStack().GetNextIdentifier()
 	stack := &portainer.EdgeStack{
-		ID:           portainer.EdgeStackID(stackID),
-		Name:         payload.Name,
-		EntryPoint:   payload.ComposeFilePathInRepository,
-		CreationDate: time.Now().Unix(),
-		EdgeGroups:   payload.EdgeGroups,
-		Status:       make(map[portainer.EndpointID]portainer.EdgeStackStatus),
-		Version:      1,
+		ID:             portainer.EdgeStackID(stackID),
+		Name:           payload.Name,
+		EntryPoint:     payload.ComposeFilePathInRepository,
+		CreationDate:   time.Now().Unix(),
+		EdgeGroups:     payload.EdgeGroups,
+		Status:         make(map[portainer.EndpointID]portainer.EdgeStackStatus),
+		Version:        1,
+		DeploymentType: payload.DeploymentType,
+		Namespace:      payload.Namespace,
 	}
 
 	projectPath := handler.FileService.GetEdgeStackProjectPath(strconv.Itoa(int(stack.ID)))
@@ -224,8 +265,48 @@ func (handler *Handler) createSwarmStackFromGitRepository(r *http.Request) (*por
 		return nil, err
 	}
 
+	stackFileContent, err := handler.FileService.GetFileContent(path.Join(projectPath, stack.EntryPoint))
+	if err != nil {
+		handler.removeProject(projectPath)
+		return nil, err
+	}
+
+	err = handler.validateEdgeStack(stackFileContent, stack.EdgeGroups, stack.DeploymentType)
+	if err != nil {
+		handler.removeProject(projectPath)
+		return nil, err
+	}
+
+	commitID, err := handler.GitService.LatestCommitID(payload.RepositoryURL, payload.RepositoryReferenceName, repositoryUsername, repositoryPassword)
+	if err != nil {
+		handler.removeProject(projectPath)
+		return nil, err
+	}
+
+	stack.GitConfig = &gittypes.RepoConfig{
+		URL:            payload.RepositoryURL,
+		ReferenceName:  payload.RepositoryReferenceName,
+		ConfigFilePath: payload.ComposeFilePathInRepository,
+		ConfigHash:     commitID,
+	}
+	edge.RecordEdgeStackCommit(stack, commitID)
+
+	if payload.RepositoryAuthentication {
+		stack.GitConfig.Authentication = &gittypes.GitAuthentication{
+			Username: repositoryUsername,
+			Password: repositoryPassword,
+		}
+	}
+
+	stack.Rollout, err = handler.newRollout(stack, payload.Rollout)
+	if err != nil {
+		handler.removeProject(projectPath)
+		return nil, err
+	}
+
 	err = handler.DataStore.EdgeStack().CreateEdgeStack(stack)
 	if err != nil {
+		handler.removeProject(projectPath)
 		return nil, err
 	}
 
@@ -236,6 +317,9 @@ type swarmStackFromFileUploadPayload struct {
 	Name             string
 	StackFileContent []byte
 	EdgeGroups       []portainer.EdgeGroupID
+	DeploymentType   portainer.EdgeStackDeploymentType
+	Namespace        string
+	Rollout          *rolloutPayload
 }
 
 func (payload *swarmStackFromFileUploadPayload) Validate(r *http.Request) error {
@@ -257,7 +341,30 @@ func (payload *swarmStackFromFileUploadPayload) Validate(r *http.Request) error
 		return errors.New("Edge Groups are mandatory for an Edge stack")
 	}
 	payload.EdgeGroups = edgeGroups
-	return nil
+
+	deploymentType, err := request.RetrieveNumericMultiPartFormValue(r, "DeploymentType", true)
+	if err != nil {
+		return errors.New("Invalid deployment type")
+	}
+	payload.DeploymentType = portainer.EdgeStackDeploymentType(deploymentType)
+
+	namespace, _ := request.RetrieveMultiPartFormValue(r, "Namespace", true)
+	payload.Namespace = namespace
+
+	var rollout *rolloutPayload
+	err = request.RetrieveMultiPartFormJSONValue(r, "Rollout", &rollout, true)
+	if err != nil {
+		return errors.New("Invalid rollout")
+	}
+	if rollout != nil {
+		err = edge.ValidateEdgeStackRollout(rollout.Strategy, rollout.BatchSize, rollout.ErrorThreshold)
+		if err != nil {
+			return err
+		}
+	}
+	payload.Rollout = rollout
+
+	return edge.ValidateEdgeStackDeployment(payload.DeploymentType, payload.Namespace)
 }
 
 func (handler *Handler) createSwarmStackFromFileUpload(r *http.Request) (*portainer.EdgeStack, error) {
@@ -272,15 +379,27 @@ func (handler *Handler) createSwarmStackFromFileUpload(r *http.Request) (*portai
 		return nil, err
 	}
 
+	err = handler.validateEdgeStack([]byte(payload.StackFileContent), payload.EdgeGroups, payload.DeploymentType)
+	if err != nil {
+		return nil, err
+	}
+
 	stackID := handler.DataStore.EdgeStack().GetNextIdentifier()
 	stack := &portainer.EdgeStack{
-		ID:           portainer.EdgeStackID(stackID),
-		Name:         payload.Name,
-		EntryPoint:   filesystem.ComposeFileDefaultName,
-		CreationDate: time.Now().Unix(),
-		EdgeGroups:   payload.EdgeGroups,
-		Status:       make(map[portainer.EndpointID]portainer.EdgeStackStatus),
-		Version:      1,
+		ID:             portainer.EdgeStackID(stackID),
+		Name:           payload.Name,
+		EntryPoint:     defaultEntryPoint(payload.DeploymentType),
+		CreationDate:   time.Now().Unix(),
+		EdgeGroups:     payload.EdgeGroups,
+		Status:         make(map[portainer.EndpointID]portainer.EdgeStackStatus),
+		Version:        1,
+		DeploymentType: payload.DeploymentType,
+		Namespace:      payload.Namespace,
+	}
+
+	stack.Rollout, err = handler.newRollout(stack, payload.Rollout)
+	if err != nil {
+		return nil, err
 	}
 
 	stackFolder := strconv.Itoa(int(stack.ID))
@@ -298,6 +417,36 @@ func (handler *Handler) createSwarmStackFromFileUpload(r *http.Request) (*portai
 	return stack, nil
 }
 
+// newRollout creates the staged rollout of the first version of an edge stack, nil when no rollout is requested.
+// The endpoints that are not released yet do not deploy the stack until their batch is released.
+func (handler *Handler) newRollout(stack *portainer.EdgeStack, payload *rolloutPayload) (*portainer.EdgeStackRollout, error) {
+	if payload == nil {
+		return nil, nil
+	}
+
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	endpointGroups, err := handler.DataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	relatedEndpoints, err := edge.EdgeStackRelatedEndpoints(stack.EdgeGroups, endpoints, endpointGroups, edgeGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	return edge.NewEdgeStackRollout(payload.Strategy, payload.BatchSize, payload.ErrorThreshold, stack.Version, 0, "", relatedEndpoints), nil
+}
+
 func (handler *Handler) validateUniqueName(name string) error {
 	edgeStacks, err := handler.DataStore.EdgeStack().EdgeStacks()
 	if err != nil {
@@ -311,3 +460,48 @@ func (handler *Handler) validateUniqueName(name string) error {
 	}
 	return nil
 }
+
+// validateEdgeStack checks that every endpoint of the edge groups can deploy the stack file
+// and that the variables it references are defined for each of them
+func (handler *Handler) validateEdgeStack(stackFileContent []byte, edgeGroupIDs []portainer.EdgeGroupID, deploymentType portainer.EdgeStackDeploymentType) error {
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return err
+	}
+
+	endpointGroups, err := handler.DataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return err
+	}
+
+	relatedEndpoints, err := edge.EdgeStackRelatedEndpoints(edgeGroupIDs, endpoints, endpointGroups, edgeGroups)
+	if err != nil {
+		return err
+	}
+
+	err = edge.ValidateEdgeStackEndpoints(deploymentType, relatedEndpoints, endpoints)
+	if err != nil {
+		return err
+	}
+
+	return edge.ValidateEdgeStackVariables(stackFileContent, edgeGroupIDs, endpoints, endpointGroups, edgeGroups)
+}
+
+func defaultEntryPoint(deploymentType portainer.EdgeStackDeploymentType) string {
+	if deploymentType == portainer.EdgeStackDeploymentKubernetes {
+		return filesystem.ManifestFileDefaultName
+	}
+	return filesystem.ComposeFileDefaultName
+}
+
+func (handler *Handler) removeProject(projectPath string) {
+	err := handler.FileService.RemoveDirectory(projectPath)
+	if err != nil {
+		log.Printf("[WARN] [http,edge_stacks] [error: %s] [message: unable to remove edge stack project directory]", err)
+	}
+}
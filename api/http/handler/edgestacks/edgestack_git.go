@@ -0,0 +1,62 @@
+package edgestacks
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/portainer/portainer/api/internal/edgegit"
+)
+
+// @id EdgeStackGitPull
+// @summary Pull the repository of an EdgeStack
+// @description Checks the repository an EdgeStack is deployed from and bumps its version when the reference moved to a new commit
+// @tags edge_stacks
+// @security jwt
+// @produce json
+// @param id path string true "EdgeStack Id"
+// @success 200 {object} portainer.EdgeStack
+// @failure 400 "The edge stack is not deployed from a git repository"
+// @failure 404
+// @failure 409 "A rollout of the edge stack is in progress"
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_stacks/{id}/git/pull [post]
+func (handler *Handler) edgeStackGitPull(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	edgeStackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge stack identifier route variable", err}
+	}
+
+	edgeStack, err := handler.EdgeStackGitService.PullEdgeStack(portainer.EdgeStackID(edgeStackID))
+	switch {
+	case err == bolterrors.ErrObjectNotFound:
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an edge stack with the specified identifier inside the database", err}
+	case err == edgegit.ErrNotGitBacked:
+		return &httperror.HandlerError{http.StatusBadRequest, "Unable to pull the edge stack repository", err}
+	case err == edgegit.ErrRolloutInProgress:
+		return &httperror.HandlerError{http.StatusConflict, "Unable to pull the edge stack repository", err}
+	case err != nil:
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to pull the edge stack repository", err}
+	}
+
+	return response.JSON(w, hideGitCredentials(edgeStack))
+}
+
+// hideGitCredentials returns a copy of the edge stack without the password of its repository
+func hideGitCredentials(edgeStack *portainer.EdgeStack) *portainer.EdgeStack {
+	if edgeStack.GitConfig == nil || edgeStack.GitConfig.Authentication == nil {
+		return edgeStack
+	}
+
+	stack := *edgeStack
+	gitConfig := *edgeStack.GitConfig
+	gitConfig.Authentication = &gittypes.GitAuthentication{Username: edgeStack.GitConfig.Authentication.Username}
+	stack.GitConfig = &gitConfig
+
+	return &stack
+}
@@ -36,5 +36,5 @@ func (handler *Handler) edgeStackInspect(w http.ResponseWriter, r *http.Request)
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an edge stack with the specified identifier inside the database", err}
 	}
 
-	return response.JSON(w, edgeStack)
+	return response.JSON(w, hideGitCredentials(edgeStack))
 }
@@ -0,0 +1,92 @@
+package edgestacks
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+// @id EdgeStackRolloutPause
+// @summary Pause the rollout of an EdgeStack
+// @description Endpoints that were not released yet keep the previous version until the rollout is resumed
+// @tags edge_stacks
+// @security jwt
+// @produce json
+// @param id path string true "EdgeStack Id"
+// @success 200 {object} portainer.EdgeStack
+// @failure 400
+// @failure 404
+// @failure 409 "The rollout is not in progress"
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_stacks/{id}/rollout/pause [post]
+func (handler *Handler) edgeStackRolloutPause(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	return handler.updateRollout(w, r, edge.PauseEdgeStackRollout)
+}
+
+// @id EdgeStackRolloutResume
+// @summary Resume the rollout of an EdgeStack
+// @description Endpoints that failed to deploy the stack before the rollout was resumed no longer count towards the error threshold
+// @tags edge_stacks
+// @security jwt
+// @produce json
+// @param id path string true "EdgeStack Id"
+// @success 200 {object} portainer.EdgeStack
+// @failure 400
+// @failure 404
+// @failure 409 "The rollout is not paused"
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_stacks/{id}/rollout/resume [post]
+func (handler *Handler) edgeStackRolloutResume(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	return handler.updateRollout(w, r, edge.ResumeEdgeStackRollout)
+}
+
+// @id EdgeStackRolloutAbort
+// @summary Abort the rollout of an EdgeStack
+// @description Endpoints that were not released keep the previous version
+// @tags edge_stacks
+// @security jwt
+// @produce json
+// @param id path string true "EdgeStack Id"
+// @success 200 {object} portainer.EdgeStack
+// @failure 400
+// @failure 404
+// @failure 409 "The rollout is already finished"
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_stacks/{id}/rollout/abort [post]
+func (handler *Handler) edgeStackRolloutAbort(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	return handler.updateRollout(w, r, edge.AbortEdgeStackRollout)
+}
+
+func (handler *Handler) updateRollout(w http.ResponseWriter, r *http.Request, update func(edgeStack *portainer.EdgeStack) error) *httperror.HandlerError {
+	edgeStackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge stack identifier route variable", err}
+	}
+
+	edgeStack, err := handler.DataStore.EdgeStack().EdgeStack(portainer.EdgeStackID(edgeStackID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an edge stack with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an edge stack with the specified identifier inside the database", err}
+	}
+
+	err = update(edgeStack)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusConflict, "Unable to update the edge stack rollout", err}
+	}
+
+	err = handler.DataStore.EdgeStack().UpdateEdgeStack(edgeStack.ID, edgeStack)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the edge stack changes inside the database", err}
+	}
+
+	return response.JSON(w, hideGitCredentials(edgeStack))
+}
@@ -0,0 +1,126 @@
+package edgestacks
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+// @id EdgeStackStatusHistory
+// @summary Retrieve the status history of an EdgeStack on an endpoint
+// @description Retrieve the statuses reported by an endpoint for an EdgeStack, with the deployment output provided by the edge agent.
+// @description Entries are ordered from the oldest to the most recent.
+// @description **Access policy**: administrator
+// @tags edge_stacks
+// @security jwt
+// @produce json
+// @param id path int true "EdgeStack Id"
+// @param endpointId path int true "Endpoint Id"
+// @param version query int false "Only return the statuses reported for this version of the EdgeStack"
+// @success 200 {array} portainer.EdgeStackStatusHistoryEntry
+// @failure 400
+// @failure 404
+// @failure 500
+// @failure 503 Edge compute features are disabled
+// @router /edge_stacks/{id}/status/{endpointId}/history [get]
+func (handler *Handler) edgeStackStatusHistory(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	edgeStackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge stack identifier route variable", err}
+	}
+
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "endpointId")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	version, err := request.RetrieveNumericQueryParameter(r, "version", true)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid query parameter: version", err}
+	}
+
+	edgeStack, err := handler.DataStore.EdgeStack().EdgeStack(portainer.EdgeStackID(edgeStackID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an edge stack with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an edge stack with the specified identifier inside the database", err}
+	}
+
+	entries, err := handler.DataStore.EdgeStackStatusHistory().EdgeStackStatusHistory(edgeStack.ID, portainer.EndpointID(endpointID))
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the edge stack status history from the database", err}
+	}
+
+	if version != 0 {
+		filteredEntries := make([]portainer.EdgeStackStatusHistoryEntry, 0)
+		for _, entry := range entries {
+			if entry.Version == version {
+				filteredEntries = append(filteredEntries, entry)
+			}
+		}
+		entries = filteredEntries
+	}
+
+	return response.JSON(w, entries)
+}
+
+// recordStatusHistory adds a status reported by an endpoint to the status history of an edge stack
+// and deletes the entries exceeding the retention limits
+func (handler *Handler) recordStatusHistory(edgeStack *portainer.EdgeStack, status *portainer.EdgeStackStatus, version int, output string) error {
+	entry := &portainer.EdgeStackStatusHistoryEntry{
+		EdgeStackID: edgeStack.ID,
+		EndpointID:  status.EndpointID,
+		Version:     version,
+		Type:        status.Type,
+		Error:       status.Error,
+		Output:      edge.TruncateEdgeStackStatusOutput(output),
+		CommitHash:  status.CommitHash,
+		Time:        time.Now().Unix(),
+	}
+
+	err := handler.DataStore.EdgeStackStatusHistory().CreateEdgeStackStatusHistoryEntry(entry)
+	if err != nil {
+		return err
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	maxEntries := settings.EdgeStackStatusHistoryMaxEntries
+	if maxEntries < 1 {
+		maxEntries = portainer.DefaultEdgeStackStatusHistoryMaxEntries
+	}
+
+	retention, err := time.ParseDuration(settings.EdgeStackStatusHistoryRetention)
+	if err != nil || retention <= 0 {
+		retention, _ = time.ParseDuration(portainer.DefaultEdgeStackStatusHistoryRetention)
+	}
+
+	entries, err := handler.DataStore.EdgeStackStatusHistory().EdgeStackStatusHistory(edgeStack.ID, status.EndpointID)
+	if err != nil {
+		return err
+	}
+
+	limit := edge.EdgeStackStatusHistoryLimit(entries, maxEntries, retention, time.Now())
+	if limit == 0 {
+		return nil
+	}
+
+	return handler.DataStore.EdgeStackStatusHistory().DeleteEdgeStackStatusHistoryEntries(edgeStack.ID, status.EndpointID, limit)
+}
+
+func (handler *Handler) deleteStatusHistory(edgeStackID portainer.EdgeStackID) {
+	err := handler.DataStore.EdgeStackStatusHistory().DeleteEdgeStackStatusHistory(edgeStackID)
+	if err != nil {
+		log.Printf("[WARN] [http,edge_stacks] [edge_stack: %d] [message: unable to remove the edge stack status history] [error: %s]", edgeStackID, err)
+	}
+}
@@ -10,12 +10,19 @@ import (
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
 	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type updateStatusPayload struct {
 	Error      string
 	Status     *portainer.EdgeStackStatusType
 	EndpointID *portainer.EndpointID
+	// Outcome of the apply of each resource, for Kubernetes manifests
+	Resources []portainer.EdgeStackResourceStatus
+	// Output of the deployment, kept in the status history
+	Output string
+	// Version of the edge stack deployed by the agent, the version sent at check-in is assumed when omitted
+	Version *int `example:"2"`
 }
 
 func (payload *updateStatusPayload) Validate(r *http.Request) error {
@@ -25,9 +32,20 @@ func (payload *updateStatusPayload) Validate(r *http.Request) error {
 	if payload.EndpointID == nil {
 		return errors.New("Invalid EndpointID")
 	}
+	if payload.Version != nil && *payload.Version < 1 {
+		return errors.New("Invalid Version")
+	}
 	if *payload.Status == portainer.StatusError && govalidator.IsNull(payload.Error) {
 		return errors.New("Error message is mandatory when status is error")
 	}
+	for _, resource := range payload.Resources {
+		if govalidator.IsNull(resource.Kind) || govalidator.IsNull(resource.Name) {
+			return errors.New("Kind and name are mandatory for a resource status")
+		}
+		if resource.Type == portainer.StatusError && govalidator.IsNull(resource.Error) {
+			return errors.New("Error message is mandatory when a resource status is error")
+		}
+	}
 	return nil
 }
 
@@ -75,17 +93,37 @@ func (handler *Handler) edgeStackStatusUpdate(w http.ResponseWriter, r *http.Req
 		return &httperror.HandlerError{http.StatusForbidden, "Permission denied to access endpoint", err}
 	}
 
-	stack.Status[*payload.EndpointID] = portainer.EdgeStackStatus{
+	version := edge.EdgeStackEndpointVersion(stack, *payload.EndpointID)
+	if payload.Version != nil {
+		version = *payload.Version
+	}
+
+	// the commit is only known for the versions recorded when the repository was pulled
+	commitHash := stack.Status[*payload.EndpointID].CommitHash
+	if deployedCommitHash, ok := stack.CommitHashes[version]; ok {
+		commitHash = deployedCommitHash
+	}
+
+	status := portainer.EdgeStackStatus{
 		Type:       *payload.Status,
 		Error:      payload.Error,
 		EndpointID: *payload.EndpointID,
+		CommitHash: commitHash,
+		Resources:  payload.Resources,
 	}
+	stack.Status[*payload.EndpointID] = status
+
+	edge.UpdateEdgeStackRollout(stack)
 
 	err = handler.DataStore.EdgeStack().UpdateEdgeStack(stack.ID, stack)
 	if err != nil {
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the stack changes inside the database", err}
 	}
 
-	return response.JSON(w, stack)
+	err = handler.recordStatusHistory(stack, &status, version, payload.Output)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the stack status history inside the database", err}
+	}
 
+	return response.JSON(w, hideGitCredentials(stack))
 }
@@ -3,6 +3,7 @@ package edgestacks
 import (
 	"errors"
 	"net/http"
+	"path"
 	"strconv"
 
 	"github.com/asaskevich/govalidator"
@@ -15,19 +16,33 @@ import (
 )
 
 type updateEdgeStackPayload struct {
+	// Content of the stack file, must be omitted for the edge stacks deployed from git
 	StackFileContent string
 	Version          *int
 	Prune            *bool
 	EdgeGroups       []portainer.EdgeGroupID
+	// Namespace the Kubernetes manifest is applied to
+	Namespace *string `example:"default"`
+	// Staged rollout of the new version, the version is released to every endpoint at once when omitted
+	Rollout *rolloutPayload
+}
+
+type rolloutPayload struct {
+	// Strategy used to size the batches (1 - percentage, 2 - fixed number of endpoints)
+	Strategy portainer.EdgeStackRolloutStrategy `example:"1"`
+	// Percentage or number of endpoints released per batch
+	BatchSize int `example:"10"`
+	// Percentage of failed deployments above which the rollout is paused
+	ErrorThreshold int `example:"20"`
 }
 
 func (payload *updateEdgeStackPayload) Validate(r *http.Request) error {
-	if govalidator.IsNull(payload.StackFileContent) {
-		return errors.New("Invalid stack file content")
-	}
 	if payload.EdgeGroups != nil && len(payload.EdgeGroups) == 0 {
 		return errors.New("Edge Groups are mandatory for an Edge stack")
 	}
+	if payload.Rollout != nil {
+		return edge.ValidateEdgeStackRollout(payload.Rollout.Strategy, payload.Rollout.BatchSize, payload.Rollout.ErrorThreshold)
+	}
 	return nil
 }
 
@@ -42,7 +57,8 @@ func (payload *updateEdgeStackPayload) Validate(r *http.Request) error {
 // @param body body updateEdgeStackPayload true "EdgeStack data"
 // @success 200 {object} portainer.EdgeStack
 // @failure 500
-// @failure 400
+// @failure 400 "Invalid request, or edit of the stack file of an edge stack deployed from git"
+// @failure 409 "A rollout of the edge stack is in progress"
 // @failure 503 Edge compute features are disabled
 // @router /edge_stacks/{id} [put]
 func (handler *Handler) edgeStackUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
@@ -64,7 +80,45 @@ func (handler *Handler) edgeStackUpdate(w http.ResponseWriter, r *http.Request)
 		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
 	}
 
+	stackFileContent := []byte(payload.StackFileContent)
+	if stack.GitConfig != nil {
+		if payload.StackFileContent != "" || (payload.Version != nil && *payload.Version != stack.Version) {
+			return &httperror.HandlerError{http.StatusBadRequest, "The stack file of an edge stack deployed from git can only be updated from its repository", errors.New("Edge stack deployed from git")}
+		}
+
+		stackFileContent, err = handler.FileService.GetFileContent(path.Join(stack.ProjectPath, stack.EntryPoint))
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve stack file from disk", err}
+		}
+	} else if govalidator.IsNull(payload.StackFileContent) {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", errors.New("Invalid stack file content")}
+	}
+
+	if payload.Namespace != nil {
+		err = edge.ValidateEdgeStackDeployment(stack.DeploymentType, *payload.Namespace)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+		}
+		stack.Namespace = *payload.Namespace
+	}
+
+	edgeGroupIDs := stack.EdgeGroups
 	if payload.EdgeGroups != nil {
+		edgeGroupIDs = payload.EdgeGroups
+	}
+
+	err = handler.validateEdgeStack(stackFileContent, edgeGroupIDs, stack.DeploymentType)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Unable to deploy the stack file on the edge groups endpoints", err}
+	}
+
+	versionUpdated := payload.Version != nil && *payload.Version != stack.Version
+	if versionUpdated && stack.Rollout != nil && (stack.Rollout.Status == portainer.EdgeStackRolloutInProgress || stack.Rollout.Status == portainer.EdgeStackRolloutPaused) {
+		return &httperror.HandlerError{http.StatusConflict, "A rollout of the edge stack is in progress, abort it before deploying a new version", errors.New("Edge stack rollout in progress")}
+	}
+
+	relatedEndpoints := []portainer.EndpointID{}
+	if payload.EdgeGroups != nil || (versionUpdated && payload.Rollout != nil) {
 		endpoints, err := handler.DataStore.Endpoint().Endpoints()
 		if err != nil {
 			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoints from database", err}
@@ -90,6 +144,11 @@ func (handler *Handler) edgeStackUpdate(w http.ResponseWriter, r *http.Request)
 			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge stack related endpoints from database", err}
 		}
 
+		relatedEndpoints = oldRelated
+		if payload.EdgeGroups != nil {
+			relatedEndpoints = newRelated
+		}
+
 		oldRelatedSet := EndpointSet(oldRelated)
 		newRelatedSet := EndpointSet(newRelated)
 
@@ -135,8 +194,13 @@ func (handler *Handler) edgeStackUpdate(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		stack.EdgeGroups = payload.EdgeGroups
+		if stack.Rollout != nil {
+			edge.RemoveRolloutEndpoints(stack.Rollout, endpointsToRemove)
+		}
 
+		if payload.EdgeGroups != nil {
+			stack.EdgeGroups = payload.EdgeGroups
+		}
 	}
 
 	if payload.Prune != nil {
@@ -144,14 +208,36 @@ func (handler *Handler) edgeStackUpdate(w http.ResponseWriter, r *http.Request)
 	}
 
 	stackFolder := strconv.Itoa(int(stack.ID))
-	_, err = handler.FileService.StoreEdgeStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(payload.StackFileContent))
-	if err != nil {
-		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist updated Compose file on disk", err}
+
+	var rollout *portainer.EdgeStackRollout
+	if versionUpdated && payload.Rollout != nil {
+		rollout, err = handler.prepareRollout(stack, stackFolder, *payload.Version, payload.Rollout, relatedEndpoints)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to keep the previous stack file for the rollout", err}
+		}
+	}
+
+	if stack.GitConfig == nil {
+		_, err = handler.FileService.StoreEdgeStackFileFromBytes(stackFolder, stack.EntryPoint, stackFileContent)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist updated Compose file on disk", err}
+		}
 	}
 
-	if payload.Version != nil && *payload.Version != stack.Version {
+	if versionUpdated {
 		stack.Version = *payload.Version
-		stack.Status = map[portainer.EndpointID]portainer.EdgeStackStatus{}
+		stack.Rollout = rollout
+
+		if rollout == nil {
+			stack.Status = map[portainer.EndpointID]portainer.EdgeStackStatus{}
+		} else {
+			// endpoints outside of the first batch keep reporting the status of the previous version
+			for _, endpointID := range rollout.Endpoints[:rollout.Released] {
+				delete(stack.Status, endpointID)
+			}
+		}
+	} else if payload.EdgeGroups != nil {
+		edge.UpdateEdgeStackRollout(stack)
 	}
 
 	err = handler.DataStore.EdgeStack().UpdateEdgeStack(stack.ID, stack)
@@ -159,7 +245,36 @@ func (handler *Handler) edgeStackUpdate(w http.ResponseWriter, r *http.Request)
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the stack changes inside the database", err}
 	}
 
-	return response.JSON(w, stack)
+	return response.JSON(w, hideGitCredentials(stack))
+}
+
+// prepareRollout keeps a copy of the stack file deployed on the endpoints that are not released yet
+// and creates the rollout of the new version. When the previous rollout was aborted, the endpoints
+// it did not reach still run its previous version, which remains the fallback.
+func (handler *Handler) prepareRollout(stack *portainer.EdgeStack, stackFolder string, version int, payload *rolloutPayload, relatedEndpoints []portainer.EndpointID) (*portainer.EdgeStackRollout, error) {
+	previousVersion := stack.Version
+	previousEntryPoint := rolloutPreviousFileName(stack.EntryPoint)
+
+	if stack.Rollout != nil && stack.Rollout.Status == portainer.EdgeStackRolloutAborted {
+		previousVersion = stack.Rollout.PreviousVersion
+		previousEntryPoint = stack.Rollout.PreviousEntryPoint
+	} else {
+		content, err := handler.FileService.GetFileContent(path.Join(stack.ProjectPath, stack.EntryPoint))
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = handler.FileService.StoreEdgeStackFileFromBytes(stackFolder, previousEntryPoint, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return edge.NewEdgeStackRollout(payload.Strategy, payload.BatchSize, payload.ErrorThreshold, version, previousVersion, previousEntryPoint, relatedEndpoints), nil
+}
+
+func rolloutPreviousFileName(entryPoint string) string {
+	return "rollout-previous-" + path.Base(entryPoint)
 }
 
 func EndpointSet(endpointIDs []portainer.EndpointID) map[portainer.EndpointID]bool {
@@ -16,6 +16,8 @@ type Handler struct {
 	DataStore      portainer.DataStore
 	FileService    portainer.FileService
 	GitService     portainer.GitService
+	// EdgeStackGitService keeps the edge stacks deployed from git up to date
+	EdgeStackGitService portainer.EdgeStackGitService
 }
 
 // NewHandler creates a handler to manage endpoint group operations.
@@ -36,6 +38,16 @@ func NewHandler(bouncer *security.RequestBouncer) *Handler {
 		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeStackDelete)))).Methods(http.MethodDelete)
 	h.Handle("/edge_stacks/{id}/file",
 		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeStackFile)))).Methods(http.MethodGet)
+	h.Handle("/edge_stacks/{id}/rollout/pause",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeStackRolloutPause)))).Methods(http.MethodPost)
+	h.Handle("/edge_stacks/{id}/rollout/resume",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeStackRolloutResume)))).Methods(http.MethodPost)
+	h.Handle("/edge_stacks/{id}/rollout/abort",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeStackRolloutAbort)))).Methods(http.MethodPost)
+	h.Handle("/edge_stacks/{id}/git/pull",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeStackGitPull)))).Methods(http.MethodPost)
+	h.Handle("/edge_stacks/{id}/status/{endpointId}/history",
+		bouncer.AdminAccess(bouncer.EdgeComputeOperation(httperror.LoggerHandler(h.edgeStackStatusHistory)))).Methods(http.MethodGet)
 	h.Handle("/edge_stacks/{id}/status",
 		bouncer.PublicAccess(httperror.LoggerHandler(h.edgeStackStatusUpdate))).Methods(http.MethodPut)
 	return h
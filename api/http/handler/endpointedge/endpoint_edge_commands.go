@@ -0,0 +1,191 @@
+package endpointedge
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+type edgeAsyncCommandCreatePayload struct {
+	// Type of the command: 1 (container start), 2 (container stop), 3 (container restart), 4 (image pull),
+	// 5 (edge stack redeploy) or 6 (snapshot upload)
+	Type portainer.EdgeAsyncCommandType `example:"3"`
+	// Identifier of the container targeted by the container commands
+	ContainerID string `example:"a1b2c3d4e5f6"`
+	// Image pulled by the image pull commands
+	Image string `example:"nginx:latest"`
+	// Edge stack redeployed by the edge stack redeploy commands
+	EdgeStackID portainer.EdgeStackID `example:"1"`
+}
+
+func (payload *edgeAsyncCommandCreatePayload) Validate(r *http.Request) error {
+	if payload.Type == 0 {
+		return errors.New("Invalid command type")
+	}
+
+	return nil
+}
+
+// @id EndpointEdgeCommandCreate
+// @summary Queue an async command for an Edge endpoint
+// @description Queue a command that is delivered to the agent at its next check-in, without opening a tunnel.
+// @description The agent reports the result of the command on a following check-in.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Endpoint identifier"
+// @param body body edgeAsyncCommandCreatePayload true "Command details"
+// @success 200 {object} portainer.EdgeAsyncCommand "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Endpoint or edge stack not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/edge/commands [post]
+func (handler *Handler) endpointEdgeCommandCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.edgeEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	var payload edgeAsyncCommandCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve user details from authentication token", err}
+	}
+
+	command := &portainer.EdgeAsyncCommand{
+		EndpointID:   endpoint.ID,
+		Type:         payload.Type,
+		ContainerID:  payload.ContainerID,
+		Image:        payload.Image,
+		EdgeStackID:  payload.EdgeStackID,
+		Status:       portainer.EdgeAsyncCommandStatusPending,
+		CreatedBy:    tokenData.Username,
+		CreationDate: time.Now().Unix(),
+	}
+
+	err = edge.ValidateEdgeAsyncCommand(command, endpoint)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid command", err}
+	}
+
+	if command.Type == portainer.EdgeAsyncCommandStackRedeploy {
+		relation, err := handler.DataStore.EndpointRelation().EndpointRelation(endpoint.ID)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve relation object from the database", err}
+		}
+
+		if !relation.EdgeStacks[command.EdgeStackID] {
+			return &httperror.HandlerError{http.StatusNotFound, "The edge stack is not deployed on the endpoint", errors.New("edge stack not found")}
+		}
+	}
+
+	err = handler.DataStore.EdgeAsyncCommand().CreateEdgeAsyncCommand(command)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the command inside the database", err}
+	}
+
+	return response.JSON(w, command)
+}
+
+// @id EndpointEdgeCommandList
+// @summary List the async commands of an Edge endpoint
+// @description List the queued, delivered and completed async commands of an Edge endpoint, oldest first.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @produce json
+// @param id path int true "Endpoint identifier"
+// @success 200 {array} portainer.EdgeAsyncCommand "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Endpoint not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/edge/commands [get]
+func (handler *Handler) endpointEdgeCommandList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.edgeEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	commands, err := handler.DataStore.EdgeAsyncCommand().EdgeAsyncCommands(endpoint.ID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the commands from the database", err}
+	}
+
+	return response.JSON(w, commands)
+}
+
+// @id EndpointEdgeCommandDelete
+// @summary Remove an async command of an Edge endpoint
+// @description Cancel a command the agent has not acknowledged yet, or remove a completed command.
+// @description A command that was already delivered may still run on the agent.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @param id path int true "Endpoint identifier"
+// @param commandId path int true "Command identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Endpoint or command not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/edge/commands/{commandId} [delete]
+func (handler *Handler) endpointEdgeCommandDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.edgeEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	commandID, err := request.RetrieveNumericRouteVariableValue(r, "commandId")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid command identifier route variable", err}
+	}
+
+	_, err = handler.DataStore.EdgeAsyncCommand().EdgeAsyncCommand(endpoint.ID, commandID)
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find a command with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find a command with the specified identifier inside the database", err}
+	}
+
+	err = handler.DataStore.EdgeAsyncCommand().DeleteEdgeAsyncCommand(endpoint.ID, commandID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the command from the database", err}
+	}
+
+	return response.Empty(w)
+}
+
+// edgeEndpointFromRequest retrieves the Edge endpoint matching the id route variable
+func (handler *Handler) edgeEndpointFromRequest(r *http.Request) (*portainer.Endpoint, *httperror.HandlerError) {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == bolterrors.ErrObjectNotFound {
+		return nil, &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return nil, &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	if !isEdgeEndpoint(endpoint) {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "The endpoint is not an Edge endpoint", errors.New("invalid endpoint type")}
+	}
+
+	return endpoint, nil
+}
@@ -0,0 +1,230 @@
+package endpointedge
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+type keyRotationPayload struct {
+	// Period during which the previous key is still accepted, defaults to the EdgeKeyRotationGracePeriod setting
+	GracePeriod string `example:"24h"`
+}
+
+type keyRotationResponse struct {
+	// Edge key of the endpoint, unchanged by the rotation
+	EdgeKey string `example:"aHR0cHM6Ly9wb3J0YWluZXIubXlkb21haW4udGxk"`
+	// New secret of the edge key, the agent sends it in the X-PortainerAgent-EdgeKeySecret header
+	EdgeKeySecret string `example:"0Ff9mQ2b7k1qXwZr"`
+}
+
+func (payload *keyRotationPayload) Validate(r *http.Request) error {
+	if payload.GracePeriod == "" {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(payload.GracePeriod)
+	if err != nil || duration < 0 {
+		return errors.New("Invalid grace period")
+	}
+
+	return nil
+}
+
+// @id EndpointEdgeKeyRotate
+// @summary Rotate the edge key of an endpoint
+// @description Generate a new secret for the edge key of an Edge endpoint. The agent receives the new secret at check-in,
+// @description the previous secret is accepted until the end of the grace period. A revoked agent does not receive the
+// @description new secret, which must be configured on the agent with its edge key.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Endpoint identifier"
+// @param body body keyRotationPayload false "Rotation details"
+// @success 200 {object} keyRotationResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Endpoint not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/edge/key/rotate [post]
+func (handler *Handler) endpointEdgeKeyRotate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	gracePeriod, handlerErr := handler.rotationGracePeriod(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	if !isEdgeEndpoint(endpoint) {
+		return &httperror.HandlerError{http.StatusBadRequest, "The endpoint is not an Edge endpoint", errors.New("invalid endpoint type")}
+	}
+
+	endpoint, err = handler.ReverseTunnelService.RotateEdgeKey(endpoint.ID, gracePeriod)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to rotate the edge key", err}
+	}
+
+	return response.JSON(w, &keyRotationResponse{EdgeKey: endpoint.EdgeKey, EdgeKeySecret: endpoint.EdgeCredentials.Secret})
+}
+
+// @id EndpointEdgeKeysRotate
+// @summary Rotate the edge keys of all the endpoints
+// @description Generate a new secret for the edge key of every Edge endpoint which is not revoked.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @accept json
+// @param body body keyRotationPayload false "Rotation details"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /endpoints/edge/keys/rotate [post]
+func (handler *Handler) endpointEdgeKeysRotate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	gracePeriod, handlerErr := handler.rotationGracePeriod(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoints from the database", err}
+	}
+
+	for _, endpoint := range endpoints {
+		if !isEdgeEndpoint(&endpoint) || endpoint.EdgeKey == "" || endpoint.EdgeCredentials.Revoked {
+			continue
+		}
+
+		_, err := handler.ReverseTunnelService.RotateEdgeKey(endpoint.ID, gracePeriod)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to rotate the edge key", err}
+		}
+	}
+
+	return response.Empty(w)
+}
+
+// @id EndpointEdgeRevoke
+// @summary Revoke an edge device
+// @description Revoke the edge key of an Edge endpoint and close its tunnel. The endpoint is refused until its
+// @description edge key is rotated and the new secret is configured on the agent.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @param id path int true "Endpoint identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Endpoint not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/edge/revoke [post]
+func (handler *Handler) endpointEdgeRevoke(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	if !isEdgeEndpoint(endpoint) {
+		return &httperror.HandlerError{http.StatusBadRequest, "The endpoint is not an Edge endpoint", errors.New("invalid endpoint type")}
+	}
+
+	edge.RevokeEdgeCredentials(&endpoint.EdgeCredentials)
+
+	err = handler.DataStore.Endpoint().UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist endpoint changes inside the database", err}
+	}
+
+	handler.ReverseTunnelService.SetTunnelStatusToIdle(endpoint.ID)
+
+	log.Printf("[INFO] [http,endpoint_edge] [endpoint_id: %d] [message: edge device revoked]", endpoint.ID)
+
+	return response.Empty(w)
+}
+
+// @id EndpointEdgeTunnelServerKeyRotate
+// @summary Rotate the tunnel server key
+// @description Generate a new private key for the tunnel server. The fingerprint of the new key is sent to the agents
+// @description at check-in and the tunnel server switches to the new key at the end of the grace period.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @accept json
+// @param body body keyRotationPayload false "Rotation details"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /endpoints/edge/tunnel_server/rotate [post]
+func (handler *Handler) endpointEdgeTunnelServerKeyRotate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	gracePeriod, handlerErr := handler.rotationGracePeriod(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	err := handler.ReverseTunnelService.RotateTunnelServerKey(gracePeriod)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to rotate the tunnel server key", err}
+	}
+
+	return response.Empty(w)
+}
+
+// rotationGracePeriod returns the grace period of the request, or the one of the settings when none is specified
+func (handler *Handler) rotationGracePeriod(r *http.Request) (time.Duration, *httperror.HandlerError) {
+	var payload keyRotationPayload
+	if r.ContentLength > 0 {
+		err := request.DecodeAndValidateJSONPayload(r, &payload)
+		if err != nil {
+			return 0, &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+		}
+	}
+
+	gracePeriod := payload.GracePeriod
+	if gracePeriod == "" {
+		settings, err := handler.DataStore.Settings().Settings()
+		if err != nil {
+			return 0, &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve settings from the database", err}
+		}
+
+		gracePeriod = settings.EdgeKeyRotationGracePeriod
+		if gracePeriod == "" {
+			gracePeriod = portainer.DefaultEdgeKeyRotationGracePeriod
+		}
+	}
+
+	duration, err := time.ParseDuration(gracePeriod)
+	if err != nil {
+		return 0, &httperror.HandlerError{http.StatusInternalServerError, "Invalid grace period setting", err}
+	}
+
+	return duration, nil
+}
+
+func isEdgeEndpoint(endpoint *portainer.Endpoint) bool {
+	return endpoint.Type == portainer.EdgeAgentOnDockerEnvironment || endpoint.Type == portainer.EdgeAgentOnKubernetesEnvironment
+}
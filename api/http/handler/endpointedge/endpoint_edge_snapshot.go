@@ -0,0 +1,150 @@
+package endpointedge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+// maxEdgeSnapshotSize is the maximum size of a decompressed snapshot pushed by an agent
+const maxEdgeSnapshotSize = 32 << 20
+
+type edgeSnapshotPayload struct {
+	// Snapshot of an Edge Docker endpoint
+	DockerSnapshot *portainer.DockerSnapshot
+	// Snapshot of an Edge Kubernetes endpoint
+	KubernetesSnapshot *portainer.KubernetesSnapshot
+}
+
+func (payload *edgeSnapshotPayload) Validate(r *http.Request) error {
+	if payload.DockerSnapshot == nil && payload.KubernetesSnapshot == nil {
+		return errors.New("A Docker or Kubernetes snapshot is required")
+	}
+
+	return nil
+}
+
+// @id EndpointEdgeSnapshotPush
+// @summary Push the snapshot of an Edge endpoint
+// @description Endpoint for edge agents to push the snapshot of their endpoint without opening a tunnel,
+// @description at the interval received at check-in. The body can be compressed with gzip (Content-Encoding: gzip).
+// @description **Access policy**: restricted to the Edge agent of the endpoint
+// @tags edge, endpoints
+// @accept json
+// @param id path int true "Endpoint identifier"
+// @param body body edgeSnapshotPayload true "Snapshot of the endpoint"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied or snapshot push disabled"
+// @failure 404 "Endpoint not found"
+// @failure 413 "Snapshot too large"
+// @failure 415 "Unsupported content encoding"
+// @failure 429 "Snapshot pushed before the end of the interval"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/edge/snapshot [post]
+func (handler *Handler) endpointEdgeSnapshotPush(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	err = handler.requestBouncer.AuthorizedEdgeEndpointOperation(r, endpoint)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusForbidden, "Permission denied to access endpoint", err}
+	}
+
+	if endpoint.EdgeID == "" {
+		return &httperror.HandlerError{http.StatusBadRequest, "The endpoint is not associated to an Edge agent yet", errors.New("the agent must check in before pushing a snapshot")}
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve settings from the database", err}
+	}
+
+	interval := edge.EdgeSnapshotPushInterval(settings)
+	if interval == 0 {
+		return &httperror.HandlerError{http.StatusForbidden, "Edge snapshot push is disabled", errors.New("the snapshot push interval is 0")}
+	}
+
+	// agents push at the interval, pushes closer than half of it are rejected to protect the instance.
+	// The time of the latest push is tracked separately as the snapshots taken through the tunnel also succeed.
+	now := time.Now()
+	if endpoint.EdgeSnapshotLastPush != 0 && now.Sub(time.Unix(endpoint.EdgeSnapshotLastPush, 0)) < interval/2 {
+		return &httperror.HandlerError{http.StatusTooManyRequests, "The snapshot was pushed before the end of the interval", fmt.Errorf("the snapshot push interval is %s", interval)}
+	}
+
+	body, handlerErr := edgeSnapshotBody(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+	r.Body = body
+
+	var payload edgeSnapshotPayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	err = edge.ValidateEdgeSnapshot(endpoint, payload.DockerSnapshot, payload.KubernetesSnapshot, now)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid snapshot", err}
+	}
+
+	handler.SnapshotService.StoreEdgeSnapshot(endpoint, payload.DockerSnapshot, payload.KubernetesSnapshot)
+	endpoint.EdgeSnapshotLastPush = now.Unix()
+
+	err = handler.DataStore.Endpoint().UpdateEndpoint(endpoint.ID, endpoint)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist endpoint changes inside the database", err}
+	}
+
+	return response.Empty(w)
+}
+
+// edgeSnapshotBody returns the decompressed body of the request, limited to maxEdgeSnapshotSize
+func edgeSnapshotBody(r *http.Request) (io.ReadCloser, *httperror.HandlerError) {
+	var reader io.Reader = r.Body
+
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, &httperror.HandlerError{http.StatusBadRequest, "Invalid gzip compressed snapshot", err}
+		}
+		reader = gzipReader
+	default:
+		return nil, &httperror.HandlerError{http.StatusUnsupportedMediaType, "Unsupported content encoding, only gzip is supported", errors.New("unsupported content encoding")}
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(reader, maxEdgeSnapshotSize+1))
+	if err != nil {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "Unable to read the snapshot", err}
+	}
+
+	if len(data) > maxEdgeSnapshotSize {
+		return nil, &httperror.HandlerError{http.StatusRequestEntityTooLarge, "The snapshot is too large", fmt.Errorf("the decompressed snapshot exceeds %d bytes", maxEdgeSnapshotSize)}
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
@@ -0,0 +1,136 @@
+package endpointedge
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+type edgeJobRunPayload struct {
+	// Version of the Edge job that ran
+	Version int `example:"2"`
+	// Exit code of the last attempt
+	ExitCode int `example:"0"`
+	// Whether the last attempt was stopped after the timeout of the job
+	TimedOut bool `example:"false"`
+	// Number of attempts, retries included
+	Attempts int `example:"1"`
+	// Unix timestamps of the start of the first attempt and of the end of the last attempt
+	StartTime int64 `example:"1625132400"`
+	EndTime   int64 `example:"1625132460"`
+}
+
+func (payload *edgeJobRunPayload) Validate(r *http.Request) error {
+	if payload.Attempts < 1 {
+		payload.Attempts = 1
+	}
+
+	if payload.StartTime <= 0 || payload.EndTime < payload.StartTime {
+		return errors.New("Invalid run start and end times")
+	}
+
+	return nil
+}
+
+// endpointEdgeJobRun
+// @summary Report the result of an EdgeJob run
+// @description Store the exit code, the number of attempts and the duration of a run of an Edge job on the endpoint
+// @tags edge, endpoints
+// @accept json
+// @produce json
+// @param id path string true "Endpoint Id"
+// @param jobID path string true "Job Id"
+// @param body body edgeJobRunPayload true "Run result"
+// @success 200 {object} portainer.EdgeJobRun
+// @failure 500
+// @failure 400
+// @failure 403
+// @failure 404
+// @router /endpoints/{id}/edge/jobs/{jobID}/runs [post]
+func (handler *Handler) endpointEdgeJobRun(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	err = handler.requestBouncer.AuthorizedEdgeEndpointOperation(r, endpoint)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusForbidden, "Permission denied to access endpoint", err}
+	}
+
+	edgeJobID, err := request.RetrieveNumericRouteVariableValue(r, "jobID")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge job identifier route variable", err}
+	}
+
+	var payload edgeJobRunPayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	edgeJob, err := handler.DataStore.EdgeJob().EdgeJob(portainer.EdgeJobID(edgeJobID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an edge job with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an edge job with the specified identifier inside the database", err}
+	}
+
+	if _, ok := edgeJob.Endpoints[endpoint.ID]; !ok {
+		return &httperror.HandlerError{http.StatusForbidden, "The edge job does not target the endpoint", errors.New("endpoint not targeted by the edge job")}
+	}
+
+	run := &portainer.EdgeJobRun{
+		EdgeJobID:  edgeJob.ID,
+		EndpointID: endpoint.ID,
+		Version:    payload.Version,
+		Status:     edge.EdgeJobRunStatusFromResult(payload.ExitCode, payload.TimedOut),
+		ExitCode:   payload.ExitCode,
+		Attempts:   payload.Attempts,
+		StartTime:  payload.StartTime,
+		EndTime:    payload.EndTime,
+	}
+
+	err = handler.DataStore.EdgeJobRun().CreateEdgeJobRun(run)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the edge job run inside the database", err}
+	}
+
+	handler.pruneEdgeJobRuns(edgeJob.ID, endpoint.ID)
+
+	return response.JSON(w, run)
+}
+
+// pruneEdgeJobRuns deletes the oldest runs of an Edge job on an endpoint, failures are only logged
+// as they do not affect the reported run
+func (handler *Handler) pruneEdgeJobRuns(edgeJobID portainer.EdgeJobID, endpointID portainer.EndpointID) {
+	runs, err := handler.DataStore.EdgeJobRun().EdgeJobEndpointRuns(edgeJobID, endpointID)
+	if err != nil {
+		log.Printf("[WARN] [http,endpoint_edge] [edge_job_id: %d] [endpoint_id: %d] [message: unable to retrieve edge job runs] [error: %s]", edgeJobID, endpointID, err)
+		return
+	}
+
+	maxID := edge.EdgeJobRunsLimit(runs, portainer.DefaultEdgeJobRunsMaxEntries)
+	if maxID == 0 {
+		return
+	}
+
+	err = handler.DataStore.EdgeJobRun().DeleteEdgeJobEndpointRuns(edgeJobID, endpointID, maxID)
+	if err != nil {
+		log.Printf("[WARN] [http,endpoint_edge] [edge_job_id: %d] [endpoint_id: %d] [message: unable to delete old edge job runs] [error: %s]", edgeJobID, endpointID, err)
+	}
+}
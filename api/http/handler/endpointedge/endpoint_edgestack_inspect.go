@@ -1,6 +1,7 @@
 package endpointedge
 
 import (
+	"errors"
 	"net/http"
 	"path"
 
@@ -8,15 +9,22 @@ import (
 	"github.com/portainer/libhttp/request"
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
-	"github.com/portainer/portainer/api/bolt/errors"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type configResponse struct {
 	Prune            bool
 	StackFileContent string
 	Name             string
+	// Type of the stack file (0 - Compose file, 1 - Kubernetes manifest)
+	DeploymentType portainer.EdgeStackDeploymentType
+	// Namespace the Kubernetes manifest is applied to
+	Namespace string
 }
 
+var errEdgeStackIncompatible = errors.New("Kubernetes manifests can only be deployed on Kubernetes edge agents")
+
 // @summary Inspect an Edge Stack for an Endpoint
 // @description
 // @tags edge, endpoints, edge_stacks
@@ -36,7 +44,7 @@ func (handler *Handler) endpointEdgeStackInspect(w http.ResponseWriter, r *http.
 	}
 
 	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
-	if err == errors.ErrObjectNotFound {
+	if err == bolterrors.ErrObjectNotFound {
 		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
 	} else if err != nil {
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
@@ -53,20 +61,49 @@ func (handler *Handler) endpointEdgeStackInspect(w http.ResponseWriter, r *http.
 	}
 
 	edgeStack, err := handler.DataStore.EdgeStack().EdgeStack(portainer.EdgeStackID(edgeStackID))
-	if err == errors.ErrObjectNotFound {
+	if err == bolterrors.ErrObjectNotFound {
 		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an edge stack with the specified identifier inside the database", err}
 	} else if err != nil {
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an edge stack with the specified identifier inside the database", err}
 	}
 
-	stackFileContent, err := handler.FileService.GetFileContent(path.Join(edgeStack.ProjectPath, edgeStack.EntryPoint))
+	if !edge.IsEdgeStackCompatible(edgeStack.DeploymentType, endpoint.Type) {
+		return &httperror.HandlerError{http.StatusBadRequest, "The edge stack cannot be deployed on this endpoint", errEdgeStackIncompatible}
+	}
+
+	entryPoint := edgeStack.EntryPoint
+	if !edge.IsReleasedToEndpoint(edgeStack, endpoint.ID) {
+		if edgeStack.Rollout.PreviousEntryPoint == "" {
+			return &httperror.HandlerError{http.StatusNotFound, "The edge stack is not released to this endpoint yet", bolterrors.ErrObjectNotFound}
+		}
+		entryPoint = edgeStack.Rollout.PreviousEntryPoint
+	}
+
+	stackFileContent, err := handler.FileService.GetFileContent(path.Join(edgeStack.ProjectPath, entryPoint))
 	if err != nil {
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve Compose file from disk", err}
 	}
 
+	endpointGroups, err := handler.DataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoint groups from the database", err}
+	}
+
+	edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge groups from the database", err}
+	}
+
+	stackFileContent, err = edge.RenderEdgeStackFile(stackFileContent, edge.EndpointVariables(edgeStack.EdgeGroups, endpoint, endpointGroups, edgeGroups))
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to render the Compose file variables", err}
+	}
+
 	return response.JSON(w, configResponse{
 		Prune:            edgeStack.Prune,
 		StackFileContent: string(stackFileContent),
 		Name:             edgeStack.Name,
+		DeploymentType:   edgeStack.DeploymentType,
+		Namespace:        edgeStack.Namespace,
 	})
 }
@@ -17,6 +17,7 @@ type Handler struct {
 	DataStore            portainer.DataStore
 	FileService          portainer.FileService
 	ReverseTunnelService portainer.ReverseTunnelService
+	SnapshotService      portainer.SnapshotService
 }
 
 // NewHandler creates a handler to manage endpoint operations.
@@ -30,5 +31,23 @@ func NewHandler(bouncer *security.RequestBouncer) *Handler {
 		bouncer.PublicAccess(httperror.LoggerHandler(h.endpointEdgeStackInspect))).Methods(http.MethodGet)
 	h.Handle("/{id}/edge/jobs/{jobID}/logs",
 		bouncer.PublicAccess(httperror.LoggerHandler(h.endpointEdgeJobsLogs))).Methods(http.MethodPost)
+	h.Handle("/{id}/edge/jobs/{jobID}/runs",
+		bouncer.PublicAccess(httperror.LoggerHandler(h.endpointEdgeJobRun))).Methods(http.MethodPost)
+	h.Handle("/{id}/edge/commands",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointEdgeCommandList))).Methods(http.MethodGet)
+	h.Handle("/{id}/edge/commands",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointEdgeCommandCreate))).Methods(http.MethodPost)
+	h.Handle("/{id}/edge/commands/{commandId}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointEdgeCommandDelete))).Methods(http.MethodDelete)
+	h.Handle("/{id}/edge/snapshot",
+		bouncer.PublicAccess(httperror.LoggerHandler(h.endpointEdgeSnapshotPush))).Methods(http.MethodPost)
+	h.Handle("/{id}/edge/key/rotate",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointEdgeKeyRotate))).Methods(http.MethodPost)
+	h.Handle("/{id}/edge/revoke",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointEdgeRevoke))).Methods(http.MethodPost)
+	h.Handle("/edge/keys/rotate",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointEdgeKeysRotate))).Methods(http.MethodPost)
+	h.Handle("/edge/tunnel_server/rotate",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointEdgeTunnelServerKeyRotate))).Methods(http.MethodPost)
 	return h
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/portainer/libhttp/request"
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type endpointGroupCreatePayload struct {
@@ -20,6 +21,8 @@ type endpointGroupCreatePayload struct {
 	AssociatedEndpoints []portainer.EndpointID `example:"1,3"`
 	// List of tag identifiers to which this endpoint group is associated
 	TagIDs []portainer.TagID `example:"1,2"`
+	// Variables rendered into the edge stacks deployed on the endpoints of this group
+	EdgeVariables map[string]string
 }
 
 func (payload *endpointGroupCreatePayload) Validate(r *http.Request) error {
@@ -29,7 +32,7 @@ func (payload *endpointGroupCreatePayload) Validate(r *http.Request) error {
 	if payload.TagIDs == nil {
 		payload.TagIDs = []portainer.TagID{}
 	}
-	return nil
+	return edge.ValidateVariableNames(payload.EdgeVariables)
 }
 
 // @summary Create an Endpoint Group
@@ -57,6 +60,7 @@ func (handler *Handler) endpointGroupCreate(w http.ResponseWriter, r *http.Reque
 		UserAccessPolicies: portainer.UserAccessPolicies{},
 		TeamAccessPolicies: portainer.TeamAccessPolicies{},
 		TagIDs:             payload.TagIDs,
+		EdgeVariables:      payload.EdgeVariables,
 	}
 
 	err = handler.DataStore.EndpointGroup().CreateEndpointGroup(endpointGroup)
@@ -9,6 +9,7 @@ import (
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
 	"github.com/portainer/portainer/api/internal/tag"
 )
 
@@ -21,10 +22,12 @@ type endpointGroupUpdatePayload struct {
 	TagIDs             []portainer.TagID `example:"3,4"`
 	UserAccessPolicies portainer.UserAccessPolicies
 	TeamAccessPolicies portainer.TeamAccessPolicies
+	// Variables rendered into the edge stacks deployed on the endpoints of this group
+	EdgeVariables map[string]string
 }
 
 func (payload *endpointGroupUpdatePayload) Validate(r *http.Request) error {
-	return nil
+	return edge.ValidateVariableNames(payload.EdgeVariables)
 }
 
 // @id EndpointGroupUpdate
@@ -69,6 +72,10 @@ func (handler *Handler) endpointGroupUpdate(w http.ResponseWriter, r *http.Reque
 		endpointGroup.Description = payload.Description
 	}
 
+	if payload.EdgeVariables != nil {
+		endpointGroup.EdgeVariables = payload.EdgeVariables
+	}
+
 	tagsChanged := false
 	if payload.TagIDs != nil {
 		payloadTagSet := tag.Set(payload.TagIDs)
@@ -1,6 +1,7 @@
 package endpoints
 
 import (
+	"math"
 	"net/http"
 	"strconv"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 // @id EndpointDelete
@@ -56,6 +58,56 @@ func (handler *Handler) endpointDelete(w http.ResponseWriter, r *http.Request) *
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove endpoint relation from the database", err}
 	}
 
+	err = handler.DataStore.SnapshotHistory().DeleteSnapshotHistory(endpoint.ID, 0, math.MaxInt64)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove endpoint snapshot history from the database", err}
+	}
+
+	edgeJobs, err := handler.DataStore.EdgeJob().EdgeJobs()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge jobs from the database", err}
+	}
+
+	for _, edgeJob := range edgeJobs {
+		err = handler.DataStore.EdgeJobRun().DeleteEdgeJobEndpointRuns(edgeJob.ID, endpoint.ID, math.MaxInt64)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the edge job runs from the database", err}
+		}
+	}
+
+	prunePolicies, err := handler.DataStore.PrunePolicy().PrunePolicies()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve prune policies from the database", err}
+	}
+
+	for _, policy := range prunePolicies {
+		err = handler.DataStore.PrunePolicyRun().DeletePrunePolicyEndpointRuns(policy.ID, endpoint.ID, math.MaxInt64)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the prune policy runs from the database", err}
+		}
+	}
+
+	if endpoint.EdgeID != "" {
+		// the device of the endpoint goes back through the waiting room when it enrols again
+		edgeDevice, err := handler.DataStore.EdgeDevice().EdgeDeviceByEdgeID(endpoint.EdgeID)
+		if err == nil {
+			err = handler.DataStore.EdgeDevice().DeleteEdgeDevice(edgeDevice.ID)
+		}
+		if err != nil && err != errors.ErrObjectNotFound {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the edge device from the database", err}
+		}
+
+		err = handler.DataStore.TunnelEvent().DeleteTunnelEvents(endpoint.ID, math.MaxInt64)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the tunnel events from the database", err}
+		}
+
+		err = handler.DataStore.EdgeAsyncCommand().DeleteEdgeAsyncCommands(endpoint.ID, math.MaxInt64)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the async commands from the database", err}
+		}
+	}
+
 	for _, tagID := range endpoint.TagIDs {
 		tag, err := handler.DataStore.Tag().Tag(tagID)
 		if err != nil {
@@ -94,8 +146,21 @@ func (handler *Handler) endpointDelete(w http.ResponseWriter, r *http.Request) *
 
 	for idx := range edgeStacks {
 		edgeStack := &edgeStacks[idx]
-		if _, ok := edgeStack.Status[endpoint.ID]; ok {
+
+		err = handler.DataStore.EdgeStackStatusHistory().DeleteEdgeStackStatusHistoryEntries(edgeStack.ID, endpoint.ID, math.MaxInt64)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove edge stack status history from the database", err}
+		}
+
+		_, hasStatus := edgeStack.Status[endpoint.ID]
+		inRollout := edgeStack.Rollout != nil && edge.IsRolloutEndpoint(edgeStack.Rollout, endpoint.ID)
+		if hasStatus || inRollout {
 			delete(edgeStack.Status, endpoint.ID)
+			if inRollout {
+				edge.RemoveRolloutEndpoints(edgeStack.Rollout, map[portainer.EndpointID]bool{endpoint.ID: true})
+				edge.UpdateEdgeStackRollout(edgeStack)
+			}
+
 			err = handler.DataStore.EdgeStack().UpdateEdgeStack(edgeStack.ID, edgeStack)
 			if err != nil {
 				return &httperror.HandlerError{http.StatusInternalServerError, "Unable to update edge stack", err}
@@ -0,0 +1,72 @@
+package endpoints
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+)
+
+// @id EndpointProxyCacheInspect
+// @summary Inspect the Docker proxy cache of an endpoint
+// @description Retrieve the hit, miss and invalidation statistics of the cache of the Docker list responses of an endpoint.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @produce json
+// @param id path int true "Endpoint identifier"
+// @success 200 {object} docker.ResponseCacheStats "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Endpoint not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/proxy_cache [get]
+func (handler *Handler) endpointProxyCacheInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.proxyCacheEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	return response.JSON(w, handler.ProxyManager.DockerResponseCacheStats(endpoint.ID))
+}
+
+// @id EndpointProxyCacheFlush
+// @summary Flush the Docker proxy cache of an endpoint
+// @description Remove the cached Docker list responses of an endpoint.
+// @description **Access policy**: administrator
+// @tags endpoints
+// @security jwt
+// @param id path int true "Endpoint identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Endpoint not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/proxy_cache [delete]
+func (handler *Handler) endpointProxyCacheFlush(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.proxyCacheEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	handler.ProxyManager.InvalidateDockerResponseCache(endpoint.ID)
+
+	return response.Empty(w)
+}
+
+func (handler *Handler) proxyCacheEndpointFromRequest(r *http.Request) (*portainer.Endpoint, *httperror.HandlerError) {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == errors.ErrObjectNotFound {
+		return nil, &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return nil, &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	return endpoint, nil
+}
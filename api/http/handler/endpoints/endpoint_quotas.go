@@ -0,0 +1,103 @@
+package endpoints
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/internal/quota"
+)
+
+type teamQuotaUsage struct {
+	// Team identifier
+	TeamID portainer.TeamID        `json:"TeamId" example:"1"`
+	Quota  portainer.ResourceQuota `json:"Quota"`
+	// Resources of the endpoint currently owned by the team
+	Usage quota.Usage `json:"Usage"`
+}
+
+// @id EndpointQuotas
+// @summary List the quotas of the teams on an endpoint
+// @description List the resource quotas of the teams on a Docker endpoint along with their current usage.
+// @description The administrators see the quotas of every team, the other users only see the quotas of their teams.
+// @description **Access policy**: restricted
+// @tags endpoints
+// @security jwt
+// @produce json
+// @param id path int true "Endpoint identifier"
+// @success 200 {array} teamQuotaUsage "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Endpoint not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/quotas [get]
+func (handler *Handler) endpointQuotas(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == errors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusForbidden, "Permission denied to access endpoint", err}
+	}
+
+	if !endpointutils.IsDockerEndpoint(endpoint) {
+		return &httperror.HandlerError{http.StatusBadRequest, "Quotas are only supported on Docker endpoints", nil}
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve info from request context", err}
+	}
+
+	quotas := make([]teamQuotaUsage, 0)
+	for teamID, policy := range endpoint.TeamAccessPolicies {
+		if policy.Quota == nil || !(securityContext.IsAdmin || isTeamMember(securityContext, teamID)) {
+			continue
+		}
+
+		quotas = append(quotas, teamQuotaUsage{TeamID: teamID, Quota: *policy.Quota})
+	}
+
+	if len(quotas) == 0 {
+		return response.JSON(w, quotas)
+	}
+
+	dockerClient, err := handler.DockerClientFactory.CreateClient(endpoint, "")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to connect to the Docker endpoint", err}
+	}
+	defer dockerClient.Close()
+
+	for i := range quotas {
+		usage, err := quota.ComputeUsage(handler.DataStore, dockerClient, endpoint, quotas[i].TeamID)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to compute the resource usage of the team", err}
+		}
+		quotas[i].Usage = *usage
+	}
+
+	return response.JSON(w, quotas)
+}
+
+func isTeamMember(securityContext *security.RestrictedRequestContext, teamID portainer.TeamID) bool {
+	for _, membership := range securityContext.UserMemberships {
+		if membership.TeamID == teamID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+)
+
+// @id EndpointSnapshotHistory
+// @summary Retrieve the snapshot history of an endpoint
+// @description Retrieve the snapshot history of an endpoint between two points in time.
+// @description Older entries are downsampled, each entry reports the number of snapshots it aggregates.
+// @description **Access policy**: restricted
+// @tags endpoints
+// @security jwt
+// @produce json
+// @param id path int true "Endpoint identifier"
+// @param from query int false "Start of the time range as a Unix timestamp, defaults to the oldest entry"
+// @param to query int false "End of the time range as a Unix timestamp, defaults to now"
+// @success 200 {array} portainer.SnapshotHistoryEntry "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Endpoint not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/snapshots/history [get]
+func (handler *Handler) endpointSnapshotHistory(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	from, err := request.RetrieveNumericQueryParameter(r, "from", true)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid query parameter: from", err}
+	}
+
+	to, err := request.RetrieveNumericQueryParameter(r, "to", true)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid query parameter: to", err}
+	}
+
+	if to == 0 {
+		to = int(time.Now().Unix())
+	}
+
+	if from < 0 || to < from {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid time range", errors.New("from must be positive and lower than to")}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == bolterrors.ErrObjectNotFound {
+		return &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusForbidden, "Permission denied to access endpoint", err}
+	}
+
+	entries, err := handler.DataStore.SnapshotHistory().SnapshotHistory(endpoint.ID, int64(from), int64(to))
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoint snapshot history from the database", err}
+	}
+
+	return response.JSON(w, entries)
+}
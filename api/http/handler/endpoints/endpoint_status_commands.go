@@ -0,0 +1,144 @@
+package endpoints
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+type endpointStatusInspectPayload struct {
+	// Results of the async commands run by the agent since its previous check-in
+	Commands []edgeAsyncCommandResult
+}
+
+type edgeAsyncCommandResult struct {
+	// Command Identifier
+	ID int `json:"Id" example:"1"`
+	// Whether the command succeeded
+	Succeeded bool `example:"true"`
+	// Output of the command
+	Output string `example:""`
+	// Error of the command, when it failed
+	Error string `example:""`
+	// Snapshot of a Docker endpoint, uploaded by the snapshot upload commands
+	DockerSnapshot *portainer.DockerSnapshot
+	// Snapshot of a Kubernetes endpoint, uploaded by the snapshot upload commands
+	KubernetesSnapshot *portainer.KubernetesSnapshot
+}
+
+func (payload *endpointStatusInspectPayload) Validate(r *http.Request) error {
+	for _, result := range payload.Commands {
+		if result.ID <= 0 {
+			return errors.New("Invalid command identifier")
+		}
+	}
+
+	return nil
+}
+
+type edgeAsyncCommandResponse struct {
+	// Command Identifier, agents must ignore the commands they already ran
+	ID int `json:"id" example:"1"`
+	// Type of the command
+	Type portainer.EdgeAsyncCommandType `json:"type" example:"3"`
+	// Identifier of the container targeted by the container commands
+	ContainerID string `json:"containerId,omitempty" example:"a1b2c3d4e5f6"`
+	// Image pulled by the image pull commands
+	Image string `json:"image,omitempty" example:"nginx:latest"`
+	// Edge stack redeployed by the edge stack redeploy commands
+	EdgeStackID portainer.EdgeStackID `json:"edgeStackId,omitempty" example:"1"`
+}
+
+// acknowledgeEdgeAsyncCommands records the results of the async commands reported by the agent.
+// A snapshot uploaded by a successful snapshot upload command is associated to the endpoint,
+// which must be persisted by the caller.
+func (handler *Handler) acknowledgeEdgeAsyncCommands(endpoint *portainer.Endpoint, results []edgeAsyncCommandResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	for _, result := range results {
+		command, err := handler.DataStore.EdgeAsyncCommand().EdgeAsyncCommand(endpoint.ID, result.ID)
+		if err == bolterrors.ErrObjectNotFound {
+			log.Printf("[WARN] [http,endpoints] [endpoint_id: %d] [message: result reported for an unknown async command] [command_id: %d]", endpoint.ID, result.ID)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if !edge.AcknowledgeEdgeAsyncCommand(command, result.Succeeded, result.Output, result.Error, now) {
+			continue
+		}
+
+		if command.Type == portainer.EdgeAsyncCommandSnapshotUpload && result.Succeeded {
+			handler.storeEdgeSnapshot(endpoint, result.DockerSnapshot, result.KubernetesSnapshot)
+		}
+
+		err = handler.DataStore.EdgeAsyncCommand().UpdateEdgeAsyncCommand(command)
+		if err != nil {
+			return err
+		}
+	}
+
+	commands, err := handler.DataStore.EdgeAsyncCommand().EdgeAsyncCommands(endpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range edge.EdgeAsyncCommandsToPrune(commands, portainer.DefaultEdgeAsyncCommandsMaxEntries) {
+		err = handler.DataStore.EdgeAsyncCommand().DeleteEdgeAsyncCommand(endpoint.ID, command.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storeEdgeSnapshot associates the snapshot uploaded by the agent to the endpoint, invalid snapshots are discarded
+func (handler *Handler) storeEdgeSnapshot(endpoint *portainer.Endpoint, dockerSnapshot *portainer.DockerSnapshot, kubernetesSnapshot *portainer.KubernetesSnapshot) {
+	err := edge.ValidateEdgeSnapshot(endpoint, dockerSnapshot, kubernetesSnapshot, time.Now())
+	if err != nil {
+		log.Printf("[WARN] [http,endpoints] [endpoint_id: %d] [message: invalid snapshot uploaded by the agent] [error: %s]", endpoint.ID, err)
+		return
+	}
+
+	handler.SnapshotService.StoreEdgeSnapshot(endpoint, dockerSnapshot, kubernetesSnapshot)
+}
+
+// deliverEdgeAsyncCommands returns the async commands the agent has not acknowledged yet and marks them as delivered
+func (handler *Handler) deliverEdgeAsyncCommands(endpointID portainer.EndpointID) ([]edgeAsyncCommandResponse, error) {
+	commands, err := handler.DataStore.EdgeAsyncCommand().EdgeAsyncCommands(endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	responses := []edgeAsyncCommandResponse{}
+	for _, command := range edge.EdgeAsyncCommandsToDeliver(commands) {
+		command.Status = portainer.EdgeAsyncCommandStatusDelivered
+		command.DeliveryDate = now
+		command.Deliveries++
+
+		err = handler.DataStore.EdgeAsyncCommand().UpdateEdgeAsyncCommand(&command)
+		if err != nil {
+			return nil, err
+		}
+
+		responses = append(responses, edgeAsyncCommandResponse{
+			ID:          command.ID,
+			Type:        command.Type,
+			ContainerID: command.ContainerID,
+			Image:       command.Image,
+			EdgeStackID: command.EdgeStackID,
+		})
+	}
+
+	return responses, nil
+}
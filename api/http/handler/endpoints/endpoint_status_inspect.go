@@ -12,6 +12,7 @@ import (
 	"github.com/portainer/libhttp/response"
 	portainer "github.com/portainer/portainer/api"
 	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type stackStatusResponse struct {
@@ -32,6 +33,12 @@ type edgeJobResponse struct {
 	Script string `json:"Script" example:"echo hello"`
 	// Version of this EdgeJob
 	Version int `json:"Version" example:"2"`
+	// Duration in seconds after which a run is stopped, 0 means no timeout
+	Timeout int `json:"Timeout" example:"300"`
+	// Number of times a failed or timed out run is retried
+	MaxRetries int `json:"MaxRetries" example:"3"`
+	// Delay in seconds between two attempts of a run
+	RetryInterval int `json:"RetryInterval" example:"60"`
 }
 
 type endpointStatusInspectResponse struct {
@@ -47,21 +54,36 @@ type endpointStatusInspectResponse struct {
 	Credentials string `json:"credentials" example:""`
 	// List of stacks to be deployed on the endpoints
 	Stacks []stackStatusResponse `json:"stacks"`
+	// Fingerprint of the tunnel server key
+	Fingerprint string `json:"fingerprint" example:"2d:9d:6c:5f:ec:d8:be:d6:9e:1d:33:b9:c0:92:32:c6"`
+	// Fingerprint of the key replacing the tunnel server key, set while a rotation is pending
+	NextFingerprint string `json:"nextFingerprint,omitempty" example:""`
+	// Secret of the rotated edge key, set while the agent still presents the previous one.
+	// The agent sends it in the X-PortainerAgent-EdgeKeySecret header of its next requests
+	EdgeKeySecret string `json:"edgeKeySecret,omitempty" example:""`
+	// Interval in seconds at which the agent pushes the snapshot of the endpoint, 0 when the push is disabled
+	SnapshotInterval int `json:"snapshotInterval" example:"300"`
+	// Async commands to run on the endpoint, delivered until the agent reports their results
+	Commands []edgeAsyncCommandResponse `json:"commands"`
 }
 
 // @id EndpointStatusInspect
 // @summary Get endpoint status
-// @description Endpoint for edge agent to check status of environment
+// @description Endpoint for edge agent to check status of environment.
+// @description The agent reports the results of the async commands it ran by checking in with a POST request.
 // @description **Access policy**: restricted only to Edge endpoints
 // @tags endpoints
 // @security jwt
+// @accept json
 // @param id path int true "Endpoint identifier"
+// @param body body endpointStatusInspectPayload false "Results of the async commands"
 // @success 200 {object} endpointStatusInspectResponse "Success"
 // @failure 400 "Invalid request"
 // @failure 403 "Permission denied to access endpoint"
 // @failure 404 "Endpoint not found"
 // @failure 500 "Server error"
 // @router /endpoints/{id}/status [get]
+// @router /endpoints/{id}/status [post]
 func (handler *Handler) endpointStatusInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
 	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
 	if err != nil {
@@ -103,6 +125,19 @@ func (handler *Handler) endpointStatusInspect(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	if r.Method == http.MethodPost {
+		var payload endpointStatusInspectPayload
+		err = request.DecodeAndValidateJSONPayload(r, &payload)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+		}
+
+		err = handler.acknowledgeEdgeAsyncCommands(endpoint, payload.Commands)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the results of the async commands inside the database", err}
+		}
+	}
+
 	endpoint.LastCheckInDate = time.Now().Unix()
 
 	err = handler.DataStore.Endpoint().UpdateEndpoint(endpoint.ID, endpoint)
@@ -129,6 +164,9 @@ func (handler *Handler) endpointStatusInspect(w http.ResponseWriter, r *http.Req
 			CronExpression: job.CronExpression,
 			CollectLogs:    job.Endpoints[endpoint.ID].CollectLogs,
 			Version:        job.Version,
+			Timeout:        job.Timeout,
+			MaxRetries:     job.MaxRetries,
+			RetryInterval:  job.RetryInterval,
 		}
 
 		file, err := handler.FileService.GetFileContent(job.ScriptPath)
@@ -150,13 +188,23 @@ func (handler *Handler) endpointStatusInspect(w http.ResponseWriter, r *http.Req
 		Credentials:     tunnel.Credentials,
 	}
 
+	statusResponse.SnapshotInterval = int(edge.EdgeSnapshotPushInterval(settings).Seconds())
+
+	statusResponse.Fingerprint, statusResponse.NextFingerprint = handler.ReverseTunnelService.TunnelServerFingerprints()
+
+	secret := r.Header.Get(portainer.PortainerAgentEdgeKeySecretHeader)
+	if endpoint.EdgeCredentials.Secret != "" && secret != endpoint.EdgeCredentials.Secret {
+		statusResponse.EdgeKeySecret = endpoint.EdgeCredentials.Secret
+	}
+
 	if tunnel.Status == portainer.EdgeAgentManagementRequired {
 		handler.ReverseTunnelService.SetTunnelStatusToActive(endpoint.ID)
 	}
 
-	relation, err := handler.DataStore.EndpointRelation().EndpointRelation(endpoint.ID)
+	// the dynamic Edge groups matching the check-in date of the endpoint change with this check-in
+	relation, err := edge.UpdateEndpointRelation(handler.DataStore, endpoint)
 	if err != nil {
-		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve relation object from the database", err}
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to update the relation object inside the database", err}
 	}
 
 	edgeStacksStatus := []stackStatusResponse{}
@@ -166,9 +214,18 @@ func (handler *Handler) endpointStatusInspect(w http.ResponseWriter, r *http.Req
 			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge stack from the database", err}
 		}
 
+		if !edge.IsEdgeStackCompatible(stack.DeploymentType, endpoint.Type) {
+			continue
+		}
+
+		version := edge.EdgeStackEndpointVersion(stack, endpoint.ID)
+		if version == 0 {
+			continue
+		}
+
 		stackStatus := stackStatusResponse{
 			ID:      stack.ID,
-			Version: stack.Version,
+			Version: version,
 		}
 
 		edgeStacksStatus = append(edgeStacksStatus, stackStatus)
@@ -176,5 +233,10 @@ func (handler *Handler) endpointStatusInspect(w http.ResponseWriter, r *http.Req
 
 	statusResponse.Stacks = edgeStacksStatus
 
+	statusResponse.Commands, err = handler.deliverEdgeAsyncCommands(endpoint.ID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the async commands from the database", err}
+	}
+
 	return response.JSON(w, statusResponse)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/portainer/portainer/api/bolt/errors"
 	"github.com/portainer/portainer/api/http/client"
 	"github.com/portainer/portainer/api/internal/edge"
+	"github.com/portainer/portainer/api/internal/quota"
 	"github.com/portainer/portainer/api/internal/tag"
 )
 
@@ -45,12 +46,30 @@ type endpointUpdatePayload struct {
 	TeamAccessPolicies portainer.TeamAccessPolicies
 	// The check in interval for edge agent (in seconds)
 	EdgeCheckinInterval *int `example:"5"`
+	// Variables rendered into the edge stacks deployed on this endpoint
+	EdgeVariables map[string]string
+	// Metadata labels, matched by the rules of dynamic Edge groups
+	Labels map[string]string
 	// Associated Kubernetes data
 	Kubernetes *portainer.KubernetesData
 }
 
 func (payload *endpointUpdatePayload) Validate(r *http.Request) error {
-	return nil
+	err := edge.ValidateLabels(payload.Labels)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range payload.TeamAccessPolicies {
+		if policy.Quota != nil {
+			err := quota.ValidateQuota(policy.Quota)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return edge.ValidateVariableNames(payload.EdgeVariables)
 }
 
 // @id EndpointUpdate
@@ -103,6 +122,16 @@ func (handler *Handler) endpointUpdate(w http.ResponseWriter, r *http.Request) *
 		endpoint.EdgeCheckinInterval = *payload.EdgeCheckinInterval
 	}
 
+	if payload.EdgeVariables != nil {
+		endpoint.EdgeVariables = payload.EdgeVariables
+	}
+
+	labelsChanged := false
+	if payload.Labels != nil {
+		labelsChanged = !reflect.DeepEqual(payload.Labels, endpoint.Labels)
+		endpoint.Labels = payload.Labels
+	}
+
 	groupIDChanged := false
 	if payload.GroupID != nil {
 		groupID := portainer.EndpointGroupID(*payload.GroupID)
@@ -110,6 +139,18 @@ func (handler *Handler) endpointUpdate(w http.ResponseWriter, r *http.Request) *
 		endpoint.GroupID = groupID
 	}
 
+	if endpoint.Type == portainer.EdgeAgentOnDockerEnvironment && (payload.GroupID != nil || payload.TagIDs != nil || payload.Labels != nil) {
+		updatedEndpoint := *endpoint
+		if payload.TagIDs != nil {
+			updatedEndpoint.TagIDs = payload.TagIDs
+		}
+
+		err = handler.validateEdgeStacks(&updatedEndpoint)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusBadRequest, "Endpoint cannot deploy its edge stacks", err}
+		}
+	}
+
 	tagsChanged := false
 	if payload.TagIDs != nil {
 		payloadTagSet := tag.Set(payload.TagIDs)
@@ -269,41 +310,69 @@ func (handler *Handler) endpointUpdate(w http.ResponseWriter, r *http.Request) *
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist endpoint changes inside the database", err}
 	}
 
-	if (endpoint.Type == portainer.EdgeAgentOnDockerEnvironment || endpoint.Type == portainer.EdgeAgentOnKubernetesEnvironment) && (groupIDChanged || tagsChanged) {
-		relation, err := handler.DataStore.EndpointRelation().EndpointRelation(endpoint.ID)
+	if (endpoint.Type == portainer.EdgeAgentOnDockerEnvironment || endpoint.Type == portainer.EdgeAgentOnKubernetesEnvironment) && (groupIDChanged || tagsChanged || labelsChanged) {
+		err = handler.updateEdgeRelations(endpoint)
 		if err != nil {
-			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find endpoint relation inside the database", err}
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist endpoint relation changes inside the database", err}
 		}
+	}
 
-		endpointGroup, err := handler.DataStore.EndpointGroup().EndpointGroup(endpoint.GroupID)
-		if err != nil {
-			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to find endpoint group inside the database", err}
-		}
+	return response.JSON(w, endpoint)
+}
 
-		edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
-		if err != nil {
-			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge groups from the database", err}
-		}
+// updateEdgeRelations updates the edge stacks related to an edge endpoint
+func (handler *Handler) updateEdgeRelations(endpoint *portainer.Endpoint) error {
+	relation, err := handler.DataStore.EndpointRelation().EndpointRelation(endpoint.ID)
+	if err != nil {
+		return err
+	}
 
-		edgeStacks, err := handler.DataStore.EdgeStack().EdgeStacks()
-		if err != nil {
-			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve edge stacks from the database", err}
-		}
+	endpointGroup, err := handler.DataStore.EndpointGroup().EndpointGroup(endpoint.GroupID)
+	if err != nil {
+		return err
+	}
 
-		edgeStackSet := map[portainer.EdgeStackID]bool{}
+	edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return err
+	}
 
-		endpointEdgeStacks := edge.EndpointRelatedEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks)
-		for _, edgeStackID := range endpointEdgeStacks {
-			edgeStackSet[edgeStackID] = true
-		}
+	edgeStacks, err := handler.DataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return err
+	}
 
-		relation.EdgeStacks = edgeStackSet
+	edgeStackSet := map[portainer.EdgeStackID]bool{}
 
-		err = handler.DataStore.EndpointRelation().UpdateEndpointRelation(endpoint.ID, relation)
-		if err != nil {
-			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist endpoint relation changes inside the database", err}
-		}
+	endpointEdgeStacks := edge.EndpointRelatedEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks)
+	for _, edgeStackID := range endpointEdgeStacks {
+		edgeStackSet[edgeStackID] = true
 	}
 
-	return response.JSON(w, endpoint)
+	if reflect.DeepEqual(relation.EdgeStacks, edgeStackSet) {
+		return nil
+	}
+
+	relation.EdgeStacks = edgeStackSet
+
+	return handler.DataStore.EndpointRelation().UpdateEndpointRelation(endpoint.ID, relation)
+}
+
+func (handler *Handler) validateEdgeStacks(endpoint *portainer.Endpoint) error {
+	endpointGroup, err := handler.DataStore.EndpointGroup().EndpointGroup(endpoint.GroupID)
+	if err != nil {
+		return err
+	}
+
+	edgeGroups, err := handler.DataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return err
+	}
+
+	edgeStacks, err := handler.DataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return err
+	}
+
+	return edge.ValidateEndpointEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks)
 }
@@ -3,6 +3,7 @@ package endpoints
 import (
 	httperror "github.com/portainer/libhttp/error"
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/docker"
 	"github.com/portainer/portainer/api/http/proxy"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
@@ -15,6 +16,8 @@ import (
 
 func hideFields(endpoint *portainer.Endpoint) {
 	endpoint.AzureCredentials = portainer.AzureCredentials{}
+	endpoint.EdgeCredentials.Secret = ""
+	endpoint.EdgeCredentials.PreviousSecret = ""
 	if len(endpoint.Snapshots) > 0 {
 		endpoint.Snapshots[0].SnapshotRaw = portainer.DockerSnapshotRaw{}
 	}
@@ -25,6 +28,7 @@ type Handler struct {
 	*mux.Router
 	requestBouncer       *security.RequestBouncer
 	DataStore            portainer.DataStore
+	DockerClientFactory  *docker.ClientFactory
 	FileService          portainer.FileService
 	ProxyManager         *proxy.Manager
 	ReverseTunnelService portainer.ReverseTunnelService
@@ -63,8 +67,16 @@ func NewHandler(bouncer *security.RequestBouncer) *Handler {
 		bouncer.RestrictedAccess(httperror.LoggerHandler(h.endpointExtensionRemove))).Methods(http.MethodDelete)
 	h.Handle("/endpoints/{id}/snapshot",
 		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointSnapshot))).Methods(http.MethodPost)
+	h.Handle("/endpoints/{id}/snapshots/history",
+		bouncer.RestrictedAccess(httperror.LoggerHandler(h.endpointSnapshotHistory))).Methods(http.MethodGet)
+	h.Handle("/endpoints/{id}/quotas",
+		bouncer.RestrictedAccess(httperror.LoggerHandler(h.endpointQuotas))).Methods(http.MethodGet)
+	h.Handle("/endpoints/{id}/proxy_cache",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointProxyCacheInspect))).Methods(http.MethodGet)
+	h.Handle("/endpoints/{id}/proxy_cache",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.endpointProxyCacheFlush))).Methods(http.MethodDelete)
 	h.Handle("/endpoints/{id}/status",
-		bouncer.PublicAccess(httperror.LoggerHandler(h.endpointStatusInspect))).Methods(http.MethodGet)
+		bouncer.PublicAccess(httperror.LoggerHandler(h.endpointStatusInspect))).Methods(http.MethodGet, http.MethodPost)
 	h.Handle("/endpoints/{id}/registries",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.endpointRegistriesList))).Methods(http.MethodGet)
 	h.Handle("/endpoints/{id}/registries/{registryId}",
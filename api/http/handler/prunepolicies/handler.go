@@ -0,0 +1,41 @@
+package prunepolicies
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/prune"
+)
+
+// Handler is the HTTP handler used to handle prune policy operations.
+type Handler struct {
+	*mux.Router
+	DataStore    portainer.DataStore
+	PruneService *prune.Service
+}
+
+// NewHandler creates a handler to manage prune policy operations.
+func NewHandler(bouncer *security.RequestBouncer) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+	h.Handle("/prune_policies",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.prunePolicyCreate))).Methods(http.MethodPost)
+	h.Handle("/prune_policies",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.prunePolicyList))).Methods(http.MethodGet)
+	h.Handle("/prune_policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.prunePolicyInspect))).Methods(http.MethodGet)
+	h.Handle("/prune_policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.prunePolicyUpdate))).Methods(http.MethodPut)
+	h.Handle("/prune_policies/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.prunePolicyDelete))).Methods(http.MethodDelete)
+	h.Handle("/prune_policies/{id}/run",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.prunePolicyRun))).Methods(http.MethodPost)
+	h.Handle("/prune_policies/{id}/runs",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.prunePolicyRunList))).Methods(http.MethodGet)
+
+	return h
+}
@@ -0,0 +1,110 @@
+package prunepolicies
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+)
+
+type prunePolicyPayload struct {
+	// Name of the policy
+	Name string `example:"nightly-cleanup"`
+	// The policy runs on these endpoints
+	EndpointIDs []portainer.EndpointID
+	// The policy runs on the endpoints of these groups
+	EndpointGroupIDs []portainer.EndpointGroupID
+	// Duration between two runs of the policy on an endpoint
+	Interval string `example:"24h"`
+	// Whether the scheduled runs only report the resources that would be removed
+	DryRun bool `example:"false"`
+	Rules  portainer.PrunePolicyRules
+}
+
+func (payload *prunePolicyPayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("Invalid prune policy name")
+	}
+	interval, err := time.ParseDuration(payload.Interval)
+	if err != nil || interval <= 0 {
+		return errors.New("Invalid prune policy interval. Value must be a positive duration such as 24h")
+	}
+	if payload.Rules.DanglingImagesOlderThanDays < 0 {
+		return errors.New("Invalid dangling images age. Value must be positive")
+	}
+	if payload.Rules.StoppedContainersOlderThanDays < 0 {
+		return errors.New("Invalid stopped containers age. Value must be positive")
+	}
+	if payload.Rules.BuildCacheThreshold < 0 {
+		return errors.New("Invalid build cache threshold. Value must be positive")
+	}
+	return nil
+}
+
+// @id PrunePolicyCreate
+// @summary Create a new prune policy
+// @description Create a new prune policy, removing the unused Docker resources of its endpoints on schedule.
+// @description **Access policy**: administrator
+// @tags prune_policies
+// @security jwt
+// @accept json
+// @produce json
+// @param body body prunePolicyPayload true "Prune policy details"
+// @success 200 {object} portainer.PrunePolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 409 "Prune policy name exists"
+// @failure 500 "Server error"
+// @router /prune_policies [post]
+func (handler *Handler) prunePolicyCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload prunePolicyPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	handlerErr := handler.checkUniqueName(payload.Name, 0)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	policy := &portainer.PrunePolicy{
+		CreationDate: time.Now().Unix(),
+	}
+	applyPayload(policy, &payload)
+
+	err = handler.DataStore.PrunePolicy().CreatePrunePolicy(policy)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the prune policy inside the database", err}
+	}
+
+	return response.JSON(w, policy)
+}
+
+func (handler *Handler) checkUniqueName(name string, policyID portainer.PrunePolicyID) *httperror.HandlerError {
+	policies, err := handler.DataStore.PrunePolicy().PrunePolicies()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve prune policies from the database", err}
+	}
+
+	for _, policy := range policies {
+		if policy.Name == name && policy.ID != policyID {
+			return &httperror.HandlerError{http.StatusConflict, "This name is already associated to a prune policy", errors.New("A prune policy already exists with this name")}
+		}
+	}
+
+	return nil
+}
+
+func applyPayload(policy *portainer.PrunePolicy, payload *prunePolicyPayload) {
+	policy.Name = payload.Name
+	policy.EndpointIDs = payload.EndpointIDs
+	policy.EndpointGroupIDs = payload.EndpointGroupIDs
+	policy.Interval = payload.Interval
+	policy.DryRun = payload.DryRun
+	policy.Rules = payload.Rules
+}
@@ -0,0 +1,39 @@
+package prunepolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id PrunePolicyDelete
+// @summary Remove a prune policy
+// @description Remove a prune policy and its recorded runs.
+// @description **Access policy**: administrator
+// @tags prune_policies
+// @security jwt
+// @param id path int true "Prune policy identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Prune policy not found"
+// @failure 500 "Server error"
+// @router /prune_policies/{id} [delete]
+func (handler *Handler) prunePolicyDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.prunePolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	err := handler.DataStore.PrunePolicy().DeletePrunePolicy(policy.ID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the prune policy from the database", err}
+	}
+
+	err = handler.DataStore.PrunePolicyRun().DeletePrunePolicyRuns(policy.ID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to remove the prune policy runs from the database", err}
+	}
+
+	return response.Empty(w)
+}
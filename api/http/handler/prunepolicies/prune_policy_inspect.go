@@ -0,0 +1,49 @@
+package prunepolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+)
+
+// @id PrunePolicyInspect
+// @summary Inspect a prune policy
+// @description Retrieve details about a prune policy.
+// @description **Access policy**: administrator
+// @tags prune_policies
+// @security jwt
+// @produce json
+// @param id path int true "Prune policy identifier"
+// @success 200 {object} portainer.PrunePolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Prune policy not found"
+// @failure 500 "Server error"
+// @router /prune_policies/{id} [get]
+func (handler *Handler) prunePolicyInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.prunePolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	return response.JSON(w, policy)
+}
+
+func (handler *Handler) prunePolicyFromRequest(r *http.Request) (*portainer.PrunePolicy, *httperror.HandlerError) {
+	policyID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "Invalid prune policy identifier route variable", err}
+	}
+
+	policy, err := handler.DataStore.PrunePolicy().PrunePolicy(portainer.PrunePolicyID(policyID))
+	if err == errors.ErrObjectNotFound {
+		return nil, &httperror.HandlerError{http.StatusNotFound, "Unable to find a prune policy with the specified identifier inside the database", err}
+	} else if err != nil {
+		return nil, &httperror.HandlerError{http.StatusInternalServerError, "Unable to find a prune policy with the specified identifier inside the database", err}
+	}
+
+	return policy, nil
+}
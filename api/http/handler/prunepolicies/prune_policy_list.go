@@ -0,0 +1,27 @@
+package prunepolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id PrunePolicyList
+// @summary List prune policies
+// @description List the prune policies.
+// @description **Access policy**: administrator
+// @tags prune_policies
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.PrunePolicy "Success"
+// @failure 500 "Server error"
+// @router /prune_policies [get]
+func (handler *Handler) prunePolicyList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policies, err := handler.DataStore.PrunePolicy().PrunePolicies()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve prune policies from the database", err}
+	}
+
+	return response.JSON(w, policies)
+}
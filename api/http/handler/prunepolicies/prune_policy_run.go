@@ -0,0 +1,113 @@
+package prunepolicies
+
+import (
+	"errors"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/prune"
+)
+
+type prunePolicyRunPayload struct {
+	// Only report the resources that would be removed
+	DryRun bool `example:"true"`
+	// Run the policy on these endpoints of the policy, or on every endpoint of the policy when empty
+	EndpointIDs []portainer.EndpointID
+}
+
+type prunePolicyRunResponse struct {
+	// Endpoints on which a run of the policy started
+	Started []portainer.EndpointID
+	// Endpoints skipped because a run of the policy is already in progress on them
+	Busy []portainer.EndpointID
+}
+
+func (payload *prunePolicyRunPayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id PrunePolicyRun
+// @summary Run a prune policy
+// @description Start runs of a prune policy on its endpoints immediately, the runs are recorded once they complete.
+// @description The endpoints on which the policy is already running are skipped and reported as busy,
+// @description failures to prune an endpoint are reported in its run.
+// @description **Access policy**: administrator
+// @tags prune_policies
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Prune policy identifier"
+// @param body body prunePolicyRunPayload true "Run details"
+// @success 200 {object} prunePolicyRunResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Prune policy not found"
+// @failure 500 "Server error"
+// @router /prune_policies/{id}/run [post]
+func (handler *Handler) prunePolicyRun(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.prunePolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	var payload prunePolicyRunPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoints from the database", err}
+	}
+
+	targets, err := selectEndpoints(prune.Endpoints(policy, endpoints), payload.EndpointIDs)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	runResponse := prunePolicyRunResponse{
+		Started: make([]portainer.EndpointID, 0, len(targets)),
+		Busy:    []portainer.EndpointID{},
+	}
+
+	for idx := range targets {
+		err := handler.PruneService.StartRun(policy, &targets[idx], payload.DryRun, true)
+		if err == prune.ErrRunInProgress {
+			runResponse.Busy = append(runResponse.Busy, targets[idx].ID)
+			continue
+		}
+
+		runResponse.Started = append(runResponse.Started, targets[idx].ID)
+	}
+
+	return response.JSON(w, runResponse)
+}
+
+// selectEndpoints returns the requested endpoints among the endpoints of the policy,
+// every endpoint of the policy when none is requested
+func selectEndpoints(endpoints []portainer.Endpoint, endpointIDs []portainer.EndpointID) ([]portainer.Endpoint, error) {
+	if len(endpointIDs) == 0 {
+		return endpoints, nil
+	}
+
+	selected := make([]portainer.Endpoint, 0, len(endpointIDs))
+	for _, endpointID := range endpointIDs {
+		found := false
+		for _, endpoint := range endpoints {
+			if endpoint.ID == endpointID {
+				selected = append(selected, endpoint)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, errors.New("The prune policy does not run on the requested endpoint")
+		}
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,46 @@
+package prunepolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+)
+
+// @id PrunePolicyRunList
+// @summary Fetch the run history of a prune policy
+// @description List the recorded runs of a prune policy, ordered by endpoint and by creation.
+// @description **Access policy**: administrator
+// @tags prune_policies
+// @security jwt
+// @produce json
+// @param id path int true "Prune policy identifier"
+// @param endpointId query int false "Only list the runs on this endpoint"
+// @success 200 {array} portainer.PrunePolicyRun "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Prune policy not found"
+// @failure 500 "Server error"
+// @router /prune_policies/{id}/runs [get]
+func (handler *Handler) prunePolicyRunList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.prunePolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	endpointID, _ := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+
+	var runs []portainer.PrunePolicyRun
+	var err error
+	if endpointID != 0 {
+		runs, err = handler.DataStore.PrunePolicyRun().PrunePolicyEndpointRuns(policy.ID, portainer.EndpointID(endpointID))
+	} else {
+		runs, err = handler.DataStore.PrunePolicyRun().PrunePolicyRuns(policy.ID)
+	}
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the prune policy runs from the database", err}
+	}
+
+	return response.JSON(w, runs)
+}
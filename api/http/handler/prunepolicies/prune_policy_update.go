@@ -0,0 +1,52 @@
+package prunepolicies
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id PrunePolicyUpdate
+// @summary Update a prune policy
+// @description Replace the scope, the schedule and the rules of a prune policy.
+// @description **Access policy**: administrator
+// @tags prune_policies
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Prune policy identifier"
+// @param body body prunePolicyPayload true "Prune policy details"
+// @success 200 {object} portainer.PrunePolicy "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Prune policy not found"
+// @failure 409 "Prune policy name exists"
+// @failure 500 "Server error"
+// @router /prune_policies/{id} [put]
+func (handler *Handler) prunePolicyUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	policy, handlerErr := handler.prunePolicyFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	var payload prunePolicyPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid request payload", err}
+	}
+
+	handlerErr = handler.checkUniqueName(payload.Name, policy.ID)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	applyPayload(policy, &payload)
+
+	err = handler.DataStore.PrunePolicy().UpdatePrunePolicy(policy.ID, policy)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to persist the prune policy changes inside the database", err}
+	}
+
+	return response.JSON(w, policy)
+}
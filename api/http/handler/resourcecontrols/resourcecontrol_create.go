@@ -15,7 +15,7 @@ type resourceControlCreatePayload struct {
 	//
 	ResourceID string `example:"617c5f22bb9b023d6daab7cba43a57576f83492867bc767d1c59416b065e5f08" validate:"required"`
 	// Type of Docker resource. Valid values are: container, volume\
-	// service, secret, config or stack
+	// service, secret, config, stack or image
 	Type string `example:"container" validate:"required"`
 	// Permit access to the associated resource to any user
 	Public bool `example:"true"`
@@ -92,8 +92,10 @@ func (handler *Handler) resourceControlCreate(w http.ResponseWriter, r *http.Req
 		resourceControlType = portainer.StackResourceControl
 	case "config":
 		resourceControlType = portainer.ConfigResourceControl
+	case "image":
+		resourceControlType = portainer.ImageResourceControl
 	default:
-		return &httperror.HandlerError{http.StatusBadRequest, "Invalid type value. Value must be one of: container, service, volume, network, secret, stack or config", errInvalidResourceControlType}
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid type value. Value must be one of: container, service, volume, network, secret, stack, config or image", errInvalidResourceControlType}
 	}
 
 	rc, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(payload.ResourceID, resourceControlType)
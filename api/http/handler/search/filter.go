@@ -0,0 +1,359 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/internal/snapshot"
+	"github.com/portainer/portainer/api/internal/stackutils"
+)
+
+const (
+	resourceLabelForDockerSwarmStackName   = "com.docker.stack.namespace"
+	resourceLabelForDockerServiceID        = "com.docker.swarm.service.id"
+	resourceLabelForDockerComposeStackName = "com.docker.compose.project"
+)
+
+// accessContext holds what is required to verify the access of a user to the resources of an endpoint.
+// Resources are visible to non administrators only when a resource control grants them access,
+// in the same way as the Docker proxy filters resource lists.
+type accessContext struct {
+	isAdmin          bool
+	userID           portainer.UserID
+	userTeamIDs      []portainer.TeamID
+	resourceControls []portainer.ResourceControl
+}
+
+func newAccessContext(securityContext *security.RestrictedRequestContext, resourceControls []portainer.ResourceControl) *accessContext {
+	context := &accessContext{
+		isAdmin:          securityContext.IsAdmin,
+		userID:           securityContext.UserID,
+		resourceControls: resourceControls,
+	}
+
+	for _, membership := range securityContext.UserMemberships {
+		context.userTeamIDs = append(context.userTeamIDs, membership.TeamID)
+	}
+
+	return context
+}
+
+func (context *accessContext) canAccess(endpointID portainer.EndpointID, resourceID string, resourceType portainer.ResourceControlType, labels map[string]string) bool {
+	if context.isAdmin {
+		return true
+	}
+
+	resourceControl := authorization.GetResourceControlByResourceIDAndType(resourceID, resourceType, context.resourceControls)
+
+	if resourceControl == nil && labels[resourceLabelForDockerServiceID] != "" {
+		resourceControl = authorization.GetResourceControlByResourceIDAndType(labels[resourceLabelForDockerServiceID], portainer.ServiceResourceControl, context.resourceControls)
+	}
+
+	if resourceControl == nil {
+		for _, label := range []string{resourceLabelForDockerSwarmStackName, resourceLabelForDockerComposeStackName} {
+			if labels[label] != "" {
+				stackResourceID := stackutils.ResourceControlID(endpointID, labels[label])
+				resourceControl = authorization.GetResourceControlByResourceIDAndType(stackResourceID, portainer.StackResourceControl, context.resourceControls)
+				break
+			}
+		}
+	}
+
+	return authorization.UserCanAccessResource(context.userID, context.userTeamIDs, resourceControl)
+}
+
+// canAccessImage verifies the access of a user to an image. Images are public unless a resource control
+// restricts them, which only happens for the images created through Portainer.
+func (context *accessContext) canAccessImage(imageID string) bool {
+	if context.isAdmin {
+		return true
+	}
+
+	resourceControl := authorization.GetResourceControlByResourceIDAndType(imageID, portainer.ImageResourceControl, context.resourceControls)
+	if resourceControl == nil {
+		return true
+	}
+
+	return authorization.UserCanAccessResource(context.userID, context.userTeamIDs, resourceControl)
+}
+
+// searchEndpoint returns the resources of the latest snapshot of an endpoint matching the filters.
+func searchEndpoint(endpoint *portainer.Endpoint, filters *searchFilters, context *accessContext, labelBlackList []portainer.Pair) ([]searchResult, error) {
+	results := make([]searchResult, 0)
+	if len(endpoint.Snapshots) == 0 {
+		return results, nil
+	}
+
+	endpointSnapshot := endpoint.Snapshots[0]
+	newResult := func(resultType, id, name string, labels map[string]string) searchResult {
+		return searchResult{
+			EndpointID:   endpoint.ID,
+			EndpointName: endpoint.Name,
+			SnapshotTime: endpointSnapshot.Time,
+			Type:         resultType,
+			ID:           id,
+			Name:         name,
+			Labels:       labels,
+		}
+	}
+
+	var containers []types.Container
+	err := decodeSnapshotData(endpointSnapshot.SnapshotRaw.Containers, &containers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode containers of endpoint %d: %w", endpoint.ID, err)
+	}
+
+	for _, container := range containers {
+		if hasBlackListedLabel(container.Labels, labelBlackList) || !filters.matchContainer(&container) {
+			continue
+		}
+
+		if !context.canAccess(endpoint.ID, container.ID, portainer.ContainerResourceControl, container.Labels) {
+			continue
+		}
+
+		result := newResult(resultTypeContainer, container.ID, containerName(&container), container.Labels)
+		result.Image = container.Image
+		result.State = container.State
+		result.Health = containerHealth(&container)
+		results = append(results, result)
+	}
+
+	var images []types.ImageSummary
+	err = decodeSnapshotData(endpointSnapshot.SnapshotRaw.Images, &images)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode images of endpoint %d: %w", endpoint.ID, err)
+	}
+
+	for _, image := range images {
+		if !filters.matchImage(&image) {
+			continue
+		}
+
+		if !context.canAccessImage(image.ID) {
+			continue
+		}
+
+		name := "<none>"
+		if len(image.RepoTags) > 0 {
+			name = image.RepoTags[0]
+		}
+
+		results = append(results, newResult(resultTypeImage, image.ID, name, image.Labels))
+	}
+
+	var volumes volume.VolumeListOKBody
+	err = decodeSnapshotData(endpointSnapshot.SnapshotRaw.Volumes, &volumes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode volumes of endpoint %d: %w", endpoint.ID, err)
+	}
+
+	// volume resource controls are scoped to the Docker engine, the identifier is only read when needed
+	dockerID := ""
+	for _, volume := range volumes.Volumes {
+		if volume == nil || !filters.matchVolume(volume) {
+			continue
+		}
+
+		if !context.isAdmin {
+			if dockerID == "" {
+				dockerID, _ = snapshot.FetchDockerID(endpointSnapshot)
+			}
+
+			resourceID := fmt.Sprintf("%s_%s", volume.Name, dockerID)
+			if !context.canAccess(endpoint.ID, resourceID, portainer.VolumeResourceControl, volume.Labels) {
+				continue
+			}
+		}
+
+		results = append(results, newResult(resultTypeVolume, volume.Name, volume.Name, volume.Labels))
+	}
+
+	return results, nil
+}
+
+// decodeSnapshotData converts the raw Docker data stored in a snapshot to its Docker API type.
+func decodeSnapshotData(data interface{}, object interface{}) error {
+	if data == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, object)
+}
+
+func hasBlackListedLabel(labels map[string]string, labelBlackList []portainer.Pair) bool {
+	for _, blackListedLabel := range labelBlackList {
+		if value, ok := labels[blackListedLabel.Name]; ok && value == blackListedLabel.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func containerName(container *types.Container) string {
+	if len(container.Names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(container.Names[0], "/")
+}
+
+func containerHealth(container *types.Container) string {
+	switch {
+	case strings.Contains(container.Status, "(healthy)"):
+		return "healthy"
+	case strings.Contains(container.Status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.Contains(container.Status, "(health: starting)"):
+		return "starting"
+	}
+	return "none"
+}
+
+func (filters *searchFilters) matchContainer(container *types.Container) bool {
+	if filters.query != "" && !containsQuery(filters.query, append([]string{container.ID, container.Image}, container.Names...), container.Labels) {
+		return false
+	}
+
+	if filters.imageName != "" && !matchImageReference(container.Image, filters.imageName, filters.imageTag) {
+		return false
+	}
+
+	if filters.hasLabel && !filters.matchLabels(container.Labels) {
+		return false
+	}
+
+	if filters.port != 0 && !containerExposesPort(container, filters.port) {
+		return false
+	}
+
+	if filters.volume != "" && !containerMountsVolume(container, filters.volume) {
+		return false
+	}
+
+	if filters.health != "" && containerHealth(container) != filters.health {
+		return false
+	}
+
+	return true
+}
+
+func (filters *searchFilters) matchImage(image *types.ImageSummary) bool {
+	if filters.port != 0 || filters.volume != "" || filters.health != "" {
+		return false
+	}
+
+	if filters.query != "" && !containsQuery(filters.query, append([]string{image.ID}, image.RepoTags...), image.Labels) {
+		return false
+	}
+
+	if filters.imageName != "" {
+		found := false
+		for _, tag := range image.RepoTags {
+			if matchImageReference(tag, filters.imageName, filters.imageTag) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if filters.hasLabel && !filters.matchLabels(image.Labels) {
+		return false
+	}
+
+	return true
+}
+
+func (filters *searchFilters) matchVolume(volume *types.Volume) bool {
+	if filters.imageName != "" || filters.port != 0 || filters.health != "" {
+		return false
+	}
+
+	if filters.query != "" && !containsQuery(filters.query, []string{volume.Name}, volume.Labels) {
+		return false
+	}
+
+	if filters.volume != "" && volume.Name != filters.volume {
+		return false
+	}
+
+	if filters.hasLabel && !filters.matchLabels(volume.Labels) {
+		return false
+	}
+
+	return true
+}
+
+func (filters *searchFilters) matchLabels(labels map[string]string) bool {
+	value, ok := labels[filters.labelKey]
+	return ok && (filters.labelValue == "" || value == filters.labelValue)
+}
+
+// containsQuery returns true when one of the values, label keys or label values contains the lowercase query.
+func containsQuery(query string, values []string, labels map[string]string) bool {
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), query) {
+			return true
+		}
+	}
+
+	for key, value := range labels {
+		if strings.Contains(strings.ToLower(key), query) || strings.Contains(strings.ToLower(value), query) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchImageReference returns true when the reference designates the image name, with the specified tag if any.
+// References without tags use the latest tag, the default Docker Hub namespace is ignored.
+func matchImageReference(reference, name, tag string) bool {
+	referenceName, referenceTag := splitImageReference(reference)
+	if referenceTag == "" {
+		referenceTag = "latest"
+	}
+
+	if normalizeImageName(referenceName) != normalizeImageName(name) {
+		return false
+	}
+
+	return tag == "" || tag == referenceTag
+}
+
+func normalizeImageName(name string) string {
+	name = strings.TrimPrefix(name, "docker.io/")
+	return strings.TrimPrefix(name, "library/")
+}
+
+func containerExposesPort(container *types.Container, port int) bool {
+	for _, containerPort := range container.Ports {
+		if int(containerPort.PrivatePort) == port || int(containerPort.PublicPort) == port {
+			return true
+		}
+	}
+	return false
+}
+
+func containerMountsVolume(container *types.Container, volumeName string) bool {
+	for _, mount := range container.Mounts {
+		if mount.Type == "volume" && mount.Name == volumeName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+)
+
+const (
+	resultTypeContainer = "container"
+	resultTypeImage     = "image"
+	resultTypeVolume    = "volume"
+)
+
+type searchResult struct {
+	// Endpoint identifier
+	EndpointID portainer.EndpointID `json:"EndpointId" example:"1"`
+	// Endpoint name
+	EndpointName string `json:"EndpointName" example:"my-endpoint"`
+	// Unix timestamp of the endpoint snapshot holding the resource
+	SnapshotTime int64 `json:"SnapshotTime" example:"1625132400"`
+	// Type of the resource, either container, image or volume
+	Type string `json:"Type" example:"container"`
+	// Docker identifier of the resource
+	ID string `json:"Id" example:"617c5f22bb9b023d6daab7cba43a57576f83492867bc767d1c59416b065e5f08"`
+	// Name of the resource, image references are used for images
+	Name string `json:"Name" example:"my-container"`
+	// Image used by a container
+	Image string `json:"Image,omitempty" example:"nginx:1.21"`
+	// State of a container
+	State string `json:"State,omitempty" example:"running"`
+	// Health status of a container, either healthy, unhealthy, starting or none
+	Health string `json:"Health,omitempty" example:"healthy"`
+	// Labels of the resource
+	Labels map[string]string `json:"Labels"`
+}
+
+// searchFilters represents the criteria a resource must match, every criterion being optional.
+// A resource type for which a criterion does not apply is excluded when the criterion is set.
+type searchFilters struct {
+	query      string
+	imageName  string
+	imageTag   string
+	labelKey   string
+	labelValue string
+	hasLabel   bool
+	port       int
+	volume     string
+	health     string
+}
+
+var validHealthStatuses = map[string]bool{"healthy": true, "unhealthy": true, "starting": true, "none": true}
+
+// @id Search
+// @summary Search resources across endpoints
+// @description Search containers, images and volumes across the latest snapshots of every endpoint the user can access.
+// @description Resources are filtered according to the resource controls associated to them.
+// @description **Access policy**: restricted
+// @tags search
+// @security jwt
+// @produce json
+// @param q query string false "Case insensitive text matched against resource names, identifiers, images and labels"
+// @param image query string false "Image name, optionally followed by a tag (e.g. nginx or nginx:1.21)"
+// @param label query string false "Label key, optionally followed by a value (e.g. com.example.team or com.example.team=ops)"
+// @param port query int false "Private or published container port"
+// @param volume query string false "Volume name"
+// @param health query string false "Container health status" Enums(healthy, unhealthy, starting, none)
+// @success 200 {array} searchResult "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /search [get]
+func (handler *Handler) search(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	filters, err := parseSearchFilters(r)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid query parameters", err}
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve info from request context", err}
+	}
+
+	endpointGroups, err := handler.DataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoint groups from the database", err}
+	}
+
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoints from the database", err}
+	}
+
+	resourceControls, err := handler.DataStore.ResourceControl().ResourceControls()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve resource controls from the database", err}
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve settings from the database", err}
+	}
+
+	accessContext := newAccessContext(securityContext, resourceControls)
+
+	results := make([]searchResult, 0)
+	for _, endpoint := range security.FilterEndpoints(endpoints, endpointGroups, securityContext) {
+		endpointResults, err := searchEndpoint(&endpoint, filters, accessContext, settings.BlackListedLabels)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to read endpoint snapshot", err}
+		}
+		results = append(results, endpointResults...)
+	}
+
+	return response.JSON(w, results)
+}
+
+func parseSearchFilters(r *http.Request) (*searchFilters, error) {
+	filters := &searchFilters{}
+
+	filters.query, _ = request.RetrieveQueryParameter(r, "q", true)
+	filters.query = strings.ToLower(strings.TrimSpace(filters.query))
+
+	image, _ := request.RetrieveQueryParameter(r, "image", true)
+	filters.imageName, filters.imageTag = splitImageReference(image)
+
+	label, _ := request.RetrieveQueryParameter(r, "label", true)
+	if label != "" {
+		filters.hasLabel = true
+		filters.labelKey = label
+		if index := strings.Index(label, "="); index != -1 {
+			filters.labelKey = label[:index]
+			filters.labelValue = label[index+1:]
+		}
+	}
+
+	port, _ := request.RetrieveQueryParameter(r, "port", true)
+	if port != "" {
+		value, err := strconv.Atoi(port)
+		if err != nil || value <= 0 || value > 65535 {
+			return nil, errors.New("Invalid port, must be a number between 1 and 65535")
+		}
+		filters.port = value
+	}
+
+	filters.volume, _ = request.RetrieveQueryParameter(r, "volume", true)
+
+	filters.health, _ = request.RetrieveQueryParameter(r, "health", true)
+	if filters.health != "" && !validHealthStatuses[filters.health] {
+		return nil, errors.New("Invalid health status, must be one of: healthy, unhealthy, starting or none")
+	}
+
+	return filters, nil
+}
+
+// splitImageReference splits an image reference into a name and a tag, the tag is empty when not specified.
+// Registry ports are not mistaken for tags.
+func splitImageReference(reference string) (string, string) {
+	reference = strings.SplitN(reference, "@", 2)[0]
+
+	index := strings.LastIndex(reference, ":")
+	if index == -1 || strings.Contains(reference[index+1:], "/") {
+		return reference, ""
+	}
+
+	return reference[:index], reference[index+1:]
+}
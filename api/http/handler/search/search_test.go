@@ -0,0 +1,142 @@
+package search
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEndpoint() *portainer.Endpoint {
+	return &portainer.Endpoint{
+		ID:   1,
+		Name: "production",
+		Snapshots: []portainer.DockerSnapshot{
+			{
+				Time: 1000,
+				SnapshotRaw: portainer.DockerSnapshotRaw{
+					Info: map[string]interface{}{"ID": "docker-id"},
+					Containers: []types.Container{
+						{
+							ID:     "web",
+							Names:  []string{"/web"},
+							Image:  "nginx:1.21",
+							State:  "running",
+							Status: "Up 2 hours (healthy)",
+							Labels: map[string]string{"com.docker.compose.project": "frontend"},
+							Ports:  []types.Port{{PrivatePort: 80, PublicPort: 8080}},
+						},
+						{
+							ID:     "db",
+							Names:  []string{"/db"},
+							Image:  "postgres",
+							State:  "running",
+							Status: "Up 2 hours (unhealthy)",
+							Mounts: []types.MountPoint{{Type: "volume", Name: "pgdata"}},
+						},
+					},
+					Images: []types.ImageSummary{
+						{ID: "sha256:nginx", RepoTags: []string{"nginx:1.21"}},
+						{ID: "sha256:postgres", RepoTags: []string{"postgres:latest"}},
+					},
+					Volumes: volume.VolumeListOKBody{
+						Volumes: []*types.Volume{{Name: "pgdata"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resultIDs(results []searchResult) []string {
+	ids := make([]string, 0)
+	for _, result := range results {
+		ids = append(ids, result.Type+":"+result.ID)
+	}
+	return ids
+}
+
+func TestSearchEndpoint_Filters(t *testing.T) {
+	admin := &accessContext{isAdmin: true}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"container:web", "container:db", "image:sha256:nginx", "image:sha256:postgres", "volume:pgdata"}},
+		{"q=NGINX", []string{"container:web", "image:sha256:nginx"}},
+		{"image=nginx:1.21", []string{"container:web", "image:sha256:nginx"}},
+		{"image=nginx:1.20", []string{}},
+		{"image=docker.io/library/postgres:latest", []string{"container:db", "image:sha256:postgres"}},
+		{"label=com.docker.compose.project=frontend", []string{"container:web"}},
+		{"port=8080", []string{"container:web"}},
+		{"volume=pgdata", []string{"container:db", "volume:pgdata"}},
+		{"health=unhealthy", []string{"container:db"}},
+		{"health=healthy&image=postgres", []string{}},
+	}
+
+	for _, test := range tests {
+		filters, err := parseSearchFilters(httptest.NewRequest("GET", "/search?"+test.query, nil))
+		require.NoError(t, err)
+
+		results, err := searchEndpoint(newTestEndpoint(), filters, admin, nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, test.expected, resultIDs(results), "query: %s", test.query)
+	}
+}
+
+func TestSearchEndpoint_AccessControl(t *testing.T) {
+	user := &accessContext{
+		userID:      2,
+		userTeamIDs: []portainer.TeamID{3},
+		resourceControls: []portainer.ResourceControl{
+			{ResourceID: "1_frontend", Type: portainer.StackResourceControl, TeamAccesses: []portainer.TeamResourceAccess{{TeamID: 3}}},
+			{ResourceID: "pgdata_docker-id", Type: portainer.VolumeResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 2}}},
+			{ResourceID: "db", Type: portainer.ContainerResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 4}}},
+		},
+	}
+
+	results, err := searchEndpoint(newTestEndpoint(), &searchFilters{}, user, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"container:web", "image:sha256:nginx", "image:sha256:postgres", "volume:pgdata"}, resultIDs(results))
+
+	assert.Equal(t, "production", results[0].EndpointName)
+	assert.Equal(t, int64(1000), results[0].SnapshotTime)
+}
+
+func TestSearchEndpoint_ImageAccessControl(t *testing.T) {
+	resourceControls := []portainer.ResourceControl{
+		{ResourceID: "sha256:nginx", Type: portainer.ImageResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 4}}},
+	}
+	filters := &searchFilters{imageName: "nginx"}
+
+	results, err := searchEndpoint(newTestEndpoint(), filters, &accessContext{userID: 2, resourceControls: resourceControls}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, resultIDs(results))
+
+	results, err = searchEndpoint(newTestEndpoint(), filters, &accessContext{userID: 4, resourceControls: resourceControls}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"image:sha256:nginx"}, resultIDs(results))
+
+	results, err = searchEndpoint(newTestEndpoint(), &searchFilters{imageName: "postgres"}, &accessContext{userID: 2, resourceControls: resourceControls}, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"image:sha256:postgres"}, resultIDs(results))
+}
+
+func TestSearchEndpoint_BlackListedLabels(t *testing.T) {
+	filters := &searchFilters{health: "healthy"}
+	results, err := searchEndpoint(newTestEndpoint(), filters, &accessContext{isAdmin: true}, []portainer.Pair{{Name: "com.docker.compose.project", Value: "frontend"}})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestParseSearchFilters_Invalid(t *testing.T) {
+	for _, query := range []string{"port=abc", "port=70000", "health=sick"} {
+		_, err := parseSearchFilters(httptest.NewRequest("GET", "/search?"+query, nil))
+		assert.Error(t, err, query)
+	}
+}
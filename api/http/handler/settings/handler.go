@@ -22,6 +22,8 @@ type Handler struct {
 	JWTService      portainer.JWTService
 	LDAPService     portainer.LDAPService
 	SnapshotService portainer.SnapshotService
+	// TunnelPortRangeFromFlags is set when the tunnel port range is set by flags, which take precedence over the settings
+	TunnelPortRangeFromFlags bool
 }
 
 // NewHandler creates a handler to manage settings operations.
@@ -24,6 +24,32 @@ type settingsUpdatePayload struct {
 	OAuthSettings        *portainer.OAuthSettings `example:""`
 	// The interval in which endpoint snapshots are created
 	SnapshotInterval *string `example:"5m"`
+	// The duration for which endpoint snapshot history is retained
+	SnapshotHistoryRetention *string `example:"720h"`
+	// The age after which endpoint snapshot history is downsampled
+	SnapshotHistoryDownsampleAfter *string `example:"24h"`
+	// The resolution of downsampled endpoint snapshot history
+	SnapshotHistoryDownsampleInterval *string `example:"1h"`
+	// The duration for which the status history of edge stacks is retained
+	EdgeStackStatusHistoryRetention *string `example:"720h"`
+	// The maximum number of status history entries retained for an edge stack on an endpoint
+	EdgeStackStatusHistoryMaxEntries *int `example:"100"`
+	// The interval at which the edge keys are rotated, an empty value disables the rotation
+	EdgeKeyRotationInterval *string `example:"720h"`
+	// The interval at which the tunnel server key is rotated, an empty value disables the rotation
+	TunnelServerKeyRotationInterval *string `example:"2160h"`
+	// The period during which the previous edge key or tunnel server key is still accepted after a rotation
+	EdgeKeyRotationGracePeriod *string `example:"24h"`
+	// The interval at which Edge agents push the snapshot of their endpoint, 0 disables the push
+	EdgeSnapshotPushInterval *string `example:"5m"`
+	// The lowest port that can be allocated to the tunnel of an Edge endpoint
+	EdgeTunnelMinPort *int `example:"49152"`
+	// The highest port that can be allocated to the tunnel of an Edge endpoint
+	EdgeTunnelMaxPort *int `example:"65535"`
+	// The duration for which the Docker list responses are cached by the proxy, 0 disables the cache
+	DockerProxyCacheTTL *string `example:"5s"`
+	// Whether the cached Docker list responses are also invalidated by the events of the Docker engine
+	DockerProxyCacheEventsInvalidation *bool `example:"false"`
 	// URL to the templates that will be displayed in the UI when navigating to App Templates
 	TemplatesURL *string `example:"https://raw.githubusercontent.com/portainer/templates/master/templates.json"`
 	// The default check in interval for edge agent (in seconds)
@@ -52,10 +78,65 @@ func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
 			return errors.New("Invalid user session timeout")
 		}
 	}
+	if payload.SnapshotHistoryRetention != nil && !isPositiveDuration(*payload.SnapshotHistoryRetention) {
+		return errors.New("Invalid snapshot history retention")
+	}
+	if payload.SnapshotHistoryDownsampleAfter != nil && !isPositiveDuration(*payload.SnapshotHistoryDownsampleAfter) {
+		return errors.New("Invalid snapshot history downsample age")
+	}
+	if payload.SnapshotHistoryDownsampleInterval != nil && !isPositiveDuration(*payload.SnapshotHistoryDownsampleInterval) {
+		return errors.New("Invalid snapshot history downsample interval")
+	}
+	if payload.EdgeStackStatusHistoryRetention != nil && !isPositiveDuration(*payload.EdgeStackStatusHistoryRetention) {
+		return errors.New("Invalid edge stack status history retention")
+	}
+	if payload.EdgeStackStatusHistoryMaxEntries != nil && *payload.EdgeStackStatusHistoryMaxEntries < 1 {
+		return errors.New("Invalid edge stack status history maximum entries. Value must be greater than 0")
+	}
+	if payload.EdgeKeyRotationInterval != nil && *payload.EdgeKeyRotationInterval != "" && !isPositiveDuration(*payload.EdgeKeyRotationInterval) {
+		return errors.New("Invalid edge key rotation interval")
+	}
+	if payload.TunnelServerKeyRotationInterval != nil && *payload.TunnelServerKeyRotationInterval != "" && !isPositiveDuration(*payload.TunnelServerKeyRotationInterval) {
+		return errors.New("Invalid tunnel server key rotation interval")
+	}
+	if payload.EdgeKeyRotationGracePeriod != nil && !isPositiveDuration(*payload.EdgeKeyRotationGracePeriod) {
+		return errors.New("Invalid edge key rotation grace period")
+	}
+	if payload.EdgeSnapshotPushInterval != nil && !isValidPushInterval(*payload.EdgeSnapshotPushInterval) {
+		return errors.New("Invalid edge snapshot push interval")
+	}
+	if payload.EdgeTunnelMinPort != nil && !isValidPort(*payload.EdgeTunnelMinPort) {
+		return errors.New("Invalid edge tunnel minimum port. Value must be between 1 and 65535")
+	}
+	if payload.EdgeTunnelMaxPort != nil && !isValidPort(*payload.EdgeTunnelMaxPort) {
+		return errors.New("Invalid edge tunnel maximum port. Value must be between 1 and 65535")
+	}
+	if payload.DockerProxyCacheTTL != nil && !isValidCacheTTL(*payload.DockerProxyCacheTTL) {
+		return errors.New("Invalid Docker proxy cache TTL")
+	}
 
 	return nil
 }
 
+func isPositiveDuration(value string) bool {
+	duration, err := time.ParseDuration(value)
+	return err == nil && duration > 0
+}
+
+func isValidPushInterval(value string) bool {
+	duration, err := time.ParseDuration(value)
+	return err == nil && (duration == 0 || duration >= time.Second)
+}
+
+func isValidCacheTTL(value string) bool {
+	duration, err := time.ParseDuration(value)
+	return err == nil && duration >= 0
+}
+
+func isValidPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
 // @id SettingsUpdate
 // @summary Update Portainer settings
 // @description Update Portainer settings.
@@ -131,6 +212,77 @@ func (handler *Handler) settingsUpdate(w http.ResponseWriter, r *http.Request) *
 		}
 	}
 
+	if payload.SnapshotHistoryRetention != nil {
+		settings.SnapshotHistoryRetention = *payload.SnapshotHistoryRetention
+	}
+
+	if payload.SnapshotHistoryDownsampleAfter != nil {
+		settings.SnapshotHistoryDownsampleAfter = *payload.SnapshotHistoryDownsampleAfter
+	}
+
+	if payload.SnapshotHistoryDownsampleInterval != nil {
+		settings.SnapshotHistoryDownsampleInterval = *payload.SnapshotHistoryDownsampleInterval
+	}
+
+	if payload.EdgeStackStatusHistoryRetention != nil {
+		settings.EdgeStackStatusHistoryRetention = *payload.EdgeStackStatusHistoryRetention
+	}
+
+	if payload.EdgeStackStatusHistoryMaxEntries != nil {
+		settings.EdgeStackStatusHistoryMaxEntries = *payload.EdgeStackStatusHistoryMaxEntries
+	}
+
+	if payload.EdgeKeyRotationInterval != nil {
+		settings.EdgeKeyRotationInterval = *payload.EdgeKeyRotationInterval
+	}
+
+	if payload.TunnelServerKeyRotationInterval != nil {
+		settings.TunnelServerKeyRotationInterval = *payload.TunnelServerKeyRotationInterval
+	}
+
+	if payload.EdgeKeyRotationGracePeriod != nil {
+		settings.EdgeKeyRotationGracePeriod = *payload.EdgeKeyRotationGracePeriod
+	}
+
+	if payload.EdgeSnapshotPushInterval != nil {
+		settings.EdgeSnapshotPushInterval = *payload.EdgeSnapshotPushInterval
+	}
+
+	// settings created before the port range was configurable use the default range
+	if settings.EdgeTunnelMinPort == 0 {
+		settings.EdgeTunnelMinPort = portainer.DefaultEdgeTunnelMinPort
+	}
+
+	if settings.EdgeTunnelMaxPort == 0 {
+		settings.EdgeTunnelMaxPort = portainer.DefaultEdgeTunnelMaxPort
+	}
+
+	tunnelPortRangeChanged := (payload.EdgeTunnelMinPort != nil && *payload.EdgeTunnelMinPort != settings.EdgeTunnelMinPort) ||
+		(payload.EdgeTunnelMaxPort != nil && *payload.EdgeTunnelMaxPort != settings.EdgeTunnelMaxPort)
+	if tunnelPortRangeChanged && handler.TunnelPortRangeFromFlags {
+		return &httperror.HandlerError{http.StatusBadRequest, "The edge tunnel port range is set by the --tunnel-min-port and --tunnel-max-port flags and cannot be updated", errors.New("the edge tunnel port range is set by flags")}
+	}
+
+	if payload.EdgeTunnelMinPort != nil {
+		settings.EdgeTunnelMinPort = *payload.EdgeTunnelMinPort
+	}
+
+	if payload.EdgeTunnelMaxPort != nil {
+		settings.EdgeTunnelMaxPort = *payload.EdgeTunnelMaxPort
+	}
+
+	if (payload.EdgeTunnelMinPort != nil || payload.EdgeTunnelMaxPort != nil) && settings.EdgeTunnelMinPort > settings.EdgeTunnelMaxPort {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid edge tunnel port range", errors.New("the minimum port exceeds the maximum port")}
+	}
+
+	if payload.DockerProxyCacheTTL != nil {
+		settings.DockerProxyCacheTTL = *payload.DockerProxyCacheTTL
+	}
+
+	if payload.DockerProxyCacheEventsInvalidation != nil {
+		settings.DockerProxyCacheEventsInvalidation = *payload.DockerProxyCacheEventsInvalidation
+	}
+
 	if payload.EdgeAgentCheckinInterval != nil {
 		settings.EdgeAgentCheckinInterval = *payload.EdgeAgentCheckinInterval
 	}
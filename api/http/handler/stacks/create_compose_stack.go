@@ -83,7 +83,7 @@ func (handler *Handler) createComposeStackFromFileContent(w http.ResponseWriter,
 
 	err = handler.deployComposeStack(config)
 	if err != nil {
-		return &httperror.HandlerError{StatusCode: http.StatusInternalServerError, Message: err.Error(), Err: err}
+		return deploymentError(err)
 	}
 
 	stack.CreatedBy = config.user.Username
@@ -184,7 +184,7 @@ func (handler *Handler) createComposeStackFromGitRepository(w http.ResponseWrite
 
 	err = handler.deployComposeStack(config)
 	if err != nil {
-		return &httperror.HandlerError{StatusCode: http.StatusInternalServerError, Message: err.Error(), Err: err}
+		return deploymentError(err)
 	}
 
 	stack.CreatedBy = config.user.Username
@@ -273,7 +273,7 @@ func (handler *Handler) createComposeStackFromFileUpload(w http.ResponseWriter,
 
 	err = handler.deployComposeStack(config)
 	if err != nil {
-		return &httperror.HandlerError{StatusCode: http.StatusInternalServerError, Message: err.Error(), Err: err}
+		return deploymentError(err)
 	}
 
 	stack.CreatedBy = config.user.Username
@@ -356,6 +356,16 @@ func (handler *Handler) deployComposeStack(config *composeStackDeploymentConfig)
 		}
 	}
 
+	err = handler.enforceDockerPolicies(config.stack, config.endpoint, config.user)
+	if err != nil {
+		return err
+	}
+
+	err = handler.checkStackQuotas(config.stack, config.endpoint, config.user, false)
+	if err != nil {
+		return err
+	}
+
 	handler.stackCreationMutex.Lock()
 	defer handler.stackCreationMutex.Unlock()
 
@@ -86,7 +86,7 @@ func (handler *Handler) createSwarmStackFromFileContent(w http.ResponseWriter, r
 
 	err = handler.deploySwarmStack(config)
 	if err != nil {
-		return &httperror.HandlerError{http.StatusInternalServerError, err.Error(), err}
+		return deploymentError(err)
 	}
 
 	stack.CreatedBy = config.user.Username
@@ -188,7 +188,7 @@ func (handler *Handler) createSwarmStackFromGitRepository(w http.ResponseWriter,
 
 	err = handler.deploySwarmStack(config)
 	if err != nil {
-		return &httperror.HandlerError{http.StatusInternalServerError, err.Error(), err}
+		return deploymentError(err)
 	}
 
 	stack.CreatedBy = config.user.Username
@@ -283,7 +283,7 @@ func (handler *Handler) createSwarmStackFromFileUpload(w http.ResponseWriter, r
 
 	err = handler.deploySwarmStack(config)
 	if err != nil {
-		return &httperror.HandlerError{http.StatusInternalServerError, err.Error(), err}
+		return deploymentError(err)
 	}
 
 	stack.CreatedBy = config.user.Username
@@ -357,6 +357,16 @@ func (handler *Handler) deploySwarmStack(config *swarmStackDeploymentConfig) err
 		}
 	}
 
+	err = handler.enforceDockerPolicies(config.stack, config.endpoint, config.user)
+	if err != nil {
+		return err
+	}
+
+	err = handler.checkStackQuotas(config.stack, config.endpoint, config.user, true)
+	if err != nil {
+		return err
+	}
+
 	handler.stackCreationMutex.Lock()
 	defer handler.stackCreationMutex.Unlock()
 
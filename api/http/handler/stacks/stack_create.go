@@ -1,10 +1,12 @@
 package stacks
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"path"
 
 	"github.com/docker/cli/cli/compose/loader"
 	"github.com/docker/cli/cli/compose/types"
@@ -17,6 +19,8 @@ import (
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
 	"github.com/portainer/portainer/api/internal/endpointutils"
+	"github.com/portainer/portainer/api/internal/policy"
+	"github.com/portainer/portainer/api/internal/quota"
 	"github.com/portainer/portainer/api/internal/stackutils"
 )
 
@@ -106,6 +110,12 @@ func (handler *Handler) stackCreate(w http.ResponseWriter, r *http.Request) *htt
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve user details from authentication token", err}
 	}
 
+	if portainer.StackType(stackType) != portainer.KubernetesStack {
+		// the quotas are verified on deployment, the stack and its resource control must be persisted before the next check
+		unlock := quota.LockEndpoint(endpoint)
+		defer unlock()
+	}
+
 	switch portainer.StackType(stackType) {
 	case portainer.DockerSwarmStack:
 		return handler.createSwarmStack(w, r, method, endpoint, tokenData.ID)
@@ -155,10 +165,10 @@ func (handler *Handler) createKubernetesStack(w http.ResponseWriter, r *http.Req
 	return &httperror.HandlerError{StatusCode: http.StatusBadRequest, Message: "Invalid value for query parameter: method. Value must be one of: string or repository", Err: errors.New(request.ErrInvalidQueryParameter)}
 }
 
-func (handler *Handler) isValidStackFile(stackFileContent []byte, securitySettings *portainer.EndpointSecuritySettings) error {
+func loadStackFile(stackFileContent []byte) (*types.Config, error) {
 	composeConfigYAML, err := loader.ParseYAML(stackFileContent)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	composeConfigFile := types.ConfigFile{
@@ -170,10 +180,14 @@ func (handler *Handler) isValidStackFile(stackFileContent []byte, securitySettin
 		Environment: map[string]string{},
 	}
 
-	composeConfig, err := loader.Load(composeConfigDetails, func(options *loader.Options) {
+	return loader.Load(composeConfigDetails, func(options *loader.Options) {
 		options.SkipValidation = true
 		options.SkipInterpolation = true
 	})
+}
+
+func (handler *Handler) isValidStackFile(stackFileContent []byte, securitySettings *portainer.EndpointSecuritySettings) error {
+	composeConfig, err := loadStackFile(stackFileContent)
 	if err != nil {
 		return err
 	}
@@ -212,6 +226,86 @@ func (handler *Handler) isValidStackFile(stackFileContent []byte, securitySettin
 	return nil
 }
 
+// enforceDockerPolicies evaluates the services of the stack file against the Docker policies
+func (handler *Handler) enforceDockerPolicies(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error {
+	stackFileContent, err := handler.FileService.GetFileContent(path.Join(stack.ProjectPath, stack.EntryPoint))
+	if err != nil {
+		return err
+	}
+
+	composeConfig, err := loadStackFile(stackFileContent)
+	if err != nil {
+		return err
+	}
+
+	workloads := make([]policy.Workload, 0, len(composeConfig.Services))
+	for i := range composeConfig.Services {
+		workloads = append(workloads, policy.StackServiceWorkload(&composeConfig.Services[i]))
+	}
+
+	return policy.Enforce(handler.DataStore, endpoint, user.ID, workloads...)
+}
+
+// checkStackQuotas verifies that the services of the stack file fit in the quotas of the teams of the user on the endpoint.
+// The resources of a stack already deployed on the endpoint are replaced by the ones of the stack file,
+// any other stack is counted as a new stack.
+func (handler *Handler) checkStackQuotas(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User, swarmMode bool) error {
+	stackFileContent, err := handler.FileService.GetFileContent(path.Join(stack.ProjectPath, stack.EntryPoint))
+	if err != nil {
+		return err
+	}
+
+	composeConfig, err := loadStackFile(stackFileContent)
+	if err != nil {
+		return err
+	}
+
+	dockerClient, err := handler.DockerClientFactory.CreateClient(endpoint, "")
+	if err != nil {
+		return err
+	}
+	defer dockerClient.Close()
+
+	nodes := 0
+	if swarmMode {
+		info, err := dockerClient.Info(context.Background())
+		if err != nil {
+			return err
+		}
+		nodes = info.Swarm.Nodes
+	}
+
+	demand := quota.StackDemand(composeConfig, swarmMode, nodes)
+
+	deployedStack, err := handler.DataStore.Stack().Stack(stack.ID)
+	if err != nil && err != bolterrors.ErrObjectNotFound {
+		return err
+	}
+
+	if err == nil && deployedStack.EndpointID == endpoint.ID {
+		demand.ExcludedStackName = deployedStack.Name
+	} else {
+		demand.Stacks = 1
+	}
+
+	return quota.Check(handler.DataStore, dockerClient, endpoint, user.ID, demand)
+}
+
+// deploymentError returns the error of a failed stack deployment, the policy denials and the exceeded quotas are reported as forbidden
+func deploymentError(err error) *httperror.HandlerError {
+	var denial *policy.DenialError
+	if errors.As(err, &denial) {
+		return &httperror.HandlerError{http.StatusForbidden, denial.Error(), err}
+	}
+
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		return &httperror.HandlerError{http.StatusForbidden, exceeded.Error(), err}
+	}
+
+	return &httperror.HandlerError{http.StatusInternalServerError, err.Error(), err}
+}
+
 func (handler *Handler) decorateStackResponse(w http.ResponseWriter, stack *portainer.Stack, userID portainer.UserID) *httperror.HandlerError {
 	var resourceControl *portainer.ResourceControl
 
@@ -1,11 +1,16 @@
 package stacks
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 
 	portainer "github.com/portainer/portainer/api"
 	gittypes "github.com/portainer/portainer/api/git/types"
 	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/policy"
+	"github.com/portainer/portainer/api/internal/quota"
 	"github.com/portainer/portainer/api/internal/testhelpers"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,3 +32,18 @@ func Test_stackHandler_cloneAndSaveConfig_shouldCallGitCloneAndSaveConfigOnStack
 		ConfigFilePath: configPath,
 	}, *stack.GitConfig)
 }
+
+func Test_deploymentError(t *testing.T) {
+	denial := &policy.DenialError{Violation: policy.Violation{PolicyName: "no-privileged", Reason: "privileged mode is not allowed"}}
+	handlerErr := deploymentError(fmt.Errorf("unable to deploy the stack: %w", denial))
+	assert.Equal(t, http.StatusForbidden, handlerErr.StatusCode)
+	assert.Equal(t, denial.Error(), handlerErr.Message)
+
+	exceeded := &quota.ExceededError{TeamName: "team", Reason: "too many stacks"}
+	handlerErr = deploymentError(exceeded)
+	assert.Equal(t, http.StatusForbidden, handlerErr.StatusCode)
+	assert.Equal(t, exceeded.Error(), handlerErr.Message)
+
+	handlerErr = deploymentError(errors.New("unable to deploy the stack"))
+	assert.Equal(t, http.StatusInternalServerError, handlerErr.StatusCode)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/portainer/portainer/api/filesystem"
 	httperrors "github.com/portainer/portainer/api/http/errors"
 	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/quota"
 	"github.com/portainer/portainer/api/internal/stackutils"
 )
 
@@ -130,6 +131,9 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 		}
 	}()
 
+	unlock := quota.LockEndpoint(endpoint)
+	defer unlock()
+
 	httpErr := handler.deployStack(r, stack, endpoint)
 	if httpErr != nil {
 		return httpErr
@@ -152,7 +156,7 @@ func (handler *Handler) deployStack(r *http.Request, stack *portainer.Stack, end
 
 		err := handler.deploySwarmStack(config)
 		if err != nil {
-			return &httperror.HandlerError{http.StatusInternalServerError, err.Error(), err}
+			return deploymentError(err)
 		}
 
 		stack.UpdateDate = time.Now().Unix()
@@ -169,7 +173,7 @@ func (handler *Handler) deployStack(r *http.Request, stack *portainer.Stack, end
 
 	err := handler.deployComposeStack(config)
 	if err != nil {
-		return &httperror.HandlerError{http.StatusInternalServerError, err.Error(), err}
+		return deploymentError(err)
 	}
 
 	stack.UpdateDate = time.Now().Unix()
@@ -0,0 +1,61 @@
+package tunnels
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	portainer "github.com/portainer/portainer/api"
+	bolterrors "github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/http/security"
+)
+
+// Handler is the HTTP handler used to handle the reverse tunnels of the Edge endpoints.
+type Handler struct {
+	*mux.Router
+	DataStore            portainer.DataStore
+	ReverseTunnelService portainer.ReverseTunnelService
+}
+
+// NewHandler creates a handler to manage the reverse tunnels.
+func NewHandler(bouncer *security.RequestBouncer) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+	h.Handle("/tunnels",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.tunnelList))).Methods(http.MethodGet)
+	h.Handle("/tunnels/{endpointId}/events",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.tunnelEvents))).Methods(http.MethodGet)
+	h.Handle("/tunnels/{endpointId}/open",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.tunnelOpen))).Methods(http.MethodPost)
+	h.Handle("/tunnels/{endpointId}/close",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.tunnelClose))).Methods(http.MethodPost)
+	return h
+}
+
+func isEdgeEndpoint(endpoint *portainer.Endpoint) bool {
+	return endpoint.Type == portainer.EdgeAgentOnDockerEnvironment || endpoint.Type == portainer.EdgeAgentOnKubernetesEnvironment
+}
+
+// edgeEndpointFromRequest retrieves the Edge endpoint matching the endpointId route variable
+func (handler *Handler) edgeEndpointFromRequest(r *http.Request) (*portainer.Endpoint, *httperror.HandlerError) {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "endpointId")
+	if err != nil {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "Invalid endpoint identifier route variable", err}
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if err == bolterrors.ErrObjectNotFound {
+		return nil, &httperror.HandlerError{http.StatusNotFound, "Unable to find an endpoint with the specified identifier inside the database", err}
+	} else if err != nil {
+		return nil, &httperror.HandlerError{http.StatusInternalServerError, "Unable to find an endpoint with the specified identifier inside the database", err}
+	}
+
+	if !isEdgeEndpoint(endpoint) {
+		return nil, &httperror.HandlerError{http.StatusBadRequest, "The endpoint is not an Edge endpoint", errors.New("invalid endpoint type")}
+	}
+
+	return endpoint, nil
+}
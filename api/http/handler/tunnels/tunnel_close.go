@@ -0,0 +1,31 @@
+package tunnels
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id TunnelClose
+// @summary Force a tunnel closed
+// @description Release the port of the tunnel of an Edge endpoint, the agent closes the tunnel on its next poll.
+// @description **Access policy**: administrator
+// @tags tunnels
+// @security jwt
+// @param endpointId path int true "Endpoint identifier"
+// @success 204
+// @failure 400
+// @failure 404
+// @failure 500
+// @router /tunnels/{endpointId}/close [post]
+func (handler *Handler) tunnelClose(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.edgeEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	handler.ReverseTunnelService.CloseTunnel(endpoint.ID, "closed by an administrator")
+
+	return response.Empty(w)
+}
@@ -0,0 +1,35 @@
+package tunnels
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id TunnelEvents
+// @summary List the status transitions of a tunnel
+// @description List the stored status transitions of the tunnel of an Edge endpoint, oldest first.
+// @description **Access policy**: administrator
+// @tags tunnels
+// @security jwt
+// @produce json
+// @param endpointId path int true "Endpoint identifier"
+// @success 200 {array} portainer.TunnelEvent
+// @failure 400
+// @failure 404
+// @failure 500
+// @router /tunnels/{endpointId}/events [get]
+func (handler *Handler) tunnelEvents(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.edgeEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	events, err := handler.DataStore.TunnelEvent().TunnelEvents(endpoint.ID)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the tunnel events from the database", err}
+	}
+
+	return response.JSON(w, events)
+}
@@ -0,0 +1,116 @@
+package tunnels
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+	portainer "github.com/portainer/portainer/api"
+)
+
+// transitionsLimit is the number of recent status transitions returned with each tunnel
+const transitionsLimit = 10
+
+type tunnelResponse struct {
+	EndpointID   portainer.EndpointID `json:"EndpointId" example:"1"`
+	EndpointName string               `json:"EndpointName" example:"edge-device"`
+	// Status of the tunnel: IDLE, REQUIRED or ACTIVE
+	Status string `json:"Status" example:"ACTIVE"`
+	// Port allocated to the tunnel, 0 when the tunnel is idle
+	Port int `json:"Port" example:"49152"`
+	// Unix timestamp at which the tunnel was opened, 0 when the tunnel is idle
+	OpenedAt int64 `json:"OpenedAt" example:"1587399600"`
+	// Number of seconds since the tunnel was opened
+	OpenDuration int64 `json:"OpenDuration" example:"120"`
+	// Unix timestamp of the last activity through the tunnel
+	LastActivity int64 `json:"LastActivity" example:"1587399600"`
+	// Bytes sent to the agent through the tunnel since it was opened
+	BytesSent int64 `json:"BytesSent" example:"1024"`
+	// Bytes received from the agent through the tunnel since it was opened
+	BytesReceived int64 `json:"BytesReceived" example:"4096"`
+	// Number of connections opened through the tunnel since it was opened
+	Connections int64 `json:"Connections" example:"3"`
+	// Number of connections currently open through the tunnel
+	OpenConnections int64 `json:"OpenConnections" example:"1"`
+	// Number of Edge jobs waiting to be collected by the agent
+	Jobs int `json:"Jobs" example:"0"`
+	// Most recent status transitions of the tunnel
+	Transitions []portainer.TunnelEvent `json:"Transitions"`
+}
+
+// @id TunnelList
+// @summary List the tunnels of the Edge endpoints
+// @description List the reverse tunnel of every Edge endpoint with its status, allocated port and traffic.
+// @description **Access policy**: administrator
+// @tags tunnels
+// @security jwt
+// @produce json
+// @success 200 {array} tunnelResponse
+// @failure 500
+// @router /tunnels [get]
+func (handler *Handler) tunnelList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoints from the database", err}
+	}
+
+	now := time.Now()
+	tunnels := make([]tunnelResponse, 0)
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if !isEdgeEndpoint(endpoint) {
+			continue
+		}
+
+		tunnel, err := handler.tunnelResponse(endpoint, now)
+		if err != nil {
+			return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the tunnel events from the database", err}
+		}
+
+		tunnels = append(tunnels, *tunnel)
+	}
+
+	return response.JSON(w, tunnels)
+}
+
+func (handler *Handler) tunnelResponse(endpoint *portainer.Endpoint, now time.Time) (*tunnelResponse, error) {
+	tunnel := handler.ReverseTunnelService.GetTunnelDetails(endpoint.ID)
+
+	events, err := handler.DataStore.TunnelEvent().TunnelEvents(endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) > transitionsLimit {
+		events = events[len(events)-transitionsLimit:]
+	}
+
+	resp := &tunnelResponse{
+		EndpointID:   endpoint.ID,
+		EndpointName: endpoint.Name,
+		Status:       tunnel.Status,
+		Port:         tunnel.Port,
+		Jobs:         len(tunnel.Jobs),
+		Transitions:  events,
+	}
+
+	if !tunnel.LastActivity.IsZero() {
+		resp.LastActivity = tunnel.LastActivity.Unix()
+	}
+
+	if !tunnel.OpenedAt.IsZero() {
+		resp.OpenedAt = tunnel.OpenedAt.Unix()
+		resp.OpenDuration = int64(now.Sub(tunnel.OpenedAt).Seconds())
+	}
+
+	if tunnel.Traffic != nil {
+		resp.BytesSent = atomic.LoadInt64(&tunnel.Traffic.BytesSent)
+		resp.BytesReceived = atomic.LoadInt64(&tunnel.Traffic.BytesReceived)
+		resp.Connections = atomic.LoadInt64(&tunnel.Traffic.Connections)
+		resp.OpenConnections = atomic.LoadInt64(&tunnel.Traffic.OpenConnections)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,41 @@
+package tunnels
+
+import (
+	"net/http"
+	"time"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id TunnelOpen
+// @summary Request a tunnel to be opened
+// @description Allocate a port to the tunnel of an Edge endpoint, the agent opens the tunnel on its next poll.
+// @description **Access policy**: administrator
+// @tags tunnels
+// @security jwt
+// @produce json
+// @param endpointId path int true "Endpoint identifier"
+// @success 200 {object} tunnelResponse
+// @failure 400
+// @failure 404
+// @failure 500
+// @router /tunnels/{endpointId}/open [post]
+func (handler *Handler) tunnelOpen(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpoint, handlerErr := handler.edgeEndpointFromRequest(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	err := handler.ReverseTunnelService.OpenTunnel(endpoint.ID, "opened by an administrator")
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to open the tunnel", err}
+	}
+
+	tunnel, err := handler.tunnelResponse(endpoint, time.Now())
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve the tunnel events from the database", err}
+	}
+
+	return response.JSON(w, tunnel)
+}
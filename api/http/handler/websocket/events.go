@@ -0,0 +1,135 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/dockerevents"
+)
+
+// @summary Stream the Docker events of the endpoints
+// @description The request will be upgraded to the websocket protocol and the Docker events of the endpoints
+// @description will be sent as JSON messages, aggregated across endpoints.
+// @description Only the events of the endpoints and resources the user can access are sent,
+// @description events of the Docker engine itself (daemon, node and plugin events) are only sent to administrators.
+// @description Edge endpoints only emit events while their tunnel is active.
+// @description Authentication and access is controlled via the mandatory token query parameter.
+// @security jwt
+// @tags websocket
+// @produce json
+// @param endpointIds query string false "JSON array of endpoint identifiers to receive the events of, all accessible endpoints when omitted" example:"[1,4]"
+// @param types query string false "JSON array of Docker object types to receive the events of, all types when omitted" example:"[\"container\",\"image\"]"
+// @param history query boolean false "Replay the recent events before streaming new ones"
+// @param token query string true "JWT token used for authentication against the endpoints"
+// @success 200 {object} dockerevents.Event "Event message"
+// @failure 400
+// @failure 500
+// @router /websocket/events [get]
+func (handler *Handler) websocketEvents(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var endpointIDs []portainer.EndpointID
+	err := request.RetrieveJSONQueryParameter(r, "endpointIds", &endpointIDs, true)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid query parameter: endpointIds", err}
+	}
+
+	var types []string
+	err = request.RetrieveJSONQueryParameter(r, "types", &types, true)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusBadRequest, "Invalid query parameter: types", err}
+	}
+
+	history, _ := request.RetrieveBooleanQueryParameter(r, "history", true)
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve user authentication token", err}
+	}
+
+	filter, err := handler.eventsFilter(r, endpointIDs, types)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve endpoints from the database", err}
+	}
+
+	access, err := dockerevents.NewAccessContext(handler.DataStore, tokenData.ID, tokenData.Role == portainer.AdministratorRole)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve user team memberships from the database", err}
+	}
+
+	websocketConn, err := handler.connectionUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return &httperror.HandlerError{http.StatusInternalServerError, "An error occured during websocket events operation", err}
+	}
+	defer websocketConn.Close()
+
+	subscription := handler.DockerEventsService.Subscribe(*filter, access, history)
+	defer handler.DockerEventsService.Unsubscribe(subscription)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the client does not send any message, reading only detects the closing of the connection
+	go func() {
+		for {
+			_, _, err := websocketConn.ReadMessage()
+			if err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	for {
+		event, ok := subscription.Next(ctx)
+		if !ok {
+			return nil
+		}
+
+		err = websocketConn.WriteJSON(event)
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// eventsFilter selects the requested Docker endpoints that the user can access,
+// every accessible Docker endpoint when no endpoint is requested
+func (handler *Handler) eventsFilter(r *http.Request, endpointIDs []portainer.EndpointID, types []string) (*dockerevents.Filter, error) {
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[portainer.EndpointID]bool)
+	for _, endpointID := range endpointIDs {
+		requested[endpointID] = true
+	}
+
+	filter := &dockerevents.Filter{
+		EndpointIDs: make([]portainer.EndpointID, 0),
+		Types:       types,
+	}
+
+	for idx := range endpoints {
+		endpoint := &endpoints[idx]
+
+		if endpoint.Type != portainer.DockerEnvironment && endpoint.Type != portainer.AgentOnDockerEnvironment && endpoint.Type != portainer.EdgeAgentOnDockerEnvironment {
+			continue
+		}
+
+		if len(requested) > 0 && !requested[endpoint.ID] {
+			continue
+		}
+
+		if handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint) != nil {
+			continue
+		}
+
+		filter.EndpointIDs = append(filter.EndpointIDs, endpoint.ID)
+	}
+
+	return filter, nil
+}
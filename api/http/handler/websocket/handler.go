@@ -6,6 +6,7 @@ import (
 	httperror "github.com/portainer/libhttp/error"
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/dockerevents"
 	"github.com/portainer/portainer/api/kubernetes/cli"
 )
 
@@ -16,6 +17,7 @@ type Handler struct {
 	SignatureService        portainer.DigitalSignatureService
 	ReverseTunnelService    portainer.ReverseTunnelService
 	KubernetesClientFactory *cli.ClientFactory
+	DockerEventsService     *dockerevents.Service
 	requestBouncer          *security.RequestBouncer
 	connectionUpgrader      websocket.Upgrader
 }
@@ -33,5 +35,7 @@ func NewHandler(bouncer *security.RequestBouncer) *Handler {
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.websocketAttach)))
 	h.PathPrefix("/websocket/pod").Handler(
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.websocketPodExec)))
+	h.PathPrefix("/websocket/events").Handler(
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.websocketEvents)))
 	return h
 }
@@ -45,6 +45,10 @@ func (factory *ProxyFactory) newDockerHTTPProxy(endpoint *portainer.Endpoint) (h
 	endpointURL.Scheme = "http"
 	httpTransport := &http.Transport{}
 
+	if endpoint.Type == portainer.EdgeAgentOnDockerEnvironment {
+		httpTransport.DialContext = factory.reverseTunnelService.TunnelDialer(endpoint.ID)
+	}
+
 	if endpoint.TLSConfig.TLS || endpoint.TLSConfig.TLSSkipVerify {
 		config, err := crypto.CreateTLSConfigurationFromDisk(endpoint.TLSConfig.TLSCACertPath, endpoint.TLSConfig.TLSCertPath, endpoint.TLSConfig.TLSKeyPath, endpoint.TLSConfig.TLSSkipVerify)
 		if err != nil {
@@ -61,6 +65,7 @@ func (factory *ProxyFactory) newDockerHTTPProxy(endpoint *portainer.Endpoint) (h
 		ReverseTunnelService: factory.reverseTunnelService,
 		SignatureService:     factory.signatureService,
 		DockerClientFactory:  factory.dockerClientFactory,
+		ResponseCache:        factory.dockerResponseCache,
 	}
 
 	dockerTransport, err := docker.NewTransport(transportParameters, httpTransport)
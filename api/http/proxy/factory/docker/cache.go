@@ -0,0 +1,312 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	portainer "github.com/portainer/portainer/api"
+)
+
+// maxCachedResponseSize is the maximum size of a response body kept in the cache
+const maxCachedResponseSize = 16 << 20
+
+// cacheableListPaths are the list operations whose upstream responses are cached.
+// Responses are cached before the access control filtering and decoration, which are applied on every request.
+var cacheableListPaths = map[string]bool{
+	"/containers/json": true,
+	"/images/json":     true,
+	"/volumes":         true,
+	"/networks":        true,
+}
+
+type (
+	// ResponseCache is a short-lived cache of the responses of the Docker list operations, shared by the
+	// transports of every endpoint. The cache of an endpoint is invalidated by the mutating requests proxied
+	// to the endpoint and, when enabled, by the events of the Docker engine.
+	ResponseCache struct {
+		mu        sync.Mutex
+		endpoints map[portainer.EndpointID]*endpointResponseCache
+	}
+
+	// ResponseCacheStats represents the statistics of the cache of an endpoint
+	ResponseCacheStats struct {
+		// Number of requests served from the cache
+		Hits int64 `json:"Hits" example:"120"`
+		// Number of requests forwarded to the Docker engine
+		Misses int64 `json:"Misses" example:"12"`
+		// Number of times the cache was invalidated
+		Invalidations int64 `json:"Invalidations" example:"4"`
+		// Number of responses currently cached
+		Entries int `json:"Entries" example:"3"`
+		// Whether the cache is invalidated by the events of the Docker engine
+		WatchingEvents bool `json:"WatchingEvents" example:"true"`
+	}
+
+	endpointResponseCache struct {
+		// generation is incremented on every invalidation, a response fetched before an invalidation is not stored
+		generation  int
+		entries     map[string]*cachedResponse
+		stats       ResponseCacheStats
+		cancelWatch context.CancelFunc
+	}
+
+	cachedResponse struct {
+		statusCode int
+		header     http.Header
+		body       []byte
+		expiresAt  time.Time
+	}
+)
+
+// NewResponseCache returns a pointer to a new instance of ResponseCache
+func NewResponseCache() *ResponseCache {
+	return &ResponseCache{
+		endpoints: make(map[portainer.EndpointID]*endpointResponseCache),
+	}
+}
+
+// endpointCache returns the cache of an endpoint, mu must be held
+func (cache *ResponseCache) endpointCache(endpointID portainer.EndpointID) *endpointResponseCache {
+	endpointCache, ok := cache.endpoints[endpointID]
+	if !ok {
+		endpointCache = &endpointResponseCache{
+			entries: make(map[string]*cachedResponse),
+		}
+		cache.endpoints[endpointID] = endpointCache
+	}
+	return endpointCache
+}
+
+// get returns the cached response matching the key along with the current generation of the cache of the endpoint
+func (cache *ResponseCache) get(endpointID portainer.EndpointID, key string, now time.Time) (*cachedResponse, int) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	endpointCache := cache.endpointCache(endpointID)
+
+	entry, ok := endpointCache.entries[key]
+	if ok && now.Before(entry.expiresAt) {
+		endpointCache.stats.Hits++
+		return entry, endpointCache.generation
+	}
+
+	if ok {
+		delete(endpointCache.entries, key)
+	}
+	endpointCache.stats.Misses++
+
+	return nil, endpointCache.generation
+}
+
+// set stores a response unless the cache of the endpoint was invalidated since the generation
+func (cache *ResponseCache) set(endpointID portainer.EndpointID, key string, generation int, entry *cachedResponse) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	endpointCache := cache.endpointCache(endpointID)
+	if endpointCache.generation != generation {
+		return
+	}
+
+	endpointCache.entries[key] = entry
+}
+
+// Invalidate removes the cached responses of an endpoint
+func (cache *ResponseCache) Invalidate(endpointID portainer.EndpointID) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	endpointCache, ok := cache.endpoints[endpointID]
+	if !ok {
+		return
+	}
+
+	endpointCache.generation++
+	endpointCache.stats.Invalidations++
+	endpointCache.entries = make(map[string]*cachedResponse)
+}
+
+// Remove removes the cache of an endpoint and stops watching its events
+func (cache *ResponseCache) Remove(endpointID portainer.EndpointID) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	endpointCache, ok := cache.endpoints[endpointID]
+	if !ok {
+		return
+	}
+
+	if endpointCache.cancelWatch != nil {
+		endpointCache.cancelWatch()
+	}
+
+	delete(cache.endpoints, endpointID)
+}
+
+// Stats returns the statistics of the cache of an endpoint
+func (cache *ResponseCache) Stats(endpointID portainer.EndpointID) ResponseCacheStats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	endpointCache, ok := cache.endpoints[endpointID]
+	if !ok {
+		return ResponseCacheStats{}
+	}
+
+	stats := endpointCache.stats
+	stats.Entries = len(endpointCache.entries)
+	stats.WatchingEvents = endpointCache.cancelWatch != nil
+
+	return stats
+}
+
+// watchEvents invalidates the cache of the endpoint on every event of the Docker engine, until the
+// subscription fails or the cache of the endpoint is removed. It does nothing when the events are already watched.
+func (cache *ResponseCache) watchEvents(endpointID portainer.EndpointID, dockerClient *client.Client) {
+	cache.mu.Lock()
+	endpointCache := cache.endpointCache(endpointID)
+	if endpointCache.cancelWatch != nil {
+		cache.mu.Unlock()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	endpointCache.cancelWatch = cancel
+	cache.mu.Unlock()
+
+	go func() {
+		defer cache.stopWatchingEvents(endpointID, cancel)
+
+		messages, errs := dockerClient.Events(ctx, types.EventsOptions{})
+		for {
+			select {
+			case <-messages:
+				cache.Invalidate(endpointID)
+			case err := <-errs:
+				if err != nil && ctx.Err() == nil {
+					log.Printf("[WARN] [http,proxy,docker] [endpoint_id: %d] [message: stopped watching the Docker events to invalidate the response cache] [error: %s]", endpointID, err)
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// stopWatchingEvents releases the subscription so that the next cached response subscribes again
+func (cache *ResponseCache) stopWatchingEvents(endpointID portainer.EndpointID, cancel context.CancelFunc) {
+	cancel()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	endpointCache, ok := cache.endpoints[endpointID]
+	if ok && endpointCache.cancelWatch != nil {
+		endpointCache.cancelWatch = nil
+		// events may have been missed, the cached responses can no longer be trusted
+		endpointCache.generation++
+		endpointCache.entries = make(map[string]*cachedResponse)
+	}
+}
+
+func (entry *cachedResponse) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(entry.statusCode),
+		StatusCode:    entry.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+		Request:       request,
+	}
+}
+
+func isCacheableRequest(request *http.Request) bool {
+	return request.Method == http.MethodGet && cacheableListPaths[request.URL.Path]
+}
+
+func isMutatingRequest(request *http.Request) bool {
+	return request.Method != http.MethodGet && request.Method != http.MethodHead && request.Method != http.MethodOptions
+}
+
+// responseCacheKey identifies a list operation, the agent target header selects the node queried by the agent
+func responseCacheKey(request *http.Request) string {
+	return request.Header.Get(portainer.PortainerAgentTargetHeader) + "|" + request.URL.RequestURI()
+}
+
+// responseCacheTTL returns the duration for which the responses are cached, 0 when the cache is disabled
+func responseCacheTTL(settings *portainer.Settings) time.Duration {
+	value := settings.DockerProxyCacheTTL
+	if value == "" {
+		value = portainer.DefaultDockerProxyCacheTTL
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0
+	}
+
+	return ttl
+}
+
+// executeCachedDockerRequest serves a list operation from the cache of the endpoint, or forwards it to
+// the Docker engine and caches the successful response
+func (transport *Transport) executeCachedDockerRequest(request *http.Request) (*http.Response, error) {
+	settings, err := transport.dataStore.Settings().Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := responseCacheTTL(settings)
+	if ttl == 0 {
+		return transport.forwardDockerRequest(request)
+	}
+
+	key := responseCacheKey(request)
+	now := time.Now()
+
+	entry, generation := transport.responseCache.get(transport.endpoint.ID, key, now)
+	if entry != nil {
+		return entry.response(request), nil
+	}
+
+	response, err := transport.forwardDockerRequest(request)
+	if err != nil || response.StatusCode != http.StatusOK || response.Header.Get("Content-Encoding") != "" {
+		return response, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if len(body) > maxCachedResponseSize {
+		return response, nil
+	}
+
+	transport.responseCache.set(transport.endpoint.ID, key, generation, &cachedResponse{
+		statusCode: response.StatusCode,
+		header:     response.Header.Clone(),
+		body:       body,
+		expiresAt:  now.Add(ttl),
+	})
+
+	// the events of the edge agents are not watched, the subscription would keep the tunnel open
+	if settings.DockerProxyCacheEventsInvalidation && transport.endpoint.Type != portainer.EdgeAgentOnDockerEnvironment {
+		transport.responseCache.watchEvents(transport.endpoint.ID, transport.dockerClient)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ResponseCache(t *testing.T) {
+	now := time.Now()
+	entry := &cachedResponse{statusCode: http.StatusOK, header: http.Header{}, body: []byte("[]"), expiresAt: now.Add(time.Minute)}
+
+	t.Run("a stored response is served until it expires", func(t *testing.T) {
+		cache := NewResponseCache()
+
+		cached, generation := cache.get(1, "key", now)
+		assert.Nil(t, cached)
+
+		cache.set(1, "key", generation, entry)
+
+		cached, _ = cache.get(1, "key", now)
+		assert.Equal(t, entry, cached)
+
+		cached, _ = cache.get(1, "key", now.Add(2*time.Minute))
+		assert.Nil(t, cached)
+
+		assert.Equal(t, ResponseCacheStats{Hits: 1, Misses: 2}, cache.Stats(1))
+	})
+
+	t.Run("the caches of the endpoints are independent", func(t *testing.T) {
+		cache := NewResponseCache()
+
+		_, generation := cache.get(1, "key", now)
+		cache.set(1, "key", generation, entry)
+
+		cached, _ := cache.get(2, "key", now)
+		assert.Nil(t, cached)
+	})
+
+	t.Run("an invalidation removes the stored responses", func(t *testing.T) {
+		cache := NewResponseCache()
+
+		_, generation := cache.get(1, "key", now)
+		cache.set(1, "key", generation, entry)
+		cache.Invalidate(1)
+
+		cached, _ := cache.get(1, "key", now)
+		assert.Nil(t, cached)
+		assert.Equal(t, ResponseCacheStats{Misses: 2, Invalidations: 1}, cache.Stats(1))
+	})
+
+	t.Run("a response fetched before an invalidation is not stored", func(t *testing.T) {
+		cache := NewResponseCache()
+
+		_, generation := cache.get(1, "key", now)
+		cache.Invalidate(1)
+		cache.set(1, "key", generation, entry)
+
+		assert.Equal(t, 0, cache.Stats(1).Entries)
+	})
+
+	t.Run("removing an endpoint resets its statistics", func(t *testing.T) {
+		cache := NewResponseCache()
+
+		_, generation := cache.get(1, "key", now)
+		cache.set(1, "key", generation, entry)
+		cache.Remove(1)
+
+		assert.Equal(t, ResponseCacheStats{}, cache.Stats(1))
+	})
+}
+
+func Test_isCacheableRequest(t *testing.T) {
+	cases := []struct {
+		method   string
+		path     string
+		expected bool
+	}{
+		{http.MethodGet, "/containers/json", true},
+		{http.MethodGet, "/images/json", true},
+		{http.MethodGet, "/volumes", true},
+		{http.MethodGet, "/networks", true},
+		{http.MethodGet, "/containers/abc/json", false},
+		{http.MethodPost, "/containers/json", false},
+		{http.MethodGet, "/services", false},
+	}
+
+	for _, tc := range cases {
+		request, _ := http.NewRequest(tc.method, "http://docker"+tc.path, nil)
+		assert.Equal(t, tc.expected, isCacheableRequest(request), "%s %s", tc.method, tc.path)
+	}
+}
+
+func Test_responseCacheTTL(t *testing.T) {
+	assert.Equal(t, 5*time.Second, responseCacheTTL(&portainer.Settings{}))
+	assert.Equal(t, 30*time.Second, responseCacheTTL(&portainer.Settings{DockerProxyCacheTTL: "30s"}))
+	assert.Equal(t, time.Duration(0), responseCacheTTL(&portainer.Settings{DockerProxyCacheTTL: "0"}))
+	assert.Equal(t, time.Duration(0), responseCacheTTL(&portainer.Settings{DockerProxyCacheTTL: "invalid"}))
+}
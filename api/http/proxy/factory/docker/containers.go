@@ -176,17 +176,17 @@ func (transport *Transport) decorateContainerCreationOperation(request *http.Req
 		return nil, err
 	}
 
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	if !isAdminOrEndpointAdmin {
 		securitySettings, err := transport.fetchEndpointSecuritySettings()
 		if err != nil {
 			return nil, err
 		}
 
-		body, err := ioutil.ReadAll(request.Body)
-		if err != nil {
-			return nil, err
-		}
-
 		partialContainer := &PartialContainer{}
 		err = json.Unmarshal(body, partialContainer)
 		if err != nil {
@@ -216,10 +216,26 @@ func (transport *Transport) decorateContainerCreationOperation(request *http.Req
 		if !securitySettings.AllowBindMountsForRegularUsers && (len(partialContainer.HostConfig.Binds) > 0) {
 			return forbiddenResponse, errors.New("forbidden to use bind mounts")
 		}
+	}
+
+	deniedResponse, err := transport.enforceContainerPolicies(request, body)
+	if err != nil || deniedResponse != nil {
+		return deniedResponse, err
+	}
+
+	unlock, err := transport.lockQuotas()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-		request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	deniedResponse, err = transport.checkContainerQuotas(request, body)
+	if err != nil || deniedResponse != nil {
+		return deniedResponse, err
 	}
 
+	request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+
 	response, err := transport.executeDockerRequest(request)
 	if err != nil {
 		return response, err
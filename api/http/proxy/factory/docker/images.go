@@ -0,0 +1,366 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/client"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/http/proxy/factory/utils"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/authorization"
+)
+
+const (
+	imageObjectIdentifier = "Id"
+)
+
+// imageListOperation extracts the response as a JSON array, loop through the images array
+// decorate and/or filter the images based on resource controls before rewriting the response.
+func (transport *Transport) imageListOperation(response *http.Response, executor *operationExecutor) error {
+	// ImageList response is a JSON array
+	// https://docs.docker.com/engine/api/v1.28/#operation/ImageList
+	responseArray, err := utils.GetResponseAsJSONArray(response)
+	if err != nil {
+		return err
+	}
+
+	resourceOperationParameters := &resourceOperationParameters{
+		resourceIdentifierAttribute: imageObjectIdentifier,
+		resourceType:                portainer.ImageResourceControl,
+		labelsObjectSelector:        selectorImageLabelsFromImageListOperation,
+	}
+
+	if executor.operationContext.isAdmin {
+		responseArray, err = transport.decorateResourceList(resourceOperationParameters, responseArray, executor.operationContext.resourceControls)
+	} else {
+		responseArray, err = transport.filterImageList(resourceOperationParameters, responseArray, executor.operationContext)
+	}
+	if err != nil {
+		return err
+	}
+
+	return utils.RewriteResponse(response, responseArray, http.StatusOK)
+}
+
+// filterImageList removes the images the user cannot access from the image list.
+// Unlike the other resources, images without a resource control are public: they were pulled before
+// image ownership existed or outside of Portainer (stack deployments, base images of builds, host pulls).
+func (transport *Transport) filterImageList(parameters *resourceOperationParameters, resourceData []interface{}, context *restrictedDockerOperationContext) ([]interface{}, error) {
+	filteredResourceData := make([]interface{}, 0)
+
+	for _, resource := range resourceData {
+		resourceObject := resource.(map[string]interface{})
+		if resourceObject[parameters.resourceIdentifierAttribute] == nil {
+			log.Printf("[WARN] [http,proxy,docker,filter] [message: unable to find resource identifier property in resource list element] [identifier_attribute: %s]", parameters.resourceIdentifierAttribute)
+			continue
+		}
+
+		resourceIdentifier := resourceObject[parameters.resourceIdentifierAttribute].(string)
+		resourceLabelsObject := parameters.labelsObjectSelector(resourceObject)
+
+		resourceControl, err := transport.findResourceControl(resourceIdentifier, parameters.resourceType, resourceLabelsObject, context.resourceControls)
+		if err != nil {
+			return nil, err
+		}
+
+		if resourceControl == nil {
+			filteredResourceData = append(filteredResourceData, resourceObject)
+			continue
+		}
+
+		if authorization.UserCanAccessResource(context.userID, context.userTeamIDs, resourceControl) {
+			filteredResourceData = append(filteredResourceData, decorateObject(resourceObject, resourceControl))
+		}
+	}
+
+	return filteredResourceData, nil
+}
+
+// imageInspectOperation extracts the response as a JSON object, verify that the user
+// has access to the image based on resource control and either rewrite an access denied response or a decorated image.
+func (transport *Transport) imageInspectOperation(response *http.Response, executor *operationExecutor) error {
+	// ImageInspect response is a JSON object
+	// https://docs.docker.com/engine/api/v1.28/#operation/ImageInspect
+	responseObject, err := utils.GetResponseAsJSONObject(response)
+	if err != nil {
+		return err
+	}
+
+	if responseObject[imageObjectIdentifier] == nil {
+		log.Printf("[WARN] [message: unable to find resource identifier property in resource object] [identifier_attribute: %s]", imageObjectIdentifier)
+		return nil
+	}
+
+	resourceControl, err := transport.findResourceControl(responseObject[imageObjectIdentifier].(string), portainer.ImageResourceControl, selectorImageLabelsFromImageInspectOperation(responseObject), executor.operationContext.resourceControls)
+	if err != nil {
+		return err
+	}
+
+	// images without a resource control are public, see filterImageList
+	if resourceControl == nil {
+		return utils.RewriteResponse(response, responseObject, http.StatusOK)
+	}
+
+	if executor.operationContext.isAdmin || authorization.UserCanAccessResource(executor.operationContext.userID, executor.operationContext.userTeamIDs, resourceControl) {
+		return utils.RewriteResponse(response, decorateObject(responseObject, resourceControl), http.StatusOK)
+	}
+
+	return utils.RewriteAccessDeniedResponse(response)
+}
+
+// selectorImageLabelsFromImageInspectOperation retrieve the labels object associated to the image object.
+// This selector is specific to the imageInspect Docker operation.
+// Labels are available under the "Config.Labels" property.
+// API schema reference: https://docs.docker.com/engine/api/v1.28/#operation/ImageInspect
+func selectorImageLabelsFromImageInspectOperation(responseObject map[string]interface{}) map[string]interface{} {
+	imageConfigObject := utils.GetJSONObject(responseObject, "Config")
+	if imageConfigObject != nil {
+		return utils.GetJSONObject(imageConfigObject, "Labels")
+	}
+	return nil
+}
+
+// selectorImageLabelsFromImageListOperation retrieve the labels object associated to the image object.
+// This selector is specific to the imageList Docker operation.
+// Labels are available under the "Labels" property.
+// API schema reference: https://docs.docker.com/engine/api/v1.28/#operation/ImageList
+func selectorImageLabelsFromImageListOperation(responseObject map[string]interface{}) map[string]interface{} {
+	return utils.GetJSONObject(responseObject, "Labels")
+}
+
+// decorateImageOwnershipOperation executes a request creating an image (pull, import or build) and
+// makes the requesting user the owner of the resulting image once the Docker response stream has been
+// entirely consumed. The image is not available before the end of the stream, which is why the resource
+// control cannot be created when the response headers are received.
+// An image that was already on the host before the request, such as a shared base image pulled again,
+// stays public: only the images created by the request are owned by the user.
+func (transport *Transport) decorateImageOwnershipOperation(request *http.Request, imageReference string) (*http.Response, error) {
+	tokenData, err := security.RetrieveTokenData(request)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeName := request.Header.Get(portainer.PortainerAgentTargetHeader)
+
+	previousImageID := ""
+	if imageReference != "" {
+		previousImageID, err = transport.getImageID(nodeName, imageReference)
+		if err != nil && !client.IsErrNotFound(err) {
+			return nil, err
+		}
+	}
+
+	response, err := transport.executeDockerRequest(request)
+	if err != nil || response.StatusCode != http.StatusOK || imageReference == "" {
+		return response, err
+	}
+
+	response.Body = &completionHookReadCloser{
+		ReadCloser: response.Body,
+		onComplete: func() {
+			transport.createImageResourceControl(nodeName, imageReference, previousImageID, tokenData.ID)
+		},
+	}
+
+	return response, nil
+}
+
+func (transport *Transport) createImageResourceControl(nodeName, imageReference, previousImageID string, userID portainer.UserID) {
+	imageID, err := transport.getImageID(nodeName, imageReference)
+	if err != nil {
+		log.Printf("[WARN] [http,proxy,docker,image] [endpoint_id: %d] [message: unable to retrieve image after creation, no ownership applied] [image: %s] [err: %s]", transport.endpoint.ID, imageReference, err)
+		return
+	}
+
+	// the reference already pointed to this image, which was not created by the request
+	if imageID == previousImageID {
+		return
+	}
+
+	resourceControl, err := transport.dataStore.ResourceControl().ResourceControlByResourceIDAndType(imageID, portainer.ImageResourceControl)
+	if err != nil {
+		log.Printf("[ERROR] [http,proxy,docker,image] [endpoint_id: %d] [message: unable to retrieve image resource control] [image: %s] [err: %s]", transport.endpoint.ID, imageReference, err)
+		return
+	}
+
+	if resourceControl != nil {
+		return
+	}
+
+	transport.createPrivateResourceControl(imageID, portainer.ImageResourceControl, userID)
+}
+
+// restrictedImageOperation resolves the image references to the image identifiers and ensures that
+// the user can access these images before executing the original request.
+// References that cannot be resolved are forwarded to Docker which will report the missing image.
+func (transport *Transport) restrictedImageOperation(request *http.Request, imageReferences ...string) (*http.Response, error) {
+	nodeName := request.Header.Get(portainer.PortainerAgentTargetHeader)
+
+	for _, imageReference := range imageReferences {
+		imageID, err := transport.getImageID(nodeName, imageReference)
+		if err != nil {
+			if client.IsErrNotFound(err) {
+				return transport.executeDockerRequest(request)
+			}
+			return nil, err
+		}
+
+		canAccess, err := transport.userCanAccessImage(request, imageID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !canAccess {
+			return utils.WriteAccessDeniedResponse()
+		}
+	}
+
+	return transport.executeDockerRequest(request)
+}
+
+// userCanAccessImage returns true when the user is an administrator, when the image has no resource control
+// (see filterImageList) or when the resource control of the image grants access to the user
+func (transport *Transport) userCanAccessImage(request *http.Request, imageID string) (bool, error) {
+	operationContext, err := transport.createOperationContext(request)
+	if err != nil {
+		return false, err
+	}
+
+	if operationContext.isAdmin {
+		return true, nil
+	}
+
+	resourceControl := authorization.GetResourceControlByResourceIDAndType(imageID, portainer.ImageResourceControl, operationContext.resourceControls)
+	if resourceControl == nil {
+		return true, nil
+	}
+
+	return authorization.UserCanAccessResource(operationContext.userID, operationContext.userTeamIDs, resourceControl), nil
+}
+
+// imageDeletionOperation ensures that the user can access the image before removing it.
+// The resource control associated to the image is only removed once the image itself is gone:
+// removing one of the tags of an image referenced by multiple tags leaves the image in place.
+func (transport *Transport) imageDeletionOperation(request *http.Request, imageReference string) (*http.Response, error) {
+	nodeName := request.Header.Get(portainer.PortainerAgentTargetHeader)
+
+	imageID, err := transport.getImageID(nodeName, imageReference)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return transport.executeDockerRequest(request)
+		}
+		return nil, err
+	}
+
+	canAccess, err := transport.userCanAccessImage(request, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canAccess {
+		return utils.WriteAccessDeniedResponse()
+	}
+
+	response, err := transport.executeDockerRequest(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+
+	_, err = transport.getImageID(nodeName, imageID)
+	if err == nil {
+		return response, nil
+	}
+
+	if !client.IsErrNotFound(err) {
+		log.Printf("[WARN] [http,proxy,docker,image] [endpoint_id: %d] [message: unable to verify image removal] [image: %s] [err: %s]", transport.endpoint.ID, imageReference, err)
+		return response, nil
+	}
+
+	resourceControl, err := transport.dataStore.ResourceControl().ResourceControlByResourceIDAndType(imageID, portainer.ImageResourceControl)
+	if err != nil {
+		return response, err
+	}
+
+	if resourceControl != nil {
+		err = transport.dataStore.ResourceControl().DeleteResourceControl(resourceControl.ID)
+	}
+
+	return response, err
+}
+
+// getImageID resolves an image reference (name, tag, digest or identifier) to the identifier of the image.
+// When the request targets a specific agent node, the image is looked up on that node.
+func (transport *Transport) getImageID(nodeName, imageReference string) (string, error) {
+	dockerClient := transport.dockerClient
+
+	if nodeName != "" {
+		nodeClient, err := transport.dockerClientFactory.CreateClient(transport.endpoint, nodeName)
+		if err != nil {
+			return "", err
+		}
+		defer nodeClient.Close()
+
+		dockerClient = nodeClient
+	}
+
+	image, _, err := dockerClient.ImageInspectWithRaw(context.Background(), imageReference)
+	if err != nil {
+		return "", err
+	}
+
+	return image.ID, nil
+}
+
+// imageReferenceFromCreateRequest builds the reference of the image created by an image create request,
+// either pulled via the fromImage parameter or imported under the repo parameter.
+// API schema reference: https://docs.docker.com/engine/api/v1.28/#operation/ImageCreate
+func imageReferenceFromCreateRequest(query url.Values) string {
+	image := query.Get("fromImage")
+	if image == "" {
+		image = query.Get("repo")
+	}
+
+	tag := query.Get("tag")
+	if image == "" || tag == "" {
+		return image
+	}
+
+	if strings.HasPrefix(tag, "sha256:") {
+		return image + "@" + tag
+	}
+
+	return image + ":" + tag
+}
+
+// imageReferenceFromPushRequest builds the reference of the image pushed by an image push request.
+// API schema reference: https://docs.docker.com/engine/api/v1.28/#operation/ImagePush
+func imageReferenceFromPushRequest(imageName string, query url.Values) string {
+	tag := query.Get("tag")
+	if tag == "" {
+		return imageName
+	}
+
+	return imageName + ":" + tag
+}
+
+// completionHookReadCloser wraps a response body and invokes onComplete once
+// the body has been read until the end. A body closed before its end does not trigger the hook.
+type completionHookReadCloser struct {
+	io.ReadCloser
+	onComplete func()
+	once       sync.Once
+}
+
+func (reader *completionHookReadCloser) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	if err == io.EOF {
+		reader.once.Do(reader.onComplete)
+	}
+	return n, err
+}
@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_imageReferenceFromCreateRequest(t *testing.T) {
+	cases := []struct {
+		description string
+		query       url.Values
+		expected    string
+	}{
+		{
+			description: "no image",
+			query:       url.Values{},
+			expected:    "",
+		},
+		{
+			description: "pull without tag",
+			query:       url.Values{"fromImage": {"nginx"}},
+			expected:    "nginx",
+		},
+		{
+			description: "pull with tag",
+			query:       url.Values{"fromImage": {"registry.local:5000/nginx"}, "tag": {"1.19"}},
+			expected:    "registry.local:5000/nginx:1.19",
+		},
+		{
+			description: "pull with digest",
+			query:       url.Values{"fromImage": {"nginx"}, "tag": {"sha256:abc"}},
+			expected:    "nginx@sha256:abc",
+		},
+		{
+			description: "import with repository",
+			query:       url.Values{"fromSrc": {"-"}, "repo": {"myimage"}, "tag": {"latest"}},
+			expected:    "myimage:latest",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expected, imageReferenceFromCreateRequest(tt.query))
+		})
+	}
+}
+
+func Test_imageReferenceFromPushRequest(t *testing.T) {
+	assert.Equal(t, "myorg/app", imageReferenceFromPushRequest("myorg/app", url.Values{}))
+	assert.Equal(t, "myorg/app:v2", imageReferenceFromPushRequest("myorg/app", url.Values{"tag": {"v2"}}))
+}
+
+func Test_completionHookReadCloser(t *testing.T) {
+	t.Run("hook is invoked once the body is entirely read", func(t *testing.T) {
+		calls := 0
+		reader := &completionHookReadCloser{
+			ReadCloser: ioutil.NopCloser(strings.NewReader(`{"status":"Pull complete"}`)),
+			onComplete: func() { calls++ },
+		}
+
+		_, err := ioutil.ReadAll(reader)
+		assert.NoError(t, err)
+		_, err = reader.Read(make([]byte, 1))
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("hook is not invoked when the body is closed early", func(t *testing.T) {
+		calls := 0
+		reader := &completionHookReadCloser{
+			ReadCloser: ioutil.NopCloser(strings.NewReader(`{"status":"Downloading"}`)),
+			onComplete: func() { calls++ },
+		}
+
+		_, err := reader.Read(make([]byte, 4))
+		assert.NoError(t, err)
+		assert.NoError(t, reader.Close())
+		assert.Equal(t, 0, calls)
+	})
+}
+
+func Test_filterImageList(t *testing.T) {
+	transport := &Transport{endpoint: &portainer.Endpoint{ID: 1}}
+
+	parameters := &resourceOperationParameters{
+		resourceIdentifierAttribute: imageObjectIdentifier,
+		resourceType:                portainer.ImageResourceControl,
+		labelsObjectSelector:        selectorImageLabelsFromImageListOperation,
+	}
+
+	context := &restrictedDockerOperationContext{
+		userID: 1,
+		resourceControls: []portainer.ResourceControl{
+			{ResourceID: "sha256:mine", Type: portainer.ImageResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 1}}},
+			{ResourceID: "sha256:other", Type: portainer.ImageResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 2}}},
+		},
+	}
+
+	images := []interface{}{
+		map[string]interface{}{"Id": "sha256:mine"},
+		map[string]interface{}{"Id": "sha256:other"},
+		map[string]interface{}{"Id": "sha256:unowned"},
+	}
+
+	filtered, err := transport.filterImageList(parameters, images, context)
+	assert.NoError(t, err)
+
+	ids := make([]string, 0)
+	for _, image := range filtered {
+		ids = append(ids, image.(map[string]interface{})["Id"].(string))
+	}
+	assert.Equal(t, []string{"sha256:mine", "sha256:unowned"}, ids, "images without resource control should be visible to every user")
+}
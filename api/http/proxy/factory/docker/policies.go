@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/portainer/portainer/api/http/proxy/factory/utils"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/policy"
+)
+
+// enforceContainerPolicies evaluates the body of a container creation request against the Docker policies.
+// It returns a forbidden response when the request is denied.
+func (transport *Transport) enforceContainerPolicies(request *http.Request, body []byte) (*http.Response, error) {
+	var containerCreation struct {
+		container.Config
+		HostConfig *container.HostConfig
+	}
+
+	err := json.Unmarshal(body, &containerCreation)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.enforceDockerPolicies(request, policy.ContainerWorkload(&containerCreation.Config, containerCreation.HostConfig))
+}
+
+// enforceServicePolicies evaluates the body of a service creation or update request against the Docker policies.
+// It returns a forbidden response when the request is denied.
+func (transport *Transport) enforceServicePolicies(request *http.Request, body []byte) (*http.Response, error) {
+	var spec swarm.ServiceSpec
+
+	err := json.Unmarshal(body, &spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.enforceDockerPolicies(request, policy.ServiceWorkload(&spec))
+}
+
+func (transport *Transport) enforceDockerPolicies(request *http.Request, workload policy.Workload) (*http.Response, error) {
+	tokenData, err := security.RetrieveTokenData(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// the group of the endpoint may have changed since the creation of the proxy
+	endpoint, err := transport.dataStore.Endpoint().Endpoint(transport.endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.Enforce(transport.dataStore, endpoint, tokenData.ID, workload)
+	if denial, ok := err.(*policy.DenialError); ok {
+		return utils.WriteForbiddenResponse(denial.Error())
+	}
+
+	return nil, err
+}
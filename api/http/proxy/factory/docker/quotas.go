@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/portainer/portainer/api/http/proxy/factory/utils"
+	"github.com/portainer/portainer/api/http/security"
+	"github.com/portainer/portainer/api/internal/quota"
+)
+
+// checkContainerQuotas verifies that the container of a creation request fits in the quotas of the teams of the user.
+// It returns a forbidden response when a quota would be exceeded.
+func (transport *Transport) checkContainerQuotas(request *http.Request, body []byte) (*http.Response, error) {
+	var containerCreation struct {
+		HostConfig *container.HostConfig
+	}
+
+	err := json.Unmarshal(body, &containerCreation)
+	if err != nil {
+		return nil, err
+	}
+
+	demand := &quota.Demand{RunsTasks: true}
+	demand.Containers = 1
+	if containerCreation.HostConfig != nil {
+		demand.Memory, demand.CPU = quota.ContainerReservation(containerCreation.HostConfig)
+	}
+
+	return transport.checkTeamQuotas(request, demand)
+}
+
+// checkServiceQuotas verifies that the service of a creation or update request fits in the quotas of the teams of the user.
+// It returns a forbidden response when a quota would be exceeded.
+func (transport *Transport) checkServiceQuotas(request *http.Request, body []byte, serviceID string) (*http.Response, error) {
+	var spec swarm.ServiceSpec
+
+	err := json.Unmarshal(body, &spec)
+	if err != nil {
+		return nil, err
+	}
+
+	demand := &quota.Demand{RunsTasks: true, ExcludedServiceID: serviceID}
+
+	if spec.Mode.Global != nil {
+		info, err := transport.dockerClient.Info(request.Context())
+		if err != nil {
+			return nil, err
+		}
+		demand.Memory, demand.CPU = quota.ServiceReservation(&spec, info.Swarm.Nodes)
+	} else {
+		demand.Memory, demand.CPU = quota.ServiceReservation(&spec, 0)
+	}
+
+	return transport.checkTeamQuotas(request, demand)
+}
+
+// lockQuotas serializes the verification of the quotas and the creation of the resources on the endpoint,
+// the lock must be held until the resource control of the created resource is persisted.
+// The returned function releases the lock.
+func (transport *Transport) lockQuotas() (func(), error) {
+	// the quotas may have changed since the creation of the proxy
+	endpoint, err := transport.dataStore.Endpoint().Endpoint(transport.endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return quota.LockEndpoint(endpoint), nil
+}
+
+func (transport *Transport) checkTeamQuotas(request *http.Request, demand *quota.Demand) (*http.Response, error) {
+	tokenData, err := security.RetrieveTokenData(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// the quotas may have changed since the creation of the proxy
+	endpoint, err := transport.dataStore.Endpoint().Endpoint(transport.endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = quota.Check(transport.dataStore, transport.dockerClient, endpoint, tokenData.ID, demand)
+	if exceeded, ok := err.(*quota.ExceededError); ok {
+		return utils.WriteForbiddenResponse(exceeded.Error())
+	}
+
+	return nil, err
+}
@@ -13,6 +13,7 @@ import (
 
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/http/proxy/factory/utils"
+	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
 )
 
@@ -110,17 +111,17 @@ func (transport *Transport) decorateServiceCreationOperation(request *http.Reque
 		return nil, err
 	}
 
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	if !isAdminOrEndpointAdmin {
 		securitySettings, err := transport.fetchEndpointSecuritySettings()
 		if err != nil {
 			return nil, err
 		}
 
-		body, err := ioutil.ReadAll(request.Body)
-		if err != nil {
-			return nil, err
-		}
-
 		partialService := &PartialService{}
 		err = json.Unmarshal(body, partialService)
 		if err != nil {
@@ -134,9 +135,71 @@ func (transport *Transport) decorateServiceCreationOperation(request *http.Reque
 				}
 			}
 		}
+	}
 
-		request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	deniedResponse, err := transport.enforceServicePolicies(request, body)
+	if err != nil || deniedResponse != nil {
+		return deniedResponse, err
 	}
 
+	unlock, err := transport.lockQuotas()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	deniedResponse, err = transport.checkServiceQuotas(request, body, "")
+	if err != nil || deniedResponse != nil {
+		return deniedResponse, err
+	}
+
+	request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+
 	return transport.replaceRegistryAuthenticationHeader(request)
 }
+
+// decorateServiceUpdateOperation verifies the access of the user to the service before evaluating the Docker
+// policies and the resource quotas, so that users who cannot access the service are denied without learning about
+// the policies and the usage of the quotas, and without holding the quota lock of the endpoint.
+func (transport *Transport) decorateServiceUpdateOperation(request *http.Request, serviceID string) (*http.Response, error) {
+	tokenData, err := security.RetrieveTokenData(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenData.Role != portainer.AdministratorRole {
+		canAccess, err := transport.userCanAccessResource(request, tokenData.ID, serviceID, serviceID, portainer.ServiceResourceControl)
+		if err != nil {
+			return nil, err
+		}
+
+		if !canAccess {
+			return utils.WriteAccessDeniedResponse()
+		}
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	deniedResponse, err := transport.enforceServicePolicies(request, body)
+	if err != nil || deniedResponse != nil {
+		return deniedResponse, err
+	}
+
+	unlock, err := transport.lockQuotas()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	deniedResponse, err = transport.checkServiceQuotas(request, body, serviceID)
+	if err != nil || deniedResponse != nil {
+		return deniedResponse, err
+	}
+
+	request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+
+	return transport.executeDockerRequest(request)
+}
@@ -35,6 +35,7 @@ type (
 		reverseTunnelService portainer.ReverseTunnelService
 		dockerClient         *client.Client
 		dockerClientFactory  *docker.ClientFactory
+		responseCache        *ResponseCache
 	}
 
 	// TransportParameters is used to create a new Transport
@@ -44,6 +45,7 @@ type (
 		SignatureService     portainer.DigitalSignatureService
 		ReverseTunnelService portainer.ReverseTunnelService
 		DockerClientFactory  *docker.ClientFactory
+		ResponseCache        *ResponseCache
 	}
 
 	restrictedDockerOperationContext struct {
@@ -58,7 +60,6 @@ type (
 		labelBlackList   []portainer.Pair
 	}
 	restrictedOperationRequest func(*http.Response, *operationExecutor) error
-	operationRequest           func(*http.Request) error
 )
 
 // NewTransport returns a pointer to a new Transport instance.
@@ -76,6 +77,7 @@ func NewTransport(parameters *TransportParameters, httpTransport *http.Transport
 		dockerClientFactory:  parameters.DockerClientFactory,
 		HTTPTransport:        httpTransport,
 		dockerClient:         dockerClient,
+		responseCache:        parameters.ResponseCache,
 	}
 
 	return transport, nil
@@ -133,6 +135,27 @@ func (transport *Transport) ProxyDockerRequest(request *http.Request) (*http.Res
 }
 
 func (transport *Transport) executeDockerRequest(request *http.Request) (*http.Response, error) {
+	if transport.responseCache == nil {
+		return transport.forwardDockerRequest(request)
+	}
+
+	if isCacheableRequest(request) {
+		return transport.executeCachedDockerRequest(request)
+	}
+
+	if !isMutatingRequest(request) {
+		return transport.forwardDockerRequest(request)
+	}
+
+	// the cache is invalidated before and after the operation so that a list operation running
+	// concurrently cannot cache a state prior to the mutation
+	transport.responseCache.Invalidate(transport.endpoint.ID)
+	defer transport.responseCache.Invalidate(transport.endpoint.ID)
+
+	return transport.forwardDockerRequest(request)
+}
+
+func (transport *Transport) forwardDockerRequest(request *http.Request) (*http.Response, error) {
 	response, err := transport.HTTPTransport.RoundTrip(request)
 
 	if transport.endpoint.Type != portainer.EdgeAgentOnDockerEnvironment {
@@ -278,6 +301,9 @@ func (transport *Transport) proxyServiceRequest(request *http.Request) (*http.Re
 		if match, _ := path.Match("/services/*/*", requestPath); match {
 			// Handle /services/{id}/{action} requests
 			serviceID := path.Base(path.Dir(requestPath))
+			if path.Base(requestPath) == "update" && request.Method == http.MethodPost {
+				return transport.decorateServiceUpdateOperation(request, serviceID)
+			}
 			return transport.restrictedResourceOperation(request, serviceID, serviceID, portainer.ServiceResourceControl, false)
 		} else if match, _ := path.Match("/services/*", requestPath); match {
 			// Handle /services/{id} requests
@@ -386,47 +412,94 @@ func (transport *Transport) proxyTaskRequest(request *http.Request) (*http.Respo
 }
 
 func (transport *Transport) proxyBuildRequest(request *http.Request) (*http.Response, error) {
-	return transport.interceptAndRewriteRequest(request, buildOperation)
+	err := buildOperation(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return transport.decorateImageOwnershipOperation(request, request.URL.Query().Get("t"))
 }
 
 func (transport *Transport) proxyImageRequest(request *http.Request) (*http.Response, error) {
 	switch requestPath := request.URL.Path; requestPath {
 	case "/images/create":
-		return transport.replaceRegistryAuthenticationHeader(request)
+		err := transport.rewriteRegistryAuthenticationHeader(request)
+		if err != nil {
+			return nil, err
+		}
+		return transport.decorateImageOwnershipOperation(request, imageReferenceFromCreateRequest(request.URL.Query()))
+	case "/images/json":
+		return transport.rewriteOperation(request, transport.imageListOperation)
+	case "/images/get":
+		return transport.restrictedImageOperation(request, request.URL.Query()["names"]...)
+	case "/images/search", "/images/load", "/images/prune":
+		return transport.executeDockerRequest(request)
 	default:
-		if path.Base(requestPath) == "push" && request.Method == http.MethodPost {
-			return transport.replaceRegistryAuthenticationHeader(request)
+		imageReference := strings.TrimPrefix(requestPath, "/images/")
+
+		if request.Method == http.MethodDelete {
+			return transport.imageDeletionOperation(request, imageReference)
+		}
+
+		action := path.Base(imageReference)
+		imageReference = path.Dir(imageReference)
+
+		switch {
+		case action == "json" && request.Method == http.MethodGet:
+			return transport.rewriteOperation(request, transport.imageInspectOperation)
+		case action == "get" && request.Method == http.MethodGet:
+			return transport.restrictedImageOperation(request, imageReference)
+		case action == "tag" && request.Method == http.MethodPost:
+			return transport.restrictedImageOperation(request, imageReference)
+		case action == "push" && request.Method == http.MethodPost:
+			err := transport.rewriteRegistryAuthenticationHeader(request)
+			if err != nil {
+				return nil, err
+			}
+			return transport.restrictedImageOperation(request, imageReferenceFromPushRequest(imageReference, request.URL.Query()))
 		}
+
 		return transport.executeDockerRequest(request)
 	}
 }
 
 func (transport *Transport) replaceRegistryAuthenticationHeader(request *http.Request) (*http.Response, error) {
-	accessContext, err := transport.createRegistryAccessContext(request)
+	err := transport.rewriteRegistryAuthenticationHeader(request)
 	if err != nil {
 		return nil, err
 	}
 
+	return transport.decorateGenericResourceCreationOperation(request, serviceObjectIdentifier, portainer.ServiceResourceControl)
+}
+
+// rewriteRegistryAuthenticationHeader replaces the registry identifier sent in the X-Registry-Auth header
+// with the credentials of that registry, when the user is allowed to use it.
+func (transport *Transport) rewriteRegistryAuthenticationHeader(request *http.Request) error {
+	accessContext, err := transport.createRegistryAccessContext(request)
+	if err != nil {
+		return err
+	}
+
 	originalHeader := request.Header.Get("X-Registry-Auth")
 
 	if originalHeader != "" {
 
 		decodedHeaderData, err := base64.StdEncoding.DecodeString(originalHeader)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		var originalHeaderData portainerRegistryAuthenticationHeader
 		err = json.Unmarshal(decodedHeaderData, &originalHeaderData)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		authenticationHeader := createRegistryAuthenticationHeader(originalHeaderData.RegistryId, accessContext)
 
 		headerData, err := json.Marshal(authenticationHeader)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		header := base64.StdEncoding.EncodeToString(headerData)
@@ -434,7 +507,7 @@ func (transport *Transport) replaceRegistryAuthenticationHeader(request *http.Re
 		request.Header.Set("X-Registry-Auth", header)
 	}
 
-	return transport.decorateGenericResourceCreationOperation(request, serviceObjectIdentifier, portainer.ServiceResourceControl)
+	return nil
 }
 
 func (transport *Transport) restrictedResourceOperation(request *http.Request, resourceID string, dockerResourceID string, resourceType portainer.ResourceControlType, volumeBrowseRestrictionCheck bool) (*http.Response, error) {
@@ -456,47 +529,56 @@ func (transport *Transport) restrictedResourceOperation(request *http.Request, r
 			}
 		}
 
-		teamMemberships, err := transport.dataStore.TeamMembership().TeamMembershipsByUserID(tokenData.ID)
+		canAccess, err := transport.userCanAccessResource(request, tokenData.ID, resourceID, dockerResourceID, resourceType)
 		if err != nil {
 			return nil, err
 		}
 
-		userTeamIDs := make([]portainer.TeamID, 0)
-		for _, membership := range teamMemberships {
-			userTeamIDs = append(userTeamIDs, membership.TeamID)
+		if !canAccess {
+			return utils.WriteAccessDeniedResponse()
 		}
+	}
 
-		resourceControls, err := transport.dataStore.ResourceControl().ResourceControls()
-		if err != nil {
-			return nil, err
-		}
+	return transport.executeDockerRequest(request)
+}
 
-		resourceControl := authorization.GetResourceControlByResourceIDAndType(resourceID, resourceType, resourceControls)
-		if resourceControl == nil {
-			agentTargetHeader := request.Header.Get(portainer.PortainerAgentTargetHeader)
+// userCanAccessResource returns true when a non administrator user can access a resource through its resource control,
+// or through the resource control of the service or the stack it belongs to when it has none.
+func (transport *Transport) userCanAccessResource(request *http.Request, userID portainer.UserID, resourceID string, dockerResourceID string, resourceType portainer.ResourceControlType) (bool, error) {
+	teamMemberships, err := transport.dataStore.TeamMembership().TeamMembershipsByUserID(userID)
+	if err != nil {
+		return false, err
+	}
 
-			if dockerResourceID == "" {
-				dockerResourceID = resourceID
-			}
+	userTeamIDs := make([]portainer.TeamID, 0)
+	for _, membership := range teamMemberships {
+		userTeamIDs = append(userTeamIDs, membership.TeamID)
+	}
 
-			// This resource was created outside of portainer,
-			// is part of a Docker service or part of a Docker Swarm/Compose stack.
-			inheritedResourceControl, err := transport.getInheritedResourceControlFromServiceOrStack(dockerResourceID, agentTargetHeader, resourceType, resourceControls)
-			if err != nil {
-				return nil, err
-			}
+	resourceControls, err := transport.dataStore.ResourceControl().ResourceControls()
+	if err != nil {
+		return false, err
+	}
 
-			if inheritedResourceControl == nil || !authorization.UserCanAccessResource(tokenData.ID, userTeamIDs, inheritedResourceControl) {
-				return utils.WriteAccessDeniedResponse()
-			}
-		}
+	resourceControl := authorization.GetResourceControlByResourceIDAndType(resourceID, resourceType, resourceControls)
+	if resourceControl != nil {
+		return authorization.UserCanAccessResource(userID, userTeamIDs, resourceControl), nil
+	}
 
-		if resourceControl != nil && !authorization.UserCanAccessResource(tokenData.ID, userTeamIDs, resourceControl) {
-			return utils.WriteAccessDeniedResponse()
-		}
+	agentTargetHeader := request.Header.Get(portainer.PortainerAgentTargetHeader)
+
+	if dockerResourceID == "" {
+		dockerResourceID = resourceID
 	}
 
-	return transport.executeDockerRequest(request)
+	// This resource was created outside of portainer,
+	// is part of a Docker service or part of a Docker Swarm/Compose stack.
+	inheritedResourceControl, err := transport.getInheritedResourceControlFromServiceOrStack(dockerResourceID, agentTargetHeader, resourceType, resourceControls)
+	if err != nil {
+		return false, err
+	}
+
+	return inheritedResourceControl != nil && authorization.UserCanAccessResource(userID, userTeamIDs, inheritedResourceControl), nil
 }
 
 // rewriteOperationWithLabelFiltering will create a new operation context with data that will be used
@@ -536,15 +618,6 @@ func (transport *Transport) rewriteOperation(request *http.Request, operation re
 	return transport.executeRequestAndRewriteResponse(request, operation, executor)
 }
 
-func (transport *Transport) interceptAndRewriteRequest(request *http.Request, operation operationRequest) (*http.Response, error) {
-	err := operation(request)
-	if err != nil {
-		return nil, err
-	}
-
-	return transport.executeDockerRequest(request)
-}
-
 // decorateGenericResourceCreationResponse extracts the response as a JSON object, extracts the resource identifier from that object based
 // on the resourceIdentifierAttribute parameter then generate a new resource control associated to that resource
 // with a random token and rewrites the response by decorating the original response with a ResourceControl object.
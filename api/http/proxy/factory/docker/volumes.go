@@ -13,6 +13,7 @@ import (
 	"github.com/portainer/portainer/api/http/proxy/factory/utils"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/internal/quota"
 	"github.com/portainer/portainer/api/internal/snapshot"
 )
 
@@ -129,6 +130,20 @@ func (transport *Transport) decorateVolumeResourceCreationOperation(request *htt
 		return nil, err
 	}
 
+	unlock, err := transport.lockQuotas()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	demand := &quota.Demand{}
+	demand.Volumes = 1
+
+	deniedResponse, err := transport.checkTeamQuotas(request, demand)
+	if err != nil || deniedResponse != nil {
+		return deniedResponse, err
+	}
+
 	volumeID := request.Header.Get("X-Portainer-VolumeName")
 
 	if volumeID != "" {
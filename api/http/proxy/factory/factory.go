@@ -6,6 +6,7 @@ import (
 	"net/url"
 
 	portainer "github.com/portainer/portainer/api"
+	dockerproxy "github.com/portainer/portainer/api/http/proxy/factory/docker"
 	"github.com/portainer/portainer/api/http/proxy/factory/kubernetes"
 
 	"github.com/portainer/portainer/api/kubernetes/cli"
@@ -24,6 +25,7 @@ type (
 		dockerClientFactory         *docker.ClientFactory
 		kubernetesClientFactory     *cli.ClientFactory
 		kubernetesTokenCacheManager *kubernetes.TokenCacheManager
+		dockerResponseCache         *dockerproxy.ResponseCache
 	}
 )
 
@@ -36,9 +38,15 @@ func NewProxyFactory(dataStore portainer.DataStore, signatureService portainer.D
 		dockerClientFactory:         clientFactory,
 		kubernetesClientFactory:     kubernetesClientFactory,
 		kubernetesTokenCacheManager: kubernetesTokenCacheManager,
+		dockerResponseCache:         dockerproxy.NewResponseCache(),
 	}
 }
 
+// DockerResponseCache returns the cache of the Docker list responses shared by the Docker proxies
+func (factory *ProxyFactory) DockerResponseCache() *dockerproxy.ResponseCache {
+	return factory.dockerResponseCache
+}
+
 // NewLegacyExtensionProxy returns a new HTTP proxy to a legacy extension server (Storidge)
 func (factory *ProxyFactory) NewLegacyExtensionProxy(extensionAPIURL string) (http.Handler, error) {
 	extensionURL, err := url.Parse(extensionAPIURL)
@@ -17,7 +17,9 @@ type edgeTransport struct {
 func NewEdgeTransport(reverseTunnelService portainer.ReverseTunnelService, endpoint *portainer.Endpoint, tokenManager *tokenManager, k8sClientFactory *cli.ClientFactory, dataStore portainer.DataStore) *edgeTransport {
 	transport := &edgeTransport{
 		baseTransport: newBaseTransport(
-			&http.Transport{},
+			&http.Transport{
+				DialContext: reverseTunnelService.TunnelDialer(endpoint.ID),
+			},
 			tokenManager,
 			endpoint,
 			k8sClientFactory,
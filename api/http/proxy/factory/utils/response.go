@@ -93,3 +93,10 @@ func getResponseBody(response *http.Response) (interface{}, error) {
 func getContentType(headers http.Header) string {
 	return headers.Get("Content-type")
 }
+
+// WriteForbiddenResponse will create a new forbidden response explaining the reason of the denial
+func WriteForbiddenResponse(message string) (*http.Response, error) {
+	response := &http.Response{}
+	err := RewriteResponse(response, errorResponse{Message: message}, http.StatusForbidden)
+	return response, err
+}
@@ -12,6 +12,7 @@ import (
 	portainer "github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/docker"
 	"github.com/portainer/portainer/api/http/proxy/factory"
+	dockerproxy "github.com/portainer/portainer/api/http/proxy/factory/docker"
 )
 
 // TODO: contain code related to legacy extension management
@@ -44,6 +45,7 @@ func (manager *Manager) CreateAndRegisterEndpointProxy(endpoint *portainer.Endpo
 		return nil, err
 	}
 
+	manager.proxyFactory.DockerResponseCache().Remove(endpoint.ID)
 	manager.endpointProxies.Set(fmt.Sprint(endpoint.ID), proxy)
 	return proxy, nil
 }
@@ -70,6 +72,17 @@ func (manager *Manager) GetEndpointProxy(endpoint *portainer.Endpoint) http.Hand
 func (manager *Manager) DeleteEndpointProxy(endpoint *portainer.Endpoint) {
 	manager.endpointProxies.Remove(fmt.Sprint(endpoint.ID))
 	manager.k8sClientFactory.RemoveKubeClient(endpoint)
+	manager.proxyFactory.DockerResponseCache().Remove(endpoint.ID)
+}
+
+// DockerResponseCacheStats returns the statistics of the cache of the Docker list responses of an endpoint
+func (manager *Manager) DockerResponseCacheStats(endpointID portainer.EndpointID) dockerproxy.ResponseCacheStats {
+	return manager.proxyFactory.DockerResponseCache().Stats(endpointID)
+}
+
+// InvalidateDockerResponseCache removes the cached Docker list responses of an endpoint
+func (manager *Manager) InvalidateDockerResponseCache(endpointID portainer.EndpointID) {
+	manager.proxyFactory.DockerResponseCache().Invalidate(endpointID)
 }
 
 // CreateLegacyExtensionProxy creates a new HTTP reverse proxy for a legacy extension and adds it to the registered proxies
@@ -4,11 +4,13 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	httperror "github.com/portainer/libhttp/error"
 	portainer "github.com/portainer/portainer/api"
 	bolterrors "github.com/portainer/portainer/api/bolt/errors"
 	httperrors "github.com/portainer/portainer/api/http/errors"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 type (
@@ -125,6 +127,12 @@ func (bouncer *RequestBouncer) AuthorizedEdgeEndpointOperation(r *http.Request,
 		return errors.New("invalid Edge identifier")
 	}
 
+	secret := r.Header.Get(portainer.PortainerAgentEdgeKeySecretHeader)
+	err := edge.ValidateEdgeKeySecret(&endpoint.EdgeCredentials, secret, time.Now())
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
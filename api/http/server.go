@@ -16,6 +16,8 @@ import (
 	"github.com/portainer/portainer/api/http/handler/auth"
 	"github.com/portainer/portainer/api/http/handler/backup"
 	"github.com/portainer/portainer/api/http/handler/customtemplates"
+	"github.com/portainer/portainer/api/http/handler/dockerpolicies"
+	"github.com/portainer/portainer/api/http/handler/edgedevices"
 	"github.com/portainer/portainer/api/http/handler/edgegroups"
 	"github.com/portainer/portainer/api/http/handler/edgejobs"
 	"github.com/portainer/portainer/api/http/handler/edgestacks"
@@ -26,9 +28,11 @@ import (
 	"github.com/portainer/portainer/api/http/handler/endpoints"
 	"github.com/portainer/portainer/api/http/handler/file"
 	"github.com/portainer/portainer/api/http/handler/motd"
+	"github.com/portainer/portainer/api/http/handler/prunepolicies"
 	"github.com/portainer/portainer/api/http/handler/registries"
 	"github.com/portainer/portainer/api/http/handler/resourcecontrols"
 	"github.com/portainer/portainer/api/http/handler/roles"
+	"github.com/portainer/portainer/api/http/handler/search"
 	"github.com/portainer/portainer/api/http/handler/settings"
 	"github.com/portainer/portainer/api/http/handler/stacks"
 	"github.com/portainer/portainer/api/http/handler/status"
@@ -36,6 +40,7 @@ import (
 	"github.com/portainer/portainer/api/http/handler/teammemberships"
 	"github.com/portainer/portainer/api/http/handler/teams"
 	"github.com/portainer/portainer/api/http/handler/templates"
+	"github.com/portainer/portainer/api/http/handler/tunnels"
 	"github.com/portainer/portainer/api/http/handler/upload"
 	"github.com/portainer/portainer/api/http/handler/users"
 	"github.com/portainer/portainer/api/http/handler/webhooks"
@@ -45,6 +50,8 @@ import (
 	"github.com/portainer/portainer/api/http/proxy/factory/kubernetes"
 	"github.com/portainer/portainer/api/http/security"
 	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/internal/dockerevents"
+	"github.com/portainer/portainer/api/internal/prune"
 	"github.com/portainer/portainer/api/kubernetes/cli"
 )
 
@@ -59,6 +66,9 @@ type Server struct {
 	CryptoService               portainer.CryptoService
 	SignatureService            portainer.DigitalSignatureService
 	SnapshotService             portainer.SnapshotService
+	EdgeStackGitService         portainer.EdgeStackGitService
+	DockerEventsService         *dockerevents.Service
+	PruneService                *prune.Service
 	FileService                 portainer.FileService
 	DataStore                   portainer.DataStore
 	GitService                  portainer.GitService
@@ -77,6 +87,7 @@ type Server struct {
 	KubernetesDeployer          portainer.KubernetesDeployer
 	ShutdownCtx                 context.Context
 	ShutdownTrigger             context.CancelFunc
+	TunnelPortRangeFromFlags    bool
 }
 
 // Start starts the HTTP server
@@ -110,6 +121,10 @@ func (server *Server) Start() error {
 	customTemplatesHandler.FileService = server.FileService
 	customTemplatesHandler.GitService = server.GitService
 
+	var edgeDevicesHandler = edgedevices.NewHandler(requestBouncer)
+	edgeDevicesHandler.DataStore = server.DataStore
+	edgeDevicesHandler.ReverseTunnelService = server.ReverseTunnelService
+
 	var edgeGroupsHandler = edgegroups.NewHandler(requestBouncer)
 	edgeGroupsHandler.DataStore = server.DataStore
 
@@ -122,12 +137,14 @@ func (server *Server) Start() error {
 	edgeStacksHandler.DataStore = server.DataStore
 	edgeStacksHandler.FileService = server.FileService
 	edgeStacksHandler.GitService = server.GitService
+	edgeStacksHandler.EdgeStackGitService = server.EdgeStackGitService
 
 	var edgeTemplatesHandler = edgetemplates.NewHandler(requestBouncer)
 	edgeTemplatesHandler.DataStore = server.DataStore
 
 	var endpointHandler = endpoints.NewHandler(requestBouncer)
 	endpointHandler.DataStore = server.DataStore
+	endpointHandler.DockerClientFactory = server.DockerClientFactory
 	endpointHandler.FileService = server.FileService
 	endpointHandler.ProxyManager = server.ProxyManager
 	endpointHandler.SnapshotService = server.SnapshotService
@@ -140,6 +157,7 @@ func (server *Server) Start() error {
 	endpointEdgeHandler.DataStore = server.DataStore
 	endpointEdgeHandler.FileService = server.FileService
 	endpointEdgeHandler.ReverseTunnelService = server.ReverseTunnelService
+	endpointEdgeHandler.SnapshotService = server.SnapshotService
 
 	var endpointGroupHandler = endpointgroups.NewHandler(requestBouncer)
 	endpointGroupHandler.AuthorizationService = server.AuthorizationService
@@ -169,6 +187,10 @@ func (server *Server) Start() error {
 	settingsHandler.JWTService = server.JWTService
 	settingsHandler.LDAPService = server.LDAPService
 	settingsHandler.SnapshotService = server.SnapshotService
+	settingsHandler.TunnelPortRangeFromFlags = server.TunnelPortRangeFromFlags
+
+	var searchHandler = search.NewHandler(requestBouncer)
+	searchHandler.DataStore = server.DataStore
 
 	var stackHandler = stacks.NewHandler(requestBouncer)
 	stackHandler.DataStore = server.DataStore
@@ -179,6 +201,13 @@ func (server *Server) Start() error {
 	stackHandler.KubernetesDeployer = server.KubernetesDeployer
 	stackHandler.GitService = server.GitService
 
+	var dockerPoliciesHandler = dockerpolicies.NewHandler(requestBouncer)
+	dockerPoliciesHandler.DataStore = server.DataStore
+
+	var prunePoliciesHandler = prunepolicies.NewHandler(requestBouncer)
+	prunePoliciesHandler.DataStore = server.DataStore
+	prunePoliciesHandler.PruneService = server.PruneService
+
 	var tagHandler = tags.NewHandler(requestBouncer)
 	tagHandler.DataStore = server.DataStore
 
@@ -202,11 +231,16 @@ func (server *Server) Start() error {
 	userHandler.DataStore = server.DataStore
 	userHandler.CryptoService = server.CryptoService
 
+	var tunnelHandler = tunnels.NewHandler(requestBouncer)
+	tunnelHandler.DataStore = server.DataStore
+	tunnelHandler.ReverseTunnelService = server.ReverseTunnelService
+
 	var websocketHandler = websocket.NewHandler(requestBouncer)
 	websocketHandler.DataStore = server.DataStore
 	websocketHandler.SignatureService = server.SignatureService
 	websocketHandler.ReverseTunnelService = server.ReverseTunnelService
 	websocketHandler.KubernetesClientFactory = server.KubernetesClientFactory
+	websocketHandler.DockerEventsService = server.DockerEventsService
 
 	var webhookHandler = webhooks.NewHandler(requestBouncer)
 	webhookHandler.DataStore = server.DataStore
@@ -217,6 +251,8 @@ func (server *Server) Start() error {
 		AuthHandler:            authHandler,
 		BackupHandler:          backupHandler,
 		CustomTemplatesHandler: customTemplatesHandler,
+		DockerPoliciesHandler:  dockerPoliciesHandler,
+		EdgeDevicesHandler:     edgeDevicesHandler,
 		EdgeGroupsHandler:      edgeGroupsHandler,
 		EdgeJobsHandler:        edgeJobsHandler,
 		EdgeStacksHandler:      edgeStacksHandler,
@@ -227,8 +263,10 @@ func (server *Server) Start() error {
 		EndpointProxyHandler:   endpointProxyHandler,
 		FileHandler:            fileHandler,
 		MOTDHandler:            motdHandler,
+		PrunePoliciesHandler:   prunePoliciesHandler,
 		RegistryHandler:        registryHandler,
 		ResourceControlHandler: resourceControlHandler,
+		SearchHandler:          searchHandler,
 		SettingsHandler:        settingsHandler,
 		StatusHandler:          statusHandler,
 		StackHandler:           stackHandler,
@@ -236,6 +274,7 @@ func (server *Server) Start() error {
 		TeamHandler:            teamHandler,
 		TeamMembershipHandler:  teamMembershipHandler,
 		TemplatesHandler:       templatesHandler,
+		TunnelHandler:          tunnelHandler,
 		UploadHandler:          uploadHandler,
 		UserHandler:            userHandler,
 		WebSocketHandler:       websocketHandler,
@@ -0,0 +1,782 @@
+// Package datastoretest provides a test suite that every portainer.DataStore
+// implementation must pass.
+package datastoretest
+
+import (
+	"math"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NewStoreFunc returns a new opened and initialized store along with a teardown function.
+type NewStoreFunc func(t *testing.T) (portainer.DataStore, func())
+
+// RunServiceTests runs the service test suite against the stores created by newStore.
+// Each test receives its own store.
+func RunServiceTests(t *testing.T, newStore NewStoreFunc) {
+	tests := map[string]func(t *testing.T, store portainer.DataStore){
+		"Init":                   testInit,
+		"User":                   testUser,
+		"Team":                   testTeam,
+		"TeamMembership":         testTeamMembership,
+		"Stack":                  testStack,
+		"Webhook":                testWebhook,
+		"ResourceControl":        testResourceControl,
+		"Endpoint":               testEndpoint,
+		"EndpointRelation":       testEndpointRelation,
+		"EdgeStack":              testEdgeStack,
+		"EdgeJob":                testEdgeJob,
+		"Tag":                    testTag,
+		"Registry":               testRegistry,
+		"CustomTemplate":         testCustomTemplate,
+		"Version":                testVersion,
+		"TunnelServer":           testTunnelServer,
+		"SnapshotHistory":        testSnapshotHistory,
+		"EdgeStackStatusHistory": testEdgeStackStatusHistory,
+		"EdgeAsyncCommand":       testEdgeAsyncCommand,
+		"DockerPolicy":           testDockerPolicy,
+		"EdgeDevice":             testEdgeDevice,
+		"EdgeJobRun":             testEdgeJobRun,
+		"TunnelEvent":            testTunnelEvent,
+		"PrunePolicy":            testPrunePolicy,
+		"PrunePolicyRun":         testPrunePolicyRun,
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			store, teardown := newStore(t)
+			defer teardown()
+
+			test(t, store)
+		})
+	}
+}
+
+func testInit(t *testing.T, store portainer.DataStore) {
+	settings, err := store.Settings().Settings()
+	require.NoError(t, err)
+	assert.Equal(t, portainer.AuthenticationInternal, settings.AuthenticationMethod)
+
+	groups, err := store.EndpointGroup().EndpointGroups()
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, portainer.EndpointGroupID(1), groups[0].ID)
+
+	instanceID, err := store.Version().InstanceID()
+	require.NoError(t, err)
+	assert.NotEmpty(t, instanceID)
+
+	assert.NoError(t, store.CheckCurrentEdition())
+}
+
+func testUser(t *testing.T, store portainer.DataStore) {
+	service := store.User()
+
+	_, err := service.User(1)
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	admin := &portainer.User{Username: "Admin", Role: portainer.AdministratorRole}
+	require.NoError(t, service.CreateUser(admin))
+	assert.Equal(t, portainer.UserID(1), admin.ID)
+	assert.Equal(t, "admin", admin.Username)
+
+	user := &portainer.User{Username: "user", Role: portainer.StandardUserRole}
+	require.NoError(t, service.CreateUser(user))
+	assert.Equal(t, portainer.UserID(2), user.ID)
+
+	found, err := service.UserByUsername("ADMIN")
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, found.ID)
+
+	_, err = service.UserByUsername("unknown")
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	admins, err := service.UsersByRole(portainer.AdministratorRole)
+	require.NoError(t, err)
+	require.Len(t, admins, 1)
+	assert.Equal(t, admin.ID, admins[0].ID)
+
+	user.Username = "Renamed"
+	require.NoError(t, service.UpdateUser(user.ID, user))
+
+	_, err = service.UserByUsername("user")
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	found, err = service.UserByUsername("renamed")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	require.NoError(t, service.DeleteUser(admin.ID))
+
+	users, err := service.Users()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, user.ID, users[0].ID)
+
+	third := &portainer.User{Username: "third"}
+	require.NoError(t, service.CreateUser(third))
+	assert.Equal(t, portainer.UserID(3), third.ID, "identifiers must not be reused")
+}
+
+func testTeam(t *testing.T, store portainer.DataStore) {
+	service := store.Team()
+
+	team := &portainer.Team{Name: "Developers"}
+	require.NoError(t, service.CreateTeam(team))
+	assert.Equal(t, portainer.TeamID(1), team.ID)
+
+	found, err := service.TeamByName("developers")
+	require.NoError(t, err)
+	assert.Equal(t, team.ID, found.ID)
+
+	team.Name = "Operators"
+	require.NoError(t, service.UpdateTeam(team.ID, team))
+
+	_, err = service.TeamByName("developers")
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	found, err = service.TeamByName("OPERATORS")
+	require.NoError(t, err)
+	assert.Equal(t, "Operators", found.Name)
+
+	require.NoError(t, service.DeleteTeam(team.ID))
+
+	teams, err := service.Teams()
+	require.NoError(t, err)
+	assert.Empty(t, teams)
+}
+
+func testTeamMembership(t *testing.T, store portainer.DataStore) {
+	service := store.TeamMembership()
+
+	memberships := []*portainer.TeamMembership{
+		{UserID: 1, TeamID: 1, Role: portainer.TeamLeader},
+		{UserID: 2, TeamID: 1, Role: portainer.TeamMember},
+		{UserID: 1, TeamID: 2, Role: portainer.TeamMember},
+	}
+	for _, membership := range memberships {
+		require.NoError(t, service.CreateTeamMembership(membership))
+	}
+
+	byUser, err := service.TeamMembershipsByUserID(1)
+	require.NoError(t, err)
+	assert.Len(t, byUser, 2)
+
+	byTeam, err := service.TeamMembershipsByTeamID(1)
+	require.NoError(t, err)
+	assert.Len(t, byTeam, 2)
+
+	memberships[1].TeamID = 2
+	require.NoError(t, service.UpdateTeamMembership(memberships[1].ID, memberships[1]))
+
+	byTeam, err = service.TeamMembershipsByTeamID(2)
+	require.NoError(t, err)
+	assert.Len(t, byTeam, 2)
+
+	require.NoError(t, service.DeleteTeamMembershipByUserID(1))
+
+	all, err := service.TeamMemberships()
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, portainer.UserID(2), all[0].UserID)
+
+	require.NoError(t, service.DeleteTeamMembershipByTeamID(2))
+
+	all, err = service.TeamMemberships()
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func testStack(t *testing.T, store portainer.DataStore) {
+	service := store.Stack()
+
+	id := service.GetNextIdentifier()
+	assert.Equal(t, 1, id)
+
+	stack := &portainer.Stack{ID: portainer.StackID(id), Name: "web"}
+	require.NoError(t, service.CreateStack(stack))
+
+	found, err := service.StackByName("web")
+	require.NoError(t, err)
+	assert.Equal(t, stack.ID, found.ID)
+
+	_, err = service.StackByName("Web")
+	assert.Equal(t, errors.ErrObjectNotFound, err, "stack names are case sensitive")
+
+	stack.Name = "api"
+	require.NoError(t, service.UpdateStack(stack.ID, stack))
+
+	_, err = service.StackByName("web")
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	assert.Equal(t, 2, service.GetNextIdentifier())
+
+	require.NoError(t, service.DeleteStack(stack.ID))
+
+	_, err = service.Stack(stack.ID)
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+}
+
+func testWebhook(t *testing.T, store portainer.DataStore) {
+	service := store.Webhook()
+
+	webhook := &portainer.Webhook{Token: "token", ResourceID: "service-id", EndpointID: 1}
+	require.NoError(t, service.CreateWebhook(webhook))
+	assert.Equal(t, portainer.WebhookID(1), webhook.ID)
+
+	found, err := service.WebhookByToken("token")
+	require.NoError(t, err)
+	assert.Equal(t, webhook.ID, found.ID)
+
+	found, err = service.WebhookByResourceID("service-id")
+	require.NoError(t, err)
+	assert.Equal(t, webhook.ID, found.ID)
+
+	_, err = service.WebhookByToken("unknown")
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	require.NoError(t, service.DeleteWebhook(webhook.ID))
+
+	_, err = service.WebhookByResourceID("service-id")
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+}
+
+func testResourceControl(t *testing.T, store portainer.DataStore) {
+	service := store.ResourceControl()
+
+	container := &portainer.ResourceControl{ResourceID: "container", Type: portainer.ContainerResourceControl}
+	require.NoError(t, service.CreateResourceControl(container))
+
+	stack := &portainer.ResourceControl{ResourceID: "stack", Type: portainer.StackResourceControl, SubResourceIDs: []string{"sub-container"}}
+	require.NoError(t, service.CreateResourceControl(stack))
+
+	found, err := service.ResourceControlByResourceIDAndType("container", portainer.ContainerResourceControl)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, container.ID, found.ID)
+
+	found, err = service.ResourceControlByResourceIDAndType("container", portainer.VolumeResourceControl)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	found, err = service.ResourceControlByResourceIDAndType("sub-container", portainer.ContainerResourceControl)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, stack.ID, found.ID)
+
+	container.Type = portainer.VolumeResourceControl
+	require.NoError(t, service.UpdateResourceControl(container.ID, container))
+
+	found, err = service.ResourceControlByResourceIDAndType("container", portainer.VolumeResourceControl)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, container.ID, found.ID)
+
+	require.NoError(t, service.DeleteResourceControl(stack.ID))
+
+	found, err = service.ResourceControlByResourceIDAndType("sub-container", portainer.ContainerResourceControl)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	all, err := service.ResourceControls()
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func testEndpoint(t *testing.T, store portainer.DataStore) {
+	service := store.Endpoint()
+
+	id := service.GetNextIdentifier()
+	endpoint := &portainer.Endpoint{ID: portainer.EndpointID(id), Name: "local"}
+	require.NoError(t, service.CreateEndpoint(endpoint))
+
+	found, err := service.Endpoint(endpoint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "local", found.Name)
+
+	endpoint.Name = "updated"
+	created := &portainer.Endpoint{Name: "created"}
+	require.NoError(t, service.Synchronize([]*portainer.Endpoint{created}, []*portainer.Endpoint{endpoint}, nil))
+	assert.Equal(t, endpoint.ID+1, created.ID)
+
+	endpoints, err := service.Endpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, "updated", endpoints[0].Name)
+	assert.Equal(t, "created", endpoints[1].Name)
+
+	require.NoError(t, service.Synchronize(nil, nil, []*portainer.Endpoint{endpoint}))
+
+	_, err = service.Endpoint(endpoint.ID)
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	require.NoError(t, service.DeleteEndpoint(created.ID))
+
+	endpoints, err = service.Endpoints()
+	require.NoError(t, err)
+	assert.Empty(t, endpoints)
+}
+
+func testEndpointRelation(t *testing.T, store portainer.DataStore) {
+	service := store.EndpointRelation()
+
+	relation := &portainer.EndpointRelation{EndpointID: 5, EdgeStacks: map[portainer.EdgeStackID]bool{1: true}}
+	require.NoError(t, service.CreateEndpointRelation(relation))
+
+	found, err := service.EndpointRelation(5)
+	require.NoError(t, err)
+	assert.True(t, found.EdgeStacks[1])
+
+	relation.EdgeStacks[2] = true
+	require.NoError(t, service.UpdateEndpointRelation(5, relation))
+
+	found, err = service.EndpointRelation(5)
+	require.NoError(t, err)
+	assert.Len(t, found.EdgeStacks, 2)
+
+	relations, err := service.EndpointRelations()
+	require.NoError(t, err)
+	require.Len(t, relations, 1)
+	assert.Equal(t, portainer.EndpointID(5), relations[0].EndpointID)
+
+	require.NoError(t, service.DeleteEndpointRelation(5))
+
+	_, err = service.EndpointRelation(5)
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+}
+
+func testEdgeStack(t *testing.T, store portainer.DataStore) {
+	service := store.EdgeStack()
+
+	edgeStack := &portainer.EdgeStack{Name: "edge"}
+	require.NoError(t, service.CreateEdgeStack(edgeStack))
+	assert.Equal(t, portainer.EdgeStackID(1), edgeStack.ID)
+
+	id := service.GetNextIdentifier()
+	withID := &portainer.EdgeStack{ID: portainer.EdgeStackID(id), Name: "with-id"}
+	require.NoError(t, service.CreateEdgeStack(withID))
+	assert.Equal(t, portainer.EdgeStackID(2), withID.ID)
+
+	edgeStack.Version = 2
+	require.NoError(t, service.UpdateEdgeStack(edgeStack.ID, edgeStack))
+
+	found, err := service.EdgeStack(edgeStack.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.Version)
+
+	require.NoError(t, service.DeleteEdgeStack(edgeStack.ID))
+
+	edgeStacks, err := service.EdgeStacks()
+	require.NoError(t, err)
+	require.Len(t, edgeStacks, 1)
+	assert.Equal(t, withID.ID, edgeStacks[0].ID)
+}
+
+func testEdgeJob(t *testing.T, store portainer.DataStore) {
+	service := store.EdgeJob()
+
+	edgeJob := &portainer.EdgeJob{Name: "job"}
+	require.NoError(t, service.CreateEdgeJob(edgeJob))
+	assert.Equal(t, portainer.EdgeJobID(1), edgeJob.ID)
+
+	edgeJob.CronExpression = "* * * * *"
+	require.NoError(t, service.UpdateEdgeJob(edgeJob.ID, edgeJob))
+
+	found, err := service.EdgeJob(edgeJob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "* * * * *", found.CronExpression)
+
+	require.NoError(t, service.DeleteEdgeJob(edgeJob.ID))
+
+	edgeJobs, err := service.EdgeJobs()
+	require.NoError(t, err)
+	assert.Empty(t, edgeJobs)
+}
+
+func testTag(t *testing.T, store portainer.DataStore) {
+	service := store.Tag()
+
+	tag := &portainer.Tag{Name: "production"}
+	require.NoError(t, service.CreateTag(tag))
+	assert.Equal(t, portainer.TagID(1), tag.ID)
+
+	tag.Name = "staging"
+	require.NoError(t, service.UpdateTag(tag.ID, tag))
+
+	found, err := service.Tag(tag.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", found.Name)
+
+	require.NoError(t, service.DeleteTag(tag.ID))
+
+	tags, err := service.Tags()
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+}
+
+func testDockerPolicy(t *testing.T, store portainer.DataStore) {
+	service := store.DockerPolicy()
+
+	policy := &portainer.DockerPolicy{Name: "production", TeamIDs: []portainer.TeamID{1}}
+	require.NoError(t, service.CreateDockerPolicy(policy))
+	assert.Equal(t, portainer.DockerPolicyID(1), policy.ID)
+
+	policy.Rules.MaxReplicas = 3
+	require.NoError(t, service.UpdateDockerPolicy(policy.ID, policy))
+
+	found, err := service.DockerPolicy(policy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, found.Rules.MaxReplicas)
+	assert.Equal(t, []portainer.TeamID{1}, found.TeamIDs)
+
+	require.NoError(t, service.DeleteDockerPolicy(policy.ID))
+
+	policies, err := service.DockerPolicies()
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+}
+
+func testRegistry(t *testing.T, store portainer.DataStore) {
+	service := store.Registry()
+
+	registry := &portainer.Registry{Name: "registry", URL: "registry.example.com"}
+	require.NoError(t, service.CreateRegistry(registry))
+	assert.Equal(t, portainer.RegistryID(1), registry.ID)
+
+	registries, err := service.Registries()
+	require.NoError(t, err)
+	assert.Len(t, registries, 1)
+
+	require.NoError(t, service.DeleteRegistry(registry.ID))
+
+	_, err = service.Registry(registry.ID)
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+}
+
+func testCustomTemplate(t *testing.T, store portainer.DataStore) {
+	service := store.CustomTemplate()
+
+	id := service.GetNextIdentifier()
+	template := &portainer.CustomTemplate{ID: portainer.CustomTemplateID(id), Title: "template"}
+	require.NoError(t, service.CreateCustomTemplate(template))
+
+	found, err := service.CustomTemplate(template.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "template", found.Title)
+
+	require.NoError(t, service.DeleteCustomTemplate(template.ID))
+
+	templates, err := service.CustomTemplates()
+	require.NoError(t, err)
+	assert.Empty(t, templates)
+}
+
+func testVersion(t *testing.T, store portainer.DataStore) {
+	service := store.Version()
+
+	require.NoError(t, service.StoreDBVersion(portainer.DBVersion))
+
+	version, err := service.DBVersion()
+	require.NoError(t, err)
+	assert.Equal(t, portainer.DBVersion, version)
+
+	_, err = service.Edition()
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+}
+
+func testTunnelServer(t *testing.T, store portainer.DataStore) {
+	service := store.TunnelServer()
+
+	_, err := service.Info()
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	require.NoError(t, service.UpdateInfo(&portainer.TunnelServerInfo{PrivateKeySeed: "seed"}))
+
+	info, err := service.Info()
+	require.NoError(t, err)
+	assert.Equal(t, "seed", info.PrivateKeySeed)
+}
+
+func testSnapshotHistory(t *testing.T, store portainer.DataStore) {
+	service := store.SnapshotHistory()
+
+	for _, entry := range []portainer.SnapshotHistoryEntry{
+		{EndpointID: 1, Time: 100, ImageCount: 1},
+		{EndpointID: 1, Time: 200, ImageCount: 2},
+		{EndpointID: 1, Time: 300, ImageCount: 3},
+		{EndpointID: 1, Time: 300, ImageCount: 5},
+		{EndpointID: 2, Time: 200, ImageCount: 4},
+	} {
+		entry := entry
+		require.NoError(t, service.CreateSnapshotHistoryEntry(&entry))
+	}
+
+	entries, err := service.SnapshotHistory(1, 150, 300)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, 2, entries[0].ImageCount)
+	assert.Equal(t, 3, entries[1].ImageCount)
+	assert.Equal(t, 5, entries[2].ImageCount, "entries recorded at the same time are all kept")
+
+	require.NoError(t, service.DeleteSnapshotHistory(1, 0, 200))
+
+	entries, err = service.SnapshotHistory(1, 0, 1000)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(300), entries[0].Time)
+
+	entries, err = service.SnapshotHistory(2, 0, 1000)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func testEdgeStackStatusHistory(t *testing.T, store portainer.DataStore) {
+	service := store.EdgeStackStatusHistory()
+
+	for _, entry := range []portainer.EdgeStackStatusHistoryEntry{
+		{EdgeStackID: 1, EndpointID: 1, Version: 1, Type: portainer.StatusError, Error: "pull failed"},
+		{EdgeStackID: 1, EndpointID: 1, Version: 2, Type: portainer.StatusOk},
+		{EdgeStackID: 1, EndpointID: 2, Version: 2, Type: portainer.StatusOk},
+		{EdgeStackID: 2, EndpointID: 1, Version: 1, Type: portainer.StatusOk},
+	} {
+		entry := entry
+		require.NoError(t, service.CreateEdgeStackStatusHistoryEntry(&entry))
+		assert.NotZero(t, entry.ID)
+	}
+
+	entries, err := service.EdgeStackStatusHistory(1, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "pull failed", entries[0].Error)
+	assert.Equal(t, 2, entries[1].Version)
+
+	require.NoError(t, service.DeleteEdgeStackStatusHistoryEntries(1, 1, entries[0].ID))
+
+	entries, err = service.EdgeStackStatusHistory(1, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, portainer.StatusOk, entries[0].Type)
+
+	require.NoError(t, service.DeleteEdgeStackStatusHistory(1))
+
+	entries, err = service.EdgeStackStatusHistory(1, 2)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries, err = service.EdgeStackStatusHistory(2, 1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func testEdgeAsyncCommand(t *testing.T, store portainer.DataStore) {
+	service := store.EdgeAsyncCommand()
+
+	for _, command := range []portainer.EdgeAsyncCommand{
+		{EndpointID: 1, Type: portainer.EdgeAsyncCommandContainerRestart, ContainerID: "a1b2", Status: portainer.EdgeAsyncCommandStatusPending},
+		{EndpointID: 2, Type: portainer.EdgeAsyncCommandSnapshotUpload, Status: portainer.EdgeAsyncCommandStatusPending},
+		{EndpointID: 1, Type: portainer.EdgeAsyncCommandImagePull, Image: "nginx", Status: portainer.EdgeAsyncCommandStatusPending},
+	} {
+		command := command
+		require.NoError(t, service.CreateEdgeAsyncCommand(&command))
+		assert.NotZero(t, command.ID)
+	}
+
+	commands, err := service.EdgeAsyncCommands(1)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	assert.Equal(t, "nginx", commands[1].Image)
+
+	command := commands[0]
+	command.Status = portainer.EdgeAsyncCommandStatusSucceeded
+	require.NoError(t, service.UpdateEdgeAsyncCommand(&command))
+
+	found, err := service.EdgeAsyncCommand(1, command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, portainer.EdgeAsyncCommandStatusSucceeded, found.Status)
+
+	_, err = service.EdgeAsyncCommand(2, command.ID)
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	require.NoError(t, service.DeleteEdgeAsyncCommand(1, command.ID))
+	commands, err = service.EdgeAsyncCommands(1)
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+
+	require.NoError(t, service.DeleteEdgeAsyncCommands(1, math.MaxInt64))
+	commands, err = service.EdgeAsyncCommands(1)
+	require.NoError(t, err)
+	assert.Empty(t, commands)
+
+	commands, err = service.EdgeAsyncCommands(2)
+	require.NoError(t, err)
+	assert.Len(t, commands, 1)
+}
+
+func testEdgeDevice(t *testing.T, store portainer.DataStore) {
+	service := store.EdgeDevice()
+
+	edgeDevice := &portainer.EdgeDevice{EdgeID: "store-0042", Status: portainer.EdgeDeviceStatusPending}
+	require.NoError(t, service.CreateEdgeDevice(edgeDevice))
+	assert.Equal(t, portainer.EdgeDeviceID(1), edgeDevice.ID)
+	require.NoError(t, service.CreateEdgeDevice(&portainer.EdgeDevice{EdgeID: "store-0043", Status: portainer.EdgeDeviceStatusPending}))
+
+	edgeDevice.Status = portainer.EdgeDeviceStatusApproved
+	edgeDevice.EndpointID = 3
+	require.NoError(t, service.UpdateEdgeDevice(edgeDevice.ID, edgeDevice))
+
+	found, err := service.EdgeDeviceByEdgeID("store-0042")
+	require.NoError(t, err)
+	assert.Equal(t, portainer.EdgeDeviceStatusApproved, found.Status)
+	assert.Equal(t, portainer.EndpointID(3), found.EndpointID)
+
+	_, err = service.EdgeDeviceByEdgeID("store-0044")
+	assert.Equal(t, errors.ErrObjectNotFound, err)
+
+	require.NoError(t, service.DeleteEdgeDevice(edgeDevice.ID))
+
+	edgeDevices, err := service.EdgeDevices()
+	require.NoError(t, err)
+	require.Len(t, edgeDevices, 1)
+	assert.Equal(t, "store-0043", edgeDevices[0].EdgeID)
+}
+
+func testEdgeJobRun(t *testing.T, store portainer.DataStore) {
+	service := store.EdgeJobRun()
+
+	for _, run := range []portainer.EdgeJobRun{
+		{EdgeJobID: 1, EndpointID: 1, Version: 1, Status: portainer.EdgeJobRunFailed, ExitCode: 2, Attempts: 3},
+		{EdgeJobID: 1, EndpointID: 1, Version: 1, Status: portainer.EdgeJobRunSucceeded, Attempts: 1},
+		{EdgeJobID: 1, EndpointID: 2, Version: 1, Status: portainer.EdgeJobRunTimedOut, ExitCode: -1, Attempts: 1},
+		{EdgeJobID: 2, EndpointID: 1, Version: 1, Status: portainer.EdgeJobRunSucceeded, Attempts: 1},
+	} {
+		run := run
+		require.NoError(t, service.CreateEdgeJobRun(&run))
+		assert.NotZero(t, run.ID)
+	}
+
+	runs, err := service.EdgeJobRuns(1)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, portainer.EndpointID(2), runs[2].EndpointID)
+
+	runs, err = service.EdgeJobEndpointRuns(1, 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, 2, runs[0].ExitCode)
+
+	require.NoError(t, service.DeleteEdgeJobEndpointRuns(1, 1, runs[0].ID))
+
+	runs, err = service.EdgeJobEndpointRuns(1, 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, portainer.EdgeJobRunSucceeded, runs[0].Status)
+
+	require.NoError(t, service.DeleteEdgeJobRuns(1))
+
+	runs, err = service.EdgeJobRuns(1)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	runs, err = service.EdgeJobRuns(2)
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+}
+
+func testTunnelEvent(t *testing.T, store portainer.DataStore) {
+	service := store.TunnelEvent()
+
+	for _, event := range []portainer.TunnelEvent{
+		{EndpointID: 1, PreviousStatus: portainer.EdgeAgentIdle, Status: portainer.EdgeAgentManagementRequired, Port: 53412},
+		{EndpointID: 1, PreviousStatus: portainer.EdgeAgentManagementRequired, Status: portainer.EdgeAgentActive, Port: 53412},
+		{EndpointID: 2, PreviousStatus: portainer.EdgeAgentIdle, Status: portainer.EdgeAgentManagementRequired, Port: 50123},
+	} {
+		event := event
+		require.NoError(t, service.CreateTunnelEvent(&event))
+		assert.NotZero(t, event.ID)
+	}
+
+	events, err := service.TunnelEvents(1)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, portainer.EdgeAgentActive, events[1].Status)
+
+	require.NoError(t, service.DeleteTunnelEvents(1, events[0].ID))
+
+	events, err = service.TunnelEvents(1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, portainer.EdgeAgentManagementRequired, events[0].PreviousStatus)
+
+	events, err = service.TunnelEvents(2)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func testPrunePolicy(t *testing.T, store portainer.DataStore) {
+	service := store.PrunePolicy()
+
+	policy := &portainer.PrunePolicy{Name: "nightly", Interval: "24h", EndpointIDs: []portainer.EndpointID{1}}
+	require.NoError(t, service.CreatePrunePolicy(policy))
+	assert.Equal(t, portainer.PrunePolicyID(1), policy.ID)
+
+	policy.Rules.DanglingImagesOlderThanDays = 7
+	require.NoError(t, service.UpdatePrunePolicy(policy.ID, policy))
+
+	found, err := service.PrunePolicy(policy.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 7, found.Rules.DanglingImagesOlderThanDays)
+	assert.Equal(t, []portainer.EndpointID{1}, found.EndpointIDs)
+
+	require.NoError(t, service.DeletePrunePolicy(policy.ID))
+
+	policies, err := service.PrunePolicies()
+	require.NoError(t, err)
+	assert.Empty(t, policies)
+}
+
+func testPrunePolicyRun(t *testing.T, store portainer.DataStore) {
+	service := store.PrunePolicyRun()
+
+	for _, run := range []portainer.PrunePolicyRun{
+		{PrunePolicyID: 1, EndpointID: 1, DryRun: true, Images: []string{"sha256:abc"}, ReclaimedBytes: 1024},
+		{PrunePolicyID: 1, EndpointID: 1, Images: []string{"sha256:abc"}, ReclaimedBytes: 1024},
+		{PrunePolicyID: 1, EndpointID: 2, Volumes: []string{"data"}},
+		{PrunePolicyID: 2, EndpointID: 1},
+	} {
+		run := run
+		require.NoError(t, service.CreatePrunePolicyRun(&run))
+		assert.NotZero(t, run.ID)
+	}
+
+	runs, err := service.PrunePolicyRuns(1)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, []string{"data"}, runs[2].Volumes)
+
+	runs, err = service.PrunePolicyEndpointRuns(1, 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.True(t, runs[0].DryRun)
+
+	require.NoError(t, service.DeletePrunePolicyEndpointRuns(1, 1, runs[0].ID))
+
+	runs, err = service.PrunePolicyEndpointRuns(1, 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.False(t, runs[0].DryRun)
+
+	require.NoError(t, service.DeletePrunePolicyRuns(1))
+
+	runs, err = service.PrunePolicyRuns(1)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	runs, err = service.PrunePolicyRuns(2)
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+}
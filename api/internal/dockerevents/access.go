@@ -0,0 +1,125 @@
+package dockerevents
+
+import (
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/internal/stackutils"
+)
+
+const (
+	labelForDockerSwarmStackName   = "com.docker.stack.namespace"
+	labelForDockerServiceID        = "com.docker.swarm.service.id"
+	labelForDockerComposeStackName = "com.docker.compose.project"
+
+	// resourceControlsRefreshInterval is the maximum age of the resource controls used to filter the events of a subscriber
+	resourceControlsRefreshInterval = 5 * time.Second
+)
+
+// resourceControlTypes associates the Docker event types to the type of their resource controls.
+// Events of other types (daemon, node and plugin) are only visible to administrators.
+var resourceControlTypes = map[string]portainer.ResourceControlType{
+	events.ContainerEventType: portainer.ContainerResourceControl,
+	events.ImageEventType:     portainer.ImageResourceControl,
+	events.VolumeEventType:    portainer.VolumeResourceControl,
+	events.NetworkEventType:   portainer.NetworkResourceControl,
+	events.ServiceEventType:   portainer.ServiceResourceControl,
+	events.SecretEventType:    portainer.SecretResourceControl,
+	events.ConfigEventType:    portainer.ConfigResourceControl,
+}
+
+// AccessContext decides which events a user can receive. Administrators receive every event,
+// other users only receive the events of the resources they can access through their resource control,
+// or through the resource control of the service or the stack the resource belongs to.
+// Images without resource control are public, as they are in the image list of the Docker proxy.
+// An AccessContext must only be used by a single goroutine.
+type AccessContext struct {
+	dataStore        portainer.DataStore
+	userID           portainer.UserID
+	userTeamIDs      []portainer.TeamID
+	isAdmin          bool
+	resourceControls []portainer.ResourceControl
+	loadedAt         time.Time
+}
+
+// NewAccessContext creates the access context of a user
+func NewAccessContext(dataStore portainer.DataStore, userID portainer.UserID, isAdmin bool) (*AccessContext, error) {
+	access := &AccessContext{
+		dataStore:   dataStore,
+		userID:      userID,
+		userTeamIDs: make([]portainer.TeamID, 0),
+		isAdmin:     isAdmin,
+	}
+
+	if isAdmin {
+		return access, nil
+	}
+
+	memberships, err := dataStore.TeamMembership().TeamMembershipsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, membership := range memberships {
+		access.userTeamIDs = append(access.userTeamIDs, membership.TeamID)
+	}
+
+	return access, nil
+}
+
+// CanAccess returns true when the user can receive the event
+func (access *AccessContext) CanAccess(event *Event) (bool, error) {
+	if access.isAdmin {
+		return true, nil
+	}
+
+	resourceType, ok := resourceControlTypes[event.Type]
+	if !ok {
+		return false, nil
+	}
+
+	if time.Since(access.loadedAt) > resourceControlsRefreshInterval {
+		resourceControls, err := access.dataStore.ResourceControl().ResourceControls()
+		if err != nil {
+			return false, err
+		}
+
+		access.resourceControls = resourceControls
+		access.loadedAt = time.Now()
+	}
+
+	resourceControl := access.findResourceControl(event, resourceType)
+	if resourceControl == nil {
+		return event.Type == events.ImageEventType, nil
+	}
+
+	return authorization.UserCanAccessResource(access.userID, access.userTeamIDs, resourceControl), nil
+}
+
+func (access *AccessContext) findResourceControl(event *Event, resourceType portainer.ResourceControlType) *portainer.ResourceControl {
+	resourceControl := authorization.GetResourceControlByResourceIDAndType(event.resourceControlID, resourceType, access.resourceControls)
+	if resourceControl != nil {
+		return resourceControl
+	}
+
+	if serviceID := event.Attributes[labelForDockerServiceID]; serviceID != "" {
+		resourceControl = authorization.GetResourceControlByResourceIDAndType(serviceID, portainer.ServiceResourceControl, access.resourceControls)
+		if resourceControl != nil {
+			return resourceControl
+		}
+	}
+
+	stackName := event.Attributes[labelForDockerSwarmStackName]
+	if stackName == "" {
+		stackName = event.Attributes[labelForDockerComposeStackName]
+	}
+
+	if stackName != "" {
+		stackResourceID := stackutils.ResourceControlID(event.EndpointID, stackName)
+		return authorization.GetResourceControlByResourceIDAndType(stackResourceID, portainer.StackResourceControl, access.resourceControls)
+	}
+
+	return nil
+}
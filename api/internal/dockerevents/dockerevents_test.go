@@ -0,0 +1,160 @@
+package dockerevents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	n := &normalizer{endpointID: 3, dockerID: "engine"}
+
+	event := n.normalize(events.Message{
+		Type:     events.VolumeEventType,
+		Action:   "create",
+		Actor:    events.Actor{ID: "data", Attributes: map[string]string{"driver": "local"}},
+		Scope:    "local",
+		TimeNano: 42,
+	})
+
+	assert.Equal(t, portainer.EndpointID(3), event.EndpointID)
+	assert.Equal(t, "data", event.Name)
+	assert.Equal(t, "data_engine", event.resourceControlID)
+	assert.Equal(t, int64(42), event.Time)
+}
+
+func TestNormalize_ImageEvents(t *testing.T) {
+	n := &normalizer{endpointID: 3}
+
+	imageEvent := func(action, id, name string) Event {
+		return n.normalize(events.Message{
+			Type:   events.ImageEventType,
+			Action: action,
+			Actor:  events.Actor{ID: id, Attributes: map[string]string{"name": name}},
+		})
+	}
+
+	assert.Equal(t, "sha256:abc", imageEvent("tag", "sha256:abc", "app:1.0").resourceControlID)
+	assert.Equal(t, "sha256:abc", imageEvent("push", "app:1.0", "app:1.0").resourceControlID, "the identifier should be resolved from the tag event")
+
+	assert.Equal(t, "sha256:abc", imageEvent("untag", "sha256:abc", "app:1.0").resourceControlID)
+	assert.Equal(t, "app:1.0", imageEvent("push", "app:1.0", "app:1.0").resourceControlID, "an untagged name should not be resolved")
+
+	imageEvent("tag", "sha256:abc", "app:2.0")
+	assert.Equal(t, "sha256:abc", imageEvent("delete", "sha256:abc", "").resourceControlID, "deleted images should be resolved without inspecting them")
+	assert.Empty(t, n.imageIDs)
+}
+
+func TestAccessContext(t *testing.T) {
+	access := &AccessContext{
+		userID:      1,
+		userTeamIDs: []portainer.TeamID{7},
+		resourceControls: []portainer.ResourceControl{
+			{ResourceID: "mine", Type: portainer.ContainerResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 1}}},
+			{ResourceID: "other", Type: portainer.ContainerResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 2}}},
+			{ResourceID: "svc", Type: portainer.ServiceResourceControl, TeamAccesses: []portainer.TeamResourceAccess{{TeamID: 7}}},
+			{ResourceID: "2_web", Type: portainer.StackResourceControl, Public: true},
+			{ResourceID: "sha256:owned", Type: portainer.ImageResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 2}}},
+		},
+		loadedAt: time.Now(),
+	}
+
+	cases := []struct {
+		description string
+		event       Event
+		expected    bool
+	}{
+		{
+			description: "owned container",
+			event:       Event{EndpointID: 2, Type: events.ContainerEventType, resourceControlID: "mine"},
+			expected:    true,
+		},
+		{
+			description: "container of another user",
+			event:       Event{EndpointID: 2, Type: events.ContainerEventType, resourceControlID: "other"},
+			expected:    false,
+		},
+		{
+			description: "container without resource control",
+			event:       Event{EndpointID: 2, Type: events.ContainerEventType, resourceControlID: "unknown"},
+			expected:    false,
+		},
+		{
+			description: "task of a service accessible to a team of the user",
+			event:       Event{EndpointID: 2, Type: events.ContainerEventType, resourceControlID: "unknown", Attributes: map[string]string{labelForDockerServiceID: "svc"}},
+			expected:    true,
+		},
+		{
+			description: "container of a public stack",
+			event:       Event{EndpointID: 2, Type: events.ContainerEventType, resourceControlID: "unknown", Attributes: map[string]string{labelForDockerComposeStackName: "web"}},
+			expected:    true,
+		},
+		{
+			description: "container of a stack with the same name on another endpoint",
+			event:       Event{EndpointID: 3, Type: events.ContainerEventType, resourceControlID: "unknown", Attributes: map[string]string{labelForDockerComposeStackName: "web"}},
+			expected:    false,
+		},
+		{
+			description: "image without resource control",
+			event:       Event{EndpointID: 2, Type: events.ImageEventType, resourceControlID: "sha256:public"},
+			expected:    true,
+		},
+		{
+			description: "image owned by another user",
+			event:       Event{EndpointID: 2, Type: events.ImageEventType, resourceControlID: "sha256:owned"},
+			expected:    false,
+		},
+		{
+			description: "daemon event",
+			event:       Event{EndpointID: 2, Type: events.DaemonEventType, resourceControlID: "mine"},
+			expected:    false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.description, func(t *testing.T) {
+			canAccess, err := access.CanAccess(&tt.event)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, canAccess)
+		})
+	}
+
+	admin := &AccessContext{isAdmin: true}
+	canAccess, err := admin.CanAccess(&Event{Type: events.DaemonEventType})
+	assert.NoError(t, err)
+	assert.True(t, canAccess)
+}
+
+func TestSubscription(t *testing.T) {
+	service := NewService(2, nil, nil, nil, context.Background())
+	admin := &AccessContext{isAdmin: true}
+
+	service.publish(Event{EndpointID: 1, Type: events.ContainerEventType, Action: "create"})
+	service.publish(Event{EndpointID: 1, Type: events.ImageEventType, Action: "pull"})
+	service.publish(Event{EndpointID: 2, Type: events.ContainerEventType, Action: "start"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	subscription := service.Subscribe(Filter{EndpointIDs: []portainer.EndpointID{1, 2}, Types: []string{events.ContainerEventType}}, admin, true)
+
+	// the first event fell out of the history
+	event, ok := subscription.Next(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "start", event.Action)
+
+	service.publish(Event{EndpointID: 3, Type: events.ContainerEventType, Action: "stop"})
+	service.publish(Event{EndpointID: 1, Type: events.ContainerEventType, Action: "die"})
+
+	event, ok = subscription.Next(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "die", event.Action)
+
+	service.Unsubscribe(subscription)
+	_, ok = subscription.Next(ctx)
+	assert.False(t, ok)
+}
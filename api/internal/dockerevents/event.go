@@ -0,0 +1,126 @@
+package dockerevents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/client"
+	portainer "github.com/portainer/portainer/api"
+)
+
+// Event is a Docker event normalised across endpoints
+type Event struct {
+	// Endpoint identifier of the Docker engine that emitted the event
+	EndpointID portainer.EndpointID `json:"EndpointId" example:"1"`
+	// Type of the Docker object: container, image, volume, network, service, secret, config, node, plugin or daemon
+	Type string `json:"Type" example:"container"`
+	// Action performed on the object
+	Action string `json:"Action" example:"start"`
+	// Identifier of the Docker object
+	ResourceID string `json:"ResourceId" example:"617c5f22bb9b023d6daab7cba43a57576f83492867bc767d1c59416b065e5f08"`
+	// Name of the Docker object, when available
+	Name string `json:"Name,omitempty" example:"nginx"`
+	// Attributes of the Docker object, the labels of a container for instance
+	Attributes map[string]string `json:"Attributes,omitempty"`
+	// Scope of the event, local for engine events and swarm for cluster events
+	Scope string `json:"Scope,omitempty" example:"local"`
+	// Unix timestamp of the event, in nanoseconds
+	Time int64 `json:"Time" example:"1613428352133542231"`
+
+	// resourceControlID is the identifier used by the resource control of the Docker object
+	resourceControlID string
+}
+
+// maxCachedImageNames is the number of image names whose identifier is kept by a normalizer
+const maxCachedImageNames = 1000
+
+// normalizer turns the Docker events of an endpoint into Event objects
+type normalizer struct {
+	endpointID   portainer.EndpointID
+	dockerID     string
+	dockerClient *client.Client
+	// imageIDs associates the image names seen in the pull and tag events to the identifier of the image
+	imageIDs map[string]string
+}
+
+func (n *normalizer) normalize(message events.Message) Event {
+	event := Event{
+		EndpointID:        n.endpointID,
+		Type:              message.Type,
+		Action:            message.Action,
+		ResourceID:        message.Actor.ID,
+		Name:              message.Actor.Attributes["name"],
+		Attributes:        message.Actor.Attributes,
+		Scope:             message.Scope,
+		Time:              message.TimeNano,
+		resourceControlID: message.Actor.ID,
+	}
+
+	switch message.Type {
+	case events.VolumeEventType:
+		// volume resource controls are scoped to the Docker engine, volume names being only unique per engine
+		event.Name = message.Actor.ID
+		event.resourceControlID = fmt.Sprintf("%s_%s", message.Actor.ID, n.dockerID)
+	case events.ImageEventType:
+		event.resourceControlID = n.imageID(message)
+	}
+
+	return event
+}
+
+// imageID returns the identifier of the image of an image event, used by image resource controls.
+// Most image events reference the image by its identifier, the names are then recorded from the tag events.
+// Pull and push events reference the image by name: the image is inspected on pull, as the name may now
+// designate another image, and resolved from the recorded names on push.
+func (n *normalizer) imageID(message events.Message) string {
+	if n.imageIDs == nil {
+		n.imageIDs = make(map[string]string)
+	}
+
+	name := message.Actor.Attributes["name"]
+
+	if strings.HasPrefix(message.Actor.ID, "sha256:") {
+		switch message.Action {
+		case "tag":
+			n.cacheImageName(name, message.Actor.ID)
+		case "untag":
+			delete(n.imageIDs, name)
+		case "delete":
+			for cachedName, imageID := range n.imageIDs {
+				if imageID == message.Actor.ID {
+					delete(n.imageIDs, cachedName)
+				}
+			}
+		}
+
+		return message.Actor.ID
+	}
+
+	if imageID, ok := n.imageIDs[message.Actor.ID]; ok && message.Action != "pull" {
+		return imageID
+	}
+
+	if n.dockerClient != nil {
+		image, _, err := n.dockerClient.ImageInspectWithRaw(context.Background(), message.Actor.ID)
+		if err == nil {
+			n.cacheImageName(message.Actor.ID, image.ID)
+			return image.ID
+		}
+	}
+
+	return message.Actor.ID
+}
+
+func (n *normalizer) cacheImageName(name, imageID string) {
+	if name == "" {
+		return
+	}
+
+	if len(n.imageIDs) >= maxCachedImageNames {
+		n.imageIDs = make(map[string]string)
+	}
+
+	n.imageIDs[name] = imageID
+}
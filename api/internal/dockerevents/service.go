@@ -0,0 +1,261 @@
+package dockerevents
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/docker"
+	"github.com/portainer/portainer/api/internal/snapshot"
+)
+
+// watchInterval is the delay between two reconciliations of the watched endpoints
+const watchInterval = 10 * time.Second
+
+// Service subscribes to the Docker events of every reachable Docker endpoint and
+// dispatches them to the subscribers of the aggregated events stream.
+// Edge endpoints are only watched while their tunnel is active.
+type Service struct {
+	dataStore            portainer.DataStore
+	clientFactory        *docker.ClientFactory
+	reverseTunnelService portainer.ReverseTunnelService
+	historySize          int
+	shutdownCtx          context.Context
+
+	mu            sync.Mutex
+	watchers      map[portainer.EndpointID]*watcher
+	subscriptions map[*Subscription]struct{}
+	history       []Event
+}
+
+type watcher struct {
+	cancel context.CancelFunc
+}
+
+// NewService creates a new instance of a service.
+// The historySize most recent events are kept in memory to be replayed to new subscribers, 0 disables the history.
+func NewService(historySize int, dataStore portainer.DataStore, clientFactory *docker.ClientFactory, reverseTunnelService portainer.ReverseTunnelService, shutdownCtx context.Context) *Service {
+	if historySize < 0 {
+		historySize = 0
+	}
+
+	return &Service{
+		dataStore:            dataStore,
+		clientFactory:        clientFactory,
+		reverseTunnelService: reverseTunnelService,
+		historySize:          historySize,
+		shutdownCtx:          shutdownCtx,
+		watchers:             make(map[portainer.EndpointID]*watcher),
+		subscriptions:        make(map[*Subscription]struct{}),
+		history:              make([]Event, 0, historySize),
+	}
+}
+
+// Start starts a background routine watching the events of the reachable endpoints until the shutdown context is done
+func (service *Service) Start() {
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		for {
+			service.reconcile()
+
+			select {
+			case <-ticker.C:
+			case <-service.shutdownCtx.Done():
+				service.stopWatchers()
+				return
+			}
+		}
+	}()
+}
+
+// Subscribe registers a subscriber receiving the events matching the filter that it can access.
+// When replayHistory is true, the recent events matching the filter are delivered first.
+func (service *Service) Subscribe(filter Filter, access *AccessContext, replayHistory bool) *Subscription {
+	subscription := &Subscription{
+		filter: filter,
+		access: access,
+		events: make(chan Event, service.historySize+subscriptionBufferSize),
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if replayHistory {
+		for _, event := range service.history {
+			subscription.deliver(event)
+		}
+	}
+
+	service.subscriptions[subscription] = struct{}{}
+
+	return subscription
+}
+
+// Unsubscribe stops the delivery of events to the subscriber
+func (service *Service) Unsubscribe(subscription *Subscription) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if _, ok := service.subscriptions[subscription]; ok {
+		delete(service.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// publish records the event in the history and delivers it to the subscribers
+func (service *Service) publish(event Event) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.historySize > 0 {
+		if len(service.history) == service.historySize {
+			service.history = append(service.history[:0], service.history[1:]...)
+		}
+		service.history = append(service.history, event)
+	}
+
+	for subscription := range service.subscriptions {
+		subscription.deliver(event)
+	}
+}
+
+// reconcile watches the reachable endpoints that are not watched yet and stops watching the endpoints
+// that were removed or whose Edge tunnel is no longer active
+func (service *Service) reconcile() {
+	endpoints, err := service.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		log.Printf("[WARN] [internal,dockerevents] [message: unable to retrieve endpoints] [error: %s]", err)
+		return
+	}
+
+	reachable := make(map[portainer.EndpointID]bool)
+	for idx := range endpoints {
+		endpoint := &endpoints[idx]
+		if !service.isReachable(endpoint) {
+			continue
+		}
+
+		reachable[endpoint.ID] = true
+
+		service.mu.Lock()
+		_, watched := service.watchers[endpoint.ID]
+		service.mu.Unlock()
+
+		if !watched {
+			service.watch(endpoint)
+		}
+	}
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	for endpointID, w := range service.watchers {
+		if !reachable[endpointID] {
+			w.cancel()
+			delete(service.watchers, endpointID)
+		}
+	}
+}
+
+func (service *Service) isReachable(endpoint *portainer.Endpoint) bool {
+	switch endpoint.Type {
+	case portainer.DockerEnvironment, portainer.AgentOnDockerEnvironment:
+		return true
+	case portainer.EdgeAgentOnDockerEnvironment:
+		return service.reverseTunnelService.GetTunnelDetails(endpoint.ID).Status == portainer.EdgeAgentActive
+	}
+
+	return false
+}
+
+// watch subscribes to the Docker events of the endpoint until the watcher is cancelled or the subscription fails.
+// A failed endpoint is watched again on the next reconciliation.
+func (service *Service) watch(endpoint *portainer.Endpoint) {
+	dockerClient, err := service.clientFactory.CreateClient(endpoint, "")
+	if err != nil {
+		log.Printf("[WARN] [internal,dockerevents] [endpoint_id: %d] [message: unable to create Docker client] [error: %s]", endpoint.ID, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(service.shutdownCtx)
+	w := &watcher{cancel: cancel}
+
+	service.mu.Lock()
+	service.watchers[endpoint.ID] = w
+	service.mu.Unlock()
+
+	go func() {
+		defer dockerClient.Close()
+		defer service.removeWatcher(endpoint.ID, w)
+
+		n := &normalizer{
+			endpointID:   endpoint.ID,
+			dockerID:     dockerID(ctx, endpoint, dockerClient),
+			dockerClient: dockerClient,
+		}
+
+		messages, errs := dockerClient.Events(ctx, types.EventsOptions{})
+		for {
+			select {
+			case message := <-messages:
+				service.publish(n.normalize(message))
+			case err := <-errs:
+				if err != nil && ctx.Err() == nil {
+					log.Printf("[WARN] [internal,dockerevents] [endpoint_id: %d] [message: stopped watching the Docker events] [error: %s]", endpoint.ID, err)
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (service *Service) removeWatcher(endpointID portainer.EndpointID, w *watcher) {
+	w.cancel()
+
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.watchers[endpointID] == w {
+		delete(service.watchers, endpointID)
+	}
+}
+
+func (service *Service) stopWatchers() {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	for endpointID, w := range service.watchers {
+		w.cancel()
+		delete(service.watchers, endpointID)
+	}
+}
+
+// dockerID returns the identifier of the Docker engine, used by the resource controls of volumes
+func dockerID(ctx context.Context, endpoint *portainer.Endpoint, dockerClient *client.Client) string {
+	if len(endpoint.Snapshots) > 0 {
+		dockerID, err := snapshot.FetchDockerID(endpoint.Snapshots[0])
+		if err == nil {
+			return dockerID
+		}
+	}
+
+	info, err := dockerClient.Info(ctx)
+	if err != nil {
+		log.Printf("[WARN] [internal,dockerevents] [endpoint_id: %d] [message: unable to retrieve Docker engine identifier] [error: %s]", endpoint.ID, err)
+		return ""
+	}
+
+	if info.Swarm.Cluster != nil {
+		return info.Swarm.Cluster.ID
+	}
+
+	return info.ID
+}
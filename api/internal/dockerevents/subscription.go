@@ -0,0 +1,90 @@
+package dockerevents
+
+import (
+	"context"
+	"log"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// subscriptionBufferSize is the number of events buffered for a subscriber, on top of the replayed history.
+// Events are dropped for the subscribers that do not keep up.
+const subscriptionBufferSize = 256
+
+// Filter selects the events delivered to a subscriber
+type Filter struct {
+	// EndpointIDs are the endpoints the subscriber receives the events of
+	EndpointIDs []portainer.EndpointID
+	// Types are the Docker object types the subscriber receives the events of, all types when empty
+	Types []string
+}
+
+func (filter *Filter) matches(event *Event) bool {
+	endpointMatch := false
+	for _, endpointID := range filter.EndpointIDs {
+		if endpointID == event.EndpointID {
+			endpointMatch = true
+			break
+		}
+	}
+
+	if !endpointMatch {
+		return false
+	}
+
+	if len(filter.Types) == 0 {
+		return true
+	}
+
+	for _, eventType := range filter.Types {
+		if eventType == event.Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscription delivers the events matching a filter that the subscriber can access
+type Subscription struct {
+	filter Filter
+	access *AccessContext
+	events chan Event
+}
+
+// Next blocks until the next event the subscriber can access is available.
+// It returns false once the context is done or the subscription is closed.
+func (subscription *Subscription) Next(ctx context.Context) (Event, bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return Event{}, false
+		case event, ok := <-subscription.events:
+			if !ok {
+				return Event{}, false
+			}
+
+			canAccess, err := subscription.access.CanAccess(&event)
+			if err != nil {
+				log.Printf("[WARN] [internal,dockerevents] [endpoint_id: %d] [message: unable to verify access to event, event discarded] [error: %s]", event.EndpointID, err)
+				continue
+			}
+
+			if canAccess {
+				return event, true
+			}
+		}
+	}
+}
+
+// deliver queues the event when it matches the filter, the event is dropped when the subscriber does not keep up
+func (subscription *Subscription) deliver(event Event) {
+	if !subscription.filter.matches(&event) {
+		return
+	}
+
+	select {
+	case subscription.events <- event:
+	default:
+	}
+}
@@ -0,0 +1,92 @@
+package edge
+
+import (
+	"errors"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// ValidateEdgeAsyncCommand verifies that a command carries the fields required by its type
+// and that its type is supported by the platform of the endpoint
+func ValidateEdgeAsyncCommand(command *portainer.EdgeAsyncCommand, endpoint *portainer.Endpoint) error {
+	switch command.Type {
+	case portainer.EdgeAsyncCommandContainerStart, portainer.EdgeAsyncCommandContainerStop, portainer.EdgeAsyncCommandContainerRestart:
+		if endpoint.Type != portainer.EdgeAgentOnDockerEnvironment {
+			return errors.New("container commands are only supported on Edge Docker endpoints")
+		}
+		if command.ContainerID == "" {
+			return errors.New("a container identifier is required")
+		}
+	case portainer.EdgeAsyncCommandImagePull:
+		if endpoint.Type != portainer.EdgeAgentOnDockerEnvironment {
+			return errors.New("image pull commands are only supported on Edge Docker endpoints")
+		}
+		if command.Image == "" {
+			return errors.New("an image is required")
+		}
+	case portainer.EdgeAsyncCommandStackRedeploy:
+		if command.EdgeStackID == 0 {
+			return errors.New("an edge stack identifier is required")
+		}
+	case portainer.EdgeAsyncCommandSnapshotUpload:
+	default:
+		return errors.New("invalid command type")
+	}
+
+	return nil
+}
+
+// IsEdgeAsyncCommandCompleted returns whether the agent acknowledged the command
+func IsEdgeAsyncCommandCompleted(command *portainer.EdgeAsyncCommand) bool {
+	return command.Status == portainer.EdgeAsyncCommandStatusSucceeded || command.Status == portainer.EdgeAsyncCommandStatusFailed
+}
+
+// EdgeAsyncCommandsToDeliver returns the commands to deliver to the agent, in creation order.
+// Delivered commands are delivered again until the agent acknowledges them, agents must
+// ignore the commands they already ran.
+func EdgeAsyncCommandsToDeliver(commands []portainer.EdgeAsyncCommand) []portainer.EdgeAsyncCommand {
+	pending := make([]portainer.EdgeAsyncCommand, 0)
+
+	for _, command := range commands {
+		if !IsEdgeAsyncCommandCompleted(&command) {
+			pending = append(pending, command)
+		}
+	}
+
+	return pending
+}
+
+// AcknowledgeEdgeAsyncCommand records the result reported by the agent. It returns false when the
+// command was already acknowledged, so that a result reported twice is only applied once.
+func AcknowledgeEdgeAsyncCommand(command *portainer.EdgeAsyncCommand, succeeded bool, output, commandError string, now int64) bool {
+	if IsEdgeAsyncCommandCompleted(command) {
+		return false
+	}
+
+	command.Status = portainer.EdgeAsyncCommandStatusFailed
+	if succeeded {
+		command.Status = portainer.EdgeAsyncCommandStatusSucceeded
+	}
+	command.Output = output
+	command.Error = commandError
+	command.CompletionDate = now
+
+	return true
+}
+
+// EdgeAsyncCommandsToPrune returns the oldest completed commands to delete so that at most
+// maxEntries completed commands are kept, commands must be ordered by creation
+func EdgeAsyncCommandsToPrune(commands []portainer.EdgeAsyncCommand, maxEntries int) []portainer.EdgeAsyncCommand {
+	completed := make([]portainer.EdgeAsyncCommand, 0)
+	for _, command := range commands {
+		if IsEdgeAsyncCommandCompleted(&command) {
+			completed = append(completed, command)
+		}
+	}
+
+	if len(completed) <= maxEntries {
+		return nil
+	}
+
+	return completed[:len(completed)-maxEntries]
+}
@@ -0,0 +1,66 @@
+package edge
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEdgeAsyncCommand(t *testing.T) {
+	docker := &portainer.Endpoint{Type: portainer.EdgeAgentOnDockerEnvironment}
+	kubernetes := &portainer.Endpoint{Type: portainer.EdgeAgentOnKubernetesEnvironment}
+
+	assert.NoError(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandContainerRestart, ContainerID: "a1b2"}, docker))
+	assert.Error(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandContainerRestart}, docker))
+	assert.Error(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandContainerStop, ContainerID: "a1b2"}, kubernetes))
+	assert.NoError(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandImagePull, Image: "nginx"}, docker))
+	assert.Error(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandImagePull}, docker))
+	assert.NoError(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandStackRedeploy, EdgeStackID: 2}, kubernetes))
+	assert.Error(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandStackRedeploy}, docker))
+	assert.NoError(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: portainer.EdgeAsyncCommandSnapshotUpload}, kubernetes))
+	assert.Error(t, ValidateEdgeAsyncCommand(&portainer.EdgeAsyncCommand{Type: 42}, docker))
+}
+
+func TestEdgeAsyncCommandsToDeliver(t *testing.T) {
+	commands := []portainer.EdgeAsyncCommand{
+		{ID: 1, Status: portainer.EdgeAsyncCommandStatusSucceeded},
+		{ID: 2, Status: portainer.EdgeAsyncCommandStatusDelivered},
+		{ID: 3, Status: portainer.EdgeAsyncCommandStatusFailed},
+		{ID: 4, Status: portainer.EdgeAsyncCommandStatusPending},
+	}
+
+	pending := EdgeAsyncCommandsToDeliver(commands)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, 2, pending[0].ID)
+	assert.Equal(t, 4, pending[1].ID)
+}
+
+func TestAcknowledgeEdgeAsyncCommand(t *testing.T) {
+	command := &portainer.EdgeAsyncCommand{Status: portainer.EdgeAsyncCommandStatusDelivered}
+
+	assert.True(t, AcknowledgeEdgeAsyncCommand(command, false, "", "no such container", 1587399610))
+	assert.Equal(t, portainer.EdgeAsyncCommandStatusFailed, command.Status)
+	assert.Equal(t, "no such container", command.Error)
+	assert.Equal(t, int64(1587399610), command.CompletionDate)
+
+	assert.False(t, AcknowledgeEdgeAsyncCommand(command, true, "", "", 1587399620))
+	assert.Equal(t, portainer.EdgeAsyncCommandStatusFailed, command.Status)
+	assert.Equal(t, int64(1587399610), command.CompletionDate)
+}
+
+func TestEdgeAsyncCommandsToPrune(t *testing.T) {
+	commands := []portainer.EdgeAsyncCommand{
+		{ID: 1, Status: portainer.EdgeAsyncCommandStatusSucceeded},
+		{ID: 2, Status: portainer.EdgeAsyncCommandStatusPending},
+		{ID: 3, Status: portainer.EdgeAsyncCommandStatusFailed},
+		{ID: 4, Status: portainer.EdgeAsyncCommandStatusSucceeded},
+	}
+
+	assert.Empty(t, EdgeAsyncCommandsToPrune(commands, 3))
+
+	pruned := EdgeAsyncCommandsToPrune(commands, 1)
+	assert.Len(t, pruned, 2)
+	assert.Equal(t, 1, pruned[0].ID)
+	assert.Equal(t, 3, pruned[1].ID)
+}
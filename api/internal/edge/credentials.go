@@ -0,0 +1,105 @@
+package edge
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+var (
+	// ErrEdgeDeviceRevoked is returned when a revoked edge device tries to reach Portainer
+	ErrEdgeDeviceRevoked = errors.New("revoked Edge device")
+	// ErrInvalidEdgeKeySecret is returned when an agent presents neither the current nor the previous edge key
+	ErrInvalidEdgeKeySecret = errors.New("invalid Edge key")
+)
+
+// EdgeKeyInfo represents the information embedded in an edge key
+type EdgeKeyInfo struct {
+	PortainerURL            string
+	TunnelServerAddr        string
+	TunnelServerFingerprint string
+	EndpointID              portainer.EndpointID
+}
+
+// ParseEdgeKey decodes an edge key, in the portainer_instance_url|tunnel_server_addr|tunnel_server_fingerprint|endpoint_ID format
+func ParseEdgeKey(key string) (*EdgeKeyInfo, error) {
+	decodedKey, err := base64.RawStdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInformation := strings.Split(string(decodedKey), "|")
+	if len(keyInformation) != 4 {
+		return nil, errors.New("invalid key format")
+	}
+
+	endpointID, err := strconv.Atoi(keyInformation[3])
+	if err != nil {
+		return nil, errors.New("invalid key format")
+	}
+
+	return &EdgeKeyInfo{
+		PortainerURL:            keyInformation[0],
+		TunnelServerAddr:        keyInformation[1],
+		TunnelServerFingerprint: keyInformation[2],
+		EndpointID:              portainer.EndpointID(endpointID),
+	}, nil
+}
+
+// ValidateEdgeKeySecret checks the edge key secret presented by the agent of an endpoint. The previous secret
+// is accepted until the end of the grace period, any secret is accepted when the key was never rotated.
+func ValidateEdgeKeySecret(credentials *portainer.EndpointEdgeCredentials, secret string, now time.Time) error {
+	if credentials.Revoked {
+		return ErrEdgeDeviceRevoked
+	}
+
+	if credentials.Secret == "" || secretEquals(secret, credentials.Secret) {
+		return nil
+	}
+
+	if now.Unix() < credentials.PreviousSecretExpiry && secretEquals(secret, credentials.PreviousSecret) {
+		return nil
+	}
+
+	return ErrInvalidEdgeKeySecret
+}
+
+// RotateEdgeCredentials replaces the secret of an edge key, the current secret is accepted for the grace period.
+// The rotation of a revoked edge key clears the revocation without grace period.
+func RotateEdgeCredentials(credentials *portainer.EndpointEdgeCredentials, secret string, gracePeriod time.Duration, now time.Time) {
+	credentials.PreviousSecret = credentials.Secret
+	credentials.PreviousSecretExpiry = now.Add(gracePeriod).Unix()
+	if credentials.Revoked || gracePeriod <= 0 {
+		credentials.PreviousSecret = ""
+		credentials.PreviousSecretExpiry = 0
+	}
+
+	credentials.Secret = secret
+	credentials.RotationDate = now.Unix()
+	credentials.Revoked = false
+}
+
+// RevokeEdgeCredentials rejects the agent of an endpoint until its edge key is rotated
+func RevokeEdgeCredentials(credentials *portainer.EndpointEdgeCredentials) {
+	credentials.Revoked = true
+	credentials.PreviousSecret = ""
+	credentials.PreviousSecretExpiry = 0
+}
+
+// ValidateFleetKey returns true when the key and the secret presented by an agent match the fleet key of the instance
+func ValidateFleetKey(fleetKey, fleetKeySecret, key, secret string) bool {
+	if fleetKey == "" || fleetKeySecret == "" {
+		return false
+	}
+
+	return secretEquals(key, fleetKey) && secretEquals(secret, fleetKeySecret)
+}
+
+func secretEquals(secret, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(expected)) == 1
+}
@@ -0,0 +1,64 @@
+package edge
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEdgeKey(t *testing.T) {
+	is := assert.New(t)
+
+	info, err := ParseEdgeKey(base64.RawStdEncoding.EncodeToString([]byte("https://portainer.example.com|portainer.example.com:8000|fingerprint|3")))
+	is.NoError(err)
+	is.Equal(&EdgeKeyInfo{
+		PortainerURL:            "https://portainer.example.com",
+		TunnelServerAddr:        "portainer.example.com:8000",
+		TunnelServerFingerprint: "fingerprint",
+		EndpointID:              3,
+	}, info)
+
+	_, err = ParseEdgeKey(base64.RawStdEncoding.EncodeToString([]byte("https://portainer.example.com|portainer.example.com:8000|fingerprint|3|secret")))
+	is.Error(err, "the secret of an edge key is never part of the key")
+
+	_, err = ParseEdgeKey(base64.RawStdEncoding.EncodeToString([]byte("https://portainer.example.com|fingerprint")))
+	is.Error(err)
+}
+
+func TestEdgeCredentialsRotation(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Unix(10000, 0)
+	credentials := &portainer.EndpointEdgeCredentials{}
+	is.NoError(ValidateEdgeKeySecret(credentials, "", now), "edge keys never rotated have no secret")
+
+	RotateEdgeCredentials(credentials, "first", time.Hour, now)
+	is.NoError(ValidateEdgeKeySecret(credentials, "first", now))
+	is.NoError(ValidateEdgeKeySecret(credentials, "", now), "the key without secret should be accepted during the grace period")
+	is.Equal(ErrInvalidEdgeKeySecret, ValidateEdgeKeySecret(credentials, "", now.Add(2*time.Hour)))
+	is.Equal(ErrInvalidEdgeKeySecret, ValidateEdgeKeySecret(credentials, "other", now))
+
+	RotateEdgeCredentials(credentials, "second", time.Hour, now.Add(2*time.Hour))
+	is.NoError(ValidateEdgeKeySecret(credentials, "first", now.Add(2*time.Hour)))
+	is.NoError(ValidateEdgeKeySecret(credentials, "second", now.Add(4*time.Hour)))
+	is.Equal(ErrInvalidEdgeKeySecret, ValidateEdgeKeySecret(credentials, "first", now.Add(4*time.Hour)))
+
+	RevokeEdgeCredentials(credentials)
+	is.Equal(ErrEdgeDeviceRevoked, ValidateEdgeKeySecret(credentials, "second", now))
+
+	RotateEdgeCredentials(credentials, "third", time.Hour, now)
+	is.NoError(ValidateEdgeKeySecret(credentials, "third", now))
+	is.Equal(ErrInvalidEdgeKeySecret, ValidateEdgeKeySecret(credentials, "second", now), "a revoked key should not get a grace period")
+}
+
+func TestValidateFleetKey(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(ValidateFleetKey("key", "secret", "key", "secret"))
+	is.False(ValidateFleetKey("key", "secret", "key", ""), "the secret of the fleet key is mandatory")
+	is.False(ValidateFleetKey("key", "secret", "other", "secret"))
+	is.False(ValidateFleetKey("", "", "", ""), "enrolment is disabled without a fleet key")
+}
@@ -0,0 +1,104 @@
+package edge
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// ValidateEdgeStackDeployment validates the deployment type of an edge stack and its namespace
+func ValidateEdgeStackDeployment(deploymentType portainer.EdgeStackDeploymentType, namespace string) error {
+	switch deploymentType {
+	case portainer.EdgeStackDeploymentCompose:
+		if namespace != "" {
+			return errors.New("A namespace can only be specified for a Kubernetes manifest")
+		}
+	case portainer.EdgeStackDeploymentKubernetes:
+		if namespace != "" && (len(namespace) > 63 || !namespacePattern.MatchString(namespace)) {
+			return errors.New("Invalid namespace, it must be a valid DNS label")
+		}
+	default:
+		return errors.New("Invalid deployment type")
+	}
+
+	return nil
+}
+
+// IsEdgeStackCompatible returns true when an endpoint can deploy an edge stack of the specified type.
+// Compose files can be deployed on every edge agent, Kubernetes edge agents converting them,
+// whereas Kubernetes manifests can only be applied by Kubernetes edge agents.
+func IsEdgeStackCompatible(deploymentType portainer.EdgeStackDeploymentType, endpointType portainer.EndpointType) bool {
+	if deploymentType == portainer.EdgeStackDeploymentKubernetes {
+		return endpointType == portainer.EdgeAgentOnKubernetesEnvironment
+	}
+
+	return true
+}
+
+// ValidateEdgeStackEndpoints returns an error when one of the endpoints cannot deploy an edge stack of the specified type
+func ValidateEdgeStackEndpoints(deploymentType portainer.EdgeStackDeploymentType, endpointIDs []portainer.EndpointID, endpoints []portainer.Endpoint) error {
+	related := map[portainer.EndpointID]bool{}
+	for _, endpointID := range endpointIDs {
+		related[endpointID] = true
+	}
+
+	for _, endpoint := range endpoints {
+		if related[endpoint.ID] && !IsEdgeStackCompatible(deploymentType, endpoint.Type) {
+			return fmt.Errorf("Edge groups contain the Docker endpoint %s which cannot deploy a Kubernetes manifest", endpoint.Name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateEdgeGroupEdgeStacks returns an error when an endpoint of an Edge group cannot deploy one of the edge stacks
+// targeting the group. It is used when the endpoints of the group change, edgeGroups containing the updated group.
+func ValidateEdgeGroupEdgeStacks(edgeGroupID portainer.EdgeGroupID, edgeStacks []portainer.EdgeStack, endpoints []portainer.Endpoint, endpointGroups []portainer.EndpointGroup, edgeGroups []portainer.EdgeGroup) error {
+	for _, edgeStack := range edgeStacks {
+		if edgeStack.DeploymentType != portainer.EdgeStackDeploymentKubernetes || !containsEdgeGroup(edgeStack.EdgeGroups, edgeGroupID) {
+			continue
+		}
+
+		relatedEndpoints, err := EdgeStackRelatedEndpoints(edgeStack.EdgeGroups, endpoints, endpointGroups, edgeGroups)
+		if err != nil {
+			return err
+		}
+
+		err = ValidateEdgeStackEndpoints(edgeStack.DeploymentType, relatedEndpoints, endpoints)
+		if err != nil {
+			return fmt.Errorf("%w, it is targeted by the edge stack %s", err, edgeStack.Name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateEndpointEdgeStacks returns an error when an endpoint cannot deploy one of its related edge stacks.
+// It is used when the group, the tags or the labels of the endpoint change.
+func ValidateEndpointEdgeStacks(endpoint *portainer.Endpoint, endpointGroup *portainer.EndpointGroup, edgeGroups []portainer.EdgeGroup, edgeStacks []portainer.EdgeStack) error {
+	relatedEdgeStacks := map[portainer.EdgeStackID]bool{}
+	for _, edgeStackID := range EndpointRelatedEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks) {
+		relatedEdgeStacks[edgeStackID] = true
+	}
+
+	for _, edgeStack := range edgeStacks {
+		if relatedEdgeStacks[edgeStack.ID] && !IsEdgeStackCompatible(edgeStack.DeploymentType, endpoint.Type) {
+			return fmt.Errorf("The Docker endpoint %s cannot deploy the Kubernetes manifest of the edge stack %s", endpoint.Name, edgeStack.Name)
+		}
+	}
+
+	return nil
+}
+
+func containsEdgeGroup(edgeGroupIDs []portainer.EdgeGroupID, edgeGroupID portainer.EdgeGroupID) bool {
+	for _, id := range edgeGroupIDs {
+		if id == edgeGroupID {
+			return true
+		}
+	}
+	return false
+}
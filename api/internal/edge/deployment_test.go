@@ -0,0 +1,77 @@
+package edge
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEdgeStackDeployment(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateEdgeStackDeployment(portainer.EdgeStackDeploymentCompose, ""))
+	is.Error(ValidateEdgeStackDeployment(portainer.EdgeStackDeploymentCompose, "default"), "compose files have no namespace")
+	is.NoError(ValidateEdgeStackDeployment(portainer.EdgeStackDeploymentKubernetes, ""))
+	is.NoError(ValidateEdgeStackDeployment(portainer.EdgeStackDeploymentKubernetes, "edge-apps"))
+	is.Error(ValidateEdgeStackDeployment(portainer.EdgeStackDeploymentKubernetes, "Edge_Apps"))
+	is.Error(ValidateEdgeStackDeployment(portainer.EdgeStackDeploymentKubernetes, "-edge"))
+	is.Error(ValidateEdgeStackDeployment(2, ""))
+}
+
+func TestValidateEdgeStackEndpoints(t *testing.T) {
+	is := assert.New(t)
+
+	endpoints := []portainer.Endpoint{
+		{ID: 1, Name: "docker", Type: portainer.EdgeAgentOnDockerEnvironment},
+		{ID: 2, Name: "kube", Type: portainer.EdgeAgentOnKubernetesEnvironment},
+	}
+
+	is.NoError(ValidateEdgeStackEndpoints(portainer.EdgeStackDeploymentCompose, []portainer.EndpointID{1, 2}, endpoints))
+	is.NoError(ValidateEdgeStackEndpoints(portainer.EdgeStackDeploymentKubernetes, []portainer.EndpointID{2}, endpoints))
+	is.EqualError(ValidateEdgeStackEndpoints(portainer.EdgeStackDeploymentKubernetes, []portainer.EndpointID{1, 2}, endpoints), "Edge groups contain the Docker endpoint docker which cannot deploy a Kubernetes manifest")
+}
+
+func TestValidateEdgeGroupEdgeStacks(t *testing.T) {
+	is := assert.New(t)
+
+	endpoints := []portainer.Endpoint{
+		{ID: 1, Name: "docker", Type: portainer.EdgeAgentOnDockerEnvironment},
+		{ID: 2, Name: "kube", Type: portainer.EdgeAgentOnKubernetesEnvironment},
+	}
+	edgeStacks := []portainer.EdgeStack{
+		{ID: 1, Name: "manifest", DeploymentType: portainer.EdgeStackDeploymentKubernetes, EdgeGroups: []portainer.EdgeGroupID{1}},
+		{ID: 2, Name: "compose", DeploymentType: portainer.EdgeStackDeploymentCompose, EdgeGroups: []portainer.EdgeGroupID{2}},
+	}
+
+	edgeGroups := []portainer.EdgeGroup{
+		{ID: 1, Endpoints: []portainer.EndpointID{2}},
+		{ID: 2, Endpoints: []portainer.EndpointID{1, 2}},
+	}
+	is.NoError(ValidateEdgeGroupEdgeStacks(1, edgeStacks, endpoints, nil, edgeGroups))
+	is.NoError(ValidateEdgeGroupEdgeStacks(2, edgeStacks, endpoints, nil, edgeGroups))
+
+	edgeGroups[0].Endpoints = []portainer.EndpointID{1, 2}
+	is.EqualError(ValidateEdgeGroupEdgeStacks(1, edgeStacks, endpoints, nil, edgeGroups), "Edge groups contain the Docker endpoint docker which cannot deploy a Kubernetes manifest, it is targeted by the edge stack manifest")
+}
+
+func TestValidateEndpointEdgeStacks(t *testing.T) {
+	is := assert.New(t)
+
+	endpoint := &portainer.Endpoint{ID: 1, Name: "docker", Type: portainer.EdgeAgentOnDockerEnvironment, GroupID: 1}
+	endpointGroup := &portainer.EndpointGroup{ID: 1}
+	edgeGroups := []portainer.EdgeGroup{
+		{ID: 1, Dynamic: true, TagIDs: []portainer.TagID{1}},
+		{ID: 2, Dynamic: true, TagIDs: []portainer.TagID{2}},
+	}
+	edgeStacks := []portainer.EdgeStack{
+		{ID: 1, Name: "manifest", DeploymentType: portainer.EdgeStackDeploymentKubernetes, EdgeGroups: []portainer.EdgeGroupID{1}},
+		{ID: 2, Name: "compose", DeploymentType: portainer.EdgeStackDeploymentCompose, EdgeGroups: []portainer.EdgeGroupID{2}},
+	}
+
+	endpoint.TagIDs = []portainer.TagID{2}
+	is.NoError(ValidateEndpointEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks))
+
+	endpoint.TagIDs = []portainer.TagID{1, 2}
+	is.EqualError(ValidateEndpointEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks), "The Docker endpoint docker cannot deploy the Kubernetes manifest of the edge stack manifest")
+}
@@ -1,6 +1,8 @@
 package edge
 
 import (
+	"time"
+
 	"github.com/portainer/portainer/api"
 	"github.com/portainer/portainer/api/internal/tag"
 )
@@ -44,6 +46,10 @@ func edgeGroupRelatedToEndpoint(edgeGroup *portainer.EdgeGroup, endpoint *portai
 		return false
 	}
 
+	if edgeGroup.Rule != nil {
+		return MatchEdgeGroupRule(edgeGroup.Rule, endpoint, endpointGroup, time.Now())
+	}
+
 	endpointTags := tag.Set(endpoint.TagIDs)
 	if endpointGroup.TagIDs != nil {
 		endpointTags = tag.Union(endpointTags, tag.Set(endpointGroup.TagIDs))
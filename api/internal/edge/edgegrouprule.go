@@ -0,0 +1,232 @@
+package edge
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// maxEdgeGroupRuleDepth limits the nesting of the And, Or and Not rules
+const maxEdgeGroupRuleDepth = 8
+
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// ValidateEdgeGroupRule returns an error describing the first invalid rule of an expression
+func ValidateEdgeGroupRule(rule *portainer.EdgeGroupRule) error {
+	return validateEdgeGroupRule(rule, 1)
+}
+
+func validateEdgeGroupRule(rule *portainer.EdgeGroupRule, depth int) error {
+	if depth > maxEdgeGroupRuleDepth {
+		return fmt.Errorf("rules cannot be nested more than %d times", maxEdgeGroupRuleDepth)
+	}
+
+	switch rule.Type {
+	case portainer.EdgeGroupRuleAnd, portainer.EdgeGroupRuleOr:
+		if len(rule.Rules) == 0 {
+			return errors.New("And and Or rules require at least one operand")
+		}
+	case portainer.EdgeGroupRuleNot:
+		if len(rule.Rules) != 1 {
+			return errors.New("Not rules require a single operand")
+		}
+	case portainer.EdgeGroupRuleEndpointGroup:
+		if rule.GroupID == 0 {
+			return errors.New("EndpointGroup rules require an endpoint group")
+		}
+	case portainer.EdgeGroupRuleName:
+		_, err := path.Match(rule.Value, "")
+		if rule.Value == "" || err != nil {
+			return fmt.Errorf("invalid name pattern: %q", rule.Value)
+		}
+	case portainer.EdgeGroupRulePlatform:
+		if rule.Platform != portainer.AgentPlatformDocker && rule.Platform != portainer.AgentPlatformKubernetes {
+			return errors.New("invalid agent platform")
+		}
+	case portainer.EdgeGroupRuleCheckIn:
+		maxAge, err := time.ParseDuration(rule.MaxAge)
+		if err != nil || maxAge <= 0 {
+			return fmt.Errorf("invalid check-in maximum age: %q", rule.MaxAge)
+		}
+	case portainer.EdgeGroupRuleDockerVersion:
+		_, _, err := parseVersionConstraint(rule.Value)
+		if err != nil {
+			return err
+		}
+	case portainer.EdgeGroupRuleLabel:
+		_, err := path.Match(rule.Value, "")
+		if rule.Key == "" || err != nil {
+			return fmt.Errorf("invalid label rule: %q=%q", rule.Key, rule.Value)
+		}
+	default:
+		return fmt.Errorf("unknown rule type: %d", rule.Type)
+	}
+
+	for idx := range rule.Rules {
+		if rule.Type != portainer.EdgeGroupRuleAnd && rule.Type != portainer.EdgeGroupRuleOr && rule.Type != portainer.EdgeGroupRuleNot {
+			return errors.New("only And, Or and Not rules accept operands")
+		}
+
+		err := validateEdgeGroupRule(&rule.Rules[idx], depth+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateLabels returns an error when an endpoint label has an empty key
+func ValidateLabels(labels map[string]string) error {
+	for key := range labels {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("invalid label: the key cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+// MatchEdgeGroupRule returns true when the endpoint, part of the endpoint group, matches the rule at the given time
+func MatchEdgeGroupRule(rule *portainer.EdgeGroupRule, endpoint *portainer.Endpoint, endpointGroup *portainer.EndpointGroup, now time.Time) bool {
+	switch rule.Type {
+	case portainer.EdgeGroupRuleAnd:
+		for idx := range rule.Rules {
+			if !MatchEdgeGroupRule(&rule.Rules[idx], endpoint, endpointGroup, now) {
+				return false
+			}
+		}
+		return len(rule.Rules) > 0
+	case portainer.EdgeGroupRuleOr:
+		for idx := range rule.Rules {
+			if MatchEdgeGroupRule(&rule.Rules[idx], endpoint, endpointGroup, now) {
+				return true
+			}
+		}
+		return false
+	case portainer.EdgeGroupRuleNot:
+		return len(rule.Rules) == 1 && !MatchEdgeGroupRule(&rule.Rules[0], endpoint, endpointGroup, now)
+	case portainer.EdgeGroupRuleEndpointGroup:
+		return endpoint.GroupID == rule.GroupID
+	case portainer.EdgeGroupRuleName:
+		matched, _ := path.Match(rule.Value, endpoint.Name)
+		return matched
+	case portainer.EdgeGroupRulePlatform:
+		return (rule.Platform == portainer.AgentPlatformDocker && endpoint.Type == portainer.EdgeAgentOnDockerEnvironment) ||
+			(rule.Platform == portainer.AgentPlatformKubernetes && endpoint.Type == portainer.EdgeAgentOnKubernetesEnvironment)
+	case portainer.EdgeGroupRuleCheckIn:
+		maxAge, err := time.ParseDuration(rule.MaxAge)
+		if err != nil || endpoint.LastCheckInDate == 0 {
+			return false
+		}
+		return now.Sub(time.Unix(endpoint.LastCheckInDate, 0)) <= maxAge
+	case portainer.EdgeGroupRuleDockerVersion:
+		if len(endpoint.Snapshots) == 0 || endpoint.Snapshots[0].DockerVersion == "" {
+			return false
+		}
+		return matchVersionConstraint(rule.Value, endpoint.Snapshots[0].DockerVersion)
+	case portainer.EdgeGroupRuleLabel:
+		value, ok := endpoint.Labels[rule.Key]
+		if !ok {
+			return false
+		}
+		if rule.Value == "" {
+			return true
+		}
+		matched, _ := path.Match(rule.Value, value)
+		return matched
+	}
+
+	return false
+}
+
+// parseVersionConstraint splits a constraint such as ">=20.10" into its operator and version.
+// A constraint without operator requires an equal version.
+func parseVersionConstraint(constraint string) (string, string, error) {
+	constraint = strings.TrimSpace(constraint)
+
+	operator := "="
+	for _, op := range versionOperators {
+		if strings.HasPrefix(constraint, op) {
+			operator = op
+			constraint = strings.TrimSpace(strings.TrimPrefix(constraint, op))
+			break
+		}
+	}
+
+	if constraint == "" {
+		return "", "", errors.New("invalid version constraint: missing version")
+	}
+
+	for _, part := range strings.Split(constraint, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return "", "", fmt.Errorf("invalid version constraint: %q", constraint)
+		}
+	}
+
+	return operator, constraint, nil
+}
+
+func matchVersionConstraint(constraint, version string) bool {
+	operator, expected, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return false
+	}
+
+	comparison := compareVersions(version, expected)
+
+	switch operator {
+	case ">=":
+		return comparison >= 0
+	case "<=":
+		return comparison <= 0
+	case "!=":
+		return comparison != 0
+	case ">":
+		return comparison > 0
+	case "<":
+		return comparison < 0
+	}
+
+	return comparison == 0
+}
+
+// compareVersions compares the components of two dotted versions up to the number of components of the expected
+// version, so that "20.10.7" equals "20.10". Suffixes such as "-ce" are ignored.
+func compareVersions(version, expected string) int {
+	versionParts := strings.Split(version, ".")
+	expectedParts := strings.Split(expected, ".")
+
+	for idx, expectedPart := range expectedParts {
+		expectedNumber, _ := strconv.Atoi(expectedPart)
+
+		versionNumber := 0
+		if idx < len(versionParts) {
+			versionNumber = leadingNumber(versionParts[idx])
+		}
+
+		if versionNumber != expectedNumber {
+			if versionNumber < expectedNumber {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func leadingNumber(value string) int {
+	end := 0
+	for end < len(value) && value[end] >= '0' && value[end] <= '9' {
+		end++
+	}
+
+	number, _ := strconv.Atoi(value[:end])
+	return number
+}
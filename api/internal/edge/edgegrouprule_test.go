@@ -0,0 +1,125 @@
+package edge
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEdgeGroupRule(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{
+		Type: portainer.EdgeGroupRuleAnd,
+		Rules: []portainer.EdgeGroupRule{
+			{Type: portainer.EdgeGroupRuleName, Value: "store-*"},
+			{Type: portainer.EdgeGroupRuleNot, Rules: []portainer.EdgeGroupRule{{Type: portainer.EdgeGroupRuleLabel, Key: "env", Value: "dev"}}},
+			{Type: portainer.EdgeGroupRuleDockerVersion, Value: ">= 20.10"},
+			{Type: portainer.EdgeGroupRuleCheckIn, MaxAge: "1h"},
+		},
+	}))
+
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{}))
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleOr}))
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleNot, Rules: make([]portainer.EdgeGroupRule, 2)}))
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleName, Value: "[store"}))
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleDockerVersion, Value: ">=twenty"}))
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleCheckIn, MaxAge: "-1h"}))
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleLabel, Value: "dev"}))
+	is.Error(ValidateEdgeGroupRule(&portainer.EdgeGroupRule{
+		Type:  portainer.EdgeGroupRuleName,
+		Value: "*",
+		Rules: []portainer.EdgeGroupRule{{Type: portainer.EdgeGroupRuleName, Value: "*"}},
+	}))
+
+	rule := portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleName, Value: "*"}
+	for i := 0; i < maxEdgeGroupRuleDepth; i++ {
+		rule = portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleNot, Rules: []portainer.EdgeGroupRule{rule}}
+	}
+	is.Error(ValidateEdgeGroupRule(&rule))
+}
+
+func TestValidateLabels(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateLabels(map[string]string{"region": "eu-west", "env": ""}))
+	is.NoError(ValidateLabels(nil))
+	is.Error(ValidateLabels(map[string]string{" ": "eu-west"}))
+}
+
+func TestMatchEdgeGroupRule(t *testing.T) {
+	now := time.Unix(1625132400, 0)
+
+	endpoint := &portainer.Endpoint{
+		Name:            "store-0042",
+		Type:            portainer.EdgeAgentOnDockerEnvironment,
+		GroupID:         2,
+		LastCheckInDate: now.Add(-10 * time.Minute).Unix(),
+		Snapshots:       []portainer.DockerSnapshot{{DockerVersion: "20.10.7"}},
+		Labels:          map[string]string{"region": "eu-west", "env": "prod"},
+	}
+	endpointGroup := &portainer.EndpointGroup{ID: 2}
+
+	tests := []struct {
+		name     string
+		rule     portainer.EdgeGroupRule
+		expected bool
+	}{
+		{"endpoint group", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleEndpointGroup, GroupID: 2}, true},
+		{"other endpoint group", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleEndpointGroup, GroupID: 3}, false},
+		{"name glob", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleName, Value: "store-*"}, true},
+		{"other name", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleName, Value: "kiosk-*"}, false},
+		{"platform", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRulePlatform, Platform: portainer.AgentPlatformDocker}, true},
+		{"other platform", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRulePlatform, Platform: portainer.AgentPlatformKubernetes}, false},
+		{"recent check-in", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleCheckIn, MaxAge: "15m"}, true},
+		{"stale check-in", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleCheckIn, MaxAge: "5m"}, false},
+		{"minimum docker version", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleDockerVersion, Value: ">=20.10"}, true},
+		{"equal docker version", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleDockerVersion, Value: "20.10"}, true},
+		{"maximum docker version", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleDockerVersion, Value: "<19.03"}, false},
+		{"label glob", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleLabel, Key: "region", Value: "eu-*"}, true},
+		{"label presence", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleLabel, Key: "env"}, true},
+		{"missing label", portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleLabel, Key: "tier"}, false},
+		{
+			"and",
+			portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleAnd, Rules: []portainer.EdgeGroupRule{
+				{Type: portainer.EdgeGroupRuleName, Value: "store-*"},
+				{Type: portainer.EdgeGroupRuleLabel, Key: "env", Value: "dev"},
+			}},
+			false,
+		},
+		{
+			"or",
+			portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleOr, Rules: []portainer.EdgeGroupRule{
+				{Type: portainer.EdgeGroupRuleName, Value: "kiosk-*"},
+				{Type: portainer.EdgeGroupRuleLabel, Key: "env", Value: "prod"},
+			}},
+			true,
+		},
+		{
+			"not",
+			portainer.EdgeGroupRule{Type: portainer.EdgeGroupRuleNot, Rules: []portainer.EdgeGroupRule{
+				{Type: portainer.EdgeGroupRuleLabel, Key: "env", Value: "dev"},
+			}},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, MatchEdgeGroupRule(&test.rule, endpoint, endpointGroup, now))
+		})
+	}
+}
+
+func TestMatchVersionConstraint(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(matchVersionConstraint(">19.03", "20.10.7"))
+	is.True(matchVersionConstraint("<=19.03.12", "19.03.12"))
+	is.True(matchVersionConstraint("!=20.10", "19.03.5"))
+	is.True(matchVersionConstraint(">=20.10.6", "20.10.7-ce"))
+	is.False(matchVersionConstraint("<20.10", "20.10.7"))
+	is.False(matchVersionConstraint(">=20.10.8", "20.10.7"))
+}
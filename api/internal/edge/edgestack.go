@@ -28,3 +28,21 @@ func EdgeStackRelatedEndpoints(edgeGroupIDs []portainer.EdgeGroupID, endpoints [
 
 	return edgeStackEndpoints, nil
 }
+
+// RecordEdgeStackCommit associates a commit of the repository to the current version of an edge stack deployed from git.
+// The commit of the previous version is kept, as the endpoints not updated yet still report it.
+func RecordEdgeStackCommit(edgeStack *portainer.EdgeStack, commitHash string) {
+	commitHashes := map[int]string{edgeStack.Version: commitHash}
+
+	previousVersion := 0
+	for version := range edgeStack.CommitHashes {
+		if version < edgeStack.Version && version > previousVersion {
+			previousVersion = version
+		}
+	}
+	if previousVersion != 0 {
+		commitHashes[previousVersion] = edgeStack.CommitHashes[previousVersion]
+	}
+
+	edgeStack.CommitHashes = commitHashes
+}
@@ -0,0 +1,24 @@
+package edge
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordEdgeStackCommit(t *testing.T) {
+	is := assert.New(t)
+
+	edgeStack := &portainer.EdgeStack{Version: 1}
+	RecordEdgeStackCommit(edgeStack, "first")
+	is.Equal(map[int]string{1: "first"}, edgeStack.CommitHashes)
+
+	edgeStack.Version = 2
+	RecordEdgeStackCommit(edgeStack, "second")
+	is.Equal(map[int]string{1: "first", 2: "second"}, edgeStack.CommitHashes)
+
+	edgeStack.Version = 3
+	RecordEdgeStackCommit(edgeStack, "third")
+	is.Equal(map[int]string{2: "second", 3: "third"}, edgeStack.CommitHashes, "only the commit of the previous version should be kept")
+}
@@ -0,0 +1,71 @@
+package edge
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// ValidateEnrolmentRules returns an error if one of the edge enrolment rules is invalid
+func ValidateEnrolmentRules(rules []portainer.EdgeEnrolmentRule) error {
+	for _, rule := range rules {
+		if rule.EdgeIDPrefix == "" {
+			return errors.New("Invalid enrolment rule, the EdgeID prefix is mandatory")
+		}
+		if rule.Action != portainer.EdgeEnrolmentRuleApprove && rule.Action != portainer.EdgeEnrolmentRuleReject {
+			return errors.New("Invalid enrolment rule action. Value must be one of: 1 (approve) or 2 (reject)")
+		}
+	}
+	return nil
+}
+
+// MatchEnrolmentRule returns the first rule whose prefix matches the EdgeID of a device, nil when none matches
+func MatchEnrolmentRule(rules []portainer.EdgeEnrolmentRule, edgeID string) *portainer.EdgeEnrolmentRule {
+	for idx := range rules {
+		if strings.HasPrefix(edgeID, rules[idx].EdgeIDPrefix) {
+			return &rules[idx]
+		}
+	}
+	return nil
+}
+
+// FleetKeyURL returns the Portainer instance URL embedded in a fleet key
+func FleetKeyURL(fleetKey string) (string, error) {
+	info, err := ParseEdgeKey(fleetKey)
+	if err != nil {
+		return "", err
+	}
+
+	if info.EndpointID != 0 {
+		return "", errors.New("invalid fleet key format")
+	}
+
+	return info.PortainerURL, nil
+}
+
+// HashEnrolmentSecret returns the hash of an enrolment secret as stored with the edge device
+func HashEnrolmentSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// ValidateEnrolmentSecret returns true when the secret presented by a device matches the secret issued on its first enrolment
+func ValidateEnrolmentSecret(edgeDevice *portainer.EdgeDevice, secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(HashEnrolmentSecret(secret)), []byte(edgeDevice.EnrolmentSecretHash)) == 1
+}
+
+// CanDeliverEdgeKey returns true when the enrolment of a device can return the edge key of its endpoint.
+// The key is only delivered once, and never once it was rotated: the secret of a rotated key
+// authenticates the agent and must be handed over to the device by an administrator, otherwise rotating
+// the key of a revoked device would hand the new secret back to that device.
+func CanDeliverEdgeKey(edgeDevice *portainer.EdgeDevice, endpoint *portainer.Endpoint) bool {
+	return !edgeDevice.EdgeKeyDelivered && !endpoint.EdgeCredentials.Revoked && endpoint.EdgeCredentials.Secret == ""
+}
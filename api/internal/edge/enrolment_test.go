@@ -0,0 +1,62 @@
+package edge
+
+import (
+	"encoding/base64"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEnrolmentRules(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateEnrolmentRules([]portainer.EdgeEnrolmentRule{{EdgeIDPrefix: "store-", Action: portainer.EdgeEnrolmentRuleApprove}}))
+	is.Error(ValidateEnrolmentRules([]portainer.EdgeEnrolmentRule{{Action: portainer.EdgeEnrolmentRuleApprove}}))
+	is.Error(ValidateEnrolmentRules([]portainer.EdgeEnrolmentRule{{EdgeIDPrefix: "store-", Action: 3}}))
+}
+
+func TestMatchEnrolmentRule(t *testing.T) {
+	is := assert.New(t)
+
+	rules := []portainer.EdgeEnrolmentRule{
+		{EdgeIDPrefix: "store-test-", Action: portainer.EdgeEnrolmentRuleReject},
+		{EdgeIDPrefix: "store-", Action: portainer.EdgeEnrolmentRuleApprove, GroupID: 2},
+	}
+
+	is.Equal(portainer.EdgeEnrolmentRuleReject, MatchEnrolmentRule(rules, "store-test-1").Action, "the first matching rule should apply")
+	is.Equal(portainer.EndpointGroupID(2), MatchEnrolmentRule(rules, "store-0042").GroupID)
+	is.Nil(MatchEnrolmentRule(rules, "warehouse-1"))
+}
+
+func TestFleetKeyURL(t *testing.T) {
+	is := assert.New(t)
+
+	fleetKey := base64.RawStdEncoding.EncodeToString([]byte("https://portainer.example.com|portainer.example.com:8000|fingerprint|0"))
+	url, err := FleetKeyURL(fleetKey)
+	is.NoError(err)
+	is.Equal("https://portainer.example.com", url)
+
+	_, err = FleetKeyURL(base64.RawStdEncoding.EncodeToString([]byte("https://portainer.example.com|portainer.example.com:8000|fingerprint|1")))
+	is.Error(err, "an edge key is not a fleet key")
+}
+
+func TestValidateEnrolmentSecret(t *testing.T) {
+	is := assert.New(t)
+
+	edgeDevice := &portainer.EdgeDevice{EnrolmentSecretHash: HashEnrolmentSecret("secret")}
+
+	is.True(ValidateEnrolmentSecret(edgeDevice, "secret"))
+	is.False(ValidateEnrolmentSecret(edgeDevice, "other"))
+	is.False(ValidateEnrolmentSecret(edgeDevice, ""))
+	is.False(ValidateEnrolmentSecret(&portainer.EdgeDevice{}, ""), "a device without secret should not accept an empty secret")
+}
+
+func TestCanDeliverEdgeKey(t *testing.T) {
+	is := assert.New(t)
+
+	is.True(CanDeliverEdgeKey(&portainer.EdgeDevice{}, &portainer.Endpoint{}))
+	is.False(CanDeliverEdgeKey(&portainer.EdgeDevice{EdgeKeyDelivered: true}, &portainer.Endpoint{}), "the key should only be delivered once")
+	is.False(CanDeliverEdgeKey(&portainer.EdgeDevice{}, &portainer.Endpoint{EdgeCredentials: portainer.EndpointEdgeCredentials{Revoked: true}}))
+	is.False(CanDeliverEdgeKey(&portainer.EdgeDevice{}, &portainer.Endpoint{EdgeCredentials: portainer.EndpointEdgeCredentials{Secret: "rotated"}}), "a rotated key should not be delivered")
+}
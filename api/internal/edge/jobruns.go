@@ -0,0 +1,68 @@
+package edge
+
+import (
+	portainer "github.com/portainer/portainer/api"
+)
+
+// EdgeJobTaskSummary aggregates the runs of an Edge job on an endpoint
+type EdgeJobTaskSummary struct {
+	Runs      int
+	Succeeded int
+	Failed    int
+	TimedOut  int
+	// Most recent run, nil when the job never ran on the endpoint
+	LastRun *portainer.EdgeJobRun
+}
+
+// EdgeJobRunStatusFromResult returns the status of a run from the result of its last attempt
+func EdgeJobRunStatusFromResult(exitCode int, timedOut bool) portainer.EdgeJobRunStatus {
+	if timedOut {
+		return portainer.EdgeJobRunTimedOut
+	}
+
+	if exitCode != 0 {
+		return portainer.EdgeJobRunFailed
+	}
+
+	return portainer.EdgeJobRunSucceeded
+}
+
+// SummarizeEdgeJobRuns aggregates runs per endpoint. Runs must be ordered by creation for each endpoint.
+func SummarizeEdgeJobRuns(runs []portainer.EdgeJobRun) map[portainer.EndpointID]*EdgeJobTaskSummary {
+	summaries := make(map[portainer.EndpointID]*EdgeJobTaskSummary)
+
+	for idx := range runs {
+		run := &runs[idx]
+
+		summary, ok := summaries[run.EndpointID]
+		if !ok {
+			summary = &EdgeJobTaskSummary{}
+			summaries[run.EndpointID] = summary
+		}
+
+		summary.Runs++
+		summary.LastRun = run
+
+		switch run.Status {
+		case portainer.EdgeJobRunSucceeded:
+			summary.Succeeded++
+		case portainer.EdgeJobRunFailed:
+			summary.Failed++
+		case portainer.EdgeJobRunTimedOut:
+			summary.TimedOut++
+		}
+	}
+
+	return summaries
+}
+
+// EdgeJobRunsLimit returns the identifier of the most recent run to delete from the runs of an Edge job
+// on an endpoint so that at most maxEntries runs are kept. Runs must be ordered by creation.
+// It returns 0 when no run needs to be deleted.
+func EdgeJobRunsLimit(runs []portainer.EdgeJobRun, maxEntries int) int {
+	if len(runs) <= maxEntries {
+		return 0
+	}
+
+	return runs[len(runs)-maxEntries-1].ID
+}
@@ -0,0 +1,45 @@
+package edge
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdgeJobRunStatusFromResult(t *testing.T) {
+	assert.Equal(t, portainer.EdgeJobRunSucceeded, EdgeJobRunStatusFromResult(0, false))
+	assert.Equal(t, portainer.EdgeJobRunFailed, EdgeJobRunStatusFromResult(1, false))
+	assert.Equal(t, portainer.EdgeJobRunTimedOut, EdgeJobRunStatusFromResult(0, true))
+	assert.Equal(t, portainer.EdgeJobRunTimedOut, EdgeJobRunStatusFromResult(137, true))
+}
+
+func TestSummarizeEdgeJobRuns(t *testing.T) {
+	runs := []portainer.EdgeJobRun{
+		{ID: 1, EndpointID: 1, Status: portainer.EdgeJobRunFailed},
+		{ID: 3, EndpointID: 1, Status: portainer.EdgeJobRunSucceeded},
+		{ID: 2, EndpointID: 2, Status: portainer.EdgeJobRunTimedOut},
+	}
+
+	summaries := SummarizeEdgeJobRuns(runs)
+	assert.Len(t, summaries, 2)
+
+	summary := summaries[1]
+	assert.Equal(t, 2, summary.Runs)
+	assert.Equal(t, 1, summary.Succeeded)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 3, summary.LastRun.ID)
+
+	summary = summaries[2]
+	assert.Equal(t, 1, summary.TimedOut)
+	assert.Equal(t, portainer.EdgeJobRunTimedOut, summary.LastRun.Status)
+}
+
+func TestEdgeJobRunsLimit(t *testing.T) {
+	runs := []portainer.EdgeJobRun{{ID: 4}, {ID: 7}, {ID: 9}, {ID: 12}}
+
+	assert.Equal(t, 0, EdgeJobRunsLimit(runs, 4))
+	assert.Equal(t, 0, EdgeJobRunsLimit(runs, 10))
+	assert.Equal(t, 4, EdgeJobRunsLimit(runs, 3))
+	assert.Equal(t, 9, EdgeJobRunsLimit(runs, 1))
+}
@@ -0,0 +1,102 @@
+package edge
+
+import (
+	"log"
+	"reflect"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// UpdateEndpointRelations recomputes the Edge stacks related to each of the Edge endpoints and persists the relations that changed.
+// The rules of the dynamic Edge groups depend on the check-in date and on the snapshots of the endpoints,
+// the relations must then be reconciled periodically rather than only when an endpoint or an Edge group is updated.
+func UpdateEndpointRelations(dataStore portainer.DataStore) error {
+	endpoints, err := dataStore.Endpoint().Endpoints()
+	if err != nil {
+		return err
+	}
+
+	endpointGroups, err := dataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	edgeGroups, err := dataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return err
+	}
+
+	edgeStacks, err := dataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return err
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if endpoint.Type != portainer.EdgeAgentOnDockerEnvironment && endpoint.Type != portainer.EdgeAgentOnKubernetesEnvironment {
+			continue
+		}
+
+		var endpointGroup portainer.EndpointGroup
+		for _, group := range endpointGroups {
+			if endpoint.GroupID == group.ID {
+				endpointGroup = group
+				break
+			}
+		}
+
+		_, err = updateEndpointRelation(dataStore, endpoint, &endpointGroup, edgeGroups, edgeStacks)
+		if err != nil {
+			log.Printf("[WARN] [internal,edge] [endpoint_id: %d] [message: unable to update the endpoint relation] [error: %s]", endpoint.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateEndpointRelation recomputes the Edge stacks related to an Edge endpoint, persists the relation when it changed
+// and returns it. It is called when the endpoint checks in, so that the stacks sent to the agent do not depend on
+// a relation computed before the check-in date was updated.
+func UpdateEndpointRelation(dataStore portainer.DataStore, endpoint *portainer.Endpoint) (*portainer.EndpointRelation, error) {
+	endpointGroup, err := dataStore.EndpointGroup().EndpointGroup(endpoint.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeGroups, err := dataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	edgeStacks, err := dataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return nil, err
+	}
+
+	return updateEndpointRelation(dataStore, endpoint, endpointGroup, edgeGroups, edgeStacks)
+}
+
+func updateEndpointRelation(dataStore portainer.DataStore, endpoint *portainer.Endpoint, endpointGroup *portainer.EndpointGroup, edgeGroups []portainer.EdgeGroup, edgeStacks []portainer.EdgeStack) (*portainer.EndpointRelation, error) {
+	relation, err := dataStore.EndpointRelation().EndpointRelation(endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeStackSet := map[portainer.EdgeStackID]bool{}
+	for _, edgeStackID := range EndpointRelatedEdgeStacks(endpoint, endpointGroup, edgeGroups, edgeStacks) {
+		edgeStackSet[edgeStackID] = true
+	}
+
+	// relations created without Edge stacks are stored with a nil set, which must not be seen as a change
+	if relation.EdgeStacks == nil {
+		relation.EdgeStacks = map[portainer.EdgeStackID]bool{}
+	}
+
+	if reflect.DeepEqual(relation.EdgeStacks, edgeStackSet) {
+		return relation, nil
+	}
+
+	relation.EdgeStacks = edgeStackSet
+
+	return relation, dataStore.EndpointRelation().UpdateEndpointRelation(endpoint.ID, relation)
+}
@@ -0,0 +1,59 @@
+package edge
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type relationDataStore struct {
+	portainer.DataStore
+	relations *relationService
+}
+
+func (d *relationDataStore) EndpointRelation() portainer.EndpointRelationService { return d.relations }
+
+type relationService struct {
+	portainer.EndpointRelationService
+	relation *portainer.EndpointRelation
+	updates  int
+}
+
+func (s *relationService) EndpointRelation(endpointID portainer.EndpointID) (*portainer.EndpointRelation, error) {
+	relation := *s.relation
+	return &relation, nil
+}
+
+func (s *relationService) UpdateEndpointRelation(endpointID portainer.EndpointID, relation *portainer.EndpointRelation) error {
+	s.relation = relation
+	s.updates++
+	return nil
+}
+
+func TestUpdateEndpointRelation(t *testing.T) {
+	is := assert.New(t)
+
+	relations := &relationService{relation: &portainer.EndpointRelation{EndpointID: 1}}
+	dataStore := &relationDataStore{relations: relations}
+
+	endpoint := &portainer.Endpoint{ID: 1, Type: portainer.EdgeAgentOnDockerEnvironment}
+	endpointGroup := &portainer.EndpointGroup{ID: 1}
+
+	relation, err := updateEndpointRelation(dataStore, endpoint, endpointGroup, nil, nil)
+	is.NoError(err)
+	is.Empty(relation.EdgeStacks)
+	is.Equal(0, relations.updates, "a relation without Edge stacks should not be written again")
+
+	edgeGroups := []portainer.EdgeGroup{{ID: 2, Endpoints: []portainer.EndpointID{1}}}
+	edgeStacks := []portainer.EdgeStack{{ID: 3, EdgeGroups: []portainer.EdgeGroupID{2}}}
+
+	relation, err = updateEndpointRelation(dataStore, endpoint, endpointGroup, edgeGroups, edgeStacks)
+	is.NoError(err)
+	is.Equal(map[portainer.EdgeStackID]bool{3: true}, relation.EdgeStacks)
+	is.Equal(1, relations.updates)
+
+	_, err = updateEndpointRelation(dataStore, endpoint, endpointGroup, edgeGroups, edgeStacks)
+	is.NoError(err)
+	is.Equal(1, relations.updates, "an unchanged relation should not be written")
+}
@@ -0,0 +1,241 @@
+package edge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+var (
+	// ErrRolloutNotInProgress is returned when pausing a rollout that is not in progress
+	ErrRolloutNotInProgress = errors.New("Edge stack rollout is not in progress")
+	// ErrRolloutNotPaused is returned when resuming a rollout that is not paused
+	ErrRolloutNotPaused = errors.New("Edge stack rollout is not paused")
+	// ErrRolloutFinished is returned when aborting a rollout that is already completed or aborted
+	ErrRolloutFinished = errors.New("Edge stack rollout is already finished")
+)
+
+// ValidateEdgeStackRollout validates the configuration of a staged rollout
+func ValidateEdgeStackRollout(strategy portainer.EdgeStackRolloutStrategy, batchSize, errorThreshold int) error {
+	switch strategy {
+	case portainer.EdgeStackRolloutPercentage:
+		if batchSize < 1 || batchSize > 100 {
+			return errors.New("Rollout batch size must be a percentage between 1 and 100")
+		}
+	case portainer.EdgeStackRolloutBatch:
+		if batchSize < 1 {
+			return errors.New("Rollout batch size must be a positive number of endpoints")
+		}
+	default:
+		return errors.New("Invalid rollout strategy")
+	}
+
+	if errorThreshold < 0 || errorThreshold > 100 {
+		return errors.New("Rollout error threshold must be a percentage between 0 and 100")
+	}
+
+	return nil
+}
+
+// NewEdgeStackRollout creates a rollout of the specified version over a set of endpoints and releases its first batch.
+// Endpoints are released in ascending identifier order so that the first batch is predictable.
+func NewEdgeStackRollout(strategy portainer.EdgeStackRolloutStrategy, batchSize, errorThreshold, version, previousVersion int, previousEntryPoint string, endpointIDs []portainer.EndpointID) *portainer.EdgeStackRollout {
+	endpoints := make([]portainer.EndpointID, 0, len(endpointIDs))
+	seen := map[portainer.EndpointID]bool{}
+	for _, endpointID := range endpointIDs {
+		if !seen[endpointID] {
+			seen[endpointID] = true
+			endpoints = append(endpoints, endpointID)
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i] < endpoints[j] })
+
+	rollout := &portainer.EdgeStackRollout{
+		Strategy:           strategy,
+		BatchSize:          batchSize,
+		ErrorThreshold:     errorThreshold,
+		Status:             portainer.EdgeStackRolloutInProgress,
+		Version:            version,
+		PreviousVersion:    previousVersion,
+		PreviousEntryPoint: previousEntryPoint,
+		Endpoints:          endpoints,
+	}
+
+	releaseNextBatch(rollout, nil)
+
+	return rollout
+}
+
+// RolloutBatchSize returns the number of endpoints released per batch, at least one
+func RolloutBatchSize(rollout *portainer.EdgeStackRollout) int {
+	size := rollout.BatchSize
+	if rollout.Strategy == portainer.EdgeStackRolloutPercentage {
+		size = (len(rollout.Endpoints)*rollout.BatchSize + 99) / 100
+	}
+
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
+// IsReleasedToEndpoint returns true when the current version of the edge stack must be deployed on the endpoint.
+// Endpoints that are not part of the rollout, such as endpoints added to the edge groups afterwards, always get the current version.
+func IsReleasedToEndpoint(edgeStack *portainer.EdgeStack, endpointID portainer.EndpointID) bool {
+	rollout := edgeStack.Rollout
+	if rollout == nil || rollout.Status == portainer.EdgeStackRolloutCompleted {
+		return true
+	}
+
+	for idx, id := range rollout.Endpoints {
+		if id == endpointID {
+			return idx < rollout.Released
+		}
+	}
+
+	return true
+}
+
+// EdgeStackEndpointVersion returns the version of the edge stack that must be deployed on the endpoint,
+// 0 if the endpoint must not deploy the edge stack yet
+func EdgeStackEndpointVersion(edgeStack *portainer.EdgeStack, endpointID portainer.EndpointID) int {
+	if IsReleasedToEndpoint(edgeStack, endpointID) {
+		return edgeStack.Version
+	}
+
+	return edgeStack.Rollout.PreviousVersion
+}
+
+// UpdateEdgeStackRollout evaluates the rollout of an edge stack after a status change. It pauses the rollout when
+// the error rate of the released endpoints exceeds the threshold and releases the next batch once every released
+// endpoint reported its deployment.
+func UpdateEdgeStackRollout(edgeStack *portainer.EdgeStack) {
+	rollout := edgeStack.Rollout
+	if rollout == nil || rollout.Status != portainer.EdgeStackRolloutInProgress {
+		return
+	}
+
+	for rollout.Status == portainer.EdgeStackRolloutInProgress {
+		reported, failed := 0, 0
+		for _, endpointID := range rollout.Endpoints[:rollout.Released] {
+			switch edgeStack.Status[endpointID].Type {
+			case portainer.StatusOk:
+				reported++
+			case portainer.StatusError:
+				reported++
+				if !containsEndpoint(rollout.AcknowledgedFailures, endpointID) {
+					failed++
+				}
+			}
+		}
+
+		if failed*100 > rollout.ErrorThreshold*rollout.Released {
+			rollout.Status = portainer.EdgeStackRolloutPaused
+			rollout.PauseReason = fmt.Sprintf("%d of %d released endpoints failed to deploy the stack, above the %d%% error threshold", failed, rollout.Released, rollout.ErrorThreshold)
+			return
+		}
+
+		if reported < rollout.Released {
+			return
+		}
+
+		releaseNextBatch(rollout, edgeStack.Status)
+	}
+}
+
+// PauseEdgeStackRollout pauses an in progress rollout
+func PauseEdgeStackRollout(edgeStack *portainer.EdgeStack) error {
+	rollout := edgeStack.Rollout
+	if rollout == nil || rollout.Status != portainer.EdgeStackRolloutInProgress {
+		return ErrRolloutNotInProgress
+	}
+
+	rollout.Status = portainer.EdgeStackRolloutPaused
+	rollout.PauseReason = ""
+	return nil
+}
+
+// ResumeEdgeStackRollout resumes a paused rollout. Endpoints that failed so far are acknowledged
+// and no longer count towards the error threshold.
+func ResumeEdgeStackRollout(edgeStack *portainer.EdgeStack) error {
+	rollout := edgeStack.Rollout
+	if rollout == nil || rollout.Status != portainer.EdgeStackRolloutPaused {
+		return ErrRolloutNotPaused
+	}
+
+	for _, endpointID := range rollout.Endpoints[:rollout.Released] {
+		if edgeStack.Status[endpointID].Type == portainer.StatusError && !containsEndpoint(rollout.AcknowledgedFailures, endpointID) {
+			rollout.AcknowledgedFailures = append(rollout.AcknowledgedFailures, endpointID)
+		}
+	}
+
+	rollout.Status = portainer.EdgeStackRolloutInProgress
+	rollout.PauseReason = ""
+	UpdateEdgeStackRollout(edgeStack)
+	return nil
+}
+
+// AbortEdgeStackRollout stops a rollout, the endpoints that were not released keep the previous version
+func AbortEdgeStackRollout(edgeStack *portainer.EdgeStack) error {
+	rollout := edgeStack.Rollout
+	if rollout == nil || rollout.Status == portainer.EdgeStackRolloutCompleted || rollout.Status == portainer.EdgeStackRolloutAborted {
+		return ErrRolloutFinished
+	}
+
+	rollout.Status = portainer.EdgeStackRolloutAborted
+	return nil
+}
+
+// IsRolloutEndpoint returns true when the endpoint is targeted by the rollout
+func IsRolloutEndpoint(rollout *portainer.EdgeStackRollout, endpointID portainer.EndpointID) bool {
+	return containsEndpoint(rollout.Endpoints, endpointID)
+}
+
+// RemoveRolloutEndpoints removes endpoints that are no longer related to the edge stack from its rollout
+func RemoveRolloutEndpoints(rollout *portainer.EdgeStackRollout, endpointIDs map[portainer.EndpointID]bool) {
+	endpoints := make([]portainer.EndpointID, 0, len(rollout.Endpoints))
+	released := rollout.Released
+	for idx, endpointID := range rollout.Endpoints {
+		if endpointIDs[endpointID] {
+			if idx < rollout.Released {
+				released--
+			}
+			continue
+		}
+		endpoints = append(endpoints, endpointID)
+	}
+
+	rollout.Endpoints = endpoints
+	rollout.Released = released
+	if rollout.Status == portainer.EdgeStackRolloutInProgress && rollout.Released >= len(rollout.Endpoints) {
+		rollout.Status = portainer.EdgeStackRolloutCompleted
+	}
+}
+
+func releaseNextBatch(rollout *portainer.EdgeStackRollout, status map[portainer.EndpointID]portainer.EdgeStackStatus) {
+	next := rollout.Released + RolloutBatchSize(rollout)
+	if next > len(rollout.Endpoints) {
+		next = len(rollout.Endpoints)
+	}
+
+	// statuses reported by these endpoints were about the previous version
+	for _, endpointID := range rollout.Endpoints[rollout.Released:next] {
+		delete(status, endpointID)
+	}
+
+	rollout.Released = next
+	if rollout.Released == len(rollout.Endpoints) {
+		rollout.Status = portainer.EdgeStackRolloutCompleted
+	}
+}
+
+func containsEndpoint(endpointIDs []portainer.EndpointID, endpointID portainer.EndpointID) bool {
+	for _, id := range endpointIDs {
+		if id == endpointID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package edge
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRolloutTestStack(strategy portainer.EdgeStackRolloutStrategy, batchSize, errorThreshold int, endpoints ...portainer.EndpointID) *portainer.EdgeStack {
+	return &portainer.EdgeStack{
+		ID:      1,
+		Version: 2,
+		Status:  map[portainer.EndpointID]portainer.EdgeStackStatus{},
+		Rollout: NewEdgeStackRollout(strategy, batchSize, errorThreshold, 2, 1, "rollout-previous-docker-compose.yml", endpoints),
+	}
+}
+
+func reportStatus(stack *portainer.EdgeStack, endpointID portainer.EndpointID, status portainer.EdgeStackStatusType) {
+	stack.Status[endpointID] = portainer.EdgeStackStatus{Type: status, EndpointID: endpointID}
+	UpdateEdgeStackRollout(stack)
+}
+
+func TestValidateEdgeStackRollout(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateEdgeStackRollout(portainer.EdgeStackRolloutPercentage, 25, 10))
+	is.NoError(ValidateEdgeStackRollout(portainer.EdgeStackRolloutBatch, 200, 0))
+	is.Error(ValidateEdgeStackRollout(portainer.EdgeStackRolloutPercentage, 101, 10))
+	is.Error(ValidateEdgeStackRollout(portainer.EdgeStackRolloutBatch, 0, 10))
+	is.Error(ValidateEdgeStackRollout(portainer.EdgeStackRolloutBatch, 1, 101))
+	is.Error(ValidateEdgeStackRollout(0, 1, 10))
+}
+
+func TestNewEdgeStackRollout(t *testing.T) {
+	is := assert.New(t)
+
+	stack := newRolloutTestStack(portainer.EdgeStackRolloutPercentage, 30, 0, 5, 3, 1, 3, 4, 2)
+	is.Equal([]portainer.EndpointID{1, 2, 3, 4, 5}, stack.Rollout.Endpoints, "endpoints should be deduplicated and sorted")
+	is.Equal(2, stack.Rollout.Released, "30% of 5 endpoints should round up to 2")
+	is.Equal(portainer.EdgeStackRolloutInProgress, stack.Rollout.Status)
+
+	is.Equal(2, EdgeStackEndpointVersion(stack, 1))
+	is.Equal(2, EdgeStackEndpointVersion(stack, 2))
+	is.Equal(1, EdgeStackEndpointVersion(stack, 3))
+	is.Equal(2, EdgeStackEndpointVersion(stack, 6), "endpoints outside of the rollout should get the current version")
+
+	stack = newRolloutTestStack(portainer.EdgeStackRolloutBatch, 10, 0, 1, 2)
+	is.Equal(portainer.EdgeStackRolloutCompleted, stack.Rollout.Status, "a batch covering every endpoint should complete the rollout")
+}
+
+func TestUpdateEdgeStackRollout(t *testing.T) {
+	is := assert.New(t)
+
+	stack := newRolloutTestStack(portainer.EdgeStackRolloutBatch, 2, 0, 1, 2, 3, 4, 5)
+	stack.Status[3] = portainer.EdgeStackStatus{Type: portainer.StatusOk, EndpointID: 3}
+
+	reportStatus(stack, 1, portainer.StatusOk)
+	is.Equal(2, stack.Rollout.Released, "the next batch should wait for every released endpoint")
+
+	reportStatus(stack, 2, portainer.StatusOk)
+	is.Equal(4, stack.Rollout.Released)
+	_, ok := stack.Status[3]
+	is.False(ok, "the status of the previous version should be cleared when an endpoint is released")
+
+	reportStatus(stack, 3, portainer.StatusOk)
+	reportStatus(stack, 4, portainer.StatusOk)
+	is.Equal(5, stack.Rollout.Released)
+	is.Equal(portainer.EdgeStackRolloutCompleted, stack.Rollout.Status)
+	is.Equal(2, EdgeStackEndpointVersion(stack, 5))
+}
+
+func TestUpdateEdgeStackRollout_ErrorThreshold(t *testing.T) {
+	is := assert.New(t)
+
+	stack := newRolloutTestStack(portainer.EdgeStackRolloutBatch, 4, 25, 1, 2, 3, 4, 5, 6, 7, 8)
+
+	reportStatus(stack, 1, portainer.StatusError)
+	is.Equal(portainer.EdgeStackRolloutInProgress, stack.Rollout.Status, "one failure out of four is within the threshold")
+
+	reportStatus(stack, 2, portainer.StatusError)
+	is.Equal(portainer.EdgeStackRolloutPaused, stack.Rollout.Status)
+	is.NotEmpty(stack.Rollout.PauseReason)
+
+	reportStatus(stack, 3, portainer.StatusOk)
+	reportStatus(stack, 4, portainer.StatusOk)
+	is.Equal(4, stack.Rollout.Released, "a paused rollout should not release batches")
+
+	is.NoError(ResumeEdgeStackRollout(stack))
+	is.ElementsMatch([]portainer.EndpointID{1, 2}, stack.Rollout.AcknowledgedFailures)
+	is.Equal(8, stack.Rollout.Released, "acknowledged failures should not block the next batch")
+	is.Equal(portainer.EdgeStackRolloutCompleted, stack.Rollout.Status)
+}
+
+func TestEdgeStackRolloutTransitions(t *testing.T) {
+	is := assert.New(t)
+
+	stack := newRolloutTestStack(portainer.EdgeStackRolloutBatch, 1, 0, 1, 2, 3)
+
+	is.Equal(ErrRolloutNotPaused, ResumeEdgeStackRollout(stack))
+	is.NoError(PauseEdgeStackRollout(stack))
+	is.Equal(ErrRolloutNotInProgress, PauseEdgeStackRollout(stack))
+
+	reportStatus(stack, 1, portainer.StatusOk)
+	is.Equal(1, stack.Rollout.Released)
+
+	is.NoError(ResumeEdgeStackRollout(stack))
+	is.Equal(2, stack.Rollout.Released, "resuming should release the batches that were waiting")
+
+	is.NoError(AbortEdgeStackRollout(stack))
+	is.Equal(ErrRolloutFinished, AbortEdgeStackRollout(stack))
+	is.Equal(1, EdgeStackEndpointVersion(stack, 3), "unreleased endpoints should keep the previous version after an abort")
+}
+
+func TestRemoveRolloutEndpoints(t *testing.T) {
+	is := assert.New(t)
+
+	stack := newRolloutTestStack(portainer.EdgeStackRolloutBatch, 2, 0, 1, 2, 3, 4)
+	RemoveRolloutEndpoints(stack.Rollout, map[portainer.EndpointID]bool{2: true, 4: true})
+
+	is.Equal([]portainer.EndpointID{1, 3}, stack.Rollout.Endpoints)
+	is.Equal(1, stack.Rollout.Released)
+
+	RemoveRolloutEndpoints(stack.Rollout, map[portainer.EndpointID]bool{3: true})
+	is.Equal(portainer.EdgeStackRolloutCompleted, stack.Rollout.Status)
+}
@@ -0,0 +1,65 @@
+package edge
+
+import (
+	"errors"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// ValidateEdgeSnapshot verifies that a snapshot pushed by an Edge agent matches the platform of the endpoint
+// and carries consistent counters. Snapshots without a time, or dated in the future, are dated now.
+func ValidateEdgeSnapshot(endpoint *portainer.Endpoint, dockerSnapshot *portainer.DockerSnapshot, kubernetesSnapshot *portainer.KubernetesSnapshot, now time.Time) error {
+	switch endpoint.Type {
+	case portainer.EdgeAgentOnDockerEnvironment:
+		if dockerSnapshot == nil || kubernetesSnapshot != nil {
+			return errors.New("a Docker snapshot is expected from an Edge Docker endpoint")
+		}
+
+		if dockerSnapshot.TotalCPU < 0 || dockerSnapshot.TotalMemory < 0 || dockerSnapshot.RunningContainerCount < 0 ||
+			dockerSnapshot.StoppedContainerCount < 0 || dockerSnapshot.HealthyContainerCount < 0 || dockerSnapshot.UnhealthyContainerCount < 0 ||
+			dockerSnapshot.VolumeCount < 0 || dockerSnapshot.ImageCount < 0 || dockerSnapshot.ServiceCount < 0 ||
+			dockerSnapshot.StackCount < 0 || dockerSnapshot.NodeCount < 0 {
+			return errors.New("the Docker snapshot counters must not be negative")
+		}
+
+		dockerSnapshot.Time = snapshotTime(dockerSnapshot.Time, now)
+	case portainer.EdgeAgentOnKubernetesEnvironment:
+		if kubernetesSnapshot == nil || dockerSnapshot != nil {
+			return errors.New("a Kubernetes snapshot is expected from an Edge Kubernetes endpoint")
+		}
+
+		if kubernetesSnapshot.NodeCount < 0 || kubernetesSnapshot.TotalCPU < 0 || kubernetesSnapshot.TotalMemory < 0 {
+			return errors.New("the Kubernetes snapshot counters must not be negative")
+		}
+
+		kubernetesSnapshot.Time = snapshotTime(kubernetesSnapshot.Time, now)
+	default:
+		return errors.New("the endpoint is not an Edge endpoint")
+	}
+
+	return nil
+}
+
+func snapshotTime(value int64, now time.Time) int64 {
+	if value <= 0 || value > now.Unix() {
+		return now.Unix()
+	}
+	return value
+}
+
+// EdgeSnapshotPushInterval returns the interval at which Edge agents push their snapshot,
+// a zero interval disables the push. An empty setting uses the default interval.
+func EdgeSnapshotPushInterval(settings *portainer.Settings) time.Duration {
+	value := settings.EdgeSnapshotPushInterval
+	if value == "" {
+		value = portainer.DefaultEdgeSnapshotPushInterval
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return 0
+	}
+
+	return interval
+}
@@ -0,0 +1,35 @@
+package edge
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEdgeSnapshot(t *testing.T) {
+	now := time.Unix(1625132400, 0)
+	docker := &portainer.Endpoint{Type: portainer.EdgeAgentOnDockerEnvironment}
+	kubernetes := &portainer.Endpoint{Type: portainer.EdgeAgentOnKubernetesEnvironment}
+
+	dockerSnapshot := &portainer.DockerSnapshot{Time: now.Unix() + 3600, RunningContainerCount: 4}
+	assert.NoError(t, ValidateEdgeSnapshot(docker, dockerSnapshot, nil, now))
+	assert.Equal(t, now.Unix(), dockerSnapshot.Time)
+
+	dockerSnapshot = &portainer.DockerSnapshot{Time: now.Unix() - 60}
+	assert.NoError(t, ValidateEdgeSnapshot(docker, dockerSnapshot, nil, now))
+	assert.Equal(t, now.Unix()-60, dockerSnapshot.Time)
+
+	assert.Error(t, ValidateEdgeSnapshot(docker, nil, &portainer.KubernetesSnapshot{}, now))
+	assert.Error(t, ValidateEdgeSnapshot(docker, &portainer.DockerSnapshot{ImageCount: -1}, nil, now))
+	assert.Error(t, ValidateEdgeSnapshot(kubernetes, &portainer.DockerSnapshot{}, nil, now))
+	assert.NoError(t, ValidateEdgeSnapshot(kubernetes, nil, &portainer.KubernetesSnapshot{NodeCount: 3}, now))
+	assert.Error(t, ValidateEdgeSnapshot(&portainer.Endpoint{Type: portainer.DockerEnvironment}, &portainer.DockerSnapshot{}, nil, now))
+}
+
+func TestEdgeSnapshotPushInterval(t *testing.T) {
+	assert.Equal(t, 5*time.Minute, EdgeSnapshotPushInterval(&portainer.Settings{}))
+	assert.Equal(t, time.Hour, EdgeSnapshotPushInterval(&portainer.Settings{EdgeSnapshotPushInterval: "1h"}))
+	assert.Equal(t, time.Duration(0), EdgeSnapshotPushInterval(&portainer.Settings{EdgeSnapshotPushInterval: "0"}))
+}
@@ -0,0 +1,40 @@
+package edge
+
+import (
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// MaxEdgeStackStatusOutputSize is the maximum size in bytes of the deployment output kept in a status history entry
+const MaxEdgeStackStatusOutputSize = 64 * 1024
+
+// TruncateEdgeStackStatusOutput keeps the last bytes of a deployment output, where the deployment errors are
+func TruncateEdgeStackStatusOutput(output string) string {
+	if len(output) <= MaxEdgeStackStatusOutputSize {
+		return output
+	}
+
+	return output[len(output)-MaxEdgeStackStatusOutputSize:]
+}
+
+// EdgeStackStatusHistoryLimit returns the identifier of the most recent entry to delete from the status history
+// of an edge stack on an endpoint so that it holds at most maxEntries entries, none older than the retention.
+// Entries must be ordered by creation. It returns 0 when no entry needs to be deleted.
+func EdgeStackStatusHistoryLimit(entries []portainer.EdgeStackStatusHistoryEntry, maxEntries int, retention time.Duration, now time.Time) int {
+	count := 0
+	if len(entries) > maxEntries {
+		count = len(entries) - maxEntries
+	}
+
+	retentionLimit := now.Add(-retention).Unix()
+	for count < len(entries) && entries[count].Time < retentionLimit {
+		count++
+	}
+
+	if count == 0 {
+		return 0
+	}
+
+	return entries[count-1].ID
+}
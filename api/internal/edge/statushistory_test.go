@@ -0,0 +1,39 @@
+package edge
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateEdgeStackStatusOutput(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal("deployed", TruncateEdgeStackStatusOutput("deployed"))
+
+	output := strings.Repeat("a", MaxEdgeStackStatusOutputSize) + "error"
+	truncated := TruncateEdgeStackStatusOutput(output)
+	is.Len(truncated, MaxEdgeStackStatusOutputSize)
+	is.True(strings.HasSuffix(truncated, "error"), "the end of the output should be kept")
+}
+
+func TestEdgeStackStatusHistoryLimit(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Unix(10000, 0)
+	entries := []portainer.EdgeStackStatusHistoryEntry{
+		{ID: 3, Time: 1000},
+		{ID: 7, Time: 8000},
+		{ID: 8, Time: 9000},
+		{ID: 12, Time: 9500},
+	}
+
+	is.Equal(0, EdgeStackStatusHistoryLimit(entries, 10, time.Hour*24, now))
+	is.Equal(3, EdgeStackStatusHistoryLimit(entries, 10, time.Hour, now), "entries older than the retention should be deleted")
+	is.Equal(7, EdgeStackStatusHistoryLimit(entries, 2, time.Hour*24, now), "oldest entries above the maximum should be deleted")
+	is.Equal(12, EdgeStackStatusHistoryLimit(entries, 10, time.Second, now))
+	is.Equal(0, EdgeStackStatusHistoryLimit(nil, 10, time.Hour, now))
+}
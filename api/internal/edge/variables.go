@@ -0,0 +1,131 @@
+package edge
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// variablePattern matches the ${{ NAME }} placeholders of an edge stack file. This syntax does not collide
+// with the Compose ${NAME} interpolation nor with the {{.Node.Hostname}} Swarm templates.
+var variablePattern = regexp.MustCompile(`\$\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateVariableNames returns an error if one of the variable names cannot be referenced from an edge stack file
+func ValidateVariableNames(variables map[string]string) error {
+	for name := range variables {
+		if !variableNamePattern.MatchString(name) {
+			return fmt.Errorf("Invalid variable name %q, names must start with a letter or an underscore and only contain letters, digits and underscores", name)
+		}
+	}
+	return nil
+}
+
+// ReferencedVariables returns the sorted names of the variables referenced in an edge stack file
+func ReferencedVariables(content []byte) []string {
+	set := map[string]bool{}
+	for _, match := range variablePattern.FindAllSubmatch(content, -1) {
+		set[string(match[1])] = true
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// EndpointVariables returns the variables of an endpoint for an edge stack. Variables of the edge groups of the
+// edge stack the endpoint belongs to are overridden by the variables of the endpoint group, themselves overridden
+// by the variables of the endpoint. When several edge groups define a variable, the last one in the edge stack wins.
+func EndpointVariables(edgeGroupIDs []portainer.EdgeGroupID, endpoint *portainer.Endpoint, endpointGroups []portainer.EndpointGroup, edgeGroups []portainer.EdgeGroup) map[string]string {
+	var endpointGroup portainer.EndpointGroup
+	for _, group := range endpointGroups {
+		if group.ID == endpoint.GroupID {
+			endpointGroup = group
+			break
+		}
+	}
+
+	variables := map[string]string{}
+	for _, edgeGroupID := range edgeGroupIDs {
+		for idx := range edgeGroups {
+			edgeGroup := &edgeGroups[idx]
+			if edgeGroup.ID == edgeGroupID && edgeGroupRelatedToEndpoint(edgeGroup, endpoint, &endpointGroup) {
+				mergeVariables(variables, edgeGroup.Variables)
+			}
+		}
+	}
+
+	mergeVariables(variables, endpointGroup.EdgeVariables)
+	mergeVariables(variables, endpoint.EdgeVariables)
+
+	return variables
+}
+
+// RenderEdgeStackFile replaces the variable placeholders of an edge stack file. It fails when a referenced
+// variable is not defined.
+func RenderEdgeStackFile(content []byte, variables map[string]string) ([]byte, error) {
+	missing := missingVariables(content, variables)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("undefined variables: %s", strings.Join(missing, ", "))
+	}
+
+	return variablePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		name := variablePattern.FindSubmatch(match)[1]
+		return []byte(variables[string(name)])
+	}), nil
+}
+
+// ValidateEdgeStackVariables checks that every variable referenced in an edge stack file is defined for each
+// endpoint the edge stack is deployed on
+func ValidateEdgeStackVariables(content []byte, edgeGroupIDs []portainer.EdgeGroupID, endpoints []portainer.Endpoint, endpointGroups []portainer.EndpointGroup, edgeGroups []portainer.EdgeGroup) error {
+	if len(ReferencedVariables(content)) == 0 {
+		return nil
+	}
+
+	relatedEndpoints, err := EdgeStackRelatedEndpoints(edgeGroupIDs, endpoints, endpointGroups, edgeGroups)
+	if err != nil {
+		return err
+	}
+	related := map[portainer.EndpointID]bool{}
+	for _, endpointID := range relatedEndpoints {
+		related[endpointID] = true
+	}
+
+	for idx := range endpoints {
+		endpoint := &endpoints[idx]
+		if !related[endpoint.ID] {
+			continue
+		}
+
+		missing := missingVariables(content, EndpointVariables(edgeGroupIDs, endpoint, endpointGroups, edgeGroups))
+		if len(missing) > 0 {
+			return fmt.Errorf("undefined variables for endpoint %s: %s", endpoint.Name, strings.Join(missing, ", "))
+		}
+	}
+
+	return nil
+}
+
+func missingVariables(content []byte, variables map[string]string) []string {
+	missing := []string{}
+	for _, name := range ReferencedVariables(content) {
+		if _, ok := variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func mergeVariables(dst, src map[string]string) {
+	for name, value := range src {
+		dst[name] = value
+	}
+}
@@ -0,0 +1,85 @@
+package edge
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+const variablesTestStackFile = `services:
+  web:
+    image: nginx
+    hostname: "{{.Node.Hostname}}"
+    environment:
+      SITE: ${{ SITE_ID }}
+      UPSTREAM: ${{UPSTREAM_URL}}
+      HOME: ${HOME}
+`
+
+func TestReferencedVariables(t *testing.T) {
+	is := assert.New(t)
+
+	is.Equal([]string{"SITE_ID", "UPSTREAM_URL"}, ReferencedVariables([]byte(variablesTestStackFile)))
+	is.Empty(ReferencedVariables([]byte("image: ${IMAGE}")))
+}
+
+func TestValidateVariableNames(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateVariableNames(map[string]string{"SITE_ID": "1", "_private": "2"}))
+	is.Error(ValidateVariableNames(map[string]string{"1SITE": "1"}))
+	is.Error(ValidateVariableNames(map[string]string{"SITE-ID": "1"}))
+}
+
+func TestEndpointVariables(t *testing.T) {
+	is := assert.New(t)
+
+	endpoint := &portainer.Endpoint{ID: 1, GroupID: 2, EdgeVariables: map[string]string{"SITE_ID": "endpoint"}}
+	endpointGroups := []portainer.EndpointGroup{{ID: 2, EdgeVariables: map[string]string{"SITE_ID": "group", "REGION": "group"}}}
+	edgeGroups := []portainer.EdgeGroup{
+		{ID: 1, Endpoints: []portainer.EndpointID{1}, Variables: map[string]string{"REGION": "edge1", "UPSTREAM_URL": "edge1", "REPLICAS": "1"}},
+		{ID: 2, Endpoints: []portainer.EndpointID{1}, Variables: map[string]string{"REPLICAS": "2"}},
+		{ID: 3, Endpoints: []portainer.EndpointID{5}, Variables: map[string]string{"OTHER": "3"}},
+	}
+
+	variables := EndpointVariables([]portainer.EdgeGroupID{1, 2, 3}, endpoint, endpointGroups, edgeGroups)
+	is.Equal(map[string]string{
+		"SITE_ID":      "endpoint",
+		"REGION":       "group",
+		"UPSTREAM_URL": "edge1",
+		"REPLICAS":     "2",
+	}, variables)
+}
+
+func TestRenderEdgeStackFile(t *testing.T) {
+	is := assert.New(t)
+
+	rendered, err := RenderEdgeStackFile([]byte(variablesTestStackFile), map[string]string{"SITE_ID": "42", "UPSTREAM_URL": "http://upstream"})
+	is.NoError(err)
+	is.Contains(string(rendered), "SITE: 42\n")
+	is.Contains(string(rendered), "UPSTREAM: http://upstream\n")
+	is.Contains(string(rendered), "HOME: ${HOME}", "compose interpolation should be left untouched")
+	is.Contains(string(rendered), `"{{.Node.Hostname}}"`, "swarm templates should be left untouched")
+
+	_, err = RenderEdgeStackFile([]byte(variablesTestStackFile), map[string]string{"SITE_ID": "42"})
+	is.EqualError(err, "undefined variables: UPSTREAM_URL")
+}
+
+func TestValidateEdgeStackVariables(t *testing.T) {
+	is := assert.New(t)
+
+	endpoints := []portainer.Endpoint{
+		{ID: 1, Name: "site-1", GroupID: 1, EdgeVariables: map[string]string{"SITE_ID": "1"}},
+		{ID: 2, Name: "site-2", GroupID: 1},
+	}
+	endpointGroups := []portainer.EndpointGroup{{ID: 1, EdgeVariables: map[string]string{"UPSTREAM_URL": "http://upstream"}}}
+	edgeGroups := []portainer.EdgeGroup{
+		{ID: 1, Endpoints: []portainer.EndpointID{1}},
+		{ID: 2, Endpoints: []portainer.EndpointID{1, 2}},
+	}
+
+	is.NoError(ValidateEdgeStackVariables([]byte(variablesTestStackFile), []portainer.EdgeGroupID{1}, endpoints, endpointGroups, edgeGroups))
+	is.EqualError(ValidateEdgeStackVariables([]byte(variablesTestStackFile), []portainer.EdgeGroupID{2}, endpoints, endpointGroups, edgeGroups), "undefined variables for endpoint site-2: SITE_ID")
+	is.NoError(ValidateEdgeStackVariables([]byte("image: nginx"), []portainer.EdgeGroupID{2}, endpoints, endpointGroups, edgeGroups))
+}
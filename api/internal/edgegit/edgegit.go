@@ -0,0 +1,220 @@
+package edgegit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/internal/edge"
+)
+
+var (
+	// ErrNotGitBacked is returned when pulling an edge stack that was not deployed from a git repository
+	ErrNotGitBacked = errors.New("Edge stack is not deployed from a git repository")
+	// ErrRolloutInProgress is returned when pulling an edge stack while a rollout of its current version is in progress
+	ErrRolloutInProgress = errors.New("A rollout of the edge stack is in progress")
+)
+
+// Service watches the repositories of the edge stacks deployed from git and bumps
+// the version of an edge stack when the reference it is deployed from moves, so that
+// the edge agents redeploy it.
+type Service struct {
+	mu           sync.Mutex
+	pollInterval time.Duration
+	dataStore    portainer.DataStore
+	fileService  portainer.FileService
+	gitService   portainer.GitService
+	shutdownCtx  context.Context
+}
+
+// NewService creates a new instance of a service
+func NewService(pollInterval string, dataStore portainer.DataStore, fileService portainer.FileService, gitService portainer.GitService, shutdownCtx context.Context) (*Service, error) {
+	interval, err := time.ParseDuration(pollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		pollInterval: interval,
+		dataStore:    dataStore,
+		fileService:  fileService,
+		gitService:   gitService,
+		shutdownCtx:  shutdownCtx,
+	}, nil
+}
+
+// Start starts a background routine checking the repositories of the edge stacks at every poll interval
+func (service *Service) Start() {
+	ticker := time.NewTicker(service.pollInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				err := service.pullEdgeStacks()
+				if err != nil {
+					log.Printf("[ERROR] [internal,edgegit] [message: background schedule error (edge stack git pull).] [error: %s]", err)
+				}
+			case <-service.shutdownCtx.Done():
+				log.Println("[DEBUG] [internal,edgegit] [message: shutting down edge stack git pull]")
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// PullEdgeStack checks the repository of an edge stack and, when the reference moved to a new commit,
+// clones it again and bumps the edge stack version. It returns the edge stack, updated or not.
+func (service *Service) PullEdgeStack(edgeStackID portainer.EdgeStackID) (*portainer.EdgeStack, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	edgeStack, err := service.dataStore.EdgeStack().EdgeStack(edgeStackID)
+	if err != nil {
+		return nil, err
+	}
+
+	if edgeStack.GitConfig == nil {
+		return nil, ErrNotGitBacked
+	}
+
+	if edgeStack.Rollout != nil && (edgeStack.Rollout.Status == portainer.EdgeStackRolloutInProgress || edgeStack.Rollout.Status == portainer.EdgeStackRolloutPaused) {
+		return nil, ErrRolloutInProgress
+	}
+
+	username, password := credentials(edgeStack)
+
+	commitID, err := service.gitService.LatestCommitID(edgeStack.GitConfig.URL, edgeStack.GitConfig.ReferenceName, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the latest commit of the repository: %w", err)
+	}
+
+	if commitID == edgeStack.GitConfig.ConfigHash {
+		return edgeStack, nil
+	}
+
+	err = service.cloneRepository(edgeStack, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	edgeStack.GitConfig.ConfigHash = commitID
+	edgeStack.Version++
+	edge.RecordEdgeStackCommit(edgeStack, commitID)
+	edgeStack.Rollout = nil
+	edgeStack.Status = map[portainer.EndpointID]portainer.EdgeStackStatus{}
+
+	err = service.dataStore.EdgeStack().UpdateEdgeStack(edgeStack.ID, edgeStack)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[INFO] [internal,edgegit] [edge_stack: %s] [commit: %s] [message: edge stack updated to version %d]", edgeStack.Name, commitID, edgeStack.Version)
+
+	return edgeStack, nil
+}
+
+func (service *Service) pullEdgeStacks() error {
+	edgeStacks, err := service.dataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return err
+	}
+
+	for _, edgeStack := range edgeStacks {
+		if edgeStack.GitConfig == nil {
+			continue
+		}
+
+		_, err := service.PullEdgeStack(edgeStack.ID)
+		if err == ErrRolloutInProgress {
+			continue
+		} else if err != nil {
+			log.Printf("[ERROR] [internal,edgegit] [edge_stack: %s] [message: unable to pull the edge stack repository] [error: %s]", edgeStack.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// cloneRepository replaces the project of the edge stack with a fresh clone, the previous project
+// is restored when the clone fails or when the new stack file references undefined variables
+func (service *Service) cloneRepository(edgeStack *portainer.EdgeStack, username, password string) error {
+	backupProjectPath := fmt.Sprintf("%s-old", edgeStack.ProjectPath)
+	err := filesystem.MoveDirectory(edgeStack.ProjectPath, backupProjectPath)
+	if err != nil {
+		return fmt.Errorf("unable to move git repository directory: %w", err)
+	}
+
+	err = service.gitService.CloneRepository(edgeStack.ProjectPath, edgeStack.GitConfig.URL, edgeStack.GitConfig.ReferenceName, username, password)
+	if err != nil {
+		service.restoreProject(edgeStack.ProjectPath, backupProjectPath)
+		return fmt.Errorf("unable to clone git repository: %w", err)
+	}
+
+	err = service.validateVariables(edgeStack)
+	if err != nil {
+		service.restoreProject(edgeStack.ProjectPath, backupProjectPath)
+		return err
+	}
+
+	err = service.fileService.RemoveDirectory(backupProjectPath)
+	if err != nil {
+		log.Printf("[WARN] [internal,edgegit] [error: %s] [message: unable to remove git repository directory]", err)
+	}
+
+	return nil
+}
+
+func (service *Service) restoreProject(projectPath, backupProjectPath string) {
+	err := service.fileService.RemoveDirectory(projectPath)
+	if err != nil {
+		log.Printf("[WARN] [internal,edgegit] [error: %s] [message: unable to remove git repository directory]", err)
+	}
+
+	err = filesystem.MoveDirectory(backupProjectPath, projectPath)
+	if err != nil {
+		log.Printf("[WARN] [internal,edgegit] [error: %s] [message: failed restoring backup folder]", err)
+	}
+}
+
+func (service *Service) validateVariables(edgeStack *portainer.EdgeStack) error {
+	stackFileContent, err := service.fileService.GetFileContent(path.Join(edgeStack.ProjectPath, edgeStack.EntryPoint))
+	if err != nil {
+		return fmt.Errorf("unable to read the stack file: %w", err)
+	}
+
+	if len(edge.ReferencedVariables(stackFileContent)) == 0 {
+		return nil
+	}
+
+	endpoints, err := service.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		return err
+	}
+
+	endpointGroups, err := service.dataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	edgeGroups, err := service.dataStore.EdgeGroup().EdgeGroups()
+	if err != nil {
+		return err
+	}
+
+	return edge.ValidateEdgeStackVariables(stackFileContent, edgeStack.EdgeGroups, endpoints, endpointGroups, edgeGroups)
+}
+
+func credentials(edgeStack *portainer.EdgeStack) (string, string) {
+	if edgeStack.GitConfig.Authentication == nil {
+		return "", ""
+	}
+
+	return edgeStack.GitConfig.Authentication.Username, edgeStack.GitConfig.Authentication.Password
+}
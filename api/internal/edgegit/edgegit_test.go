@@ -0,0 +1,120 @@
+package edgegit
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/bolttest"
+	"github.com/portainer/portainer/api/filesystem"
+	gittypes "github.com/portainer/portainer/api/git/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGitService struct {
+	commitID string
+	clones   int
+}
+
+func (g *fakeGitService) CloneRepository(destination string, repositoryURL, referenceName, username, password string) error {
+	g.clones++
+	err := os.MkdirAll(destination, 0700)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(destination, "docker-compose.yml"), []byte(g.commitID), 0600)
+}
+
+func (g *fakeGitService) LatestCommitID(repositoryURL, referenceName, username, password string) (string, error) {
+	return g.commitID, nil
+}
+
+func newTestService(t *testing.T, gitService portainer.GitService) (*Service, portainer.DataStore, string) {
+	store, teardown := bolttest.MustNewTestStore(false)
+	t.Cleanup(teardown)
+
+	dir, err := ioutil.TempDir("", "edgegit")
+	if err != nil {
+		t.Fatalf("failed to create a temp dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	fileService, err := filesystem.NewService(dir, "")
+	if err != nil {
+		t.Fatalf("failed to create the file service: %s", err)
+	}
+
+	service, err := NewService("1m", store, fileService, gitService, context.Background())
+	if err != nil {
+		t.Fatalf("failed to create the service: %s", err)
+	}
+
+	return service, store, dir
+}
+
+func TestPullEdgeStack(t *testing.T) {
+	is := assert.New(t)
+
+	gitService := &fakeGitService{commitID: "abc"}
+	service, store, dir := newTestService(t, gitService)
+
+	projectPath := path.Join(dir, "edge_stacks", "1")
+	is.NoError(gitService.CloneRepository(projectPath, "", "", "", ""))
+
+	edgeStack := &portainer.EdgeStack{
+		ID:          1,
+		Name:        "stack",
+		ProjectPath: projectPath,
+		EntryPoint:  "docker-compose.yml",
+		Version:     1,
+		Status:      map[portainer.EndpointID]portainer.EdgeStackStatus{1: {Type: portainer.StatusOk, EndpointID: 1, CommitHash: "abc"}},
+		GitConfig:   &gittypes.RepoConfig{URL: "https://example.com/repo.git", ReferenceName: "refs/heads/main", ConfigHash: "abc"},
+	}
+	is.NoError(store.EdgeStack().CreateEdgeStack(edgeStack))
+
+	pulled, err := service.PullEdgeStack(1)
+	is.NoError(err)
+	is.Equal(1, pulled.Version, "the version should not change while the reference does not move")
+	is.Equal(1, gitService.clones)
+
+	gitService.commitID = "def"
+	pulled, err = service.PullEdgeStack(1)
+	is.NoError(err)
+	is.Equal(2, pulled.Version)
+	is.Equal("def", pulled.GitConfig.ConfigHash)
+	is.Equal("def", pulled.CommitHashes[2], "the commit of the new version should be recorded")
+	is.Empty(pulled.Status, "statuses of the previous version should be cleared")
+
+	content, err := ioutil.ReadFile(path.Join(projectPath, "docker-compose.yml"))
+	is.NoError(err)
+	is.Equal("def", string(content), "the repository should be cloned again")
+	is.NoDirExists(projectPath + "-old")
+
+	stored, err := store.EdgeStack().EdgeStack(1)
+	is.NoError(err)
+	is.Equal(2, stored.Version)
+}
+
+func TestPullEdgeStack_Refused(t *testing.T) {
+	is := assert.New(t)
+
+	service, store, _ := newTestService(t, &fakeGitService{commitID: "def"})
+
+	is.NoError(store.EdgeStack().CreateEdgeStack(&portainer.EdgeStack{ID: 1, Name: "file", Version: 1}))
+	is.NoError(store.EdgeStack().CreateEdgeStack(&portainer.EdgeStack{
+		ID:        2,
+		Name:      "rollout",
+		Version:   2,
+		GitConfig: &gittypes.RepoConfig{URL: "https://example.com/repo.git", ConfigHash: "abc"},
+		Rollout:   &portainer.EdgeStackRollout{Status: portainer.EdgeStackRolloutPaused},
+	}))
+
+	_, err := service.PullEdgeStack(1)
+	is.Equal(ErrNotGitBacked, err)
+
+	_, err = service.PullEdgeStack(2)
+	is.Equal(ErrRolloutInProgress, err)
+}
@@ -0,0 +1,248 @@
+package integrity
+
+import (
+	"fmt"
+	"strconv"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/stackutils"
+)
+
+// Issue describes a record of the datastore referencing data that does not exist anymore.
+type Issue struct {
+	// Type of the record holding the dangling reference (stack, resource control...)
+	Resource string
+	// Identifier of the record holding the dangling reference
+	ID string
+	// Description of the dangling reference
+	Reason string
+}
+
+func (issue Issue) String() string {
+	return fmt.Sprintf("%s %s: %s", issue.Resource, issue.ID, issue.Reason)
+}
+
+type checker struct {
+	dataStore      portainer.DataStore
+	endpoints      map[portainer.EndpointID]bool
+	endpointGroups map[portainer.EndpointGroupID]bool
+	users          map[portainer.UserID]bool
+	teams          map[portainer.TeamID]bool
+	edgeStacks     map[portainer.EdgeStackID]bool
+	issues         []Issue
+}
+
+// Check verifies the referential integrity of the datastore and returns every dangling reference found:
+// stacks pointing at deleted endpoints, orphan resource controls, endpoint relations without
+// endpoints and tags referencing missing endpoints or endpoint groups.
+// The datastore is never modified.
+func Check(dataStore portainer.DataStore) ([]Issue, error) {
+	checker := &checker{
+		dataStore: dataStore,
+		issues:    make([]Issue, 0),
+	}
+
+	err := checker.load()
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []func() error{
+		checker.checkStacks,
+		checker.checkResourceControls,
+		checker.checkEndpointRelations,
+		checker.checkTags,
+	}
+
+	for _, check := range checks {
+		err := check()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return checker.issues, nil
+}
+
+func (checker *checker) load() error {
+	endpoints, err := checker.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		return err
+	}
+
+	checker.endpoints = make(map[portainer.EndpointID]bool)
+	for _, endpoint := range endpoints {
+		checker.endpoints[endpoint.ID] = true
+	}
+
+	endpointGroups, err := checker.dataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	checker.endpointGroups = make(map[portainer.EndpointGroupID]bool)
+	for _, endpointGroup := range endpointGroups {
+		checker.endpointGroups[endpointGroup.ID] = true
+	}
+
+	users, err := checker.dataStore.User().Users()
+	if err != nil {
+		return err
+	}
+
+	checker.users = make(map[portainer.UserID]bool)
+	for _, user := range users {
+		checker.users[user.ID] = true
+	}
+
+	teams, err := checker.dataStore.Team().Teams()
+	if err != nil {
+		return err
+	}
+
+	checker.teams = make(map[portainer.TeamID]bool)
+	for _, team := range teams {
+		checker.teams[team.ID] = true
+	}
+
+	edgeStacks, err := checker.dataStore.EdgeStack().EdgeStacks()
+	if err != nil {
+		return err
+	}
+
+	checker.edgeStacks = make(map[portainer.EdgeStackID]bool)
+	for _, edgeStack := range edgeStacks {
+		checker.edgeStacks[edgeStack.ID] = true
+	}
+
+	return nil
+}
+
+func (checker *checker) report(resource, id, reason string, args ...interface{}) {
+	checker.issues = append(checker.issues, Issue{
+		Resource: resource,
+		ID:       id,
+		Reason:   fmt.Sprintf(reason, args...),
+	})
+}
+
+func (checker *checker) checkStacks() error {
+	stacks, err := checker.dataStore.Stack().Stacks()
+	if err != nil {
+		return err
+	}
+
+	for _, stack := range stacks {
+		if !checker.endpoints[stack.EndpointID] {
+			checker.report("stack", strconv.Itoa(int(stack.ID)), "endpoint %d does not exist", stack.EndpointID)
+		}
+	}
+
+	return nil
+}
+
+func (checker *checker) checkResourceControls() error {
+	stacks, err := checker.dataStore.Stack().Stacks()
+	if err != nil {
+		return err
+	}
+
+	stackResourceIDs := make(map[string]bool)
+	for _, stack := range stacks {
+		stackResourceIDs[stackutils.ResourceControlID(stack.EndpointID, stack.Name)] = true
+		// resource controls created before stacks were scoped to an endpoint use the stack name
+		stackResourceIDs[stack.Name] = true
+	}
+
+	customTemplates, err := checker.dataStore.CustomTemplate().CustomTemplates()
+	if err != nil {
+		return err
+	}
+
+	customTemplateResourceIDs := make(map[string]bool)
+	for _, customTemplate := range customTemplates {
+		customTemplateResourceIDs[strconv.Itoa(int(customTemplate.ID))] = true
+	}
+
+	resourceControls, err := checker.dataStore.ResourceControl().ResourceControls()
+	if err != nil {
+		return err
+	}
+
+	for _, resourceControl := range resourceControls {
+		id := strconv.Itoa(int(resourceControl.ID))
+
+		switch resourceControl.Type {
+		case portainer.StackResourceControl:
+			if !stackResourceIDs[resourceControl.ResourceID] {
+				checker.report("resource control", id, "stack %s does not exist", resourceControl.ResourceID)
+			}
+		case portainer.CustomTemplateResourceControl:
+			if !customTemplateResourceIDs[resourceControl.ResourceID] {
+				checker.report("resource control", id, "custom template %s does not exist", resourceControl.ResourceID)
+			}
+		}
+
+		for _, access := range resourceControl.UserAccesses {
+			if !checker.users[access.UserID] {
+				checker.report("resource control", id, "user %d does not exist", access.UserID)
+			}
+		}
+
+		for _, access := range resourceControl.TeamAccesses {
+			if !checker.teams[access.TeamID] {
+				checker.report("resource control", id, "team %d does not exist", access.TeamID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (checker *checker) checkEndpointRelations() error {
+	endpointRelations, err := checker.dataStore.EndpointRelation().EndpointRelations()
+	if err != nil {
+		return err
+	}
+
+	for _, relation := range endpointRelations {
+		id := strconv.Itoa(int(relation.EndpointID))
+
+		if !checker.endpoints[relation.EndpointID] {
+			checker.report("endpoint relation", id, "endpoint %d does not exist", relation.EndpointID)
+		}
+
+		for edgeStackID := range relation.EdgeStacks {
+			if !checker.edgeStacks[edgeStackID] {
+				checker.report("endpoint relation", id, "edge stack %d does not exist", edgeStackID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (checker *checker) checkTags() error {
+	tags, err := checker.dataStore.Tag().Tags()
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		id := strconv.Itoa(int(tag.ID))
+
+		for endpointID := range tag.Endpoints {
+			if !checker.endpoints[endpointID] {
+				checker.report("tag", id, "endpoint %d does not exist", endpointID)
+			}
+		}
+
+		for endpointGroupID := range tag.EndpointGroups {
+			if !checker.endpointGroups[endpointGroupID] {
+				checker.report("tag", id, "endpoint group %d does not exist", endpointGroupID)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package integrity
+
+import (
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/bolttest"
+	"github.com/portainer/portainer/api/internal/stackutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	store, teardown := bolttest.MustNewTestStore(true)
+	defer teardown()
+
+	require.NoError(t, store.Endpoint().CreateEndpoint(&portainer.Endpoint{ID: 1, GroupID: 1}))
+	require.NoError(t, store.EndpointRelation().CreateEndpointRelation(&portainer.EndpointRelation{EndpointID: 1, EdgeStacks: map[portainer.EdgeStackID]bool{}}))
+	require.NoError(t, store.User().CreateUser(&portainer.User{ID: 1, Username: "admin"}))
+
+	require.NoError(t, store.Stack().CreateStack(&portainer.Stack{ID: 1, Name: "healthy", EndpointID: 1}))
+	require.NoError(t, store.Stack().CreateStack(&portainer.Stack{ID: 2, Name: "orphan", EndpointID: 2}))
+
+	require.NoError(t, store.ResourceControl().CreateResourceControl(&portainer.ResourceControl{
+		ResourceID:   stackutils.ResourceControlID(1, "healthy"),
+		Type:         portainer.StackResourceControl,
+		UserAccesses: []portainer.UserResourceAccess{{UserID: 1}},
+	}))
+	require.NoError(t, store.ResourceControl().CreateResourceControl(&portainer.ResourceControl{
+		ResourceID:   stackutils.ResourceControlID(1, "deleted"),
+		Type:         portainer.StackResourceControl,
+		TeamAccesses: []portainer.TeamResourceAccess{{TeamID: 3}},
+	}))
+	require.NoError(t, store.ResourceControl().CreateResourceControl(&portainer.ResourceControl{
+		ResourceID: "container",
+		Type:       portainer.ContainerResourceControl,
+	}))
+
+	require.NoError(t, store.EndpointRelation().CreateEndpointRelation(&portainer.EndpointRelation{EndpointID: 4, EdgeStacks: map[portainer.EdgeStackID]bool{5: true}}))
+
+	require.NoError(t, store.Tag().CreateTag(&portainer.Tag{
+		Name:           "tag",
+		Endpoints:      map[portainer.EndpointID]bool{1: true, 6: true},
+		EndpointGroups: map[portainer.EndpointGroupID]bool{1: true, 7: true},
+	}))
+
+	issues, err := Check(store)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []Issue{
+		{Resource: "stack", ID: "2", Reason: "endpoint 2 does not exist"},
+		{Resource: "resource control", ID: "2", Reason: "stack 1_deleted does not exist"},
+		{Resource: "resource control", ID: "2", Reason: "team 3 does not exist"},
+		{Resource: "endpoint relation", ID: "4", Reason: "endpoint 4 does not exist"},
+		{Resource: "endpoint relation", ID: "4", Reason: "edge stack 5 does not exist"},
+		{Resource: "tag", ID: "1", Reason: "endpoint 6 does not exist"},
+		{Resource: "tag", ID: "1", Reason: "endpoint group 7 does not exist"},
+	}, issues)
+}
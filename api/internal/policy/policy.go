@@ -0,0 +1,234 @@
+package policy
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	portainer "github.com/portainer/portainer/api"
+)
+
+type (
+	// Workload represents a container, a service or a stack service evaluated against the Docker policies
+	Workload struct {
+		// Name identifies the workload in the denial reasons, it is empty for a single container or service
+		Name   string
+		Image  string
+		Labels map[string]string
+		// Ports published on the host, 0 when the port is chosen by the Docker engine
+		PublishedPorts []int
+		HasMemoryLimit bool
+		HasCPULimit    bool
+		// Replicas of a replicated service, nil for the containers and the global services
+		Replicas *uint64
+	}
+
+	// Subject represents the endpoint on which a workload is deployed and the teams of the user deploying it
+	Subject struct {
+		EndpointID      portainer.EndpointID
+		EndpointGroupID portainer.EndpointGroupID
+		TeamIDs         []portainer.TeamID
+	}
+
+	// Violation represents a rule of a policy broken by a workload
+	Violation struct {
+		PolicyID   portainer.DockerPolicyID
+		PolicyName string
+		AuditOnly  bool
+		Reason     string
+	}
+
+	// DenialError is returned when a workload breaks a rule of an enforced policy
+	DenialError struct {
+		Violation Violation
+	}
+)
+
+func (err *DenialError) Error() string {
+	return fmt.Sprintf("denied by policy %q: %s", err.Violation.PolicyName, err.Violation.Reason)
+}
+
+// Applies returns true when the policy is scoped to the subject
+func Applies(policy *portainer.DockerPolicy, subject Subject) bool {
+	if len(policy.EndpointIDs) > 0 || len(policy.EndpointGroupIDs) > 0 {
+		if !containsEndpoint(policy.EndpointIDs, subject.EndpointID) && !containsEndpointGroup(policy.EndpointGroupIDs, subject.EndpointGroupID) {
+			return false
+		}
+	}
+
+	if len(policy.TeamIDs) == 0 {
+		return true
+	}
+
+	for _, teamID := range subject.TeamIDs {
+		if containsTeam(policy.TeamIDs, teamID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Evaluate returns the violations of the policies applying to the subject
+func Evaluate(policies []portainer.DockerPolicy, subject Subject, workload Workload) []Violation {
+	violations := make([]Violation, 0)
+
+	for i := range policies {
+		policy := &policies[i]
+		if !Applies(policy, subject) {
+			continue
+		}
+
+		for _, reason := range evaluateRules(&policy.Rules, workload) {
+			if workload.Name != "" {
+				reason = fmt.Sprintf("%s: %s", workload.Name, reason)
+			}
+
+			violations = append(violations, Violation{
+				PolicyID:   policy.ID,
+				PolicyName: policy.Name,
+				AuditOnly:  policy.AuditOnly,
+				Reason:     reason,
+			})
+		}
+	}
+
+	return violations
+}
+
+// Enforce evaluates the workloads deployed by a user on an endpoint against the stored policies.
+// The violations of the audit only policies are logged, the first violation of an enforced policy
+// is returned as a *DenialError.
+func Enforce(dataStore portainer.DataStore, endpoint *portainer.Endpoint, userID portainer.UserID, workloads ...Workload) error {
+	policies, err := dataStore.DockerPolicy().DockerPolicies()
+	if err != nil {
+		return err
+	}
+
+	if len(policies) == 0 {
+		return nil
+	}
+
+	memberships, err := dataStore.TeamMembership().TeamMembershipsByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	subject := Subject{
+		EndpointID:      endpoint.ID,
+		EndpointGroupID: endpoint.GroupID,
+		TeamIDs:         make([]portainer.TeamID, 0, len(memberships)),
+	}
+	for _, membership := range memberships {
+		subject.TeamIDs = append(subject.TeamIDs, membership.TeamID)
+	}
+
+	var denial *DenialError
+	for _, workload := range workloads {
+		for _, violation := range Evaluate(policies, subject, workload) {
+			if violation.AuditOnly {
+				log.Printf("[INFO] [internal,policy] [endpoint_id: %d] [user_id: %d] [policy: %s] [message: audit only policy violated] [reason: %s]", endpoint.ID, userID, violation.PolicyName, violation.Reason)
+				continue
+			}
+
+			if denial == nil {
+				denial = &DenialError{Violation: violation}
+			}
+		}
+	}
+
+	if denial != nil {
+		return denial
+	}
+
+	return nil
+}
+
+func evaluateRules(rules *portainer.DockerPolicyRules, workload Workload) []string {
+	reasons := make([]string, 0)
+
+	if len(rules.AllowedRegistries) > 0 {
+		registry, err := imageRegistry(workload.Image)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("invalid image reference %q", workload.Image))
+		} else if !containsRegistry(rules.AllowedRegistries, registry) {
+			reasons = append(reasons, fmt.Sprintf("image %q is not pulled from an allowed registry (%s)", workload.Image, strings.Join(rules.AllowedRegistries, ", ")))
+		}
+	}
+
+	if rules.RequireMemoryLimit && !workload.HasMemoryLimit {
+		reasons = append(reasons, "a memory limit is required")
+	}
+
+	if rules.RequireCPULimit && !workload.HasCPULimit {
+		reasons = append(reasons, "a CPU limit is required")
+	}
+
+	if rules.ForbidPrivilegedPorts {
+		for _, port := range workload.PublishedPorts {
+			if port > 0 && port < 1024 {
+				reasons = append(reasons, fmt.Sprintf("publishing the port %d below 1024 is forbidden", port))
+				break
+			}
+		}
+	}
+
+	for _, label := range rules.RequiredLabels {
+		if _, ok := workload.Labels[label]; !ok {
+			reasons = append(reasons, fmt.Sprintf("the label %q is required", label))
+		}
+	}
+
+	if rules.MaxReplicas > 0 && workload.Replicas != nil && *workload.Replicas > uint64(rules.MaxReplicas) {
+		reasons = append(reasons, fmt.Sprintf("%d replicas exceed the maximum of %d", *workload.Replicas, rules.MaxReplicas))
+	}
+
+	return reasons
+}
+
+// imageRegistry returns the registry of an image, docker.io for the images of the Docker Hub
+func imageRegistry(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+
+	return reference.Domain(named), nil
+}
+
+func containsRegistry(registries []string, registry string) bool {
+	for _, allowed := range registries {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), registry) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEndpoint(endpointIDs []portainer.EndpointID, endpointID portainer.EndpointID) bool {
+	for _, id := range endpointIDs {
+		if id == endpointID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEndpointGroup(groupIDs []portainer.EndpointGroupID, groupID portainer.EndpointGroupID) bool {
+	for _, id := range groupIDs {
+		if id == groupID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTeam(teamIDs []portainer.TeamID, teamID portainer.TeamID) bool {
+	for _, id := range teamIDs {
+		if id == teamID {
+			return true
+		}
+	}
+	return false
+}
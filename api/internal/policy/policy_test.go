@@ -0,0 +1,103 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-connections/nat"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplies(t *testing.T) {
+	subject := Subject{EndpointID: 1, EndpointGroupID: 2, TeamIDs: []portainer.TeamID{3}}
+
+	assert.True(t, Applies(&portainer.DockerPolicy{}, subject))
+	assert.True(t, Applies(&portainer.DockerPolicy{EndpointIDs: []portainer.EndpointID{1}}, subject))
+	assert.True(t, Applies(&portainer.DockerPolicy{EndpointGroupIDs: []portainer.EndpointGroupID{2}}, subject))
+	assert.False(t, Applies(&portainer.DockerPolicy{EndpointIDs: []portainer.EndpointID{4}}, subject))
+	assert.True(t, Applies(&portainer.DockerPolicy{TeamIDs: []portainer.TeamID{3, 5}}, subject))
+	assert.False(t, Applies(&portainer.DockerPolicy{TeamIDs: []portainer.TeamID{5}}, subject))
+	assert.False(t, Applies(&portainer.DockerPolicy{EndpointIDs: []portainer.EndpointID{1}, TeamIDs: []portainer.TeamID{5}}, subject))
+}
+
+func TestEvaluate(t *testing.T) {
+	replicas := uint64(10)
+	policies := []portainer.DockerPolicy{
+		{
+			ID:        1,
+			Name:      "production",
+			AuditOnly: true,
+			Rules: portainer.DockerPolicyRules{
+				AllowedRegistries:     []string{"registry.example.com"},
+				RequireMemoryLimit:    true,
+				RequireCPULimit:       true,
+				ForbidPrivilegedPorts: true,
+				RequiredLabels:        []string{"owner"},
+				MaxReplicas:           3,
+			},
+		},
+		{ID: 2, Name: "other endpoint", EndpointIDs: []portainer.EndpointID{9}, Rules: portainer.DockerPolicyRules{RequireMemoryLimit: true}},
+	}
+
+	workload := Workload{Name: "web", Image: "nginx:latest", PublishedPorts: []int{0, 80}, Replicas: &replicas}
+
+	violations := Evaluate(policies, Subject{EndpointID: 1}, workload)
+	assert.Len(t, violations, 6)
+	for _, violation := range violations {
+		assert.Equal(t, portainer.DockerPolicyID(1), violation.PolicyID)
+		assert.True(t, violation.AuditOnly)
+		assert.Contains(t, violation.Reason, "web: ")
+	}
+
+	compliant := Workload{
+		Image:          "registry.example.com/team/app:1.0",
+		Labels:         map[string]string{"owner": "team"},
+		PublishedPorts: []int{8080},
+		HasMemoryLimit: true,
+		HasCPULimit:    true,
+	}
+	assert.Empty(t, Evaluate(policies, Subject{EndpointID: 1}, compliant))
+}
+
+func TestDenialError(t *testing.T) {
+	err := &DenialError{Violation: Violation{PolicyName: "production", Reason: "a memory limit is required"}}
+	assert.Equal(t, `denied by policy "production": a memory limit is required`, err.Error())
+}
+
+func TestContainerWorkload(t *testing.T) {
+	config := &container.Config{Image: "nginx", Labels: map[string]string{"owner": "team"}}
+	hostConfig := &container.HostConfig{
+		PortBindings: nat.PortMap{"80/tcp": []nat.PortBinding{{HostPort: "80"}, {HostPort: ""}}, "90/tcp": []nat.PortBinding{{HostPort: "8000-8010"}}},
+		Resources:    container.Resources{Memory: 1 << 20},
+	}
+
+	workload := ContainerWorkload(config, hostConfig)
+	assert.Equal(t, "nginx", workload.Image)
+	assert.ElementsMatch(t, []int{80, 0, 8000}, workload.PublishedPorts)
+	assert.True(t, workload.HasMemoryLimit)
+	assert.False(t, workload.HasCPULimit)
+	assert.Nil(t, workload.Replicas)
+}
+
+func TestServiceWorkload(t *testing.T) {
+	replicas := uint64(2)
+	spec := &swarm.ServiceSpec{
+		Annotations: swarm.Annotations{Labels: map[string]string{"owner": "team"}},
+		TaskTemplate: swarm.TaskSpec{
+			ContainerSpec: &swarm.ContainerSpec{Image: "nginx", Labels: map[string]string{"tier": "web"}},
+			Resources:     &swarm.ResourceRequirements{Limits: &swarm.Resources{NanoCPUs: 500000000}},
+		},
+		Mode:         swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+		EndpointSpec: &swarm.EndpointSpec{Ports: []swarm.PortConfig{{PublishedPort: 443}}},
+	}
+
+	workload := ServiceWorkload(spec)
+	assert.Equal(t, "nginx", workload.Image)
+	assert.Equal(t, map[string]string{"owner": "team", "tier": "web"}, workload.Labels)
+	assert.Equal(t, []int{443}, workload.PublishedPorts)
+	assert.False(t, workload.HasMemoryLimit)
+	assert.True(t, workload.HasCPULimit)
+	assert.Equal(t, &replicas, workload.Replicas)
+}
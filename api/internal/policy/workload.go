@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/docker/cli/cli/compose/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// ContainerWorkload returns the workload of a container creation request
+func ContainerWorkload(config *container.Config, hostConfig *container.HostConfig) Workload {
+	workload := Workload{
+		Image:          config.Image,
+		Labels:         config.Labels,
+		PublishedPorts: make([]int, 0),
+	}
+
+	if hostConfig == nil {
+		return workload
+	}
+
+	workload.HasMemoryLimit = hostConfig.Memory > 0
+	workload.HasCPULimit = hostConfig.NanoCPUs > 0 || hostConfig.CPUQuota > 0
+
+	for _, bindings := range hostConfig.PortBindings {
+		for _, binding := range bindings {
+			workload.PublishedPorts = append(workload.PublishedPorts, hostPort(binding.HostPort))
+		}
+	}
+
+	return workload
+}
+
+// ServiceWorkload returns the workload of a service creation or update request
+func ServiceWorkload(spec *swarm.ServiceSpec) Workload {
+	workload := Workload{
+		Labels:         make(map[string]string),
+		PublishedPorts: make([]int, 0),
+	}
+
+	for key, value := range spec.Labels {
+		workload.Labels[key] = value
+	}
+
+	if containerSpec := spec.TaskTemplate.ContainerSpec; containerSpec != nil {
+		workload.Image = containerSpec.Image
+		for key, value := range containerSpec.Labels {
+			workload.Labels[key] = value
+		}
+	}
+
+	if resources := spec.TaskTemplate.Resources; resources != nil && resources.Limits != nil {
+		workload.HasMemoryLimit = resources.Limits.MemoryBytes > 0
+		workload.HasCPULimit = resources.Limits.NanoCPUs > 0
+	}
+
+	if spec.EndpointSpec != nil {
+		for _, port := range spec.EndpointSpec.Ports {
+			workload.PublishedPorts = append(workload.PublishedPorts, int(port.PublishedPort))
+		}
+	}
+
+	if spec.Mode.Replicated != nil {
+		workload.Replicas = spec.Mode.Replicated.Replicas
+	}
+
+	return workload
+}
+
+// StackServiceWorkload returns the workload of a service of a stack file.
+// The limits of the service are read from its deploy section.
+func StackServiceWorkload(service *types.ServiceConfig) Workload {
+	workload := Workload{
+		Name:           service.Name,
+		Image:          service.Image,
+		Labels:         make(map[string]string),
+		PublishedPorts: make([]int, 0),
+	}
+
+	for key, value := range service.Labels {
+		workload.Labels[key] = value
+	}
+	for key, value := range service.Deploy.Labels {
+		workload.Labels[key] = value
+	}
+
+	if limits := service.Deploy.Resources.Limits; limits != nil {
+		workload.HasMemoryLimit = limits.MemoryBytes > 0
+		cpus, err := strconv.ParseFloat(limits.NanoCPUs, 64)
+		workload.HasCPULimit = err == nil && cpus > 0
+	}
+
+	for _, port := range service.Ports {
+		workload.PublishedPorts = append(workload.PublishedPorts, int(port.Published))
+	}
+
+	if service.Deploy.Mode != "global" {
+		workload.Replicas = service.Deploy.Replicas
+	}
+
+	return workload
+}
+
+// hostPort returns the first port of a host port binding such as 8080 or 8000-8010, 0 when the port is chosen by the Docker engine
+func hostPort(value string) int {
+	port, err := strconv.Atoi(strings.SplitN(value, "-", 2)[0])
+	if err != nil {
+		return 0
+	}
+	return port
+}
@@ -0,0 +1,231 @@
+package prune
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	portainer "github.com/portainer/portainer/api"
+)
+
+// DockerClient is the subset of the Docker client used to prune an endpoint
+type DockerClient interface {
+	ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error)
+	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error
+	ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error)
+	ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error)
+	DiskUsage(ctx context.Context) (types.DiskUsage, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+	BuildCachePrune(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error)
+}
+
+// Execute applies the rules on a Docker engine and records the removed resources and the reclaimed space in the run,
+// or the resources that would be removed when the run is a dry run.
+// Failures are recorded in the run and do not stop the removal of the other resources.
+func Execute(ctx context.Context, dockerClient DockerClient, rules *portainer.PrunePolicyRules, run *portainer.PrunePolicyRun, now time.Time) {
+	if rules.StoppedContainersOlderThanDays > 0 {
+		pruneContainers(ctx, dockerClient, olderThan(now, rules.StoppedContainersOlderThanDays), run)
+	}
+
+	if rules.DanglingImagesOlderThanDays > 0 {
+		pruneImages(ctx, dockerClient, olderThan(now, rules.DanglingImagesOlderThanDays), run)
+	}
+
+	if !rules.UnusedVolumes && rules.BuildCacheThreshold <= 0 {
+		return
+	}
+
+	// disk usage is retrieved after the removal of the containers, so that their volumes are seen as unused
+	diskUsage, err := dockerClient.DiskUsage(ctx)
+	if err != nil {
+		run.Errors = append(run.Errors, fmt.Sprintf("unable to retrieve disk usage: %s", err))
+		return
+	}
+
+	if rules.UnusedVolumes {
+		pruneVolumes(ctx, dockerClient, diskUsage.Volumes, rules.VolumeExclusionLabel, run)
+	}
+
+	if rules.BuildCacheThreshold > 0 {
+		pruneBuildCache(ctx, dockerClient, diskUsage.BuildCache, rules.BuildCacheThreshold, run)
+	}
+}
+
+func olderThan(now time.Time, days int) time.Time {
+	return now.Add(-time.Duration(days) * 24 * time.Hour)
+}
+
+// pruneContainers removes the stopped containers that exited before the cutoff,
+// containers that never started are considered from their creation.
+func pruneContainers(ctx context.Context, dockerClient DockerClient, cutoff time.Time, run *portainer.PrunePolicyRun) {
+	containers, err := dockerClient.ContainerList(ctx, types.ContainerListOptions{
+		All:  true,
+		Size: true,
+		Filters: filters.NewArgs(
+			filters.Arg("status", "created"),
+			filters.Arg("status", "exited"),
+			filters.Arg("status", "dead"),
+		),
+	})
+	if err != nil {
+		run.Errors = append(run.Errors, fmt.Sprintf("unable to list containers: %s", err))
+		return
+	}
+
+	for _, container := range containers {
+		stoppedAt := time.Unix(container.Created, 0)
+
+		details, err := dockerClient.ContainerInspect(ctx, container.ID)
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("unable to inspect container %s: %s", container.ID, err))
+			continue
+		}
+
+		if details.State != nil {
+			finishedAt, err := time.Parse(time.RFC3339Nano, details.State.FinishedAt)
+			if err == nil && !finishedAt.IsZero() {
+				stoppedAt = finishedAt
+			}
+		}
+
+		if !stoppedAt.Before(cutoff) {
+			continue
+		}
+
+		if !run.DryRun {
+			err = dockerClient.ContainerRemove(ctx, container.ID, types.ContainerRemoveOptions{})
+			if err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("unable to remove container %s: %s", container.ID, err))
+				continue
+			}
+		}
+
+		run.Containers = append(run.Containers, container.ID)
+		run.ReclaimedBytes += container.SizeRw
+	}
+}
+
+// pruneImages removes the dangling images created before the cutoff
+func pruneImages(ctx context.Context, dockerClient DockerClient, cutoff time.Time, run *portainer.PrunePolicyRun) {
+	images, err := dockerClient.ImageList(ctx, types.ImageListOptions{
+		Filters: filters.NewArgs(filters.Arg("dangling", "true")),
+	})
+	if err != nil {
+		run.Errors = append(run.Errors, fmt.Sprintf("unable to list images: %s", err))
+		return
+	}
+
+	for _, image := range images {
+		if !time.Unix(image.Created, 0).Before(cutoff) {
+			continue
+		}
+
+		if !run.DryRun {
+			_, err = dockerClient.ImageRemove(ctx, image.ID, types.ImageRemoveOptions{PruneChildren: true})
+			if err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("unable to remove image %s: %s", image.ID, err))
+				continue
+			}
+		}
+
+		run.Images = append(run.Images, image.ID)
+		run.ReclaimedBytes += image.Size
+	}
+}
+
+// pruneVolumes removes the volumes that are not used by any container, except the ones holding the exclusion label
+func pruneVolumes(ctx context.Context, dockerClient DockerClient, volumes []*types.Volume, exclusionLabel string, run *portainer.PrunePolicyRun) {
+	for _, volume := range volumes {
+		if volume.UsageData == nil || volume.UsageData.RefCount != 0 {
+			continue
+		}
+
+		if exclusionLabel != "" && hasLabel(volume.Labels, exclusionLabel) {
+			continue
+		}
+
+		if !run.DryRun {
+			err := dockerClient.VolumeRemove(ctx, volume.Name, false)
+			if err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("unable to remove volume %s: %s", volume.Name, err))
+				continue
+			}
+		}
+
+		run.Volumes = append(run.Volumes, volume.Name)
+		if volume.UsageData.Size > 0 {
+			run.ReclaimedBytes += volume.UsageData.Size
+		}
+	}
+}
+
+// hasLabel returns true when the labels contain the label, expressed either as a label name or as a name=value pair
+func hasLabel(labels map[string]string, label string) bool {
+	name, value, withValue := label, "", false
+	if idx := strings.Index(label, "="); idx != -1 {
+		name, value, withValue = label[:idx], label[idx+1:], true
+	}
+
+	labelValue, ok := labels[name]
+	return ok && (!withValue || labelValue == value)
+}
+
+// pruneBuildCache prunes the build cache down to the threshold when it grows above it.
+// A dry run reports the least recently used records that are not in use, up to the space above the threshold.
+func pruneBuildCache(ctx context.Context, dockerClient DockerClient, records []*types.BuildCache, threshold int64, run *portainer.PrunePolicyRun) {
+	var size int64
+	for _, record := range records {
+		if !record.Shared {
+			size += record.Size
+		}
+	}
+
+	if size <= threshold {
+		return
+	}
+
+	if !run.DryRun {
+		report, err := dockerClient.BuildCachePrune(ctx, types.BuildCachePruneOptions{KeepStorage: threshold})
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("unable to prune build cache: %s", err))
+			return
+		}
+
+		run.BuildCache = append(run.BuildCache, report.CachesDeleted...)
+		run.ReclaimedBytes += int64(report.SpaceReclaimed)
+		return
+	}
+
+	candidates := make([]*types.BuildCache, 0)
+	for _, record := range records {
+		if !record.InUse && !record.Shared {
+			candidates = append(candidates, record)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return lastUsed(candidates[i]).Before(lastUsed(candidates[j]))
+	})
+
+	for _, record := range candidates {
+		if size <= threshold {
+			break
+		}
+
+		run.BuildCache = append(run.BuildCache, record.ID)
+		run.ReclaimedBytes += record.Size
+		size -= record.Size
+	}
+}
+
+func lastUsed(record *types.BuildCache) time.Time {
+	if record.LastUsedAt != nil {
+		return *record.LastUsedAt
+	}
+	return record.CreatedAt
+}
@@ -0,0 +1,192 @@
+package prune
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDockerClient struct {
+	containers map[string]types.ContainerJSON
+	images     []types.ImageSummary
+	diskUsage  types.DiskUsage
+	pruneKeep  int64
+
+	removed []string
+}
+
+func (client *fakeDockerClient) ContainerList(ctx context.Context, options types.ContainerListOptions) ([]types.Container, error) {
+	containers := make([]types.Container, 0)
+	for id, details := range client.containers {
+		containers = append(containers, types.Container{ID: id, Created: 0, SizeRw: int64(len(details.Name))})
+	}
+	return containers, nil
+}
+
+func (client *fakeDockerClient) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	return client.containers[containerID], nil
+}
+
+func (client *fakeDockerClient) ContainerRemove(ctx context.Context, containerID string, options types.ContainerRemoveOptions) error {
+	client.removed = append(client.removed, containerID)
+	return nil
+}
+
+func (client *fakeDockerClient) ImageList(ctx context.Context, options types.ImageListOptions) ([]types.ImageSummary, error) {
+	return client.images, nil
+}
+
+func (client *fakeDockerClient) ImageRemove(ctx context.Context, imageID string, options types.ImageRemoveOptions) ([]types.ImageDeleteResponseItem, error) {
+	client.removed = append(client.removed, imageID)
+	return nil, nil
+}
+
+func (client *fakeDockerClient) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	return client.diskUsage, nil
+}
+
+func (client *fakeDockerClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	client.removed = append(client.removed, volumeID)
+	return nil
+}
+
+func (client *fakeDockerClient) BuildCachePrune(ctx context.Context, opts types.BuildCachePruneOptions) (*types.BuildCachePruneReport, error) {
+	client.pruneKeep = opts.KeepStorage
+	return &types.BuildCachePruneReport{CachesDeleted: []string{"cache"}, SpaceReclaimed: 100}, nil
+}
+
+func containerDetails(name string, finishedAt time.Time) types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			Name:  name,
+			State: &types.ContainerState{FinishedAt: finishedAt.Format(time.RFC3339Nano)},
+		},
+	}
+}
+
+func newFakeDockerClient(now time.Time) *fakeDockerClient {
+	lastUsed := now.Add(-time.Hour)
+
+	return &fakeDockerClient{
+		containers: map[string]types.ContainerJSON{
+			"old":    containerDetails("old", now.Add(-10*24*time.Hour)),
+			"recent": containerDetails("recent", now.Add(-time.Hour)),
+		},
+		images: []types.ImageSummary{
+			{ID: "sha256:old", Created: now.Add(-30 * 24 * time.Hour).Unix(), Size: 1000},
+			{ID: "sha256:recent", Created: now.Unix(), Size: 1000},
+		},
+		diskUsage: types.DiskUsage{
+			Volumes: []*types.Volume{
+				{Name: "unused", UsageData: &types.VolumeUsageData{RefCount: 0, Size: 10}},
+				{Name: "used", UsageData: &types.VolumeUsageData{RefCount: 1, Size: 10}},
+				{Name: "kept", Labels: map[string]string{"backup": "true"}, UsageData: &types.VolumeUsageData{RefCount: 0, Size: 10}},
+			},
+			BuildCache: []*types.BuildCache{
+				{ID: "lru", Size: 300, LastUsedAt: &lastUsed},
+				{ID: "mru", Size: 300, LastUsedAt: &now},
+				{ID: "busy", Size: 300, InUse: true, CreatedAt: now.Add(-24 * time.Hour)},
+			},
+		},
+	}
+}
+
+func testRules() *portainer.PrunePolicyRules {
+	return &portainer.PrunePolicyRules{
+		DanglingImagesOlderThanDays:    7,
+		StoppedContainersOlderThanDays: 7,
+		UnusedVolumes:                  true,
+		VolumeExclusionLabel:           "backup=true",
+		BuildCacheThreshold:            700,
+	}
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	now := time.Now()
+	dockerClient := newFakeDockerClient(now)
+	run := &portainer.PrunePolicyRun{DryRun: true}
+
+	Execute(context.Background(), dockerClient, testRules(), run, now)
+
+	assert.Empty(t, run.Errors)
+	assert.Empty(t, dockerClient.removed, "a dry run should not remove any resource")
+	assert.Equal(t, []string{"old"}, run.Containers)
+	assert.Equal(t, []string{"sha256:old"}, run.Images)
+	assert.Equal(t, []string{"unused"}, run.Volumes)
+	assert.Equal(t, []string{"lru"}, run.BuildCache, "only the least recently used records should be reported down to the threshold")
+	assert.Equal(t, int64(len("old")+1000+10+300), run.ReclaimedBytes)
+}
+
+func TestExecute(t *testing.T) {
+	now := time.Now()
+	dockerClient := newFakeDockerClient(now)
+	run := &portainer.PrunePolicyRun{}
+
+	Execute(context.Background(), dockerClient, testRules(), run, now)
+
+	assert.Empty(t, run.Errors)
+	assert.ElementsMatch(t, []string{"old", "sha256:old", "unused"}, dockerClient.removed)
+	assert.Equal(t, int64(700), dockerClient.pruneKeep)
+	assert.Equal(t, []string{"cache"}, run.BuildCache)
+	assert.Equal(t, int64(len("old")+1000+10+100), run.ReclaimedBytes)
+}
+
+func TestExecute_DisabledRules(t *testing.T) {
+	now := time.Now()
+	dockerClient := newFakeDockerClient(now)
+	run := &portainer.PrunePolicyRun{}
+
+	Execute(context.Background(), dockerClient, &portainer.PrunePolicyRules{UnusedVolumes: true, BuildCacheThreshold: 1000}, run, now)
+
+	assert.Equal(t, []string{"unused", "kept"}, dockerClient.removed, "volumes should not be excluded without an exclusion label")
+	assert.Empty(t, run.BuildCache, "the build cache should not be pruned below the threshold")
+	assert.Empty(t, run.Containers)
+	assert.Empty(t, run.Images)
+}
+
+func TestHasLabel(t *testing.T) {
+	labels := map[string]string{"backup": "true"}
+
+	assert.True(t, hasLabel(labels, "backup"))
+	assert.True(t, hasLabel(labels, "backup=true"))
+	assert.False(t, hasLabel(labels, "backup=false"))
+	assert.False(t, hasLabel(labels, "keep"))
+}
+
+func TestEndpoints(t *testing.T) {
+	policy := &portainer.PrunePolicy{
+		EndpointIDs:      []portainer.EndpointID{1, 3},
+		EndpointGroupIDs: []portainer.EndpointGroupID{2},
+	}
+
+	endpoints := []portainer.Endpoint{
+		{ID: 1, GroupID: 1, Type: portainer.DockerEnvironment},
+		{ID: 2, GroupID: 2, Type: portainer.EdgeAgentOnDockerEnvironment},
+		{ID: 3, GroupID: 1, Type: portainer.KubernetesLocalEnvironment},
+		{ID: 4, GroupID: 1, Type: portainer.AgentOnDockerEnvironment},
+	}
+
+	targets := Endpoints(policy, endpoints)
+
+	ids := make([]portainer.EndpointID, 0)
+	for _, endpoint := range targets {
+		ids = append(ids, endpoint.ID)
+	}
+	assert.Equal(t, []portainer.EndpointID{1, 2}, ids)
+}
+
+func TestReserve(t *testing.T) {
+	service := &Service{running: make(map[runKey]bool)}
+	key := runKey{policyID: 1, endpointID: 1}
+
+	assert.True(t, service.reserve(key))
+	assert.False(t, service.reserve(key), "the policy should not run twice on the same endpoint")
+	assert.True(t, service.reserve(runKey{policyID: 1, endpointID: 2}))
+
+	service.release(key)
+	assert.True(t, service.reserve(key))
+}
@@ -0,0 +1,371 @@
+package prune
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/docker"
+)
+
+const (
+	// checkInterval is the delay between two checks of the prune policies that are due
+	checkInterval = time.Minute
+	// runTimeout is the maximum duration of the run of a prune policy on an endpoint
+	runTimeout = 30 * time.Minute
+	// maxConcurrentRuns is the maximum number of runs executed at the same time, the other runs wait for a slot
+	maxConcurrentRuns = 4
+	// tunnelKeepAliveInterval is the delay between two refreshes of the activity of the tunnel of an Edge endpoint
+	// during a run, it must be shorter than the inactivity timeout of the tunnels
+	tunnelKeepAliveInterval = time.Minute
+)
+
+// ErrRunInProgress is returned when the policy is already running on the endpoint
+var ErrRunInProgress = errors.New("A run of the prune policy is already in progress on this endpoint")
+
+// Service runs the prune policies on their endpoints on schedule and records the runs.
+// The tunnels of the Edge endpoints are opened for the runs and kept active until the runs complete.
+type Service struct {
+	dataStore            portainer.DataStore
+	clientFactory        *docker.ClientFactory
+	reverseTunnelService portainer.ReverseTunnelService
+	shutdownCtx          context.Context
+
+	slots    chan struct{}
+	mu       sync.Mutex
+	running  map[runKey]bool
+	lastRuns map[runKey]time.Time
+}
+
+type runKey struct {
+	policyID   portainer.PrunePolicyID
+	endpointID portainer.EndpointID
+}
+
+// NewService creates a new instance of a service.
+func NewService(dataStore portainer.DataStore, clientFactory *docker.ClientFactory, reverseTunnelService portainer.ReverseTunnelService, shutdownCtx context.Context) *Service {
+	return &Service{
+		dataStore:            dataStore,
+		clientFactory:        clientFactory,
+		reverseTunnelService: reverseTunnelService,
+		shutdownCtx:          shutdownCtx,
+		slots:                make(chan struct{}, maxConcurrentRuns),
+		running:              make(map[runKey]bool),
+		lastRuns:             make(map[runKey]time.Time),
+	}
+}
+
+// Start starts a background routine running the prune policies that are due until the shutdown context is done
+func (service *Service) Start() {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				service.runDuePolicies()
+			case <-service.shutdownCtx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (service *Service) runDuePolicies() {
+	policies, err := service.dataStore.PrunePolicy().PrunePolicies()
+	if err != nil {
+		log.Printf("[WARN] [internal,prune] [message: unable to retrieve prune policies] [error: %s]", err)
+		return
+	}
+
+	if len(policies) == 0 {
+		return
+	}
+
+	endpoints, err := service.dataStore.Endpoint().Endpoints()
+	if err != nil {
+		log.Printf("[WARN] [internal,prune] [message: unable to retrieve endpoints] [error: %s]", err)
+		return
+	}
+
+	for idx := range policies {
+		policy := &policies[idx]
+
+		interval, err := time.ParseDuration(policy.Interval)
+		if err != nil || interval <= 0 {
+			continue
+		}
+
+		for _, endpoint := range Endpoints(policy, endpoints) {
+			endpoint := endpoint
+			if !service.isDue(policy.ID, endpoint.ID, interval) {
+				continue
+			}
+
+			// ErrRunInProgress is ignored, the previous run takes longer than the interval of the policy
+			service.StartRun(policy, &endpoint, policy.DryRun, false)
+		}
+	}
+}
+
+// isDue returns true when the last scheduled run of the policy on the endpoint is older than the interval
+func (service *Service) isDue(policyID portainer.PrunePolicyID, endpointID portainer.EndpointID, interval time.Duration) bool {
+	key := runKey{policyID: policyID, endpointID: endpointID}
+
+	service.mu.Lock()
+	lastRun, ok := service.lastRuns[key]
+	service.mu.Unlock()
+
+	if !ok {
+		runs, err := service.dataStore.PrunePolicyRun().PrunePolicyEndpointRuns(policyID, endpointID)
+		if err != nil {
+			log.Printf("[WARN] [internal,prune] [prune_policy_id: %d] [endpoint_id: %d] [message: unable to retrieve prune policy runs] [error: %s]", policyID, endpointID, err)
+			return false
+		}
+
+		for _, run := range runs {
+			if !run.Manual {
+				lastRun = time.Unix(run.StartTime, 0)
+			}
+		}
+
+		service.mu.Lock()
+		service.lastRuns[key] = lastRun
+		service.mu.Unlock()
+	}
+
+	return time.Since(lastRun) >= interval
+}
+
+// openTunnel requests the tunnel of an Edge endpoint when it is not active and waits for the agent to connect,
+// an error is returned when the tunnel is still not active at the end of the wait
+func (service *Service) openTunnel(endpoint *portainer.Endpoint) error {
+	if endpoint.Type != portainer.EdgeAgentOnDockerEnvironment {
+		return nil
+	}
+
+	tunnel := service.reverseTunnelService.GetTunnelDetails(endpoint.ID)
+	if tunnel.Status == portainer.EdgeAgentActive {
+		return nil
+	}
+
+	// a required tunnel is left as is, the agent needs its credentials to connect
+	if tunnel.Status == portainer.EdgeAgentIdle {
+		err := service.reverseTunnelService.SetTunnelStatusToRequired(endpoint.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	checkinInterval := settings.EdgeAgentCheckinInterval
+	if endpoint.EdgeCheckinInterval != 0 {
+		checkinInterval = endpoint.EdgeCheckinInterval
+	}
+
+	waitForAgentToConnect := time.Duration(checkinInterval) * time.Second
+	select {
+	case <-time.After(waitForAgentToConnect * 2):
+	case <-service.shutdownCtx.Done():
+		return service.shutdownCtx.Err()
+	}
+
+	if service.reverseTunnelService.GetTunnelDetails(endpoint.ID).Status != portainer.EdgeAgentActive {
+		return errors.New("the Edge agent did not open its tunnel")
+	}
+
+	return nil
+}
+
+// keepTunnelActive refreshes the activity of the active tunnel of an Edge endpoint until the context is done,
+// the tunnel would otherwise be closed during long runs as the requests of the runs do not go through the proxy
+func (service *Service) keepTunnelActive(ctx context.Context, endpointID portainer.EndpointID) {
+	ticker := time.NewTicker(tunnelKeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		service.reverseTunnelService.KeepTunnelAlive(endpointID)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// StartRun starts a run of the policy on the endpoint in the background and records the run once it completes,
+// failures to prune the endpoint are reported in the run. At most maxConcurrentRuns runs are executed at the same time.
+// ErrRunInProgress is returned when the policy is already running on the endpoint.
+func (service *Service) StartRun(policy *portainer.PrunePolicy, endpoint *portainer.Endpoint, dryRun, manual bool) error {
+	key := runKey{policyID: policy.ID, endpointID: endpoint.ID}
+
+	if !service.reserve(key) {
+		return ErrRunInProgress
+	}
+
+	go func() {
+		defer service.release(key)
+
+		select {
+		case service.slots <- struct{}{}:
+			defer func() { <-service.slots }()
+		case <-service.shutdownCtx.Done():
+			return
+		}
+
+		err := service.run(policy, endpoint, dryRun, manual)
+		if err != nil {
+			log.Printf("[WARN] [internal,prune] [prune_policy_id: %d] [endpoint_id: %d] [message: unable to record prune policy run] [error: %s]", policy.ID, endpoint.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// reserve marks the policy as running on the endpoint, it returns false when the policy is already running on it
+func (service *Service) reserve(key runKey) bool {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.running[key] {
+		return false
+	}
+	service.running[key] = true
+
+	return true
+}
+
+func (service *Service) release(key runKey) {
+	service.mu.Lock()
+	delete(service.running, key)
+	service.mu.Unlock()
+}
+
+// run runs the policy on the endpoint and records the run
+func (service *Service) run(policy *portainer.PrunePolicy, endpoint *portainer.Endpoint, dryRun, manual bool) error {
+	key := runKey{policyID: policy.ID, endpointID: endpoint.ID}
+
+	startTime := time.Now()
+	run := &portainer.PrunePolicyRun{
+		PrunePolicyID: policy.ID,
+		EndpointID:    endpoint.ID,
+		DryRun:        dryRun,
+		Manual:        manual,
+		Containers:    []string{},
+		Images:        []string{},
+		Volumes:       []string{},
+		BuildCache:    []string{},
+		Errors:        []string{},
+		StartTime:     startTime.Unix(),
+	}
+
+	err := service.openTunnel(endpoint)
+	if err == nil {
+		service.execute(policy, endpoint, run, startTime)
+	} else {
+		run.Errors = append(run.Errors, "unable to open the Edge tunnel of the endpoint: "+err.Error())
+	}
+
+	run.EndTime = time.Now().Unix()
+
+	if !manual {
+		service.mu.Lock()
+		service.lastRuns[key] = startTime
+		service.mu.Unlock()
+	}
+
+	log.Printf("[INFO] [internal,prune] [prune_policy_id: %d] [endpoint_id: %d] [message: prune policy run completed] [dry_run: %t] [containers: %d] [images: %d] [volumes: %d] [build_cache: %d] [reclaimed_bytes: %d] [errors: %d]",
+		policy.ID, endpoint.ID, dryRun, len(run.Containers), len(run.Images), len(run.Volumes), len(run.BuildCache), run.ReclaimedBytes, len(run.Errors))
+
+	err = service.dataStore.PrunePolicyRun().CreatePrunePolicyRun(run)
+	if err != nil {
+		return err
+	}
+
+	service.deleteOldRuns(policy.ID, endpoint.ID)
+
+	return nil
+}
+
+func (service *Service) execute(policy *portainer.PrunePolicy, endpoint *portainer.Endpoint, run *portainer.PrunePolicyRun, now time.Time) {
+	dockerClient, err := service.clientFactory.CreateClient(endpoint, "")
+	if err != nil {
+		run.Errors = append(run.Errors, "unable to create Docker client: "+err.Error())
+		return
+	}
+	defer dockerClient.Close()
+
+	ctx, cancel := context.WithTimeout(service.shutdownCtx, runTimeout)
+	defer cancel()
+
+	if endpoint.Type == portainer.EdgeAgentOnDockerEnvironment {
+		go service.keepTunnelActive(ctx, endpoint.ID)
+	}
+
+	Execute(ctx, dockerClient, &policy.Rules, run, now)
+}
+
+// deleteOldRuns deletes the oldest runs of a policy on an endpoint, failures are only logged
+// as they do not affect the recorded run
+func (service *Service) deleteOldRuns(policyID portainer.PrunePolicyID, endpointID portainer.EndpointID) {
+	runs, err := service.dataStore.PrunePolicyRun().PrunePolicyEndpointRuns(policyID, endpointID)
+	if err != nil {
+		log.Printf("[WARN] [internal,prune] [prune_policy_id: %d] [endpoint_id: %d] [message: unable to retrieve prune policy runs] [error: %s]", policyID, endpointID, err)
+		return
+	}
+
+	if len(runs) <= portainer.DefaultPrunePolicyRunsMaxEntries {
+		return
+	}
+
+	maxID := runs[len(runs)-portainer.DefaultPrunePolicyRunsMaxEntries-1].ID
+	err = service.dataStore.PrunePolicyRun().DeletePrunePolicyEndpointRuns(policyID, endpointID, maxID)
+	if err != nil {
+		log.Printf("[WARN] [internal,prune] [prune_policy_id: %d] [endpoint_id: %d] [message: unable to delete old prune policy runs] [error: %s]", policyID, endpointID, err)
+	}
+}
+
+// Endpoints returns the Docker endpoints the policy runs on, the endpoints of the policy
+// and the endpoints of its endpoint groups
+func Endpoints(policy *portainer.PrunePolicy, endpoints []portainer.Endpoint) []portainer.Endpoint {
+	targets := make([]portainer.Endpoint, 0)
+
+	for _, endpoint := range endpoints {
+		if endpoint.Type != portainer.DockerEnvironment && endpoint.Type != portainer.AgentOnDockerEnvironment && endpoint.Type != portainer.EdgeAgentOnDockerEnvironment {
+			continue
+		}
+
+		if containsEndpoint(policy.EndpointIDs, endpoint.ID) || containsEndpointGroup(policy.EndpointGroupIDs, endpoint.GroupID) {
+			targets = append(targets, endpoint)
+		}
+	}
+
+	return targets
+}
+
+func containsEndpoint(endpointIDs []portainer.EndpointID, endpointID portainer.EndpointID) bool {
+	for _, id := range endpointIDs {
+		if id == endpointID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEndpointGroup(groupIDs []portainer.EndpointGroupID, groupID portainer.EndpointGroupID) bool {
+	for _, id := range groupIDs {
+		if id == groupID {
+			return true
+		}
+	}
+	return false
+}
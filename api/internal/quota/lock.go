@@ -0,0 +1,42 @@
+package quota
+
+import (
+	"sync"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+var endpointLocks = struct {
+	sync.Mutex
+	locks map[portainer.EndpointID]*sync.Mutex
+}{locks: make(map[portainer.EndpointID]*sync.Mutex)}
+
+// LockEndpoint serializes the verification of the quotas and the creation of the resources on an endpoint,
+// so that concurrent creations cannot all fit in a quota that only has room for one of them.
+// The lock must be held until the resources are created and their resource controls are persisted,
+// the returned function releases it. Endpoints without quota are not locked.
+func LockEndpoint(endpoint *portainer.Endpoint) func() {
+	if !hasQuotas(endpoint) {
+		return func() {}
+	}
+
+	endpointLocks.Lock()
+	lock, ok := endpointLocks.locks[endpoint.ID]
+	if !ok {
+		lock = &sync.Mutex{}
+		endpointLocks.locks[endpoint.ID] = lock
+	}
+	endpointLocks.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+func hasQuotas(endpoint *portainer.Endpoint) bool {
+	for _, policy := range endpoint.TeamAccessPolicies {
+		if policy.Quota != nil {
+			return true
+		}
+	}
+	return false
+}
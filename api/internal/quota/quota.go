@@ -0,0 +1,136 @@
+package quota
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/client"
+	units "github.com/docker/go-units"
+	portainer "github.com/portainer/portainer/api"
+)
+
+type (
+	// Usage represents the resources of a Docker endpoint owned by a team
+	Usage struct {
+		// Number of containers, the tasks of the services are not counted
+		Containers int `json:"Containers" example:"4"`
+		// Memory reserved by the containers and the services, in bytes
+		Memory int64 `json:"Memory" example:"1073741824"`
+		// Number of CPUs reserved by the containers and the services
+		CPU     float64 `json:"CPU" example:"1.5"`
+		Volumes int     `json:"Volumes" example:"2"`
+		Stacks  int     `json:"Stacks" example:"1"`
+	}
+
+	// Demand represents the resources requested by the creation or the update of a resource
+	Demand struct {
+		Usage
+		// Whether the resource runs tasks, a memory and CPU reservation is then required by the quotas limiting them
+		RunsTasks bool
+		// Service whose current reservation is replaced by the demand
+		ExcludedServiceID string
+		// Stack whose current resources are replaced by the demand
+		ExcludedStackName string
+	}
+
+	// ExceededError is returned when a demand exceeds the quota of a team
+	ExceededError struct {
+		TeamName string
+		Reason   string
+	}
+)
+
+func (err *ExceededError) Error() string {
+	return fmt.Sprintf("quota of the team %q exceeded: %s", err.TeamName, err.Reason)
+}
+
+// Check verifies that a demand of a user fits in the quotas of the teams of the user on the endpoint.
+// The administrators are not limited. The first exceeded quota is returned as an *ExceededError.
+// The Docker client is not used when the demand is limited to stacks, it can then be nil.
+func Check(dataStore portainer.DataStore, dockerClient *client.Client, endpoint *portainer.Endpoint, userID portainer.UserID, demand *Demand) error {
+	user, err := dataStore.User().User(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == portainer.AdministratorRole {
+		return nil
+	}
+
+	memberships, err := dataStore.TeamMembership().TeamMembershipsByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, membership := range memberships {
+		policy, ok := endpoint.TeamAccessPolicies[membership.TeamID]
+		if !ok || policy.Quota == nil {
+			continue
+		}
+
+		usage, err := computeUsage(dataStore, dockerClient, endpoint, membership.TeamID, demand)
+		if err != nil {
+			return err
+		}
+
+		reason := exceededReason(policy.Quota, usage, demand)
+		if reason == "" {
+			continue
+		}
+
+		team, err := dataStore.Team().Team(membership.TeamID)
+		if err != nil {
+			return err
+		}
+
+		return &ExceededError{TeamName: team.Name, Reason: reason}
+	}
+
+	return nil
+}
+
+// ComputeUsage returns the resources of the endpoint owned by the team
+func ComputeUsage(dataStore portainer.DataStore, dockerClient *client.Client, endpoint *portainer.Endpoint, teamID portainer.TeamID) (*Usage, error) {
+	return computeUsage(dataStore, dockerClient, endpoint, teamID, nil)
+}
+
+// ValidateQuota returns an error when a limit of the quota is negative
+func ValidateQuota(quota *portainer.ResourceQuota) error {
+	if quota.MaxContainers < 0 || quota.MaxMemory < 0 || quota.MaxCPU < 0 || quota.MaxVolumes < 0 || quota.MaxStacks < 0 {
+		return fmt.Errorf("the limits of a quota cannot be negative")
+	}
+	return nil
+}
+
+func exceededReason(quota *portainer.ResourceQuota, usage *Usage, demand *Demand) string {
+	if quota.MaxContainers > 0 && demand.Containers > 0 && usage.Containers+demand.Containers > quota.MaxContainers {
+		return fmt.Sprintf("%d containers out of %d", usage.Containers+demand.Containers, quota.MaxContainers)
+	}
+
+	if quota.MaxMemory > 0 && demand.RunsTasks {
+		if demand.Memory == 0 {
+			return "a memory reservation or limit is required"
+		}
+		if usage.Memory+demand.Memory > quota.MaxMemory {
+			return fmt.Sprintf("%s of memory reserved out of %s", units.BytesSize(float64(usage.Memory+demand.Memory)), units.BytesSize(float64(quota.MaxMemory)))
+		}
+	}
+
+	if quota.MaxCPU > 0 && demand.RunsTasks {
+		if demand.CPU == 0 {
+			return "a CPU reservation or limit is required"
+		}
+		if usage.CPU+demand.CPU > quota.MaxCPU {
+			return fmt.Sprintf("%.2f CPUs reserved out of %.2f", usage.CPU+demand.CPU, quota.MaxCPU)
+		}
+	}
+
+	if quota.MaxVolumes > 0 && demand.Volumes > 0 && usage.Volumes+demand.Volumes > quota.MaxVolumes {
+		return fmt.Sprintf("%d volumes out of %d", usage.Volumes+demand.Volumes, quota.MaxVolumes)
+	}
+
+	if quota.MaxStacks > 0 && demand.Stacks > 0 && usage.Stacks+demand.Stacks > quota.MaxStacks {
+		return fmt.Sprintf("%d stacks out of %d", usage.Stacks+demand.Stacks, quota.MaxStacks)
+	}
+
+	return ""
+}
@@ -0,0 +1,142 @@
+package quota
+
+import (
+	"testing"
+
+	"github.com/docker/cli/cli/compose/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/swarm"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExceededReason(t *testing.T) {
+	quota := &portainer.ResourceQuota{MaxContainers: 2, MaxMemory: 1 << 30, MaxCPU: 2, MaxVolumes: 1, MaxStacks: 1}
+	usage := &Usage{Containers: 1, Memory: 512 << 20, CPU: 1, Volumes: 1, Stacks: 0}
+
+	container := func(memory int64, cpu float64) *Demand {
+		demand := &Demand{RunsTasks: true}
+		demand.Containers = 1
+		demand.Memory = memory
+		demand.CPU = cpu
+		return demand
+	}
+
+	assert.Empty(t, exceededReason(quota, usage, container(256<<20, 0.5)))
+	assert.Equal(t, "3 containers out of 2", exceededReason(quota, &Usage{Containers: 2}, container(256<<20, 0.5)))
+	assert.Equal(t, "a memory reservation or limit is required", exceededReason(quota, usage, container(0, 0.5)))
+	assert.Equal(t, "1.5GiB of memory reserved out of 1GiB", exceededReason(quota, usage, container(1<<30, 0.5)))
+	assert.Equal(t, "a CPU reservation or limit is required", exceededReason(quota, usage, container(256<<20, 0)))
+	assert.Equal(t, "2.50 CPUs reserved out of 2.00", exceededReason(quota, usage, container(256<<20, 1.5)))
+
+	volume := &Demand{}
+	volume.Volumes = 1
+	assert.Equal(t, "2 volumes out of 1", exceededReason(quota, usage, volume))
+
+	stack := &Demand{}
+	stack.Stacks = 1
+	assert.Empty(t, exceededReason(quota, usage, stack))
+	assert.Empty(t, exceededReason(&portainer.ResourceQuota{}, &Usage{Stacks: 10}, stack))
+}
+
+func TestValidateQuota(t *testing.T) {
+	assert.NoError(t, ValidateQuota(&portainer.ResourceQuota{MaxContainers: 5}))
+	assert.Error(t, ValidateQuota(&portainer.ResourceQuota{MaxCPU: -1}))
+}
+
+func TestOwnership(t *testing.T) {
+	owner := &ownership{
+		endpointID: 1,
+		teamID:     2,
+		memberIDs:  map[portainer.UserID]bool{3: true},
+		resourceControls: []portainer.ResourceControl{
+			{ResourceID: "team", Type: portainer.ContainerResourceControl, TeamAccesses: []portainer.TeamResourceAccess{{TeamID: 2}}},
+			{ResourceID: "member", Type: portainer.ContainerResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 3}}},
+			{ResourceID: "other", Type: portainer.ContainerResourceControl, UserAccesses: []portainer.UserResourceAccess{{UserID: 4}}},
+			{ResourceID: "1_web", Type: portainer.StackResourceControl, TeamAccesses: []portainer.TeamResourceAccess{{TeamID: 2}}},
+		},
+	}
+
+	assert.True(t, owner.owns("team", portainer.ContainerResourceControl, nil))
+	assert.True(t, owner.owns("member", portainer.ContainerResourceControl, nil))
+	assert.False(t, owner.owns("other", portainer.ContainerResourceControl, nil))
+	assert.False(t, owner.owns("unknown", portainer.ContainerResourceControl, nil))
+	assert.True(t, owner.owns("unknown", portainer.ContainerResourceControl, map[string]string{labelForDockerComposeStackName: "web"}))
+}
+
+func TestReservations(t *testing.T) {
+	memory, cpu := ContainerReservation(&container.HostConfig{Resources: container.Resources{MemoryReservation: 1 << 20, CPUQuota: 50000, CPUPeriod: 100000}})
+	assert.Equal(t, int64(1<<20), memory)
+	assert.Equal(t, 0.5, cpu)
+
+	replicas := uint64(3)
+	spec := &swarm.ServiceSpec{
+		TaskTemplate: swarm.TaskSpec{Resources: &swarm.ResourceRequirements{
+			Limits:       &swarm.Resources{MemoryBytes: 2 << 20, NanoCPUs: 1e9},
+			Reservations: &swarm.Resources{MemoryBytes: 1 << 20},
+		}},
+		Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+	}
+	memory, cpu = ServiceReservation(spec, 5)
+	assert.Equal(t, int64(3<<20), memory)
+	assert.Equal(t, 3.0, cpu)
+
+	spec.Mode = swarm.ServiceMode{Global: &swarm.GlobalService{}}
+	memory, _ = ServiceReservation(spec, 5)
+	assert.Equal(t, int64(5<<20), memory)
+}
+
+func TestStackDemand(t *testing.T) {
+	replicas := uint64(2)
+	config := &types.Config{
+		Services: []types.ServiceConfig{
+			{
+				Name: "web",
+				Deploy: types.DeployConfig{
+					Replicas: &replicas,
+					Resources: types.Resources{
+						Limits:       &types.Resource{MemoryBytes: 2 << 20, NanoCPUs: "1"},
+						Reservations: &types.Resource{MemoryBytes: 1 << 20},
+					},
+				},
+			},
+			{
+				Name: "agent",
+				Deploy: types.DeployConfig{
+					Mode:      "global",
+					Resources: types.Resources{Limits: &types.Resource{MemoryBytes: 1 << 20, NanoCPUs: "0.5"}},
+				},
+			},
+		},
+		Volumes: map[string]types.VolumeConfig{
+			"data":   {},
+			"shared": {External: types.External{External: true}},
+		},
+	}
+
+	demand := StackDemand(config, false, 0)
+	assert.Equal(t, 3, demand.Containers)
+	assert.Equal(t, int64(3<<20), demand.Memory)
+	assert.Equal(t, 2.5, demand.CPU)
+	assert.Equal(t, 1, demand.Volumes, "external volumes should not be counted")
+	assert.True(t, demand.RunsTasks)
+
+	demand = StackDemand(config, true, 3)
+	assert.Equal(t, 0, demand.Containers, "the tasks of a swarm stack should not be counted as containers")
+	assert.Equal(t, int64(5<<20), demand.Memory, "a global service should run a task on each node")
+
+	config.Services[1].Deploy.Resources = types.Resources{}
+	demand = StackDemand(config, true, 3)
+	assert.Zero(t, demand.Memory, "a service without reservation should require a reservation")
+	assert.Zero(t, demand.CPU)
+}
+
+func TestExclusion(t *testing.T) {
+	excluded := &exclusion{serviceID: "svc", stackName: "web"}
+
+	assert.True(t, excluded.excludes("svc", nil))
+	assert.True(t, excluded.excludes("abc", map[string]string{labelForDockerComposeStackName: "web"}))
+	assert.True(t, excluded.excludes("abc", map[string]string{labelForDockerSwarmStackName: "web"}))
+	assert.False(t, excluded.excludes("abc", map[string]string{labelForDockerComposeStackName: "api"}))
+	assert.False(t, (&exclusion{}).excludes("abc", map[string]string{labelForDockerComposeStackName: ""}))
+}
@@ -0,0 +1,77 @@
+package quota
+
+import (
+	"strconv"
+
+	"github.com/docker/cli/cli/compose/types"
+)
+
+// StackDemand returns the resources requested by the services of a stack file, read from their deploy section.
+// The services of a Compose stack run as containers, the services of a Swarm stack run tasks which are reserved
+// by their service and are not counted as containers. A global service runs a task on each of the nodes.
+// When a service has no memory or no CPU reservation, the memory or the CPU of the demand is 0 so that the
+// quotas limiting them require a reservation for each of the services.
+func StackDemand(config *types.Config, swarmMode bool, nodes int) *Demand {
+	demand := &Demand{RunsTasks: len(config.Services) > 0}
+
+	missingMemory, missingCPU := false, false
+
+	for _, service := range config.Services {
+		tasks := uint64(1)
+		if service.Deploy.Mode == "global" {
+			if nodes > 0 {
+				tasks = uint64(nodes)
+			}
+		} else if service.Deploy.Replicas != nil {
+			tasks = *service.Deploy.Replicas
+		}
+
+		if !swarmMode {
+			demand.Containers += int(tasks)
+		}
+
+		memory, cpu := stackServiceReservation(&service.Deploy.Resources)
+		missingMemory = missingMemory || memory == 0
+		missingCPU = missingCPU || cpu == 0
+
+		demand.Memory += memory * int64(tasks)
+		demand.CPU += cpu * float64(tasks)
+	}
+
+	for _, volume := range config.Volumes {
+		if !volume.External.External {
+			demand.Volumes++
+		}
+	}
+
+	if missingMemory {
+		demand.Memory = 0
+	}
+	if missingCPU {
+		demand.CPU = 0
+	}
+
+	return demand
+}
+
+// stackServiceReservation returns the memory, in bytes, and the number of CPUs reserved by a task of a stack service
+func stackServiceReservation(resources *types.Resources) (int64, float64) {
+	var memory int64
+	var cpu float64
+
+	for _, resource := range []*types.Resource{resources.Reservations, resources.Limits} {
+		if resource == nil {
+			continue
+		}
+
+		if memory == 0 {
+			memory = int64(resource.MemoryBytes)
+		}
+
+		if cpu == 0 {
+			cpu, _ = strconv.ParseFloat(resource.NanoCPUs, 64)
+		}
+	}
+
+	return memory, cpu
+}
@@ -0,0 +1,294 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/client"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/internal/stackutils"
+)
+
+const (
+	labelForDockerSwarmStackName   = "com.docker.stack.namespace"
+	labelForDockerServiceID        = "com.docker.swarm.service.id"
+	labelForDockerComposeStackName = "com.docker.compose.project"
+)
+
+// ownership identifies the resources owned by a team. A resource is owned by a team when its resource control,
+// or the resource control of the service or the stack it belongs to, grants access to the team or to one of its members.
+type ownership struct {
+	endpointID       portainer.EndpointID
+	teamID           portainer.TeamID
+	memberIDs        map[portainer.UserID]bool
+	resourceControls []portainer.ResourceControl
+}
+
+func newOwnership(dataStore portainer.DataStore, endpointID portainer.EndpointID, teamID portainer.TeamID) (*ownership, error) {
+	memberships, err := dataStore.TeamMembership().TeamMembershipsByTeamID(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceControls, err := dataStore.ResourceControl().ResourceControls()
+	if err != nil {
+		return nil, err
+	}
+
+	owner := &ownership{
+		endpointID:       endpointID,
+		teamID:           teamID,
+		memberIDs:        make(map[portainer.UserID]bool),
+		resourceControls: resourceControls,
+	}
+	for _, membership := range memberships {
+		owner.memberIDs[membership.UserID] = true
+	}
+
+	return owner, nil
+}
+
+func (owner *ownership) owns(resourceID string, resourceType portainer.ResourceControlType, labels map[string]string) bool {
+	resourceControl := authorization.GetResourceControlByResourceIDAndType(resourceID, resourceType, owner.resourceControls)
+
+	if resourceControl == nil && labels[labelForDockerServiceID] != "" {
+		resourceControl = authorization.GetResourceControlByResourceIDAndType(labels[labelForDockerServiceID], portainer.ServiceResourceControl, owner.resourceControls)
+	}
+
+	if resourceControl == nil {
+		stackName := labels[labelForDockerSwarmStackName]
+		if stackName == "" {
+			stackName = labels[labelForDockerComposeStackName]
+		}
+		if stackName != "" {
+			resourceControl = authorization.GetResourceControlByResourceIDAndType(stackutils.ResourceControlID(owner.endpointID, stackName), portainer.StackResourceControl, owner.resourceControls)
+		}
+	}
+
+	if resourceControl == nil {
+		return false
+	}
+
+	for _, access := range resourceControl.TeamAccesses {
+		if access.TeamID == owner.teamID {
+			return true
+		}
+	}
+
+	for _, access := range resourceControl.UserAccesses {
+		if owner.memberIDs[access.UserID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// computeUsage returns the resources owned by the team, only the resources requested by the demand are counted when there is one
+func computeUsage(dataStore portainer.DataStore, dockerClient *client.Client, endpoint *portainer.Endpoint, teamID portainer.TeamID, demand *Demand) (*Usage, error) {
+	owner, err := newOwnership(dataStore, endpoint.ID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &Usage{}
+	excluded := &exclusion{}
+	if demand != nil {
+		excluded.serviceID = demand.ExcludedServiceID
+		excluded.stackName = demand.ExcludedStackName
+	}
+
+	if demand == nil || demand.Stacks > 0 {
+		err = countStacks(dataStore, owner, usage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if demand != nil && demand.Stacks > 0 && demand.Containers == 0 && demand.Volumes == 0 && !demand.RunsTasks {
+		return usage, nil
+	}
+
+	ctx := context.Background()
+
+	info, err := dockerClient.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if demand == nil || demand.Containers > 0 || demand.RunsTasks {
+		err = countContainers(ctx, dockerClient, owner, excluded, usage)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Swarm.ControlAvailable {
+			err = countServices(ctx, dockerClient, owner, info.Swarm.Nodes, excluded, usage)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if demand == nil || demand.Volumes > 0 {
+		dockerID := info.ID
+		if info.Swarm.Cluster != nil {
+			dockerID = info.Swarm.Cluster.ID
+		}
+
+		err = countVolumes(ctx, dockerClient, owner, dockerID, excluded, usage)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return usage, nil
+}
+
+// exclusion identifies the resources replaced by a demand, which are not counted in the usage
+type exclusion struct {
+	serviceID string
+	stackName string
+}
+
+func (excluded *exclusion) excludes(resourceID string, labels map[string]string) bool {
+	if excluded.serviceID != "" && resourceID == excluded.serviceID {
+		return true
+	}
+
+	if excluded.stackName == "" {
+		return false
+	}
+
+	return labels[labelForDockerSwarmStackName] == excluded.stackName || labels[labelForDockerComposeStackName] == excluded.stackName
+}
+
+func countStacks(dataStore portainer.DataStore, owner *ownership, usage *Usage) error {
+	stacks, err := dataStore.Stack().Stacks()
+	if err != nil {
+		return err
+	}
+
+	for _, stack := range stacks {
+		if stack.EndpointID == owner.endpointID && owner.owns(stackutils.ResourceControlID(stack.EndpointID, stack.Name), portainer.StackResourceControl, nil) {
+			usage.Stacks++
+		}
+	}
+
+	return nil
+}
+
+func countContainers(ctx context.Context, dockerClient *client.Client, owner *ownership, excluded *exclusion, usage *Usage) error {
+	containers, err := dockerClient.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return err
+	}
+
+	for _, summary := range containers {
+		// the tasks are reserved by their service
+		if summary.Labels[labelForDockerServiceID] != "" || excluded.excludes(summary.ID, summary.Labels) || !owner.owns(summary.ID, portainer.ContainerResourceControl, summary.Labels) {
+			continue
+		}
+
+		usage.Containers++
+
+		inspect, err := dockerClient.ContainerInspect(ctx, summary.ID)
+		if err != nil {
+			return err
+		}
+
+		if inspect.HostConfig != nil {
+			memory, cpu := ContainerReservation(inspect.HostConfig)
+			usage.Memory += memory
+			usage.CPU += cpu
+		}
+	}
+
+	return nil
+}
+
+func countServices(ctx context.Context, dockerClient *client.Client, owner *ownership, nodes int, excluded *exclusion, usage *Usage) error {
+	services, err := dockerClient.ServiceList(ctx, types.ServiceListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		if excluded.excludes(service.ID, service.Spec.Labels) || !owner.owns(service.ID, portainer.ServiceResourceControl, service.Spec.Labels) {
+			continue
+		}
+
+		memory, cpu := ServiceReservation(&service.Spec, nodes)
+		usage.Memory += memory
+		usage.CPU += cpu
+	}
+
+	return nil
+}
+
+func countVolumes(ctx context.Context, dockerClient *client.Client, owner *ownership, dockerID string, excluded *exclusion, usage *Usage) error {
+	volumes, err := dockerClient.VolumeList(ctx, filters.NewArgs())
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range volumes.Volumes {
+		// the resource controls of the volumes are identified by the name of the volume and the Docker ID of the endpoint
+		resourceID := fmt.Sprintf("%s_%s", volume.Name, dockerID)
+		if !excluded.excludes(resourceID, volume.Labels) && owner.owns(resourceID, portainer.VolumeResourceControl, volume.Labels) {
+			usage.Volumes++
+		}
+	}
+
+	return nil
+}
+
+// ContainerReservation returns the memory, in bytes, and the number of CPUs reserved by a container
+func ContainerReservation(hostConfig *container.HostConfig) (int64, float64) {
+	memory := hostConfig.Memory
+	if memory == 0 {
+		memory = hostConfig.MemoryReservation
+	}
+
+	cpu := float64(hostConfig.NanoCPUs) / 1e9
+	if cpu == 0 && hostConfig.CPUQuota > 0 && hostConfig.CPUPeriod > 0 {
+		cpu = float64(hostConfig.CPUQuota) / float64(hostConfig.CPUPeriod)
+	}
+
+	return memory, cpu
+}
+
+// ServiceReservation returns the memory, in bytes, and the number of CPUs reserved by all the tasks of a service.
+// A global service runs a task on each of the nodes.
+func ServiceReservation(spec *swarm.ServiceSpec, nodes int) (int64, float64) {
+	var memory, nanoCPUs int64
+
+	if resources := spec.TaskTemplate.Resources; resources != nil {
+		if resources.Reservations != nil {
+			memory = resources.Reservations.MemoryBytes
+			nanoCPUs = resources.Reservations.NanoCPUs
+		}
+		if resources.Limits != nil {
+			if memory == 0 {
+				memory = resources.Limits.MemoryBytes
+			}
+			if nanoCPUs == 0 {
+				nanoCPUs = resources.Limits.NanoCPUs
+			}
+		}
+	}
+
+	tasks := uint64(1)
+	if spec.Mode.Replicated != nil && spec.Mode.Replicated.Replicas != nil {
+		tasks = *spec.Mode.Replicated.Replicas
+	} else if spec.Mode.Global != nil && nodes > 0 {
+		tasks = uint64(nodes)
+	}
+
+	return memory * int64(tasks), float64(nanoCPUs) / 1e9 * float64(tasks)
+}
@@ -0,0 +1,206 @@
+package snapshot
+
+import (
+	"log"
+	"math"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
+
+// historyConfiguration represents the retention and downsampling of the snapshot history.
+type historyConfiguration struct {
+	retention          time.Duration
+	downsampleAfter    time.Duration
+	downsampleInterval time.Duration
+}
+
+// newHistoryConfiguration reads the snapshot history configuration from the settings,
+// falling back to the default values for empty or invalid settings.
+func newHistoryConfiguration(settings *portainer.Settings) historyConfiguration {
+	return historyConfiguration{
+		retention:          parseDuration(settings.SnapshotHistoryRetention, portainer.DefaultSnapshotHistoryRetention),
+		downsampleAfter:    parseDuration(settings.SnapshotHistoryDownsampleAfter, portainer.DefaultSnapshotHistoryDownsampleAfter),
+		downsampleInterval: parseDuration(settings.SnapshotHistoryDownsampleInterval, portainer.DefaultSnapshotHistoryDownsampleInterval),
+	}
+}
+
+func parseDuration(value, defaultValue string) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		duration, _ = time.ParseDuration(defaultValue)
+	}
+	return duration
+}
+
+// newHistoryEntry creates a history entry from the latest snapshot of an endpoint.
+// When the snapshot failed, the entry only records that the endpoint was down.
+func newHistoryEntry(endpoint *portainer.Endpoint, snapshotError error) *portainer.SnapshotHistoryEntry {
+	entry := &portainer.SnapshotHistoryEntry{
+		EndpointID:  endpoint.ID,
+		Time:        time.Now().Unix(),
+		Status:      portainer.EndpointStatusDown,
+		SampleCount: 1,
+	}
+
+	if snapshotError != nil {
+		return entry
+	}
+
+	entry.Status = portainer.EndpointStatusUp
+
+	if len(endpoint.Kubernetes.Snapshots) > 0 {
+		snapshot := endpoint.Kubernetes.Snapshots[0]
+		entry.Time = snapshot.Time
+		entry.TotalCPU = snapshot.TotalCPU
+		entry.TotalMemory = snapshot.TotalMemory
+		entry.NodeCount = snapshot.NodeCount
+	}
+
+	if len(endpoint.Snapshots) > 0 {
+		snapshot := endpoint.Snapshots[0]
+		entry.Time = snapshot.Time
+		entry.TotalCPU = int64(snapshot.TotalCPU)
+		entry.TotalMemory = snapshot.TotalMemory
+		entry.RunningContainerCount = snapshot.RunningContainerCount
+		entry.StoppedContainerCount = snapshot.StoppedContainerCount
+		entry.HealthyContainerCount = snapshot.HealthyContainerCount
+		entry.UnhealthyContainerCount = snapshot.UnhealthyContainerCount
+		entry.ImageCount = snapshot.ImageCount
+		entry.VolumeCount = snapshot.VolumeCount
+		entry.NodeCount = snapshot.NodeCount
+	}
+
+	return entry
+}
+
+// recordHistory appends the outcome of the latest snapshot of an endpoint to its history.
+func (service *Service) recordHistory(endpoint *portainer.Endpoint, snapshotError error) {
+	err := service.dataStore.SnapshotHistory().CreateSnapshotHistoryEntry(newHistoryEntry(endpoint, snapshotError))
+	if err != nil {
+		log.Printf("[ERROR] [internal,snapshot] [message: unable to record snapshot history] [endpoint: %d] [error: %s]", endpoint.ID, err)
+	}
+}
+
+// compactHistory removes the history entries older than the retention period and
+// downsamples the entries older than the downsampling age, at most once per downsampling interval.
+func (service *Service) compactHistory(endpoints []portainer.Endpoint) error {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	configuration := newHistoryConfiguration(settings)
+
+	now := time.Now()
+	if now.Sub(service.lastHistoryCompaction) < configuration.downsampleInterval {
+		return nil
+	}
+	service.lastHistoryCompaction = now
+
+	for _, endpoint := range endpoints {
+		err := compactEndpointHistory(service.dataStore.SnapshotHistory(), endpoint.ID, configuration, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func compactEndpointHistory(historyService portainer.SnapshotHistoryService, endpointID portainer.EndpointID, configuration historyConfiguration, now time.Time) error {
+	retentionLimit := now.Add(-configuration.retention).Unix()
+	if retentionLimit > 0 {
+		err := historyService.DeleteSnapshotHistory(endpointID, 0, retentionLimit-1)
+		if err != nil {
+			return err
+		}
+	}
+
+	downsampleLimit := now.Add(-configuration.downsampleAfter).Unix()
+	if downsampleLimit <= 0 {
+		return nil
+	}
+
+	entries, err := historyService.SnapshotHistory(endpointID, 0, downsampleLimit)
+	if err != nil {
+		return err
+	}
+
+	interval := int64(configuration.downsampleInterval.Seconds())
+	if interval <= 0 {
+		return nil
+	}
+
+	for start := 0; start < len(entries); {
+		periodStart := entries[start].Time - entries[start].Time%interval
+
+		end := start + 1
+		for end < len(entries) && entries[end].Time < periodStart+interval {
+			end++
+		}
+
+		// periods ending after the downsampling age are left untouched until they are complete
+		if end-start > 1 && periodStart+interval-1 <= downsampleLimit {
+			err := historyService.DeleteSnapshotHistory(endpointID, periodStart, periodStart+interval-1)
+			if err != nil {
+				return err
+			}
+
+			err = historyService.CreateSnapshotHistoryEntry(downsample(entries[start:end], periodStart))
+			if err != nil {
+				return err
+			}
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// downsample aggregates entries into a single entry, averaging each metric weighted by the
+// number of snapshots of every entry. The endpoint is considered down if it was down in any entry.
+func downsample(entries []portainer.SnapshotHistoryEntry, time int64) *portainer.SnapshotHistoryEntry {
+	aggregate := &portainer.SnapshotHistoryEntry{
+		EndpointID: entries[0].EndpointID,
+		Time:       time,
+		Status:     portainer.EndpointStatusUp,
+	}
+
+	var totalCPU, totalMemory, running, stopped, healthy, unhealthy, images, volumes, nodes float64
+	for _, entry := range entries {
+		weight := float64(entry.SampleCount)
+		if weight == 0 {
+			weight = 1
+		}
+
+		if entry.Status == portainer.EndpointStatusDown {
+			aggregate.Status = portainer.EndpointStatusDown
+		}
+
+		aggregate.SampleCount += int(weight)
+		totalCPU += float64(entry.TotalCPU) * weight
+		totalMemory += float64(entry.TotalMemory) * weight
+		running += float64(entry.RunningContainerCount) * weight
+		stopped += float64(entry.StoppedContainerCount) * weight
+		healthy += float64(entry.HealthyContainerCount) * weight
+		unhealthy += float64(entry.UnhealthyContainerCount) * weight
+		images += float64(entry.ImageCount) * weight
+		volumes += float64(entry.VolumeCount) * weight
+		nodes += float64(entry.NodeCount) * weight
+	}
+
+	count := float64(aggregate.SampleCount)
+	aggregate.TotalCPU = int64(math.Round(totalCPU / count))
+	aggregate.TotalMemory = int64(math.Round(totalMemory / count))
+	aggregate.RunningContainerCount = int(math.Round(running / count))
+	aggregate.StoppedContainerCount = int(math.Round(stopped / count))
+	aggregate.HealthyContainerCount = int(math.Round(healthy / count))
+	aggregate.UnhealthyContainerCount = int(math.Round(unhealthy / count))
+	aggregate.ImageCount = int(math.Round(images / count))
+	aggregate.VolumeCount = int(math.Round(volumes / count))
+	aggregate.NodeCount = int(math.Round(nodes / count))
+
+	return aggregate
+}
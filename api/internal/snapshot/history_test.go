@@ -0,0 +1,113 @@
+package snapshot
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySnapshotHistoryService struct {
+	entries []portainer.SnapshotHistoryEntry
+}
+
+func (service *memorySnapshotHistoryService) SnapshotHistory(endpointID portainer.EndpointID, from, to int64) ([]portainer.SnapshotHistoryEntry, error) {
+	entries := make([]portainer.SnapshotHistoryEntry, 0)
+	for _, entry := range service.entries {
+		if entry.EndpointID == endpointID && entry.Time >= from && entry.Time <= to {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (service *memorySnapshotHistoryService) CreateSnapshotHistoryEntry(entry *portainer.SnapshotHistoryEntry) error {
+	service.DeleteSnapshotHistory(entry.EndpointID, entry.Time, entry.Time)
+	service.entries = append(service.entries, *entry)
+	sort.Slice(service.entries, func(i, j int) bool { return service.entries[i].Time < service.entries[j].Time })
+	return nil
+}
+
+func (service *memorySnapshotHistoryService) DeleteSnapshotHistory(endpointID portainer.EndpointID, from, to int64) error {
+	entries := make([]portainer.SnapshotHistoryEntry, 0)
+	for _, entry := range service.entries {
+		if entry.EndpointID != endpointID || entry.Time < from || entry.Time > to {
+			entries = append(entries, entry)
+		}
+	}
+	service.entries = entries
+	return nil
+}
+
+func TestCompactEndpointHistory(t *testing.T) {
+	historyService := &memorySnapshotHistoryService{}
+	now := time.Unix(100000, 0)
+	configuration := historyConfiguration{
+		retention:          10000 * time.Second,
+		downsampleAfter:    1000 * time.Second,
+		downsampleInterval: 100 * time.Second,
+	}
+
+	entries := []portainer.SnapshotHistoryEntry{
+		// expired
+		{Time: 89000, Status: portainer.EndpointStatusUp, ImageCount: 100},
+		// downsampled together
+		{Time: 98010, Status: portainer.EndpointStatusUp, ImageCount: 2, SampleCount: 1},
+		{Time: 98050, Status: portainer.EndpointStatusDown, SampleCount: 1},
+		{Time: 98090, Status: portainer.EndpointStatusUp, ImageCount: 4, SampleCount: 2},
+		// single entry in its period
+		{Time: 98150, Status: portainer.EndpointStatusUp, ImageCount: 5, SampleCount: 1},
+		// recent
+		{Time: 99950, Status: portainer.EndpointStatusUp, ImageCount: 6, SampleCount: 1},
+		{Time: 99960, Status: portainer.EndpointStatusUp, ImageCount: 7, SampleCount: 1},
+	}
+	for _, entry := range entries {
+		entry := entry
+		entry.EndpointID = 1
+		require.NoError(t, historyService.CreateSnapshotHistoryEntry(&entry))
+	}
+
+	require.NoError(t, compactEndpointHistory(historyService, 1, configuration, now))
+
+	history, err := historyService.SnapshotHistory(1, 0, now.Unix())
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+
+	assert.Equal(t, int64(98000), history[0].Time)
+	assert.Equal(t, 4, history[0].SampleCount)
+	assert.Equal(t, 3, history[0].ImageCount)
+	assert.Equal(t, portainer.EndpointStatus(portainer.EndpointStatusDown), history[0].Status)
+
+	assert.Equal(t, int64(98150), history[1].Time)
+	assert.Equal(t, int64(99950), history[2].Time)
+	assert.Equal(t, int64(99960), history[3].Time)
+}
+
+func TestNewHistoryEntry(t *testing.T) {
+	endpoint := &portainer.Endpoint{
+		ID: 1,
+		Snapshots: []portainer.DockerSnapshot{
+			{Time: 10, TotalCPU: 4, RunningContainerCount: 3, ImageCount: 2, VolumeCount: 1, NodeCount: 1},
+		},
+	}
+
+	entry := newHistoryEntry(endpoint, nil)
+	assert.Equal(t, portainer.SnapshotHistoryEntry{
+		EndpointID:            1,
+		Time:                  10,
+		Status:                portainer.EndpointStatusUp,
+		TotalCPU:              4,
+		RunningContainerCount: 3,
+		ImageCount:            2,
+		VolumeCount:           1,
+		NodeCount:             1,
+		SampleCount:           1,
+	}, *entry)
+
+	entry = newHistoryEntry(endpoint, assert.AnError)
+	assert.Equal(t, portainer.EndpointStatus(portainer.EndpointStatusDown), entry.Status)
+	assert.Zero(t, entry.ImageCount)
+}
@@ -3,10 +3,15 @@ package snapshot
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"math/rand"
+	"sort"
+	"sync"
 	"time"
 
 	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/internal/edge"
 )
 
 // Service repesents a service to manage endpoint snapshots.
@@ -16,21 +21,43 @@ type Service struct {
 	dataStore                 portainer.DataStore
 	refreshSignal             chan struct{}
 	snapshotIntervalInSeconds float64
+	snapshotConcurrency       int
+	snapshotTimeout           time.Duration
 	dockerSnapshotter         portainer.DockerSnapshotter
 	kubernetesSnapshotter     portainer.KubernetesSnapshotter
 	shutdownCtx               context.Context
+	lastHistoryCompaction     time.Time
 }
 
-// NewService creates a new instance of a service
-func NewService(snapshotInterval string, dataStore portainer.DataStore, dockerSnapshotter portainer.DockerSnapshotter, kubernetesSnapshotter portainer.KubernetesSnapshotter, shutdownCtx context.Context) (*Service, error) {
+const (
+	// maxSnapshotBackoff is the maximum delay between two snapshots of an endpoint that keeps failing
+	maxSnapshotBackoff = time.Hour
+	// snapshotJitterRatio is the fraction of the snapshot interval over which snapshots are spread
+	snapshotJitterRatio = 0.25
+)
+
+// NewService creates a new instance of a service.
+// Endpoints are snapshotted by snapshotConcurrency workers, each snapshot being aborted after snapshotTimeout.
+func NewService(snapshotInterval string, snapshotConcurrency int, snapshotTimeout string, dataStore portainer.DataStore, dockerSnapshotter portainer.DockerSnapshotter, kubernetesSnapshotter portainer.KubernetesSnapshotter, shutdownCtx context.Context) (*Service, error) {
 	snapshotFrequency, err := time.ParseDuration(snapshotInterval)
 	if err != nil {
 		return nil, err
 	}
 
+	timeout, err := time.ParseDuration(snapshotTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshotConcurrency < 1 {
+		snapshotConcurrency = 1
+	}
+
 	return &Service{
 		dataStore:                 dataStore,
 		snapshotIntervalInSeconds: snapshotFrequency.Seconds(),
+		snapshotConcurrency:       snapshotConcurrency,
+		snapshotTimeout:           timeout,
 		dockerSnapshotter:         dockerSnapshotter,
 		kubernetesSnapshotter:     kubernetesSnapshotter,
 		shutdownCtx:               shutdownCtx,
@@ -84,19 +111,118 @@ func SupportDirectSnapshot(endpoint *portainer.Endpoint) bool {
 
 // SnapshotEndpoint will create a snapshot of the endpoint based on the endpoint type.
 // If the snapshot is a success, it will be associated to the endpoint.
+// The outcome of the snapshot is stored in the endpoint snapshot status and recorded in the endpoint snapshot history.
 func (service *Service) SnapshotEndpoint(endpoint *portainer.Endpoint) error {
-	switch endpoint.Type {
-	case portainer.AzureEnvironment:
+	if endpoint.Type == portainer.AzureEnvironment {
 		return nil
+	}
+
+	start := time.Now()
+	err := service.snapshotEndpointWithTimeout(endpoint)
+	updateSnapshotStatus(&endpoint.SnapshotStatus, start, time.Now(), err)
+
+	service.recordHistory(endpoint, err)
+
+	return err
+}
+
+// StoreEdgeSnapshot associates a snapshot pushed by an Edge agent to the endpoint, without opening a tunnel.
+// The snapshot is recorded in the endpoint snapshot status and history, the endpoint must be persisted by the caller.
+func (service *Service) StoreEdgeSnapshot(endpoint *portainer.Endpoint, dockerSnapshot *portainer.DockerSnapshot, kubernetesSnapshot *portainer.KubernetesSnapshot) {
+	now := time.Now()
+
+	if dockerSnapshot != nil {
+		if dockerSnapshot.Time == 0 {
+			dockerSnapshot.Time = now.Unix()
+		}
+		endpoint.Snapshots = []portainer.DockerSnapshot{*dockerSnapshot}
+	}
+
+	if kubernetesSnapshot != nil {
+		if kubernetesSnapshot.Time == 0 {
+			kubernetesSnapshot.Time = now.Unix()
+		}
+		endpoint.Kubernetes.Snapshots = []portainer.KubernetesSnapshot{*kubernetesSnapshot}
+	}
+
+	updateSnapshotStatus(&endpoint.SnapshotStatus, now, now, nil)
+
+	service.recordHistory(endpoint, nil)
+}
+
+// snapshotEndpointWithTimeout snapshots a copy of the endpoint and gives up after the snapshot timeout.
+// The requests of the snapshot are cancelled on timeout or on shutdown.
+func (service *Service) snapshotEndpointWithTimeout(endpoint *portainer.Endpoint) error {
+	ctx, cancel := context.WithTimeout(service.shutdownCtx, service.snapshotTimeout)
+	defer cancel()
+
+	snapshotEndpoint := *endpoint
+	result := make(chan error, 1)
+
+	go func() {
+		result <- service.snapshotEndpoint(ctx, &snapshotEndpoint)
+	}()
+
+	select {
+	case err := <-result:
+		if ctx.Err() == nil {
+			endpoint.Snapshots = snapshotEndpoint.Snapshots
+			endpoint.Kubernetes.Snapshots = snapshotEndpoint.Kubernetes.Snapshots
+			return err
+		}
+	case <-ctx.Done():
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("snapshot timed out after %s", service.snapshotTimeout)
+	}
+	return ctx.Err()
+}
+
+func (service *Service) snapshotEndpoint(ctx context.Context, endpoint *portainer.Endpoint) error {
+	switch endpoint.Type {
 	case portainer.KubernetesLocalEnvironment, portainer.AgentOnKubernetesEnvironment, portainer.EdgeAgentOnKubernetesEnvironment:
-		return service.snapshotKubernetesEndpoint(endpoint)
+		return service.snapshotKubernetesEndpoint(ctx, endpoint)
+	}
+
+	return service.snapshotDockerEndpoint(ctx, endpoint)
+}
+
+func updateSnapshotStatus(status *portainer.EndpointSnapshotStatus, start, end time.Time, snapshotError error) {
+	status.LastAttempt = start.Unix()
+	status.LastDuration = end.Sub(start).Milliseconds()
+
+	if snapshotError != nil {
+		status.LastError = snapshotError.Error()
+		status.LastErrorTime = start.Unix()
+		status.ConsecutiveFailures++
+		return
 	}
 
-	return service.snapshotDockerEndpoint(endpoint)
+	status.LastSuccess = start.Unix()
+	status.ConsecutiveFailures = 0
 }
 
-func (service *Service) snapshotKubernetesEndpoint(endpoint *portainer.Endpoint) error {
-	snapshot, err := service.kubernetesSnapshotter.CreateSnapshot(endpoint)
+// backoffDelay returns the minimum delay between the latest snapshot attempt of an endpoint and the next one.
+// The delay doubles with every consecutive failure, endpoints that failed only once are retried at the next interval.
+func backoffDelay(status portainer.EndpointSnapshotStatus, interval time.Duration) time.Duration {
+	if status.ConsecutiveFailures < 2 {
+		return 0
+	}
+
+	delay := interval
+	for i := 2; i < status.ConsecutiveFailures && delay < maxSnapshotBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxSnapshotBackoff {
+		return maxSnapshotBackoff
+	}
+	return delay
+}
+
+func (service *Service) snapshotKubernetesEndpoint(ctx context.Context, endpoint *portainer.Endpoint) error {
+	snapshot, err := service.kubernetesSnapshotter.CreateSnapshot(ctx, endpoint)
 	if err != nil {
 		return err
 	}
@@ -108,8 +234,8 @@ func (service *Service) snapshotKubernetesEndpoint(endpoint *portainer.Endpoint)
 	return nil
 }
 
-func (service *Service) snapshotDockerEndpoint(endpoint *portainer.Endpoint) error {
-	snapshot, err := service.dockerSnapshotter.CreateSnapshot(endpoint)
+func (service *Service) snapshotDockerEndpoint(ctx context.Context, endpoint *portainer.Endpoint) error {
+	snapshot, err := service.dockerSnapshotter.CreateSnapshot(ctx, endpoint)
 	if err != nil {
 		return err
 	}
@@ -157,36 +283,106 @@ func (service *Service) snapshotEndpoints() error {
 		return err
 	}
 
+	interval := time.Duration(service.snapshotIntervalInSeconds) * time.Second
+	now := time.Now()
+
+	pending := make([]portainer.Endpoint, 0, len(endpoints))
 	for _, endpoint := range endpoints {
 		if !SupportDirectSnapshot(&endpoint) {
 			continue
 		}
 
-		snapshotError := service.SnapshotEndpoint(&endpoint)
-
-		latestEndpointReference, err := service.dataStore.Endpoint().Endpoint(endpoint.ID)
-		if latestEndpointReference == nil {
-			log.Printf("background schedule error (endpoint snapshot). Endpoint not found inside the database anymore (endpoint=%s, URL=%s) (err=%s)\n", endpoint.Name, endpoint.URL, err)
+		lastAttempt := time.Unix(endpoint.SnapshotStatus.LastAttempt, 0)
+		if now.Before(lastAttempt.Add(backoffDelay(endpoint.SnapshotStatus, interval))) {
 			continue
 		}
 
-		latestEndpointReference.Status = portainer.EndpointStatusUp
-		if snapshotError != nil {
-			log.Printf("background schedule error (endpoint snapshot). Unable to create snapshot (endpoint=%s, URL=%s) (err=%s)\n", endpoint.Name, endpoint.URL, snapshotError)
-			latestEndpointReference.Status = portainer.EndpointStatusDown
+		pending = append(pending, endpoint)
+	}
+
+	jobs := make(chan portainer.Endpoint)
+
+	var wg sync.WaitGroup
+	for i := 0; i < service.snapshotConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for endpoint := range jobs {
+				service.snapshotAndPersistEndpoint(endpoint)
+			}
+		}()
+	}
+
+	service.dispatchSnapshots(pending, jobs, time.Duration(float64(interval)*snapshotJitterRatio))
+	close(jobs)
+	wg.Wait()
+
+	// the rules of the dynamic Edge groups depend on the check-in dates and on the snapshots
+	err = edge.UpdateEndpointRelations(service.dataStore)
+	if err != nil {
+		log.Printf("[WARN] [internal,snapshot] [message: unable to update the Edge endpoint relations] [error: %s]", err)
+	}
+
+	return service.compactHistory(endpoints)
+}
+
+// dispatchSnapshots sends the endpoints to the workers at random times spread over the jitter window
+// so that the snapshots of all the endpoints do not start at once.
+func (service *Service) dispatchSnapshots(endpoints []portainer.Endpoint, jobs chan<- portainer.Endpoint, jitter time.Duration) {
+	offsets := make([]time.Duration, len(endpoints))
+	if jitter > 0 {
+		for i := range offsets {
+			offsets[i] = time.Duration(rand.Int63n(int64(jitter)))
 		}
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	}
 
-		latestEndpointReference.Snapshots = endpoint.Snapshots
-		latestEndpointReference.Kubernetes.Snapshots = endpoint.Kubernetes.Snapshots
+	rand.Shuffle(len(endpoints), func(i, j int) {
+		endpoints[i], endpoints[j] = endpoints[j], endpoints[i]
+	})
 
-		err = service.dataStore.Endpoint().UpdateEndpoint(latestEndpointReference.ID, latestEndpointReference)
-		if err != nil {
-			log.Printf("background schedule error (endpoint snapshot). Unable to update endpoint (endpoint=%s, URL=%s) (err=%s)\n", endpoint.Name, endpoint.URL, err)
-			continue
+	start := time.Now()
+	for i, endpoint := range endpoints {
+		wait := time.Until(start.Add(offsets[i]))
+		if wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-service.shutdownCtx.Done():
+				return
+			}
+		}
+
+		select {
+		case jobs <- endpoint:
+		case <-service.shutdownCtx.Done():
+			return
 		}
 	}
+}
 
-	return nil
+func (service *Service) snapshotAndPersistEndpoint(endpoint portainer.Endpoint) {
+	snapshotError := service.SnapshotEndpoint(&endpoint)
+
+	latestEndpointReference, err := service.dataStore.Endpoint().Endpoint(endpoint.ID)
+	if latestEndpointReference == nil {
+		log.Printf("background schedule error (endpoint snapshot). Endpoint not found inside the database anymore (endpoint=%s, URL=%s) (err=%s)\n", endpoint.Name, endpoint.URL, err)
+		return
+	}
+
+	latestEndpointReference.Status = portainer.EndpointStatusUp
+	if snapshotError != nil {
+		log.Printf("background schedule error (endpoint snapshot). Unable to create snapshot (endpoint=%s, URL=%s) (err=%s)\n", endpoint.Name, endpoint.URL, snapshotError)
+		latestEndpointReference.Status = portainer.EndpointStatusDown
+	}
+
+	latestEndpointReference.Snapshots = endpoint.Snapshots
+	latestEndpointReference.Kubernetes.Snapshots = endpoint.Kubernetes.Snapshots
+	latestEndpointReference.SnapshotStatus = endpoint.SnapshotStatus
+
+	err = service.dataStore.Endpoint().UpdateEndpoint(latestEndpointReference.ID, latestEndpointReference)
+	if err != nil {
+		log.Printf("background schedule error (endpoint snapshot). Unable to update endpoint (endpoint=%s, URL=%s) (err=%s)\n", endpoint.Name, endpoint.URL, err)
+	}
 }
 
 // FetchDockerID fetches info.Swarm.Cluster.ID if endpoint is swarm and info.ID otherwise
@@ -0,0 +1,98 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubDockerSnapshotter struct {
+	delay     time.Duration
+	cancelled chan struct{}
+}
+
+func (snapshotter *stubDockerSnapshotter) CreateSnapshot(ctx context.Context, endpoint *portainer.Endpoint) (*portainer.DockerSnapshot, error) {
+	select {
+	case <-time.After(snapshotter.delay):
+		return &portainer.DockerSnapshot{ImageCount: 1}, nil
+	case <-ctx.Done():
+		close(snapshotter.cancelled)
+		return nil, ctx.Err()
+	}
+}
+
+func TestSnapshotEndpointWithTimeout(t *testing.T) {
+	service := &Service{
+		dockerSnapshotter: &stubDockerSnapshotter{},
+		snapshotTimeout:   time.Second,
+		shutdownCtx:       context.Background(),
+	}
+
+	endpoint := &portainer.Endpoint{Type: portainer.DockerEnvironment}
+	err := service.snapshotEndpointWithTimeout(endpoint)
+	assert.NoError(t, err)
+	assert.Len(t, endpoint.Snapshots, 1)
+
+	snapshotter := &stubDockerSnapshotter{delay: time.Minute, cancelled: make(chan struct{})}
+	service.dockerSnapshotter = snapshotter
+	service.snapshotTimeout = 10 * time.Millisecond
+
+	endpoint = &portainer.Endpoint{Type: portainer.DockerEnvironment}
+	err = service.snapshotEndpointWithTimeout(endpoint)
+	assert.Error(t, err)
+	assert.Empty(t, endpoint.Snapshots)
+
+	select {
+	case <-snapshotter.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the snapshot should be cancelled on timeout")
+	}
+}
+
+func TestUpdateSnapshotStatus(t *testing.T) {
+	status := portainer.EndpointSnapshotStatus{}
+	start := time.Unix(1000, 0)
+
+	updateSnapshotStatus(&status, start, start.Add(1500*time.Millisecond), errors.New("unreachable"))
+	updateSnapshotStatus(&status, start, start.Add(time.Second), errors.New("unreachable"))
+	assert.Equal(t, portainer.EndpointSnapshotStatus{
+		LastAttempt:         1000,
+		LastError:           "unreachable",
+		LastErrorTime:       1000,
+		LastDuration:        1000,
+		ConsecutiveFailures: 2,
+	}, status)
+
+	updateSnapshotStatus(&status, start.Add(time.Minute), start.Add(time.Minute+200*time.Millisecond), nil)
+	assert.Equal(t, int64(1060), status.LastSuccess)
+	assert.Equal(t, int64(200), status.LastDuration)
+	assert.Equal(t, 0, status.ConsecutiveFailures)
+	assert.Equal(t, "unreachable", status.LastError)
+}
+
+func TestBackoffDelay(t *testing.T) {
+	interval := 5 * time.Minute
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 5 * time.Minute},
+		{3, 10 * time.Minute},
+		{4, 20 * time.Minute},
+		{5, 40 * time.Minute},
+		{6, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, test := range tests {
+		delay := backoffDelay(portainer.EndpointSnapshotStatus{ConsecutiveFailures: test.failures}, interval)
+		assert.Equal(t, test.expected, delay, "failures: %d", test.failures)
+	}
+}
@@ -7,25 +7,34 @@ import (
 )
 
 type datastore struct {
-	customTemplate   portainer.CustomTemplateService
-	edgeGroup        portainer.EdgeGroupService
-	edgeJob          portainer.EdgeJobService
-	edgeStack        portainer.EdgeStackService
-	endpoint         portainer.EndpointService
-	endpointGroup    portainer.EndpointGroupService
-	endpointRelation portainer.EndpointRelationService
-	registry         portainer.RegistryService
-	resourceControl  portainer.ResourceControlService
-	role             portainer.RoleService
-	settings         portainer.SettingsService
-	stack            portainer.StackService
-	tag              portainer.TagService
-	teamMembership   portainer.TeamMembershipService
-	team             portainer.TeamService
-	tunnelServer     portainer.TunnelServerService
-	user             portainer.UserService
-	version          portainer.VersionService
-	webhook          portainer.WebhookService
+	customTemplate         portainer.CustomTemplateService
+	dockerPolicy           portainer.DockerPolicyService
+	edgeAsyncCommand       portainer.EdgeAsyncCommandService
+	edgeDevice             portainer.EdgeDeviceService
+	edgeGroup              portainer.EdgeGroupService
+	edgeJob                portainer.EdgeJobService
+	edgeJobRun             portainer.EdgeJobRunService
+	edgeStack              portainer.EdgeStackService
+	endpoint               portainer.EndpointService
+	edgeStackStatusHistory portainer.EdgeStackStatusHistoryService
+	endpointGroup          portainer.EndpointGroupService
+	endpointRelation       portainer.EndpointRelationService
+	prunePolicy            portainer.PrunePolicyService
+	prunePolicyRun         portainer.PrunePolicyRunService
+	registry               portainer.RegistryService
+	resourceControl        portainer.ResourceControlService
+	role                   portainer.RoleService
+	settings               portainer.SettingsService
+	snapshotHistory        portainer.SnapshotHistoryService
+	stack                  portainer.StackService
+	tag                    portainer.TagService
+	teamMembership         portainer.TeamMembershipService
+	team                   portainer.TeamService
+	tunnelServer           portainer.TunnelServerService
+	tunnelEvent            portainer.TunnelEventService
+	user                   portainer.UserService
+	version                portainer.VersionService
+	webhook                portainer.WebhookService
 }
 
 func (d *datastore) BackupTo(io.Writer) error                            { return nil }
@@ -37,21 +46,32 @@ func (d *datastore) IsNew() bool                                         { retur
 func (d *datastore) MigrateData(force bool) error                        { return nil }
 func (d *datastore) RollbackToCE() error                                 { return nil }
 func (d *datastore) CustomTemplate() portainer.CustomTemplateService     { return d.customTemplate }
+func (d *datastore) DockerPolicy() portainer.DockerPolicyService         { return d.dockerPolicy }
+func (d *datastore) EdgeAsyncCommand() portainer.EdgeAsyncCommandService { return d.edgeAsyncCommand }
+func (d *datastore) EdgeDevice() portainer.EdgeDeviceService             { return d.edgeDevice }
 func (d *datastore) EdgeGroup() portainer.EdgeGroupService               { return d.edgeGroup }
 func (d *datastore) EdgeJob() portainer.EdgeJobService                   { return d.edgeJob }
+func (d *datastore) EdgeJobRun() portainer.EdgeJobRunService             { return d.edgeJobRun }
 func (d *datastore) EdgeStack() portainer.EdgeStackService               { return d.edgeStack }
+func (d *datastore) EdgeStackStatusHistory() portainer.EdgeStackStatusHistoryService {
+	return d.edgeStackStatusHistory
+}
 func (d *datastore) Endpoint() portainer.EndpointService                 { return d.endpoint }
 func (d *datastore) EndpointGroup() portainer.EndpointGroupService       { return d.endpointGroup }
 func (d *datastore) EndpointRelation() portainer.EndpointRelationService { return d.endpointRelation }
+func (d *datastore) PrunePolicy() portainer.PrunePolicyService           { return d.prunePolicy }
+func (d *datastore) PrunePolicyRun() portainer.PrunePolicyRunService     { return d.prunePolicyRun }
 func (d *datastore) Registry() portainer.RegistryService                 { return d.registry }
 func (d *datastore) ResourceControl() portainer.ResourceControlService   { return d.resourceControl }
 func (d *datastore) Role() portainer.RoleService                         { return d.role }
 func (d *datastore) Settings() portainer.SettingsService                 { return d.settings }
+func (d *datastore) SnapshotHistory() portainer.SnapshotHistoryService   { return d.snapshotHistory }
 func (d *datastore) Stack() portainer.StackService                       { return d.stack }
 func (d *datastore) Tag() portainer.TagService                           { return d.tag }
 func (d *datastore) TeamMembership() portainer.TeamMembershipService     { return d.teamMembership }
 func (d *datastore) Team() portainer.TeamService                         { return d.team }
 func (d *datastore) TunnelServer() portainer.TunnelServerService         { return d.tunnelServer }
+func (d *datastore) TunnelEvent() portainer.TunnelEventService           { return d.tunnelEvent }
 func (d *datastore) User() portainer.UserService                         { return d.user }
 func (d *datastore) Version() portainer.VersionService                   { return d.version }
 func (d *datastore) Webhook() portainer.WebhookService                   { return d.webhook }
@@ -1,6 +1,12 @@
 package testhelpers
 
-import portainer "github.com/portainer/portainer/api"
+import (
+	"context"
+	"net"
+	"time"
+
+	portainer "github.com/portainer/portainer/api"
+)
 
 type ReverseTunnelService struct{}
 
@@ -10,11 +16,31 @@ func (r ReverseTunnelService) StartTunnelServer(addr, port string, snapshotServi
 func (r ReverseTunnelService) GenerateEdgeKey(url, host string, endpointIdentifier int) string {
 	return "nil"
 }
+func (r ReverseTunnelService) TunnelServerFingerprints() (string, string) {
+	return "", ""
+}
+func (r ReverseTunnelService) RotateEdgeKey(endpointID portainer.EndpointID, gracePeriod time.Duration) (*portainer.Endpoint, error) {
+	return nil, nil
+}
+func (r ReverseTunnelService) RotateTunnelServerKey(gracePeriod time.Duration) error {
+	return nil
+}
 func (r ReverseTunnelService) SetTunnelStatusToActive(endpointID portainer.EndpointID) {}
 func (r ReverseTunnelService) SetTunnelStatusToRequired(endpointID portainer.EndpointID) error {
 	return nil
 }
 func (r ReverseTunnelService) SetTunnelStatusToIdle(endpointID portainer.EndpointID) {}
+func (r ReverseTunnelService) KeepTunnelAlive(endpointID portainer.EndpointID)       {}
+func (r ReverseTunnelService) OpenTunnel(endpointID portainer.EndpointID, reason string) error {
+	return nil
+}
+func (r ReverseTunnelService) CloseTunnel(endpointID portainer.EndpointID, reason string) {}
+func (r ReverseTunnelService) Tunnels() map[portainer.EndpointID]portainer.TunnelDetails {
+	return nil
+}
+func (r ReverseTunnelService) TunnelDialer(endpointID portainer.EndpointID) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return (&net.Dialer{}).DialContext
+}
 func (r ReverseTunnelService) GetTunnelDetails(endpointID portainer.EndpointID) *portainer.TunnelDetails {
 	return nil
 }
@@ -1,13 +1,16 @@
 package kubernetes
 
 import (
+	"context"
+	"encoding/json"
 	"log"
 	"time"
 
 	"github.com/portainer/portainer/api/kubernetes/cli"
 
 	portainer "github.com/portainer/portainer/api"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -22,30 +25,30 @@ func NewSnapshotter(clientFactory *cli.ClientFactory) *Snapshotter {
 	}
 }
 
-// CreateSnapshot creates a snapshot of a specific Kubernetes endpoint
-func (snapshotter *Snapshotter) CreateSnapshot(endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
+// CreateSnapshot creates a snapshot of a specific Kubernetes endpoint, the requests to the cluster are cancelled with the context
+func (snapshotter *Snapshotter) CreateSnapshot(ctx context.Context, endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
 	client, err := snapshotter.clientFactory.CreateClient(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	return snapshot(client, endpoint)
+	return snapshot(ctx, client, endpoint)
 }
 
-func snapshot(cli *kubernetes.Clientset, endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
-	res := cli.RESTClient().Get().AbsPath("/healthz").Do()
+func snapshot(ctx context.Context, cli *kubernetes.Clientset, endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
+	res := cli.RESTClient().Get().AbsPath("/healthz").Context(ctx).Do()
 	if res.Error() != nil {
 		return nil, res.Error()
 	}
 
 	snapshot := &portainer.KubernetesSnapshot{}
 
-	err := snapshotVersion(snapshot, cli)
+	err := snapshotVersion(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [kubernetes,snapshot] [message: unable to snapshot cluster version] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
 
-	err = snapshotNodes(snapshot, cli)
+	err = snapshotNodes(ctx, snapshot, cli)
 	if err != nil {
 		log.Printf("[WARN] [kubernetes,snapshot] [message: unable to snapshot cluster nodes] [endpoint: %s] [err: %s]", endpoint.Name, err)
 	}
@@ -54,8 +57,15 @@ func snapshot(cli *kubernetes.Clientset, endpoint *portainer.Endpoint) (*portain
 	return snapshot, nil
 }
 
-func snapshotVersion(snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Clientset) error {
-	versionInfo, err := cli.ServerVersion()
+// snapshotVersion and snapshotNodes use the REST clients directly as the typed clients do not support a context
+func snapshotVersion(ctx context.Context, snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Clientset) error {
+	body, err := cli.Discovery().RESTClient().Get().AbsPath("/version").Context(ctx).Do().Raw()
+	if err != nil {
+		return err
+	}
+
+	var versionInfo version.Info
+	err = json.Unmarshal(body, &versionInfo)
 	if err != nil {
 		return err
 	}
@@ -64,8 +74,9 @@ func snapshotVersion(snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Cli
 	return nil
 }
 
-func snapshotNodes(snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Clientset) error {
-	nodeList, err := cli.CoreV1().Nodes().List(metav1.ListOptions{})
+func snapshotNodes(ctx context.Context, snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Clientset) error {
+	nodeList := &v1.NodeList{}
+	err := cli.CoreV1().RESTClient().Get().Resource("nodes").Context(ctx).Do().Into(nodeList)
 	if err != nil {
 		return err
 	}
@@ -1,7 +1,9 @@
 package portainer
 
 import (
+	"context"
 	"io"
+	"net"
 	"time"
 
 	gittypes "github.com/portainer/portainer/api/git/types"
@@ -12,6 +14,8 @@ type (
 	AccessPolicy struct {
 		// Role identifier. Reference the role that will be associated to this access policy
 		RoleID RoleID `json:"RoleId" example:"1"`
+		// Resources the team can create on the endpoint, only enforced for the team access policies of a Docker endpoint
+		Quota *ResourceQuota `json:"Quota,omitempty"`
 	}
 
 	// AgentPlatform represents a platform type for an Agent
@@ -39,15 +43,21 @@ type (
 
 	// CLIFlags represents the available flags on the CLI
 	CLIFlags struct {
+		Command                   string
 		Addr                      *string
 		TunnelAddr                *string
 		TunnelPort                *string
+		TunnelMinPort             *int
+		TunnelMaxPort             *int
 		AdminPassword             *string
 		AdminPasswordFile         *string
 		Assets                    *string
 		Data                      *string
+		Datastore                 *string
 		EnableEdgeComputeFeatures *bool
 		EndpointURL               *string
+		ImportBoltDB              *bool
+		RollbackToVersion         *int
 		Labels                    *[]Pair
 		Logo                      *string
 		NoAnalytics               *bool
@@ -61,6 +71,10 @@ type (
 		SSLCert                   *string
 		SSLKey                    *string
 		SnapshotInterval          *string
+		SnapshotConcurrency       *int
+		SnapshotTimeout           *string
+		EdgeStackGitPollInterval  *string
+		DockerEventsHistory       *int
 	}
 
 	// CustomTemplate represents a custom template
@@ -106,6 +120,45 @@ type (
 		Password string `json:"Password,omitempty" example:"passwd"`
 	}
 
+	// DockerPolicy represents a set of rules evaluated by the Docker proxy and the stack deployments
+	// when containers, services and stacks are created or updated
+	DockerPolicy struct {
+		// DockerPolicy Identifier
+		ID          DockerPolicyID `json:"Id" example:"1"`
+		Name        string         `json:"Name" example:"production"`
+		Description string         `json:"Description" example:"Restrictions of the production endpoints"`
+		// Whether the violations are only logged instead of denying the requests
+		AuditOnly bool `json:"AuditOnly" example:"false"`
+		// The policy applies to these endpoints, or to every endpoint when both the endpoints and the endpoint groups are empty
+		EndpointIDs []EndpointID `json:"EndpointIds"`
+		// The policy applies to the endpoints of these groups
+		EndpointGroupIDs []EndpointGroupID `json:"EndpointGroupIds"`
+		// The policy applies to the members of these teams, or to every user when empty
+		TeamIDs []TeamID          `json:"TeamIds"`
+		Rules   DockerPolicyRules `json:"Rules"`
+		// Unix timestamp of the creation of the policy
+		CreationDate int64 `json:"CreationDate" example:"1625132400"`
+	}
+
+	// DockerPolicyID represents a Docker policy identifier
+	DockerPolicyID int
+
+	// DockerPolicyRules represents the rules of a Docker policy, a zero value rule is not enforced
+	DockerPolicyRules struct {
+		// Registries the images must be pulled from, e.g. docker.io or registry.example.com:5000
+		AllowedRegistries []string `json:"AllowedRegistries"`
+		// Whether a memory limit is required
+		RequireMemoryLimit bool `json:"RequireMemoryLimit" example:"true"`
+		// Whether a CPU limit is required
+		RequireCPULimit bool `json:"RequireCPULimit" example:"true"`
+		// Whether publishing ports below 1024 on the host is forbidden
+		ForbidPrivilegedPorts bool `json:"ForbidPrivilegedPorts" example:"true"`
+		// Labels that must be set
+		RequiredLabels []string `json:"RequiredLabels"`
+		// Maximum number of replicas of a service
+		MaxReplicas int `json:"MaxReplicas" example:"5"`
+	}
+
 	// DockerSnapshot represents a snapshot of a specific Docker endpoint at a specific time
 	DockerSnapshot struct {
 		Time                    int64             `json:"Time"`
@@ -135,6 +188,87 @@ type (
 		Version    interface{} `json:"Version"`
 	}
 
+	// EdgeAsyncCommand represents a command queued for an Edge agent. It is delivered in the response of the
+	// agent check-in and acknowledged with its result on a following check-in, without opening a tunnel
+	EdgeAsyncCommand struct {
+		// Command Identifier, ordering the commands of an endpoint
+		ID         int        `json:"Id" example:"1"`
+		EndpointID EndpointID `json:"EndpointId" example:"1"`
+		// Type of the command: 1 (container start), 2 (container stop), 3 (container restart), 4 (image pull),
+		// 5 (edge stack redeploy) or 6 (snapshot upload)
+		Type EdgeAsyncCommandType `json:"Type" example:"3"`
+		// Identifier of the container targeted by the container commands
+		ContainerID string `json:"ContainerId,omitempty" example:"a1b2c3d4e5f6"`
+		// Image pulled by the image pull commands
+		Image string `json:"Image,omitempty" example:"nginx:latest"`
+		// Edge stack redeployed by the edge stack redeploy commands
+		EdgeStackID EdgeStackID `json:"EdgeStackId,omitempty" example:"1"`
+		// Status of the command: 1 (pending), 2 (delivered), 3 (succeeded) or 4 (failed)
+		Status EdgeAsyncCommandStatus `json:"Status" example:"1"`
+		// Name of the user who queued the command
+		CreatedBy string `json:"CreatedBy" example:"admin"`
+		// Unix timestamp at which the command was queued
+		CreationDate int64 `json:"CreationDate" example:"1587399600"`
+		// Unix timestamp of the latest delivery of the command to the agent
+		DeliveryDate int64 `json:"DeliveryDate" example:"1587399605"`
+		// Number of times the command was delivered to the agent
+		Deliveries int `json:"Deliveries" example:"1"`
+		// Unix timestamp at which the agent acknowledged the command
+		CompletionDate int64 `json:"CompletionDate" example:"1587399610"`
+		// Output of the command reported by the agent
+		Output string `json:"Output" example:""`
+		// Error reported by the agent when the command failed
+		Error string `json:"Error" example:""`
+	}
+
+	// EdgeAsyncCommandType represents the type of an async command
+	EdgeAsyncCommandType int
+
+	// EdgeAsyncCommandStatus represents the status of an async command
+	EdgeAsyncCommandStatus int
+
+	// EdgeDevice represents an edge agent which enrolled with the fleet key and is waiting for,
+	// or went through, the approval of an administrator
+	EdgeDevice struct {
+		// EdgeDevice Identifier
+		ID EdgeDeviceID `json:"Id" example:"1"`
+		// Identifier sent by the agent in the X-PortainerAgent-EdgeID header
+		EdgeID   string           `json:"EdgeID" example:"store-0042-pos"`
+		Platform AgentPlatform    `json:"Platform" example:"1"`
+		Status   EdgeDeviceStatus `json:"Status" example:"1"`
+		// Address the device enrolled from
+		RemoteAddress string `json:"RemoteAddress" example:"203.0.113.4"`
+		// Endpoint created when the device was approved
+		EndpointID EndpointID `json:"EndpointId,omitempty" example:"1"`
+		// Unix timestamp of the first enrolment request
+		CreationDate int64 `json:"CreationDate" example:"1625132400"`
+		// Unix timestamp of the last enrolment request
+		LastCheckInDate int64 `json:"LastCheckInDate" example:"1625132400"`
+		// SHA-256 hash of the enrolment secret issued to the device on its first enrolment request
+		EnrolmentSecretHash string `json:"EnrolmentSecretHash,omitempty"`
+		// Whether the edge key of the endpoint was delivered to the device, the key is only delivered once
+		EdgeKeyDelivered bool `json:"EdgeKeyDelivered" example:"false"`
+	}
+
+	// EdgeDeviceID represents an edge device identifier
+	EdgeDeviceID int
+
+	// EdgeDeviceStatus represents the approval status of an edge device
+	EdgeDeviceStatus int
+
+	// EdgeEnrolmentRule approves or rejects the edge devices whose EdgeID starts with a prefix
+	EdgeEnrolmentRule struct {
+		EdgeIDPrefix string                  `json:"EdgeIDPrefix" example:"store-"`
+		Action       EdgeEnrolmentRuleAction `json:"Action" example:"1"`
+		// Endpoint group of the endpoints created for approved devices
+		GroupID EndpointGroupID `json:"GroupId" example:"1"`
+		// Tags of the endpoints created for approved devices
+		TagIDs []TagID `json:"TagIds"`
+	}
+
+	// EdgeEnrolmentRuleAction represents the action of an edge enrolment rule
+	EdgeEnrolmentRuleAction int
+
 	// EdgeGroup represents an Edge group
 	EdgeGroup struct {
 		// EdgeGroup Identifier
@@ -144,11 +278,35 @@ type (
 		TagIDs       []TagID      `json:"TagIds"`
 		Endpoints    []EndpointID `json:"Endpoints"`
 		PartialMatch bool         `json:"PartialMatch"`
+		// Variables rendered into the edge stacks deployed on the endpoints of this group
+		Variables map[string]string `json:"Variables,omitempty"`
+		// Membership rule of a dynamic group, the tags are used when no rule is set
+		Rule *EdgeGroupRule `json:"Rule,omitempty"`
 	}
 
 	// EdgeGroupID represents an Edge group identifier
 	EdgeGroupID int
 
+	// EdgeGroupRule represents an expression matching the endpoints of a dynamic Edge group
+	EdgeGroupRule struct {
+		Type EdgeGroupRuleType `json:"Type" example:"1"`
+		// Operands of the And, Or and Not rules, a Not rule has a single operand
+		Rules []EdgeGroupRule `json:"Rules,omitempty"`
+		// Endpoint group of the EndpointGroup rule
+		GroupID EndpointGroupID `json:"GroupId,omitempty" example:"1"`
+		// Label key of the Label rule
+		Key string `json:"Key,omitempty" example:"region"`
+		// Glob pattern of the Name and Label rules, or version constraint of the DockerVersion rule
+		Value string `json:"Value,omitempty" example:"eu-*"`
+		// Agent platform of the Platform rule
+		Platform AgentPlatform `json:"Platform,omitempty" example:"1"`
+		// Maximum duration since the last check-in of the CheckIn rule
+		MaxAge string `json:"MaxAge,omitempty" example:"1h"`
+	}
+
+	// EdgeGroupRuleType represents the type of an Edge group rule
+	EdgeGroupRuleType int
+
 	// EdgeJob represents a job that can run on Edge environments.
 	EdgeJob struct {
 		// EdgeJob Identifier
@@ -160,7 +318,33 @@ type (
 		ScriptPath     string                             `json:"ScriptPath"`
 		Recurring      bool                               `json:"Recurring"`
 		Version        int                                `json:"Version"`
-	}
+		// Duration in seconds after which the edge agent stops a run of the script, 0 means no timeout
+		Timeout int `json:"Timeout" example:"300"`
+		// Number of times the edge agent runs the script again after a failed or timed out run
+		MaxRetries int `json:"MaxRetries" example:"3"`
+		// Delay in seconds between two attempts of a run
+		RetryInterval int `json:"RetryInterval" example:"60"`
+	}
+
+	// EdgeJobRun represents the result of a run of an Edge job on an endpoint, as reported by the edge agent
+	EdgeJobRun struct {
+		// Run Identifier, ordering the runs of an Edge job on an endpoint
+		ID         int              `json:"Id" example:"1"`
+		EdgeJobID  EdgeJobID        `json:"EdgeJobId" example:"1"`
+		EndpointID EndpointID       `json:"EndpointId" example:"1"`
+		Version    int              `json:"Version" example:"2"`
+		Status     EdgeJobRunStatus `json:"Status" example:"1"`
+		// Exit code of the last attempt
+		ExitCode int `json:"ExitCode" example:"0"`
+		// Number of attempts, retries included
+		Attempts int `json:"Attempts" example:"1"`
+		// Unix timestamps of the start of the first attempt and of the end of the last attempt
+		StartTime int64 `json:"StartTime" example:"1625132400"`
+		EndTime   int64 `json:"EndTime" example:"1625132460"`
+	}
+
+	// EdgeJobRunStatus represents the outcome of a run of an Edge job
+	EdgeJobRunStatus int
 
 	// EdgeJobEndpointMeta represents a meta data object for an Edge job and Endpoint relation
 	EdgeJobEndpointMeta struct {
@@ -197,6 +381,16 @@ type (
 		EntryPoint   string                         `json:"EntryPoint"`
 		Version      int                            `json:"Version"`
 		Prune        bool                           `json:"Prune"`
+		// Staged rollout of the current version, nil when the version is released to every endpoint at once
+		Rollout *EdgeStackRollout `json:"Rollout,omitempty"`
+		// Repository the edge stack is deployed from, the stack is redeployed when the reference moves
+		GitConfig *gittypes.RepoConfig `json:"GitConfig,omitempty"`
+		// Commit of the repository deployed by the current and the previous version of an edge stack deployed from git
+		CommitHashes map[int]string `json:"CommitHashes,omitempty"`
+		// Type of the stack file (0 - Compose file, 1 - Kubernetes manifest)
+		DeploymentType EdgeStackDeploymentType `json:"DeploymentType" example:"0"`
+		// Namespace the Kubernetes manifest is applied to
+		Namespace string `json:"Namespace,omitempty" example:"default"`
 	}
 
 	//EdgeStackID represents an edge stack id
@@ -207,11 +401,77 @@ type (
 		Type       EdgeStackStatusType `json:"Type"`
 		Error      string              `json:"Error"`
 		EndpointID EndpointID          `json:"EndpointID"`
-	}
+		// Commit of the repository deployed on the endpoint, for edge stacks deployed from git
+		CommitHash string `json:"CommitHash,omitempty"`
+		// Outcome of the apply of each resource of a Kubernetes manifest
+		Resources []EdgeStackResourceStatus `json:"Resources,omitempty"`
+	}
+
+	// EdgeStackStatusHistoryEntry represents a status reported by an edge agent for a version of an edge stack
+	EdgeStackStatusHistoryEntry struct {
+		// Entry Identifier, ordering the entries of an edge stack and an endpoint
+		ID          int                 `json:"Id" example:"1"`
+		EdgeStackID EdgeStackID         `json:"EdgeStackId" example:"1"`
+		EndpointID  EndpointID          `json:"EndpointId" example:"1"`
+		Version     int                 `json:"Version" example:"2"`
+		Type        EdgeStackStatusType `json:"Type" example:"1"`
+		Error       string              `json:"Error,omitempty"`
+		// Output of the deployment provided by the edge agent, truncated to its last bytes when too large
+		Output     string `json:"Output,omitempty"`
+		CommitHash string `json:"CommitHash,omitempty"`
+		// Unix timestamp of the status report
+		Time int64 `json:"Time" example:"1625132400"`
+	}
+
+	// EdgeStackResourceStatus represents the outcome of the apply of a Kubernetes resource on an endpoint
+	EdgeStackResourceStatus struct {
+		Kind      string              `json:"Kind" example:"Deployment"`
+		Name      string              `json:"Name" example:"nginx"`
+		Namespace string              `json:"Namespace,omitempty" example:"default"`
+		Type      EdgeStackStatusType `json:"Type" example:"1"`
+		Error     string              `json:"Error,omitempty"`
+	}
+
+	// EdgeStackDeploymentType represents the type of the file deployed by an edge stack
+	EdgeStackDeploymentType int
 
 	//EdgeStackStatusType represents an edge stack status type
 	EdgeStackStatusType int
 
+	// EdgeStackRollout represents the staged rollout of an edge stack version.
+	// Endpoints are released in batches, a batch being released once every endpoint
+	// of the previous batches reported a deployment status
+	EdgeStackRollout struct {
+		// Strategy used to compute the size of each batch
+		Strategy EdgeStackRolloutStrategy `json:"Strategy" example:"1"`
+		// Percentage of the endpoints (percentage strategy) or number of endpoints (batch strategy) released per batch
+		BatchSize int `json:"BatchSize" example:"10"`
+		// Percentage of failed deployments among the released endpoints above which the rollout is paused
+		ErrorThreshold int `json:"ErrorThreshold" example:"20"`
+		// Rollout status
+		Status EdgeStackRolloutStatus `json:"Status" example:"1"`
+		// Reason of the last automatic pause
+		PauseReason string `json:"PauseReason,omitempty"`
+		// Version being rolled out
+		Version int `json:"Version" example:"2"`
+		// Version still deployed on the endpoints that were not released yet, 0 if none
+		PreviousVersion int `json:"PreviousVersion" example:"1"`
+		// Stack file of the previous version, relative to the edge stack project path
+		PreviousEntryPoint string `json:"PreviousEntryPoint,omitempty"`
+		// Endpoints targeted by the rollout, in release order
+		Endpoints []EndpointID `json:"Endpoints"`
+		// Number of endpoints, starting from the beginning of Endpoints, the version is released to
+		Released int `json:"Released" example:"3"`
+		// Failed endpoints excluded from the error rate when the rollout was resumed
+		AcknowledgedFailures []EndpointID `json:"AcknowledgedFailures,omitempty"`
+	}
+
+	// EdgeStackRolloutStrategy represents the way batches of an edge stack rollout are sized
+	EdgeStackRolloutStrategy int
+
+	// EdgeStackRolloutStatus represents the status of an edge stack rollout
+	EdgeStackRolloutStatus int
+
 	// Endpoint represents a Docker endpoint with all the info required
 	// to connect to it
 	Endpoint struct {
@@ -236,6 +496,8 @@ type (
 		Status EndpointStatus `json:"Status" example:"1"`
 		// List of snapshots
 		Snapshots []DockerSnapshot `json:"Snapshots" example:""`
+		// Outcome of the latest snapshots of the endpoint
+		SnapshotStatus EndpointSnapshotStatus `json:"SnapshotStatus"`
 		// List of user identifiers authorized to connect to this endpoint
 		UserAccessPolicies UserAccessPolicies `json:"UserAccessPolicies"`
 		// List of team identifiers authorized to connect to this endpoint
@@ -244,8 +506,18 @@ type (
 		EdgeID string `json:"EdgeID,omitempty" example:""`
 		// The key which is used to map the agent to Portainer
 		EdgeKey string `json:"EdgeKey" example:""`
+		// Secrets and rotation state of the edge key
+		EdgeCredentials EndpointEdgeCredentials `json:"EdgeCredentials"`
 		// The check in interval for edge agent (in seconds)
 		EdgeCheckinInterval int `json:"EdgeCheckinInterval" example:"5"`
+		// The port allocated to the tunnel of the edge agent, reused each time a tunnel is opened
+		EdgeTunnelPort int `json:"EdgeTunnelPort,omitempty" example:"49152"`
+		// Unix timestamp of the latest snapshot pushed by the edge agent, used to rate limit the pushes
+		EdgeSnapshotLastPush int64 `json:"EdgeSnapshotLastPush,omitempty" example:"1625132400"`
+		// Variables rendered into the edge stacks deployed on this endpoint, they take precedence over the group variables
+		EdgeVariables map[string]string `json:"EdgeVariables,omitempty"`
+		// Metadata labels, matched by the rules of dynamic Edge groups
+		Labels map[string]string `json:"Labels,omitempty"`
 		// Associated Kubernetes data
 		Kubernetes KubernetesData `json:"Kubernetes" example:""`
 		// Maximum version of docker-compose
@@ -284,6 +556,21 @@ type (
 	// one extension of each type can be associated to an endpoint
 	EndpointExtensionType int
 
+	// EndpointEdgeCredentials represents the rotation state of the edge key of an endpoint.
+	// Edge keys issued before their first rotation have no secret and are only bound to the EdgeID of the agent.
+	EndpointEdgeCredentials struct {
+		// Secret of the current edge key, sent by the agent in the X-PortainerAgent-EdgeKeySecret header
+		Secret string `json:"Secret,omitempty"`
+		// Secret of the previous edge key, accepted until PreviousSecretExpiry
+		PreviousSecret string `json:"PreviousSecret,omitempty"`
+		// Unix timestamp until which the previous edge key is accepted
+		PreviousSecretExpiry int64 `json:"PreviousSecretExpiry,omitempty" example:"1625132400"`
+		// Unix timestamp of the last rotation of the edge key
+		RotationDate int64 `json:"RotationDate,omitempty" example:"1625132400"`
+		// Whether the edge device was revoked, a revoked endpoint rejects its agent until its edge key is rotated
+		Revoked bool `json:"Revoked" example:"false"`
+	}
+
 	// EndpointGroup represents a group of endpoints
 	EndpointGroup struct {
 		// Endpoint group Identifier
@@ -296,6 +583,8 @@ type (
 		TeamAccessPolicies TeamAccessPolicies `json:"TeamAccessPolicies" example:""`
 		// List of tags associated to this endpoint group
 		TagIDs []TagID `json:"TagIds"`
+		// Variables rendered into the edge stacks deployed on the endpoints of this group
+		EdgeVariables map[string]string `json:"EdgeVariables,omitempty"`
 
 		// Deprecated fields
 		Labels []Pair `json:"Labels"`
@@ -343,6 +632,22 @@ type (
 		EnableHostManagementFeatures bool `json:"enableHostManagementFeatures" example:"true"`
 	}
 
+	// EndpointSnapshotStatus represents the outcome of the latest snapshots of an endpoint
+	EndpointSnapshotStatus struct {
+		// Unix timestamp of the latest snapshot attempt
+		LastAttempt int64 `json:"LastAttempt" example:"1625132400"`
+		// Unix timestamp of the latest successful snapshot
+		LastSuccess int64 `json:"LastSuccess" example:"1625132400"`
+		// Error returned by the latest failed snapshot
+		LastError string `json:"LastError" example:"context deadline exceeded"`
+		// Unix timestamp of the latest failed snapshot
+		LastErrorTime int64 `json:"LastErrorTime" example:"1625132100"`
+		// Duration of the latest snapshot attempt in milliseconds
+		LastDuration int64 `json:"LastDuration" example:"350"`
+		// Number of snapshots that failed since the latest successful snapshot
+		ConsecutiveFailures int `json:"ConsecutiveFailures" example:"0"`
+	}
+
 	// EndpointType represents the type of an endpoint
 	EndpointType int
 
@@ -506,6 +811,66 @@ type (
 		Value string `json:"value" example:"value"`
 	}
 
+	// PrunePolicy represents a garbage collection policy removing unused Docker resources
+	// from endpoints on a schedule
+	PrunePolicy struct {
+		// PrunePolicy Identifier
+		ID   PrunePolicyID `json:"Id" example:"1"`
+		Name string        `json:"Name" example:"nightly-cleanup"`
+		// The policy runs on these endpoints
+		EndpointIDs []EndpointID `json:"EndpointIds"`
+		// The policy runs on the endpoints of these groups
+		EndpointGroupIDs []EndpointGroupID `json:"EndpointGroupIds"`
+		// Duration between two runs of the policy on an endpoint
+		Interval string `json:"Interval" example:"24h"`
+		// Whether the scheduled runs only report the resources that would be removed
+		DryRun bool             `json:"DryRun" example:"false"`
+		Rules  PrunePolicyRules `json:"Rules"`
+		// Unix timestamp of the creation of the policy
+		CreationDate int64 `json:"CreationDate" example:"1625132400"`
+	}
+
+	// PrunePolicyID represents a prune policy identifier
+	PrunePolicyID int
+
+	// PrunePolicyRules represents the rules of a prune policy, a zero value rule is not applied
+	PrunePolicyRules struct {
+		// Remove the dangling images created more than this number of days ago
+		DanglingImagesOlderThanDays int `json:"DanglingImagesOlderThanDays" example:"7"`
+		// Remove the stopped containers that exited more than this number of days ago
+		StoppedContainersOlderThanDays int `json:"StoppedContainersOlderThanDays" example:"14"`
+		// Remove the volumes that are not used by any container
+		UnusedVolumes bool `json:"UnusedVolumes" example:"true"`
+		// Volumes with this label are never removed, either a label name or a name=value pair
+		VolumeExclusionLabel string `json:"VolumeExclusionLabel" example:"io.portainer.keep"`
+		// Prune the build cache down to this size, in bytes, when it grows above it
+		BuildCacheThreshold int64 `json:"BuildCacheThreshold" example:"10737418240"`
+	}
+
+	// PrunePolicyRun represents the outcome of a run of a prune policy on an endpoint
+	PrunePolicyRun struct {
+		// Run Identifier, ordering the runs of a prune policy on an endpoint
+		ID            int           `json:"Id" example:"1"`
+		PrunePolicyID PrunePolicyID `json:"PrunePolicyId" example:"1"`
+		EndpointID    EndpointID    `json:"EndpointId" example:"1"`
+		// Whether the run only reported the resources that would be removed
+		DryRun bool `json:"DryRun" example:"false"`
+		// Whether the run was requested by an administrator instead of being triggered by the schedule
+		Manual bool `json:"Manual" example:"false"`
+		// Identifiers of the removed resources, or of the resources that would be removed by a dry run
+		Containers []string `json:"Containers"`
+		Images     []string `json:"Images"`
+		Volumes    []string `json:"Volumes"`
+		BuildCache []string `json:"BuildCache"`
+		// Disk space reclaimed in bytes, or that would be reclaimed by a dry run
+		ReclaimedBytes int64 `json:"ReclaimedBytes" example:"1073741824"`
+		// Errors encountered during the run, the run carries on with the other resources
+		Errors []string `json:"Errors"`
+		// Unix timestamps of the start and of the end of the run
+		StartTime int64 `json:"StartTime" example:"1625132400"`
+		EndTime   int64 `json:"EndTime" example:"1625132460"`
+	}
+
 	// Registry represents a Docker registry with all the info required
 	// to connect to it
 	Registry struct {
@@ -579,7 +944,8 @@ type (
 		// List of Docker resources that will inherit this access control
 		SubResourceIDs []string `json:"SubResourceIds" example:"617c5f22bb9b023d6daab7cba43a57576f83492867bc767d1c59416b065e5f08"`
 		// Type of Docker resource. Valid values are: 1- container, 2 -service
-		// 3 - volume, 4 - secret, 5 - stack, 6 - config or 7 - custom template
+		// 3 - volume, 4 - network, 5 - secret, 6 - stack, 7 - config, 8 - custom template,
+		// 9 - container group or 10 - image
 		Type         ResourceControlType  `json:"Type" example:"1"`
 		UserAccesses []UserResourceAccess `json:"UserAccesses" example:""`
 		TeamAccesses []TeamResourceAccess `json:"TeamAccesses" example:""`
@@ -601,6 +967,20 @@ type (
 	// ResourceControlType represents the type of resource associated to the resource control (volume, container, service...)
 	ResourceControlType int
 
+	// ResourceQuota represents the resources a team can own on a Docker endpoint, a zero value is not limited
+	ResourceQuota struct {
+		// Maximum number of containers, the tasks of the services are not counted
+		MaxContainers int `json:"MaxContainers" example:"20"`
+		// Maximum memory reserved by the containers and the services, in bytes
+		MaxMemory int64 `json:"MaxMemory" example:"4294967296"`
+		// Maximum number of CPUs reserved by the containers and the services
+		MaxCPU float64 `json:"MaxCPU" example:"2.5"`
+		// Maximum number of volumes
+		MaxVolumes int `json:"MaxVolumes" example:"10"`
+		// Maximum number of stacks
+		MaxStacks int `json:"MaxStacks" example:"5"`
+	}
+
 	// Role represents a set of authorizations that can be associated to a user or
 	// to a team.
 	Role struct {
@@ -659,6 +1039,16 @@ type (
 		OAuthSettings        OAuthSettings        `json:"OAuthSettings" example:""`
 		// The interval in which endpoint snapshots are created
 		SnapshotInterval string `json:"SnapshotInterval" example:"5m"`
+		// The duration for which endpoint snapshot history is retained
+		SnapshotHistoryRetention string `json:"SnapshotHistoryRetention" example:"720h"`
+		// The age after which endpoint snapshot history is downsampled
+		SnapshotHistoryDownsampleAfter string `json:"SnapshotHistoryDownsampleAfter" example:"24h"`
+		// The resolution of downsampled endpoint snapshot history
+		SnapshotHistoryDownsampleInterval string `json:"SnapshotHistoryDownsampleInterval" example:"1h"`
+		// The duration for which the status history of edge stacks is retained
+		EdgeStackStatusHistoryRetention string `json:"EdgeStackStatusHistoryRetention" example:"720h"`
+		// The maximum number of status history entries retained for an edge stack on an endpoint
+		EdgeStackStatusHistoryMaxEntries int `json:"EdgeStackStatusHistoryMaxEntries" example:"100"`
 		// URL to the templates that will be displayed in the UI when navigating to App Templates
 		TemplatesURL string `json:"TemplatesURL" example:"https://raw.githubusercontent.com/portainer/templates/master/templates.json"`
 		// The default check in interval for edge agent (in seconds)
@@ -669,6 +1059,28 @@ type (
 		UserSessionTimeout string `json:"UserSessionTimeout" example:"5m"`
 		// Whether telemetry is enabled
 		EnableTelemetry bool `json:"EnableTelemetry" example:"false"`
+		// Key shared by a fleet of edge agents to enrol without a pre-created endpoint, enrolment is disabled when empty
+		EdgeFleetKey string `json:"EdgeFleetKey" example:""`
+		// Secret of the fleet key, presented by the agents in the X-PortainerAgent-FleetKeySecret header
+		EdgeFleetKeySecret string `json:"EdgeFleetKeySecret,omitempty" example:""`
+		// Rules applied to the edge devices when they enrol, the first rule matching the EdgeID of a device applies
+		EdgeEnrolmentRules []EdgeEnrolmentRule `json:"EdgeEnrolmentRules"`
+		// The interval at which the edge keys are rotated, rotation is disabled when empty
+		EdgeKeyRotationInterval string `json:"EdgeKeyRotationInterval" example:"720h"`
+		// The interval at which the private key of the tunnel server is rotated, rotation is disabled when empty
+		TunnelServerKeyRotationInterval string `json:"TunnelServerKeyRotationInterval" example:"2160h"`
+		// The duration for which the previous edge key, or tunnel server key, is still accepted after a rotation
+		EdgeKeyRotationGracePeriod string `json:"EdgeKeyRotationGracePeriod" example:"24h"`
+		// The interval at which Edge agents push the snapshot of their endpoint, 0 disables the push
+		EdgeSnapshotPushInterval string `json:"EdgeSnapshotPushInterval" example:"5m"`
+		// The lowest port that can be allocated to the tunnel of an Edge endpoint
+		EdgeTunnelMinPort int `json:"EdgeTunnelMinPort" example:"49152"`
+		// The highest port that can be allocated to the tunnel of an Edge endpoint
+		EdgeTunnelMaxPort int `json:"EdgeTunnelMaxPort" example:"65535"`
+		// The duration for which the Docker list responses are cached by the proxy, 0 disables the cache
+		DockerProxyCacheTTL string `json:"DockerProxyCacheTTL" example:"5s"`
+		// Whether the cached Docker list responses are also invalidated by the events of the Docker engine
+		DockerProxyCacheEventsInvalidation bool `json:"DockerProxyCacheEventsInvalidation" example:"false"`
 
 		// Deprecated fields
 		DisplayDonationHeader       bool
@@ -685,6 +1097,25 @@ type (
 		AllowContainerCapabilitiesForRegularUsers bool `json:"AllowContainerCapabilitiesForRegularUsers"`
 	}
 
+	// SnapshotHistoryEntry represents the metrics of an endpoint at a point in time,
+	// either from a single snapshot or averaged over several snapshots once downsampled
+	SnapshotHistoryEntry struct {
+		EndpointID              EndpointID     `json:"EndpointId" example:"1"`
+		Time                    int64          `json:"Time" example:"1625132400"`
+		Status                  EndpointStatus `json:"Status" example:"1"`
+		TotalCPU                int64          `json:"TotalCPU" example:"4"`
+		TotalMemory             int64          `json:"TotalMemory" example:"8348471296"`
+		RunningContainerCount   int            `json:"RunningContainerCount" example:"3"`
+		StoppedContainerCount   int            `json:"StoppedContainerCount" example:"1"`
+		HealthyContainerCount   int            `json:"HealthyContainerCount" example:"2"`
+		UnhealthyContainerCount int            `json:"UnhealthyContainerCount" example:"0"`
+		ImageCount              int            `json:"ImageCount" example:"12"`
+		VolumeCount             int            `json:"VolumeCount" example:"4"`
+		NodeCount               int            `json:"NodeCount" example:"1"`
+		// Number of snapshots aggregated in this entry
+		SampleCount int `json:"SampleCount" example:"1"`
+	}
+
 	// SnapshotJob represents a scheduled job that can create endpoint snapshots
 	SnapshotJob struct{}
 
@@ -938,11 +1369,43 @@ type (
 		Port         int
 		Jobs         []EdgeJob
 		Credentials  string
+		// Time at which the port of the tunnel was allocated, zero when the tunnel is idle
+		OpenedAt time.Time
+		// Traffic of the connections opened through the tunnel since it was allocated a port
+		Traffic *TunnelTraffic
+	}
+
+	// TunnelTraffic represents the traffic through a tunnel, its fields are updated atomically
+	TunnelTraffic struct {
+		BytesSent       int64
+		BytesReceived   int64
+		Connections     int64
+		OpenConnections int64
+	}
+
+	// TunnelEvent represents a transition of the status of the tunnel of an Edge endpoint
+	TunnelEvent struct {
+		// Event Identifier, ordering the events of an endpoint
+		ID             int        `json:"Id" example:"1"`
+		EndpointID     EndpointID `json:"EndpointId" example:"1"`
+		PreviousStatus string     `json:"PreviousStatus" example:"REQUIRED"`
+		Status         string     `json:"Status" example:"ACTIVE"`
+		Port           int        `json:"Port" example:"53412"`
+		Reason         string     `json:"Reason" example:"tunnel established"`
+		// Unix timestamp of the transition
+		Time int64 `json:"Time" example:"1625132400"`
 	}
 
 	// TunnelServerInfo represents information associated to the tunnel server
 	TunnelServerInfo struct {
 		PrivateKeySeed string `json:"PrivateKeySeed"`
+		// Unix timestamp of the last rotation of the private key seed
+		RotationDate int64 `json:"RotationDate,omitempty"`
+		// Seed replacing the private key seed at NextPrivateKeySeedActivation, the fingerprint
+		// of its key is distributed to the edge agents in the meantime
+		NextPrivateKeySeed string `json:"NextPrivateKeySeed,omitempty"`
+		// Unix timestamp of the activation of the next private key seed
+		NextPrivateKeySeedActivation int64 `json:"NextPrivateKeySeedActivation,omitempty"`
 	}
 
 	// User represents a user account
@@ -1033,21 +1496,30 @@ type (
 		BackupTo(w io.Writer) error
 
 		CustomTemplate() CustomTemplateService
+		DockerPolicy() DockerPolicyService
+		EdgeAsyncCommand() EdgeAsyncCommandService
+		EdgeDevice() EdgeDeviceService
 		EdgeGroup() EdgeGroupService
 		EdgeJob() EdgeJobService
+		EdgeJobRun() EdgeJobRunService
 		EdgeStack() EdgeStackService
+		EdgeStackStatusHistory() EdgeStackStatusHistoryService
 		Endpoint() EndpointService
 		EndpointGroup() EndpointGroupService
 		EndpointRelation() EndpointRelationService
+		PrunePolicy() PrunePolicyService
+		PrunePolicyRun() PrunePolicyRunService
 		Registry() RegistryService
 		ResourceControl() ResourceControlService
 		Role() RoleService
 		Settings() SettingsService
+		SnapshotHistory() SnapshotHistoryService
 		Stack() StackService
 		Tag() TagService
 		TeamMembership() TeamMembershipService
 		Team() TeamService
 		TunnelServer() TunnelServerService
+		TunnelEvent() TunnelEventService
 		User() UserService
 		Version() VersionService
 		Webhook() WebhookService
@@ -1062,9 +1534,38 @@ type (
 		CreateSignature(message string) (string, error)
 	}
 
+	// DockerPolicyService represents a service for managing Docker policy data
+	DockerPolicyService interface {
+		DockerPolicies() ([]DockerPolicy, error)
+		DockerPolicy(ID DockerPolicyID) (*DockerPolicy, error)
+		CreateDockerPolicy(policy *DockerPolicy) error
+		UpdateDockerPolicy(ID DockerPolicyID, policy *DockerPolicy) error
+		DeleteDockerPolicy(ID DockerPolicyID) error
+	}
+
 	// DockerSnapshotter represents a service used to create Docker endpoint snapshots
 	DockerSnapshotter interface {
-		CreateSnapshot(endpoint *Endpoint) (*DockerSnapshot, error)
+		CreateSnapshot(ctx context.Context, endpoint *Endpoint) (*DockerSnapshot, error)
+	}
+
+	// EdgeAsyncCommandService represents a service for managing Edge async command data
+	EdgeAsyncCommandService interface {
+		EdgeAsyncCommands(endpointID EndpointID) ([]EdgeAsyncCommand, error)
+		EdgeAsyncCommand(endpointID EndpointID, ID int) (*EdgeAsyncCommand, error)
+		CreateEdgeAsyncCommand(command *EdgeAsyncCommand) error
+		UpdateEdgeAsyncCommand(command *EdgeAsyncCommand) error
+		DeleteEdgeAsyncCommand(endpointID EndpointID, ID int) error
+		DeleteEdgeAsyncCommands(endpointID EndpointID, maxID int) error
+	}
+
+	// EdgeDeviceService represents a service to manage the edge devices of the waiting room
+	EdgeDeviceService interface {
+		EdgeDevices() ([]EdgeDevice, error)
+		EdgeDevice(ID EdgeDeviceID) (*EdgeDevice, error)
+		EdgeDeviceByEdgeID(edgeID string) (*EdgeDevice, error)
+		CreateEdgeDevice(edgeDevice *EdgeDevice) error
+		UpdateEdgeDevice(ID EdgeDeviceID, edgeDevice *EdgeDevice) error
+		DeleteEdgeDevice(ID EdgeDeviceID) error
 	}
 
 	// EdgeGroupService represents a service to manage Edge groups
@@ -1086,6 +1587,15 @@ type (
 		GetNextIdentifier() int
 	}
 
+	// EdgeJobRunService represents a service for managing Edge job run data
+	EdgeJobRunService interface {
+		EdgeJobRuns(edgeJobID EdgeJobID) ([]EdgeJobRun, error)
+		EdgeJobEndpointRuns(edgeJobID EdgeJobID, endpointID EndpointID) ([]EdgeJobRun, error)
+		CreateEdgeJobRun(run *EdgeJobRun) error
+		DeleteEdgeJobEndpointRuns(edgeJobID EdgeJobID, endpointID EndpointID, maxID int) error
+		DeleteEdgeJobRuns(edgeJobID EdgeJobID) error
+	}
+
 	// EdgeStackService represents a service to manage Edge stacks
 	EdgeStackService interface {
 		EdgeStacks() ([]EdgeStack, error)
@@ -1096,6 +1606,14 @@ type (
 		GetNextIdentifier() int
 	}
 
+	// EdgeStackStatusHistoryService represents a service for managing edge stack status history data
+	EdgeStackStatusHistoryService interface {
+		EdgeStackStatusHistory(edgeStackID EdgeStackID, endpointID EndpointID) ([]EdgeStackStatusHistoryEntry, error)
+		CreateEdgeStackStatusHistoryEntry(entry *EdgeStackStatusHistoryEntry) error
+		DeleteEdgeStackStatusHistoryEntries(edgeStackID EdgeStackID, endpointID EndpointID, maxID int) error
+		DeleteEdgeStackStatusHistory(edgeStackID EdgeStackID) error
+	}
+
 	// EndpointService represents a service for managing endpoint data
 	EndpointService interface {
 		Endpoint(ID EndpointID) (*Endpoint, error)
@@ -1119,6 +1637,7 @@ type (
 	// EndpointRelationService represents a service for managing endpoint relations data
 	EndpointRelationService interface {
 		EndpointRelation(EndpointID EndpointID) (*EndpointRelation, error)
+		EndpointRelations() ([]EndpointRelation, error)
 		CreateEndpointRelation(endpointRelation *EndpointRelation) error
 		UpdateEndpointRelation(EndpointID EndpointID, endpointRelation *EndpointRelation) error
 		DeleteEndpointRelation(EndpointID EndpointID) error
@@ -1158,6 +1677,7 @@ type (
 	// GitService represents a service for managing Git
 	GitService interface {
 		CloneRepository(destination string, repositoryURL, referenceName, username, password string) error
+		LatestCommitID(repositoryURL, referenceName, username, password string) (string, error)
 	}
 
 	// JWTService represents a service for managing JWT tokens
@@ -1189,7 +1709,7 @@ type (
 
 	// KubernetesSnapshotter represents a service used to create Kubernetes endpoint snapshots
 	KubernetesSnapshotter interface {
-		CreateSnapshot(endpoint *Endpoint) (*KubernetesSnapshot, error)
+		CreateSnapshot(ctx context.Context, endpoint *Endpoint) (*KubernetesSnapshot, error)
 	}
 
 	// LDAPService represents a service used to authenticate users against a LDAP/AD
@@ -1204,6 +1724,24 @@ type (
 		Authenticate(code string, configuration *OAuthSettings) (string, *time.Time, error)
 	}
 
+	// PrunePolicyService represents a service for managing prune policy data
+	PrunePolicyService interface {
+		PrunePolicies() ([]PrunePolicy, error)
+		PrunePolicy(ID PrunePolicyID) (*PrunePolicy, error)
+		CreatePrunePolicy(policy *PrunePolicy) error
+		UpdatePrunePolicy(ID PrunePolicyID, policy *PrunePolicy) error
+		DeletePrunePolicy(ID PrunePolicyID) error
+	}
+
+	// PrunePolicyRunService represents a service for managing the runs of prune policies
+	PrunePolicyRunService interface {
+		PrunePolicyRuns(policyID PrunePolicyID) ([]PrunePolicyRun, error)
+		PrunePolicyEndpointRuns(policyID PrunePolicyID, endpointID EndpointID) ([]PrunePolicyRun, error)
+		CreatePrunePolicyRun(run *PrunePolicyRun) error
+		DeletePrunePolicyEndpointRuns(policyID PrunePolicyID, endpointID EndpointID, maxID int) error
+		DeletePrunePolicyRuns(policyID PrunePolicyID) error
+	}
+
 	// RegistryService represents a service for managing registry data
 	RegistryService interface {
 		Registry(ID RegistryID) (*Registry, error)
@@ -1228,10 +1766,18 @@ type (
 		StartTunnelServer(addr, port string, snapshotService SnapshotService) error
 		StopTunnelServer() error
 		GenerateEdgeKey(url, host string, endpointIdentifier int) string
+		TunnelServerFingerprints() (current, next string)
+		RotateEdgeKey(endpointID EndpointID, gracePeriod time.Duration) (*Endpoint, error)
+		RotateTunnelServerKey(gracePeriod time.Duration) error
 		SetTunnelStatusToActive(endpointID EndpointID)
 		SetTunnelStatusToRequired(endpointID EndpointID) error
 		SetTunnelStatusToIdle(endpointID EndpointID)
+		KeepTunnelAlive(endpointID EndpointID)
+		OpenTunnel(endpointID EndpointID, reason string) error
+		CloseTunnel(endpointID EndpointID, reason string)
 		GetTunnelDetails(endpointID EndpointID) *TunnelDetails
+		Tunnels() map[EndpointID]TunnelDetails
+		TunnelDialer(endpointID EndpointID) func(ctx context.Context, network, address string) (net.Conn, error)
 		AddEdgeJob(endpointID EndpointID, edgeJob *EdgeJob)
 		RemoveEdgeJob(edgeJobID EdgeJobID)
 	}
@@ -1266,12 +1812,26 @@ type (
 		GetNextIdentifier() int
 	}
 
+	// SnapshotHistoryService represents a service for managing endpoint snapshot history data
+	SnapshotHistoryService interface {
+		SnapshotHistory(endpointID EndpointID, from, to int64) ([]SnapshotHistoryEntry, error)
+		CreateSnapshotHistoryEntry(entry *SnapshotHistoryEntry) error
+		DeleteSnapshotHistory(endpointID EndpointID, from, to int64) error
+	}
+
+	// EdgeStackGitService represents a service keeping the edge stacks deployed from git up to date with their repository
+	EdgeStackGitService interface {
+		Start()
+		PullEdgeStack(edgeStackID EdgeStackID) (*EdgeStack, error)
+	}
+
 	// SnapshotService represents a service for managing endpoint snapshots
 	SnapshotService interface {
 		Start()
 		Stop()
 		SetSnapshotInterval(snapshotInterval string) error
 		SnapshotEndpoint(endpoint *Endpoint) error
+		StoreEdgeSnapshot(endpoint *Endpoint, dockerSnapshot *DockerSnapshot, kubernetesSnapshot *KubernetesSnapshot)
 	}
 
 	// SwarmStackManager represents a service to manage Swarm stacks
@@ -1320,6 +1880,13 @@ type (
 		UpdateInfo(info *TunnelServerInfo) error
 	}
 
+	// TunnelEventService represents a service for managing tunnel event data
+	TunnelEventService interface {
+		TunnelEvents(endpointID EndpointID) ([]TunnelEvent, error)
+		CreateTunnelEvent(event *TunnelEvent) error
+		DeleteTunnelEvents(endpointID EndpointID, maxID int) error
+	}
+
 	// UserService represents a service for managing user data
 	UserService interface {
 		User(ID UserID) (*User, error)
@@ -1372,6 +1939,14 @@ const (
 	HTTPResponseAgentPlatform = "Portainer-Agent-Platform"
 	// PortainerAgentTargetHeader represent the name of the header containing the target node name
 	PortainerAgentTargetHeader = "X-PortainerAgent-Target"
+	// PortainerAgentFleetKeyHeader represent the name of the header containing the fleet key used by an agent to enrol
+	PortainerAgentFleetKeyHeader = "X-PortainerAgent-FleetKey"
+	// PortainerAgentFleetKeySecretHeader represent the name of the header containing the secret of the fleet key
+	PortainerAgentFleetKeySecretHeader = "X-PortainerAgent-FleetKeySecret"
+	// PortainerAgentEnrolmentSecretHeader represent the name of the header containing the enrolment secret issued to an edge device
+	PortainerAgentEnrolmentSecretHeader = "X-PortainerAgent-EnrolmentSecret"
+	// PortainerAgentEdgeKeySecretHeader represent the name of the header containing the secret of a rotated edge key
+	PortainerAgentEdgeKeySecretHeader = "X-PortainerAgent-EdgeKeySecret"
 	// PortainerAgentSignatureHeader represent the name of the header containing the digital signature
 	PortainerAgentSignatureHeader = "X-PortainerAgent-Signature"
 	// PortainerAgentPublicKeyHeader represent the name of the header containing the public key
@@ -1387,6 +1962,38 @@ const (
 	DefaultTemplatesURL = "https://raw.githubusercontent.com/portainer/templates/master/templates-2.0.json"
 	// DefaultUserSessionTimeout represents the default timeout after which the user session is cleared
 	DefaultUserSessionTimeout = "8h"
+	// DefaultSnapshotHistoryRetention represents the default duration for which endpoint snapshot history is retained
+	DefaultSnapshotHistoryRetention = "720h"
+	// DefaultSnapshotHistoryDownsampleAfter represents the default age after which endpoint snapshot history is downsampled
+	DefaultSnapshotHistoryDownsampleAfter = "24h"
+	// DefaultSnapshotHistoryDownsampleInterval represents the default resolution of downsampled endpoint snapshot history
+	DefaultSnapshotHistoryDownsampleInterval = "1h"
+	// DefaultEdgeStackStatusHistoryRetention represents the default duration for which edge stack status history is retained
+	DefaultEdgeStackStatusHistoryRetention = "720h"
+	// DefaultEdgeStackStatusHistoryMaxEntries represents the default number of status history entries retained for an edge stack on an endpoint
+	DefaultEdgeStackStatusHistoryMaxEntries = 100
+	// DefaultEdgeJobRunsMaxEntries represents the number of runs retained for an Edge job on an endpoint
+	DefaultEdgeJobRunsMaxEntries = 50
+	// DefaultPrunePolicyRunsMaxEntries represents the number of runs retained for a prune policy on an endpoint
+	DefaultPrunePolicyRunsMaxEntries = 50
+	// DefaultTunnelEventsMaxEntries represents the number of tunnel events retained for an endpoint
+	DefaultTunnelEventsMaxEntries = 100
+	// DefaultEdgeAsyncCommandsMaxEntries represents the number of completed async commands retained for an endpoint
+	DefaultEdgeAsyncCommandsMaxEntries = 100
+	// DefaultEdgeKeyRotationGracePeriod represents the default duration for which a previous edge key is accepted after a rotation
+	DefaultEdgeKeyRotationGracePeriod = "24h"
+	// DefaultEdgeSnapshotPushInterval represents the default interval at which Edge agents push the snapshot of their endpoint
+	DefaultEdgeSnapshotPushInterval = "5m"
+	// DefaultEdgeTunnelMinPort represents the lowest port of the default tunnel port range, the start of the dynamic ports
+	DefaultEdgeTunnelMinPort = 49152
+	// DefaultEdgeTunnelMaxPort represents the highest port of the default tunnel port range
+	DefaultEdgeTunnelMaxPort = 65535
+	// DefaultDockerProxyCacheTTL represents the default duration for which the Docker list responses are cached by the proxy
+	DefaultDockerProxyCacheTTL = "5s"
+	// DatastoreBoltDB represents the BoltDB storage engine, used by default to store the data
+	DatastoreBoltDB = "boltdb"
+	// DatastoreSQLite represents the SQLite storage engine
+	DatastoreSQLite = "sqlite"
 )
 
 const (
@@ -1399,6 +2006,28 @@ const (
 	AuthenticationOAuth
 )
 
+const (
+	_ EdgeGroupRuleType = iota
+	// EdgeGroupRuleAnd matches the endpoints matched by every operand
+	EdgeGroupRuleAnd
+	// EdgeGroupRuleOr matches the endpoints matched by at least one operand
+	EdgeGroupRuleOr
+	// EdgeGroupRuleNot matches the endpoints not matched by its operand
+	EdgeGroupRuleNot
+	// EdgeGroupRuleEndpointGroup matches the endpoints of an endpoint group
+	EdgeGroupRuleEndpointGroup
+	// EdgeGroupRuleName matches the endpoints whose name matches a glob pattern
+	EdgeGroupRuleName
+	// EdgeGroupRulePlatform matches the endpoints running an agent on a platform
+	EdgeGroupRulePlatform
+	// EdgeGroupRuleCheckIn matches the endpoints which checked in recently
+	EdgeGroupRuleCheckIn
+	// EdgeGroupRuleDockerVersion matches the endpoints whose latest snapshot satisfies a Docker version constraint
+	EdgeGroupRuleDockerVersion
+	// EdgeGroupRuleLabel matches the endpoints with a label whose value matches a glob pattern
+	EdgeGroupRuleLabel
+)
+
 const (
 	_ AgentPlatform = iota
 	// AgentPlatformDocker represent the Docker platform (Standalone/Swarm)
@@ -1407,6 +2036,62 @@ const (
 	AgentPlatformKubernetes
 )
 
+const (
+	_ EdgeAsyncCommandType = iota
+	// EdgeAsyncCommandContainerStart represents a command starting a container
+	EdgeAsyncCommandContainerStart
+	// EdgeAsyncCommandContainerStop represents a command stopping a container
+	EdgeAsyncCommandContainerStop
+	// EdgeAsyncCommandContainerRestart represents a command restarting a container
+	EdgeAsyncCommandContainerRestart
+	// EdgeAsyncCommandImagePull represents a command pulling an image
+	EdgeAsyncCommandImagePull
+	// EdgeAsyncCommandStackRedeploy represents a command redeploying an edge stack
+	EdgeAsyncCommandStackRedeploy
+	// EdgeAsyncCommandSnapshotUpload represents a command requesting the agent to upload a snapshot
+	EdgeAsyncCommandSnapshotUpload
+)
+
+const (
+	_ EdgeAsyncCommandStatus = iota
+	// EdgeAsyncCommandStatusPending represents a command waiting to be delivered to the agent
+	EdgeAsyncCommandStatusPending
+	// EdgeAsyncCommandStatusDelivered represents a command delivered to the agent and not acknowledged yet
+	EdgeAsyncCommandStatusDelivered
+	// EdgeAsyncCommandStatusSucceeded represents a command acknowledged as successful by the agent
+	EdgeAsyncCommandStatusSucceeded
+	// EdgeAsyncCommandStatusFailed represents a command acknowledged as failed by the agent
+	EdgeAsyncCommandStatusFailed
+)
+
+const (
+	_ EdgeDeviceStatus = iota
+	// EdgeDeviceStatusPending represents an edge device waiting for approval
+	EdgeDeviceStatusPending
+	// EdgeDeviceStatusApproved represents an approved edge device, associated to an endpoint
+	EdgeDeviceStatusApproved
+	// EdgeDeviceStatusRejected represents a rejected edge device
+	EdgeDeviceStatusRejected
+)
+
+const (
+	_ EdgeEnrolmentRuleAction = iota
+	// EdgeEnrolmentRuleApprove represents a rule approving the matching edge devices
+	EdgeEnrolmentRuleApprove
+	// EdgeEnrolmentRuleReject represents a rule rejecting the matching edge devices
+	EdgeEnrolmentRuleReject
+)
+
+const (
+	_ EdgeJobRunStatus = iota
+	// EdgeJobRunSucceeded represents a run whose last attempt exited with a zero exit code
+	EdgeJobRunSucceeded
+	// EdgeJobRunFailed represents a run whose last attempt exited with a non-zero exit code
+	EdgeJobRunFailed
+	// EdgeJobRunTimedOut represents a run whose last attempt was stopped after the timeout of the job
+	EdgeJobRunTimedOut
+)
+
 const (
 	_ EdgeJobLogsStatus = iota
 	// EdgeJobLogsStatusIdle represents an idle log collection job
@@ -1435,6 +2120,33 @@ const (
 	StatusAcknowledged
 )
 
+const (
+	// EdgeStackDeploymentCompose represents an edge stack deployed from a Compose file
+	EdgeStackDeploymentCompose EdgeStackDeploymentType = iota
+	// EdgeStackDeploymentKubernetes represents an edge stack deployed from a Kubernetes manifest
+	EdgeStackDeploymentKubernetes
+)
+
+const (
+	_ EdgeStackRolloutStrategy = iota
+	// EdgeStackRolloutPercentage releases a percentage of the endpoints per batch
+	EdgeStackRolloutPercentage
+	// EdgeStackRolloutBatch releases a fixed number of endpoints per batch
+	EdgeStackRolloutBatch
+)
+
+const (
+	_ EdgeStackRolloutStatus = iota
+	// EdgeStackRolloutInProgress represents a rollout releasing its batches
+	EdgeStackRolloutInProgress
+	// EdgeStackRolloutPaused represents a rollout paused manually or because of the error threshold
+	EdgeStackRolloutPaused
+	// EdgeStackRolloutAborted represents a rollout stopped before reaching every endpoint
+	EdgeStackRolloutAborted
+	// EdgeStackRolloutCompleted represents a rollout released to every endpoint
+	EdgeStackRolloutCompleted
+)
+
 const (
 	_ EndpointExtensionType = iota
 	// StoridgeEndpointExtension represents the Storidge extension
@@ -1533,6 +2245,8 @@ const (
 	CustomTemplateResourceControl
 	// ContainerGroupResourceControl represents a resource control associated to an Azure container group
 	ContainerGroupResourceControl
+	// ImageResourceControl represents a resource control associated to a Docker image
+	ImageResourceControl
 )
 
 const (
@@ -0,0 +1,81 @@
+package customtemplate
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "customtemplates"
+)
+
+// Service represents a service for managing custom template data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// CustomTemplates return an array containing all the custom templates.
+func (service *Service) CustomTemplates() ([]portainer.CustomTemplate, error) {
+	var customTemplates = make([]portainer.CustomTemplate, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var customTemplate portainer.CustomTemplate
+		err := internal.UnmarshalObject(data, &customTemplate)
+		if err != nil {
+			return err
+		}
+		customTemplates = append(customTemplates, customTemplate)
+
+		return nil
+	})
+
+	return customTemplates, err
+}
+
+// CustomTemplate returns a custom template by ID.
+func (service *Service) CustomTemplate(ID portainer.CustomTemplateID) (*portainer.CustomTemplate, error) {
+	var customTemplate portainer.CustomTemplate
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &customTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &customTemplate, nil
+}
+
+// CreateCustomTemplate saves a new custom template. The identifier must have been retrieved through GetNextIdentifier.
+func (service *Service) CreateCustomTemplate(customTemplate *portainer.CustomTemplate) error {
+	return internal.UpdateObject(service.connection, TableName, internal.Itob(int(customTemplate.ID)), customTemplate)
+}
+
+// UpdateCustomTemplate updates a custom template.
+func (service *Service) UpdateCustomTemplate(ID portainer.CustomTemplateID, customTemplate *portainer.CustomTemplate) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, customTemplate)
+}
+
+// DeleteCustomTemplate deletes a custom template.
+func (service *Service) DeleteCustomTemplate(ID portainer.CustomTemplateID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+// GetNextIdentifier returns the next identifier for a custom template.
+func (service *Service) GetNextIdentifier() int {
+	return internal.GetNextIdentifier(service.connection, TableName)
+}
@@ -0,0 +1,359 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+
+	_ "github.com/mattn/go-sqlite3"
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/bolt/migrator"
+	"github.com/portainer/portainer/api/internal/authorization"
+	"github.com/portainer/portainer/api/sqlite/customtemplate"
+	"github.com/portainer/portainer/api/sqlite/dockerpolicy"
+	"github.com/portainer/portainer/api/sqlite/edgeasynccommand"
+	"github.com/portainer/portainer/api/sqlite/edgedevice"
+	"github.com/portainer/portainer/api/sqlite/edgegroup"
+	"github.com/portainer/portainer/api/sqlite/edgejob"
+	"github.com/portainer/portainer/api/sqlite/edgejobrun"
+	"github.com/portainer/portainer/api/sqlite/edgestack"
+	"github.com/portainer/portainer/api/sqlite/edgestackstatushistory"
+	"github.com/portainer/portainer/api/sqlite/endpoint"
+	"github.com/portainer/portainer/api/sqlite/endpointgroup"
+	"github.com/portainer/portainer/api/sqlite/endpointrelation"
+	"github.com/portainer/portainer/api/sqlite/internal"
+	"github.com/portainer/portainer/api/sqlite/prunepolicy"
+	"github.com/portainer/portainer/api/sqlite/prunepolicyrun"
+	"github.com/portainer/portainer/api/sqlite/registry"
+	"github.com/portainer/portainer/api/sqlite/resourcecontrol"
+	"github.com/portainer/portainer/api/sqlite/role"
+	"github.com/portainer/portainer/api/sqlite/settings"
+	"github.com/portainer/portainer/api/sqlite/snapshothistory"
+	"github.com/portainer/portainer/api/sqlite/stack"
+	"github.com/portainer/portainer/api/sqlite/tag"
+	"github.com/portainer/portainer/api/sqlite/team"
+	"github.com/portainer/portainer/api/sqlite/teammembership"
+	"github.com/portainer/portainer/api/sqlite/tunnelevent"
+	"github.com/portainer/portainer/api/sqlite/tunnelserver"
+	"github.com/portainer/portainer/api/sqlite/user"
+	"github.com/portainer/portainer/api/sqlite/version"
+	"github.com/portainer/portainer/api/sqlite/webhook"
+)
+
+const (
+	// DatabaseFileName represents the name of the SQLite database file inside the data folder
+	DatabaseFileName = "portainer.sqlite"
+)
+
+// Store defines the implementation of portainer.DataStore using
+// SQLite as the storage system.
+type Store struct {
+	path                          string
+	connection                    *internal.DbConnection
+	isNew                         bool
+	fileService                   portainer.FileService
+	CustomTemplateService         *customtemplate.Service
+	DockerPolicyService           *dockerpolicy.Service
+	EdgeAsyncCommandService       *edgeasynccommand.Service
+	EdgeDeviceService             *edgedevice.Service
+	EdgeGroupService              *edgegroup.Service
+	EdgeJobService                *edgejob.Service
+	EdgeJobRunService             *edgejobrun.Service
+	EdgeStackService              *edgestack.Service
+	EdgeStackStatusHistoryService *edgestackstatushistory.Service
+	EndpointGroupService          *endpointgroup.Service
+	EndpointService               *endpoint.Service
+	EndpointRelationService       *endpointrelation.Service
+	PrunePolicyService            *prunepolicy.Service
+	PrunePolicyRunService         *prunepolicyrun.Service
+	RegistryService               *registry.Service
+	ResourceControlService        *resourcecontrol.Service
+	RoleService                   *role.Service
+	SettingsService               *settings.Service
+	SnapshotHistoryService        *snapshothistory.Service
+	StackService                  *stack.Service
+	TagService                    *tag.Service
+	TeamMembershipService         *teammembership.Service
+	TeamService                   *team.Service
+	TunnelServerService           *tunnelserver.Service
+	TunnelEventService            *tunnelevent.Service
+	UserService                   *user.Service
+	VersionService                *version.Service
+	WebhookService                *webhook.Service
+}
+
+func (store *Store) edition() portainer.SoftwareEdition {
+	edition, err := store.VersionService.Edition()
+	if err == errors.ErrObjectNotFound {
+		edition = portainer.PortainerCE
+	}
+	return edition
+}
+
+// NewStore initializes a new Store and the associated services
+func NewStore(storePath string, fileService portainer.FileService) (*Store, error) {
+	store := &Store{
+		path:        storePath,
+		fileService: fileService,
+		isNew:       true,
+		connection:  &internal.DbConnection{},
+	}
+
+	databasePath := path.Join(storePath, DatabaseFileName)
+	databaseFileExists, err := fileService.FileExists(databasePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseFileExists {
+		store.isNew = false
+	}
+
+	return store, nil
+}
+
+// Open opens and initializes the SQLite database.
+// The database runs in WAL mode so that readers are never blocked by a writer,
+// write transactions acquire their lock immediately to avoid upgrade deadlocks.
+func (store *Store) Open() error {
+	databasePath := path.Join(store.path, DatabaseFileName)
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL&_txlock=immediate", databasePath)
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return err
+	}
+	store.connection.DB = db
+
+	err = internal.CreateSequenceTable(store.connection)
+	if err != nil {
+		return err
+	}
+
+	return store.initServices()
+}
+
+// Close closes the SQLite database.
+func (store *Store) Close() error {
+	if store.connection.DB != nil {
+		return store.connection.Close()
+	}
+	return nil
+}
+
+// IsNew returns true if the database was just created and false if it is re-using
+// existing data.
+func (store *Store) IsNew() bool {
+	return store.isNew
+}
+
+// CheckCurrentEdition checks if current edition is community edition
+func (store *Store) CheckCurrentEdition() error {
+	if store.edition() != portainer.PortainerCE {
+		return errors.ErrWrongDBEdition
+	}
+	return nil
+}
+
+// MigrateData automatically migrate the data based on the DBVersion.
+// This process is only triggered on an existing database, not if the database was just created.
+// if force is true, then migrate regardless.
+// The migrations run through the services of the store, a snapshot of the database is taken before the
+// migration and restored if it fails.
+func (store *Store) MigrateData(force bool) error {
+	if store.isNew && !force {
+		return store.VersionService.StoreDBVersion(portainer.DBVersion)
+	}
+
+	version, err := store.VersionService.DBVersion()
+	if err == errors.ErrObjectNotFound {
+		version = 0
+	} else if err != nil {
+		return err
+	}
+
+	if version >= portainer.DBVersion {
+		return nil
+	}
+
+	backupPath, err := store.backupBeforeMigration(version)
+	if err != nil {
+		log.Printf("An error occurred while creating the pre-migration backup: %s\n", err)
+		return err
+	}
+
+	migrator := migrator.NewMigrator(&migrator.Parameters{
+		DatabaseVersion:         version,
+		EndpointGroupService:    store.EndpointGroupService,
+		EndpointService:         store.EndpointService,
+		EndpointRelationService: store.EndpointRelationService,
+		RegistryService:         store.RegistryService,
+		ResourceControlService:  store.ResourceControlService,
+		RoleService:             store.RoleService,
+		SettingsService:         store.SettingsService,
+		StackService:            store.StackService,
+		TagService:              store.TagService,
+		TeamMembershipService:   store.TeamMembershipService,
+		UserService:             store.UserService,
+		VersionService:          store.VersionService,
+		FileService:             store.fileService,
+		AuthorizationService:    authorization.NewService(store),
+	})
+
+	log.Printf("Migrating database from version %v to %v.\n", version, portainer.DBVersion)
+	err = migrator.Migrate()
+	if err != nil {
+		log.Printf("An error occurred during database migration: %s\n", err)
+
+		restoreErr := store.restoreBackup(backupPath)
+		if restoreErr != nil {
+			log.Printf("An error occurred while restoring the pre-migration backup %s: %s\n", backupPath, restoreErr)
+			return err
+		}
+
+		log.Printf("Database restored to version %v from %s.\n", version, backupPath)
+		return err
+	}
+
+	return nil
+}
+
+// BackupFileName returns the name of the snapshot of the database taken before a migration from version
+func BackupFileName(version int) string {
+	return fmt.Sprintf("%s.v%d.bak", DatabaseFileName, version)
+}
+
+// backupBeforeMigration copies the database to a snapshot stamped with its current version
+// so that it can be restored if the migration fails.
+func (store *Store) backupBeforeMigration(version int) (string, error) {
+	backupPath := path.Join(store.path, BackupFileName(version))
+
+	backup, err := os.OpenFile(backupPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	err = store.BackupTo(backup)
+	if err != nil {
+		backup.Close()
+		os.Remove(backupPath)
+		return "", err
+	}
+
+	err = backup.Sync()
+	if err != nil {
+		backup.Close()
+		return "", err
+	}
+
+	err = backup.Close()
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("Database snapshot created before migration: %s\n", backupPath)
+
+	return backupPath, nil
+}
+
+// restoreBackup replaces the database with a snapshot and re-opens the store.
+// The write-ahead log of the replaced database is removed so that it is not replayed on the snapshot.
+func (store *Store) restoreBackup(backupPath string) error {
+	err := store.Close()
+	if err != nil {
+		return err
+	}
+
+	databasePath := path.Join(store.path, DatabaseFileName)
+
+	restorePath := databasePath + ".restore"
+	err = copyFile(backupPath, restorePath)
+	if err != nil {
+		return err
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		err = os.Remove(databasePath + suffix)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	err = os.Rename(restorePath, databasePath)
+	if err != nil {
+		return err
+	}
+
+	return store.Open()
+}
+
+func copyFile(sourcePath, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(destinationPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(destination, source)
+	if err != nil {
+		destination.Close()
+		return err
+	}
+
+	err = destination.Sync()
+	if err != nil {
+		destination.Close()
+		return err
+	}
+
+	return destination.Close()
+}
+
+// BackupTo backs up db to a provided writer.
+// It relies on VACUUM INTO to create a consistent copy of the database without
+// blocking other database reads and writes.
+func (store *Store) BackupTo(w io.Writer) error {
+	backupFile, err := ioutil.TempFile(store.path, DatabaseFileName+".backup-*")
+	if err != nil {
+		return err
+	}
+	backupPath := backupFile.Name()
+	backupFile.Close()
+	defer os.Remove(backupPath)
+
+	// VACUUM INTO requires the destination file to not exist
+	err = os.Remove(backupPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.connection.Exec(`VACUUM INTO ?`, backupPath)
+	if err != nil {
+		return err
+	}
+
+	backup, err := os.Open(backupPath)
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	_, err = io.Copy(w, backup)
+	return err
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/migrator"
+	"github.com/portainer/portainer/api/filesystem"
+	"github.com/portainer/portainer/api/internal/datastoretest"
+)
+
+func newTestStore(t *testing.T, dataStorePath string) *Store {
+	fileService, err := filesystem.NewService(dataStorePath, "")
+	if err != nil {
+		t.Fatalf("failed creating file service: %v", err)
+	}
+
+	store, err := NewStore(dataStorePath, fileService)
+	if err != nil {
+		t.Fatalf("failed creating store: %v", err)
+	}
+
+	err = store.Open()
+	if err != nil {
+		t.Fatalf("failed opening store: %v", err)
+	}
+
+	return store
+}
+
+func TestStoreServices(t *testing.T) {
+	datastoretest.RunServiceTests(t, func(t *testing.T) (portainer.DataStore, func()) {
+		dataStorePath, err := ioutil.TempDir("", "sqlite")
+		if err != nil {
+			t.Fatalf("failed creating temp dir: %v", err)
+		}
+
+		store := newTestStore(t, dataStorePath)
+
+		err = store.Init()
+		if err != nil {
+			t.Fatalf("failed initializing store: %v", err)
+		}
+
+		return store, func() {
+			store.Close()
+			os.RemoveAll(dataStorePath)
+		}
+	})
+}
+
+func TestMigrateData_RestoresTheDatabaseOnFailure(t *testing.T) {
+	dataStorePath, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatalf("failed creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dataStorePath)
+
+	store := newTestStore(t, dataStorePath)
+	defer store.Close()
+
+	err = store.Init()
+	if err != nil {
+		t.Fatalf("failed initializing store: %v", err)
+	}
+
+	// databases older than MinDataStoreDBVersion can only be migrated by the BoltDB store
+	err = store.VersionService.StoreDBVersion(migrator.MinDataStoreDBVersion - 1)
+	if err != nil {
+		t.Fatalf("failed storing the database version: %v", err)
+	}
+
+	err = store.MigrateData(true)
+	if err == nil {
+		t.Fatal("expected the migration to fail")
+	}
+
+	version, err := store.VersionService.DBVersion()
+	if err != nil {
+		t.Fatalf("failed reading the database version of the restored database: %v", err)
+	}
+	if version != migrator.MinDataStoreDBVersion-1 {
+		t.Errorf("expected the database to be restored to version %d, got %d", migrator.MinDataStoreDBVersion-1, version)
+	}
+
+	_, err = os.Stat(path.Join(dataStorePath, BackupFileName(version)))
+	if err != nil {
+		t.Errorf("expected the pre-migration snapshot to be kept: %v", err)
+	}
+}
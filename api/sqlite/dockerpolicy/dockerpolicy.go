@@ -0,0 +1,86 @@
+package dockerpolicy
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "docker_policies"
+)
+
+// Service represents a service for managing Docker policy data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// DockerPolicies returns an array containing all the Docker policies.
+func (service *Service) DockerPolicies() ([]portainer.DockerPolicy, error) {
+	var policies = make([]portainer.DockerPolicy, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var policy portainer.DockerPolicy
+		err := internal.UnmarshalObject(data, &policy)
+		if err != nil {
+			return err
+		}
+		policies = append(policies, policy)
+
+		return nil
+	})
+
+	return policies, err
+}
+
+// DockerPolicy returns a Docker policy by ID.
+func (service *Service) DockerPolicy(ID portainer.DockerPolicyID) (*portainer.DockerPolicy, error) {
+	var policy portainer.DockerPolicy
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// CreateDockerPolicy assigns an ID to a new Docker policy and saves it.
+func (service *Service) CreateDockerPolicy(policy *portainer.DockerPolicy) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		policy.ID = portainer.DockerPolicyID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(policy.ID)), policy)
+	})
+}
+
+// UpdateDockerPolicy updates a Docker policy.
+func (service *Service) UpdateDockerPolicy(ID portainer.DockerPolicyID, policy *portainer.DockerPolicy) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, policy)
+}
+
+// DeleteDockerPolicy deletes a Docker policy.
+func (service *Service) DeleteDockerPolicy(ID portainer.DockerPolicyID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
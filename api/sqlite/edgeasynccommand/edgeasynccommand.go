@@ -0,0 +1,95 @@
+package edgeasynccommand
+
+import (
+	"database/sql"
+	"math"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "edge_async_commands"
+)
+
+// Service represents a service for managing Edge async command data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// commandKey returns the key of a command, made of the endpoint identifier followed by the command
+// identifier so that the commands of an endpoint are stored next to each other, in creation order.
+func commandKey(endpointID portainer.EndpointID, ID int) []byte {
+	return append(internal.Itob(int(endpointID)), internal.Itob(ID)...)
+}
+
+// EdgeAsyncCommands returns the async commands of an endpoint, ordered by creation.
+func (service *Service) EdgeAsyncCommands(endpointID portainer.EndpointID) ([]portainer.EdgeAsyncCommand, error) {
+	var commands = make([]portainer.EdgeAsyncCommand, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, TableName, commandKey(endpointID, 0), commandKey(endpointID, math.MaxInt64), func(data []byte) error {
+		var command portainer.EdgeAsyncCommand
+		err := internal.UnmarshalObject(data, &command)
+		if err != nil {
+			return err
+		}
+		commands = append(commands, command)
+
+		return nil
+	})
+
+	return commands, err
+}
+
+// EdgeAsyncCommand returns an async command of an endpoint by ID.
+func (service *Service) EdgeAsyncCommand(endpointID portainer.EndpointID, ID int) (*portainer.EdgeAsyncCommand, error) {
+	var command portainer.EdgeAsyncCommand
+
+	err := internal.GetObject(service.connection, TableName, commandKey(endpointID, ID), &command)
+	if err != nil {
+		return nil, err
+	}
+
+	return &command, nil
+}
+
+// CreateEdgeAsyncCommand assigns an identifier to an async command and saves it.
+func (service *Service) CreateEdgeAsyncCommand(command *portainer.EdgeAsyncCommand) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		command.ID = id
+
+		return internal.PutObject(tx, TableName, commandKey(command.EndpointID, command.ID), command)
+	})
+}
+
+// UpdateEdgeAsyncCommand saves an async command.
+func (service *Service) UpdateEdgeAsyncCommand(command *portainer.EdgeAsyncCommand) error {
+	return internal.UpdateObject(service.connection, TableName, commandKey(command.EndpointID, command.ID), command)
+}
+
+// DeleteEdgeAsyncCommand deletes an async command of an endpoint.
+func (service *Service) DeleteEdgeAsyncCommand(endpointID portainer.EndpointID, ID int) error {
+	return internal.DeleteObject(service.connection, TableName, commandKey(endpointID, ID))
+}
+
+// DeleteEdgeAsyncCommands deletes the async commands of an endpoint up to the command identified by maxID (inclusive).
+func (service *Service) DeleteEdgeAsyncCommands(endpointID portainer.EndpointID, maxID int) error {
+	return internal.DeleteObjectsInRange(service.connection, TableName, commandKey(endpointID, 0), commandKey(endpointID, maxID))
+}
@@ -0,0 +1,127 @@
+package edgedevice
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "edge_devices"
+
+	edgeIDColumn = "edge_id"
+)
+
+// Service represents a service for managing edge device data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName, edgeIDColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// EdgeDevices returns an array containing all the edge devices.
+func (service *Service) EdgeDevices() ([]portainer.EdgeDevice, error) {
+	var edgeDevices = make([]portainer.EdgeDevice, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var edgeDevice portainer.EdgeDevice
+		err := internal.UnmarshalObject(data, &edgeDevice)
+		if err != nil {
+			return err
+		}
+		edgeDevices = append(edgeDevices, edgeDevice)
+
+		return nil
+	})
+
+	return edgeDevices, err
+}
+
+// EdgeDevice returns an edge device by ID.
+func (service *Service) EdgeDevice(ID portainer.EdgeDeviceID) (*portainer.EdgeDevice, error) {
+	var edgeDevice portainer.EdgeDevice
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &edgeDevice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &edgeDevice, nil
+}
+
+// EdgeDeviceByEdgeID returns an edge device by the EdgeID of its agent.
+func (service *Service) EdgeDeviceByEdgeID(edgeID string) (*portainer.EdgeDevice, error) {
+	var edgeDevice portainer.EdgeDevice
+
+	err := internal.FindObject(service.connection, TableName, &edgeDevice, internal.Index{Column: edgeIDColumn, Value: edgeID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &edgeDevice, nil
+}
+
+// CreateEdgeDevice assigns an ID to a new edge device and saves it.
+func (service *Service) CreateEdgeDevice(edgeDevice *portainer.EdgeDevice) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		edgeDevice.ID = portainer.EdgeDeviceID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(edgeDevice.ID)), edgeDevice, indexes(edgeDevice)...)
+	})
+}
+
+// UpdateEdgeDevice updates an edge device.
+func (service *Service) UpdateEdgeDevice(ID portainer.EdgeDeviceID, edgeDevice *portainer.EdgeDevice) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, edgeDevice, indexes(edgeDevice)...)
+}
+
+// RebuildIndexes saves every edge device again to populate the index columns,
+// it is used after data was copied into the table without going through the service.
+func (service *Service) RebuildIndexes() error {
+	edgeDevices, err := service.EdgeDevices()
+	if err != nil {
+		return err
+	}
+
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for i := range edgeDevices {
+			edgeDevice := &edgeDevices[i]
+			err := internal.PutObject(tx, TableName, internal.Itob(int(edgeDevice.ID)), edgeDevice, indexes(edgeDevice)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteEdgeDevice deletes an edge device.
+func (service *Service) DeleteEdgeDevice(ID portainer.EdgeDeviceID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+func indexes(edgeDevice *portainer.EdgeDevice) []internal.Index {
+	return []internal.Index{
+		{Column: edgeIDColumn, Value: edgeDevice.EdgeID},
+	}
+}
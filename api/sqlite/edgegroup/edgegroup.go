@@ -0,0 +1,86 @@
+package edgegroup
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "edgegroups"
+)
+
+// Service represents a service for managing Edge group data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// EdgeGroups return an array containing all the Edge groups.
+func (service *Service) EdgeGroups() ([]portainer.EdgeGroup, error) {
+	var groups = make([]portainer.EdgeGroup, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var group portainer.EdgeGroup
+		err := internal.UnmarshalObject(data, &group)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, group)
+
+		return nil
+	})
+
+	return groups, err
+}
+
+// EdgeGroup returns an Edge group by ID.
+func (service *Service) EdgeGroup(ID portainer.EdgeGroupID) (*portainer.EdgeGroup, error) {
+	var group portainer.EdgeGroup
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &group)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// CreateEdgeGroup assigns an ID to a new Edge group and saves it.
+func (service *Service) CreateEdgeGroup(group *portainer.EdgeGroup) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		group.ID = portainer.EdgeGroupID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(group.ID)), group)
+	})
+}
+
+// UpdateEdgeGroup updates an Edge group.
+func (service *Service) UpdateEdgeGroup(ID portainer.EdgeGroupID, group *portainer.EdgeGroup) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, group)
+}
+
+// DeleteEdgeGroup deletes an Edge group.
+func (service *Service) DeleteEdgeGroup(ID portainer.EdgeGroupID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
@@ -0,0 +1,93 @@
+package edgejob
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "edgejobs"
+)
+
+// Service represents a service for managing Edge job data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// EdgeJobs return an array containing all the Edge jobs.
+func (service *Service) EdgeJobs() ([]portainer.EdgeJob, error) {
+	var edgeJobs = make([]portainer.EdgeJob, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var edgeJob portainer.EdgeJob
+		err := internal.UnmarshalObject(data, &edgeJob)
+		if err != nil {
+			return err
+		}
+		edgeJobs = append(edgeJobs, edgeJob)
+
+		return nil
+	})
+
+	return edgeJobs, err
+}
+
+// EdgeJob returns an Edge job by ID.
+func (service *Service) EdgeJob(ID portainer.EdgeJobID) (*portainer.EdgeJob, error) {
+	var edgeJob portainer.EdgeJob
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &edgeJob)
+	if err != nil {
+		return nil, err
+	}
+
+	return &edgeJob, nil
+}
+
+// CreateEdgeJob assigns an ID to a new Edge job if it does not have one yet and saves it.
+func (service *Service) CreateEdgeJob(edgeJob *portainer.EdgeJob) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		if edgeJob.ID == 0 {
+			id, err := internal.NextSequence(tx, TableName)
+			if err != nil {
+				return err
+			}
+			edgeJob.ID = portainer.EdgeJobID(id)
+		}
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(edgeJob.ID)), edgeJob)
+	})
+}
+
+// UpdateEdgeJob updates an Edge job.
+func (service *Service) UpdateEdgeJob(ID portainer.EdgeJobID, edgeJob *portainer.EdgeJob) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, edgeJob)
+}
+
+// DeleteEdgeJob deletes an Edge job.
+func (service *Service) DeleteEdgeJob(ID portainer.EdgeJobID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+// GetNextIdentifier returns the next identifier for an Edge job.
+func (service *Service) GetNextIdentifier() int {
+	return internal.GetNextIdentifier(service.connection, TableName)
+}
@@ -0,0 +1,93 @@
+package edgestack
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "edge_stack"
+)
+
+// Service represents a service for managing Edge stack data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// EdgeStacks return an array containing all the Edge stacks.
+func (service *Service) EdgeStacks() ([]portainer.EdgeStack, error) {
+	var edgeStacks = make([]portainer.EdgeStack, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var edgeStack portainer.EdgeStack
+		err := internal.UnmarshalObject(data, &edgeStack)
+		if err != nil {
+			return err
+		}
+		edgeStacks = append(edgeStacks, edgeStack)
+
+		return nil
+	})
+
+	return edgeStacks, err
+}
+
+// EdgeStack returns an Edge stack by ID.
+func (service *Service) EdgeStack(ID portainer.EdgeStackID) (*portainer.EdgeStack, error) {
+	var edgeStack portainer.EdgeStack
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &edgeStack)
+	if err != nil {
+		return nil, err
+	}
+
+	return &edgeStack, nil
+}
+
+// CreateEdgeStack assigns an ID to a new Edge stack if it does not have one yet and saves it.
+func (service *Service) CreateEdgeStack(edgeStack *portainer.EdgeStack) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		if edgeStack.ID == 0 {
+			id, err := internal.NextSequence(tx, TableName)
+			if err != nil {
+				return err
+			}
+			edgeStack.ID = portainer.EdgeStackID(id)
+		}
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(edgeStack.ID)), edgeStack)
+	})
+}
+
+// UpdateEdgeStack updates an Edge stack.
+func (service *Service) UpdateEdgeStack(ID portainer.EdgeStackID, edgeStack *portainer.EdgeStack) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, edgeStack)
+}
+
+// DeleteEdgeStack deletes an Edge stack.
+func (service *Service) DeleteEdgeStack(ID portainer.EdgeStackID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+// GetNextIdentifier returns the next identifier for an Edge stack.
+func (service *Service) GetNextIdentifier() int {
+	return internal.GetNextIdentifier(service.connection, TableName)
+}
@@ -0,0 +1,80 @@
+package edgestackstatushistory
+
+import (
+	"database/sql"
+	"math"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "edge_stack_status_history"
+)
+
+// Service represents a service for managing edge stack status history data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// entryKey returns the key of an entry, made of the edge stack and endpoint identifiers followed by the entry
+// identifier so that the entries of an edge stack on an endpoint are stored next to each other, in creation order.
+func entryKey(edgeStackID portainer.EdgeStackID, endpointID portainer.EndpointID, ID int) []byte {
+	key := append(internal.Itob(int(edgeStackID)), internal.Itob(int(endpointID))...)
+	return append(key, internal.Itob(ID)...)
+}
+
+// EdgeStackStatusHistory returns the history entries of an edge stack on an endpoint, ordered by creation.
+func (service *Service) EdgeStackStatusHistory(edgeStackID portainer.EdgeStackID, endpointID portainer.EndpointID) ([]portainer.EdgeStackStatusHistoryEntry, error) {
+	var entries = make([]portainer.EdgeStackStatusHistoryEntry, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, TableName, entryKey(edgeStackID, endpointID, 0), entryKey(edgeStackID, endpointID, math.MaxInt64), func(data []byte) error {
+		var entry portainer.EdgeStackStatusHistoryEntry
+		err := internal.UnmarshalObject(data, &entry)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// CreateEdgeStackStatusHistoryEntry assigns an identifier to a history entry and saves it.
+func (service *Service) CreateEdgeStackStatusHistoryEntry(entry *portainer.EdgeStackStatusHistoryEntry) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		entry.ID = id
+
+		return internal.PutObject(tx, TableName, entryKey(entry.EdgeStackID, entry.EndpointID, entry.ID), entry)
+	})
+}
+
+// DeleteEdgeStackStatusHistoryEntries deletes the history entries of an edge stack on an endpoint
+// up to the entry identified by maxID (inclusive).
+func (service *Service) DeleteEdgeStackStatusHistoryEntries(edgeStackID portainer.EdgeStackID, endpointID portainer.EndpointID, maxID int) error {
+	return internal.DeleteObjectsInRange(service.connection, TableName, entryKey(edgeStackID, endpointID, 0), entryKey(edgeStackID, endpointID, maxID))
+}
+
+// DeleteEdgeStackStatusHistory deletes the history entries of an edge stack on every endpoint.
+func (service *Service) DeleteEdgeStackStatusHistory(edgeStackID portainer.EdgeStackID) error {
+	return internal.DeleteObjectsInRange(service.connection, TableName, entryKey(edgeStackID, 0, 0), entryKey(edgeStackID, math.MaxInt64, math.MaxInt64))
+}
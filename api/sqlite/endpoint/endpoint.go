@@ -0,0 +1,125 @@
+package endpoint
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "endpoints"
+)
+
+// Service represents a service for managing endpoint data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// Endpoint returns an endpoint by ID.
+func (service *Service) Endpoint(ID portainer.EndpointID) (*portainer.Endpoint, error) {
+	var endpoint portainer.Endpoint
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// UpdateEndpoint updates an endpoint.
+func (service *Service) UpdateEndpoint(ID portainer.EndpointID, endpoint *portainer.Endpoint) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, endpoint)
+}
+
+// DeleteEndpoint deletes an endpoint.
+func (service *Service) DeleteEndpoint(ID portainer.EndpointID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+// Endpoints return an array containing all the endpoints.
+func (service *Service) Endpoints() ([]portainer.Endpoint, error) {
+	var endpoints = make([]portainer.Endpoint, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var endpoint portainer.Endpoint
+		err := internal.UnmarshalObjectWithJsoniter(data, &endpoint)
+		if err != nil {
+			return err
+		}
+		endpoints = append(endpoints, endpoint)
+
+		return nil
+	})
+
+	return endpoints, err
+}
+
+// CreateEndpoint saves a new endpoint using the identifier it already holds.
+func (service *Service) CreateEndpoint(endpoint *portainer.Endpoint) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		// We manually manage sequences for endpoints
+		err := internal.SetSequence(tx, TableName, int(endpoint.ID))
+		if err != nil {
+			return err
+		}
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(endpoint.ID)), endpoint)
+	})
+}
+
+// GetNextIdentifier returns the next identifier for an endpoint.
+func (service *Service) GetNextIdentifier() int {
+	return internal.GetNextIdentifier(service.connection, TableName)
+}
+
+// Synchronize creates, updates and deletes endpoints inside a single transaction.
+func (service *Service) Synchronize(toCreate, toUpdate, toDelete []*portainer.Endpoint) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for _, endpoint := range toCreate {
+			id, err := internal.NextSequence(tx, TableName)
+			if err != nil {
+				return err
+			}
+			endpoint.ID = portainer.EndpointID(id)
+
+			err = internal.PutObject(tx, TableName, internal.Itob(int(endpoint.ID)), endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, endpoint := range toUpdate {
+			err := internal.PutObject(tx, TableName, internal.Itob(int(endpoint.ID)), endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, endpoint := range toDelete {
+			err := internal.RemoveObject(tx, TableName, internal.Itob(int(endpoint.ID)))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
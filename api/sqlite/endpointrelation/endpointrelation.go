@@ -0,0 +1,77 @@
+package endpointrelation
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "endpoint_relations"
+)
+
+// Service represents a service for managing endpoint relation data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// EndpointRelation returns a Endpoint relation object by EndpointID
+func (service *Service) EndpointRelation(endpointID portainer.EndpointID) (*portainer.EndpointRelation, error) {
+	var endpointRelation portainer.EndpointRelation
+	identifier := internal.Itob(int(endpointID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &endpointRelation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpointRelation, nil
+}
+
+// EndpointRelations returns an array containing all the endpoint relations.
+func (service *Service) EndpointRelations() ([]portainer.EndpointRelation, error) {
+	var endpointRelations = make([]portainer.EndpointRelation, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var endpointRelation portainer.EndpointRelation
+		err := internal.UnmarshalObject(data, &endpointRelation)
+		if err != nil {
+			return err
+		}
+		endpointRelations = append(endpointRelations, endpointRelation)
+
+		return nil
+	})
+
+	return endpointRelations, err
+}
+
+// CreateEndpointRelation saves endpointRelation
+func (service *Service) CreateEndpointRelation(endpointRelation *portainer.EndpointRelation) error {
+	identifier := internal.Itob(int(endpointRelation.EndpointID))
+	return internal.UpdateObject(service.connection, TableName, identifier, endpointRelation)
+}
+
+// UpdateEndpointRelation updates an Endpoint relation object
+func (service *Service) UpdateEndpointRelation(EndpointID portainer.EndpointID, endpointRelation *portainer.EndpointRelation) error {
+	identifier := internal.Itob(int(EndpointID))
+	return internal.UpdateObject(service.connection, TableName, identifier, endpointRelation)
+}
+
+// DeleteEndpointRelation deletes an Endpoint relation object
+func (service *Service) DeleteEndpointRelation(EndpointID portainer.EndpointID) error {
+	identifier := internal.Itob(int(EndpointID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
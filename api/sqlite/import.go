@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/portainer/portainer/api/sqlite/customtemplate"
+	"github.com/portainer/portainer/api/sqlite/dockerpolicy"
+	"github.com/portainer/portainer/api/sqlite/edgeasynccommand"
+	"github.com/portainer/portainer/api/sqlite/edgedevice"
+	"github.com/portainer/portainer/api/sqlite/edgegroup"
+	"github.com/portainer/portainer/api/sqlite/edgejob"
+	"github.com/portainer/portainer/api/sqlite/edgejobrun"
+	"github.com/portainer/portainer/api/sqlite/edgestack"
+	"github.com/portainer/portainer/api/sqlite/edgestackstatushistory"
+	"github.com/portainer/portainer/api/sqlite/endpoint"
+	"github.com/portainer/portainer/api/sqlite/endpointgroup"
+	"github.com/portainer/portainer/api/sqlite/endpointrelation"
+	"github.com/portainer/portainer/api/sqlite/internal"
+	"github.com/portainer/portainer/api/sqlite/prunepolicy"
+	"github.com/portainer/portainer/api/sqlite/prunepolicyrun"
+	"github.com/portainer/portainer/api/sqlite/registry"
+	"github.com/portainer/portainer/api/sqlite/resourcecontrol"
+	"github.com/portainer/portainer/api/sqlite/role"
+	"github.com/portainer/portainer/api/sqlite/settings"
+	"github.com/portainer/portainer/api/sqlite/snapshothistory"
+	"github.com/portainer/portainer/api/sqlite/stack"
+	"github.com/portainer/portainer/api/sqlite/tag"
+	"github.com/portainer/portainer/api/sqlite/team"
+	"github.com/portainer/portainer/api/sqlite/teammembership"
+	"github.com/portainer/portainer/api/sqlite/tunnelevent"
+	"github.com/portainer/portainer/api/sqlite/tunnelserver"
+	"github.com/portainer/portainer/api/sqlite/user"
+	"github.com/portainer/portainer/api/sqlite/version"
+	"github.com/portainer/portainer/api/sqlite/webhook"
+)
+
+// tableNames lists every table that can be imported from a BoltDB database.
+// Tables share their name with the BoltDB bucket holding the same data.
+var tableNames = []string{
+	customtemplate.TableName,
+	dockerpolicy.TableName,
+	edgeasynccommand.TableName,
+	edgedevice.TableName,
+	edgegroup.TableName,
+	edgejob.TableName,
+	edgejobrun.TableName,
+	edgestack.TableName,
+	edgestackstatushistory.TableName,
+	endpoint.TableName,
+	endpointgroup.TableName,
+	endpointrelation.TableName,
+	prunepolicy.TableName,
+	prunepolicyrun.TableName,
+	registry.TableName,
+	resourcecontrol.TableName,
+	role.TableName,
+	settings.TableName,
+	snapshothistory.TableName,
+	stack.TableName,
+	tag.TableName,
+	team.TableName,
+	teammembership.TableName,
+	tunnelevent.TableName,
+	tunnelserver.TableName,
+	user.TableName,
+	version.TableName,
+	webhook.TableName,
+}
+
+// ImportBoltDatabase copies the content of a BoltDB database into the store.
+// Keys, values and bucket sequences are copied as-is, the BoltDB database must
+// have been migrated to the current DBVersion beforehand. The store must be opened and empty.
+func (store *Store) ImportBoltDatabase(boltDatabasePath string) error {
+	db, err := bolt.Open(boltDatabasePath, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.View(func(boltTx *bolt.Tx) error {
+		return store.connection.Update(func(tx *sql.Tx) error {
+			for _, tableName := range tableNames {
+				bucket := boltTx.Bucket([]byte(tableName))
+				if bucket == nil {
+					continue
+				}
+
+				err := bucket.ForEach(func(k, v []byte) error {
+					return internal.PutRawObject(tx, tableName, k, v)
+				})
+				if err != nil {
+					return err
+				}
+
+				err = internal.SetSequence(tx, tableName, int(bucket.Sequence()))
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	store.isNew = false
+
+	return store.rebuildIndexes()
+}
+
+// rebuildIndexes populates the index columns of every indexed table after a raw import.
+func (store *Store) rebuildIndexes() error {
+	services := []interface{ RebuildIndexes() error }{
+		store.EdgeDeviceService,
+		store.ResourceControlService,
+		store.StackService,
+		store.TeamMembershipService,
+		store.TeamService,
+		store.UserService,
+		store.WebhookService,
+	}
+
+	for _, service := range services {
+		err := service.RebuildIndexes()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
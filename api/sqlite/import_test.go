@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt"
+	"github.com/portainer/portainer/api/bolt/bolttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportBoltDatabase(t *testing.T) {
+	boltStore, teardown := bolttest.MustNewTestStore(true)
+	defer teardown()
+
+	require.NoError(t, boltStore.Version().StoreDBVersion(portainer.DBVersion))
+	require.NoError(t, boltStore.User().CreateUser(&portainer.User{Username: "admin", Role: portainer.AdministratorRole}))
+	require.NoError(t, boltStore.User().CreateUser(&portainer.User{Username: "user", Role: portainer.StandardUserRole}))
+	require.NoError(t, boltStore.User().DeleteUser(2))
+	require.NoError(t, boltStore.Webhook().CreateWebhook(&portainer.Webhook{Token: "token", ResourceID: "service"}))
+
+	// The bolt test store is kept open, the database is exported to a new file instead
+	var backup bytes.Buffer
+	require.NoError(t, boltStore.BackupTo(&backup))
+
+	dataStorePath, err := ioutil.TempDir("", "sqlite")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataStorePath)
+
+	boltDatabasePath := filepath.Join(dataStorePath, bolt.DatabaseFileName)
+	require.NoError(t, ioutil.WriteFile(boltDatabasePath, backup.Bytes(), 0600))
+
+	store := newTestStore(t, dataStorePath)
+	defer store.Close()
+
+	require.NoError(t, store.ImportBoltDatabase(boltDatabasePath))
+	assert.False(t, store.IsNew())
+
+	version, err := store.Version().DBVersion()
+	require.NoError(t, err)
+	assert.Equal(t, portainer.DBVersion, version)
+
+	user, err := store.User().UserByUsername("admin")
+	require.NoError(t, err)
+	assert.Equal(t, portainer.UserID(1), user.ID)
+
+	webhook, err := store.Webhook().WebhookByToken("token")
+	require.NoError(t, err)
+	assert.Equal(t, "service", webhook.ResourceID)
+
+	groups, err := store.EndpointGroup().EndpointGroups()
+	require.NoError(t, err)
+	assert.Len(t, groups, 1)
+
+	newUser := &portainer.User{Username: "new"}
+	require.NoError(t, store.User().CreateUser(newUser))
+	assert.Equal(t, portainer.UserID(3), newUser.ID, "sequences must be imported")
+
+	assert.NoError(t, store.MigrateData(false))
+}
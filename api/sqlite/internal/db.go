@@ -0,0 +1,289 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/portainer/portainer/api/bolt/errors"
+)
+
+const sequenceTableName = "sequences"
+
+// DbConnection wraps the SQLite database handle shared by every service.
+type DbConnection struct {
+	*sql.DB
+}
+
+// Index represents the value of an indexed column associated to an object.
+// Indexed columns are stored next to the serialized object so that lookups
+// do not require a full table scan.
+type Index struct {
+	Column string
+	Value  interface{}
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Update executes a function inside a read-write transaction. The transaction
+// is committed if the function returns nil and rolled back otherwise.
+func (connection *DbConnection) Update(fn func(tx *sql.Tx) error) error {
+	tx, err := connection.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Itob returns an 8-byte big endian representation of v.
+// Keys are encoded the same way as in the BoltDB store so that
+// rows are ordered by identifier and data can be copied as-is between stores.
+func Itob(v int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
+
+// CreateSequenceTable creates the table used to store the identifier sequence of every table.
+func CreateSequenceTable(connection *DbConnection) error {
+	_, err := connection.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, value INTEGER NOT NULL)`, quote(sequenceTableName)))
+	return err
+}
+
+// CreateTable is a generic function used to create a table inside a SQLite database.
+// Each index column is created alongside the serialized object and gets its own index.
+func CreateTable(connection *DbConnection, tableName string, indexColumns ...string) error {
+	return connection.Update(func(tx *sql.Tx) error {
+		columns := []string{"id BLOB PRIMARY KEY", "data BLOB NOT NULL"}
+		columns = append(columns, indexColumns...)
+
+		_, err := tx.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s)`, quote(tableName), strings.Join(columns, ", ")))
+		if err != nil {
+			return err
+		}
+
+		for _, column := range indexColumns {
+			indexName := quote(tableName + "_" + column)
+			_, err := tx.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (%s)`, indexName, quote(tableName), column))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetObject is a generic function used to retrieve an unmarshalled object from a SQLite database.
+func GetObject(connection *DbConnection, tableName string, key []byte, object interface{}) error {
+	data, err := GetRawObject(connection, tableName, key)
+	if err != nil {
+		return err
+	}
+
+	return UnmarshalObject(data, object)
+}
+
+// GetRawObject is a generic function used to retrieve the raw content associated to a key.
+func GetRawObject(connection *DbConnection, tableName string, key []byte) ([]byte, error) {
+	var data []byte
+
+	err := connection.QueryRow(fmt.Sprintf(`SELECT data FROM %s WHERE id = ?`, quote(tableName)), key).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, errors.ErrObjectNotFound
+	}
+
+	return data, err
+}
+
+// FindObject is a generic function used to retrieve the first unmarshalled object
+// matching every index condition.
+func FindObject(connection *DbConnection, tableName string, object interface{}, conditions ...Index) error {
+	where, args := whereClause(conditions)
+
+	var data []byte
+	err := connection.QueryRow(fmt.Sprintf(`SELECT data FROM %s%s ORDER BY id LIMIT 1`, quote(tableName), where), args...).Scan(&data)
+	if err == sql.ErrNoRows {
+		return errors.ErrObjectNotFound
+	} else if err != nil {
+		return err
+	}
+
+	return UnmarshalObject(data, object)
+}
+
+// ForEachObject is a generic function that calls fn with the raw content of every object
+// matching the index conditions, ordered by key.
+func ForEachObject(connection *DbConnection, tableName string, fn func(data []byte) error, conditions ...Index) error {
+	where, args := whereClause(conditions)
+
+	rows, err := connection.Query(fmt.Sprintf(`SELECT data FROM %s%s ORDER BY id`, quote(tableName), where), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return err
+		}
+
+		err = fn(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ForEachObjectInRange is a generic function that calls fn with the raw content of every object
+// whose key is between fromKey and toKey (inclusive), ordered by key.
+func ForEachObjectInRange(connection *DbConnection, tableName string, fromKey, toKey []byte, fn func(data []byte) error) error {
+	rows, err := connection.Query(fmt.Sprintf(`SELECT data FROM %s WHERE id >= ? AND id <= ? ORDER BY id`, quote(tableName)), fromKey, toKey)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		err := rows.Scan(&data)
+		if err != nil {
+			return err
+		}
+
+		err = fn(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// UpdateObject is a generic function used to update an object inside a SQLite database.
+func UpdateObject(connection *DbConnection, tableName string, key []byte, object interface{}, indexes ...Index) error {
+	return PutObject(connection.DB, tableName, key, object, indexes...)
+}
+
+// PutObject marshals and stores an object using either a connection or a transaction.
+func PutObject(q queryer, tableName string, key []byte, object interface{}, indexes ...Index) error {
+	data, err := MarshalObject(object)
+	if err != nil {
+		return err
+	}
+
+	return PutRawObject(q, tableName, key, data, indexes...)
+}
+
+// PutRawObject stores raw content using either a connection or a transaction.
+func PutRawObject(q queryer, tableName string, key []byte, data []byte, indexes ...Index) error {
+	columns := []string{"id", "data"}
+	placeholders := []string{"?", "?"}
+	args := []interface{}{key, data}
+
+	for _, index := range indexes {
+		columns = append(columns, index.Column)
+		placeholders = append(placeholders, "?")
+		args = append(args, index.Value)
+	}
+
+	query := fmt.Sprintf(`INSERT OR REPLACE INTO %s (%s) VALUES (%s)`, quote(tableName), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	_, err := q.Exec(query, args...)
+	return err
+}
+
+// DeleteObject is a generic function used to delete an object inside a SQLite database.
+func DeleteObject(connection *DbConnection, tableName string, key []byte) error {
+	return RemoveObject(connection.DB, tableName, key)
+}
+
+// RemoveObject deletes an object using either a connection or a transaction.
+func RemoveObject(q queryer, tableName string, key []byte) error {
+	_, err := q.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, quote(tableName)), key)
+	return err
+}
+
+// DeleteObjects is a generic function used to delete every object matching the index conditions.
+func DeleteObjects(connection *DbConnection, tableName string, conditions ...Index) error {
+	where, args := whereClause(conditions)
+
+	_, err := connection.Exec(fmt.Sprintf(`DELETE FROM %s%s`, quote(tableName), where), args...)
+	return err
+}
+
+// DeleteObjectsInRange is a generic function used to delete every object whose key is between
+// fromKey and toKey (inclusive).
+func DeleteObjectsInRange(connection *DbConnection, tableName string, fromKey, toKey []byte) error {
+	_, err := connection.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id >= ? AND id <= ?`, quote(tableName)), fromKey, toKey)
+	return err
+}
+
+// GetNextIdentifier is a generic function that returns the specified table identifier incremented by 1.
+func GetNextIdentifier(connection *DbConnection, tableName string) int {
+	var identifier int
+
+	connection.Update(func(tx *sql.Tx) error {
+		id, err := NextSequence(tx, tableName)
+		if err != nil {
+			return err
+		}
+		identifier = id
+		return nil
+	})
+
+	return identifier
+}
+
+// NextSequence increments and returns the identifier sequence of a table.
+func NextSequence(tx *sql.Tx, tableName string) (int, error) {
+	_, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s (name, value) VALUES (?, 1) ON CONFLICT(name) DO UPDATE SET value = value + 1`, quote(sequenceTableName)), tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = tx.QueryRow(fmt.Sprintf(`SELECT value FROM %s WHERE name = ?`, quote(sequenceTableName)), tableName).Scan(&id)
+	return id, err
+}
+
+// SetSequence sets the identifier sequence of a table.
+func SetSequence(q queryer, tableName string, value int) error {
+	_, err := q.Exec(fmt.Sprintf(`INSERT INTO %s (name, value) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET value = excluded.value`, quote(sequenceTableName)), tableName, value)
+	return err
+}
+
+func whereClause(conditions []Index) (string, []interface{}) {
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	clauses := make([]string, 0, len(conditions))
+	args := make([]interface{}, 0, len(conditions))
+	for _, condition := range conditions {
+		clauses = append(clauses, condition.Column+" = ?")
+		args = append(args, condition.Value)
+	}
+
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func quote(identifier string) string {
+	return `"` + identifier + `"`
+}
@@ -0,0 +1,22 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+)
+
+// Compact rebuilds the database file stored inside storePath, reclaiming the space
+// left free by deleted data. The database must not be opened by another process.
+func Compact(storePath string) error {
+	databasePath := path.Join(storePath, DatabaseFileName)
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000", databasePath))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`VACUUM`)
+	return err
+}
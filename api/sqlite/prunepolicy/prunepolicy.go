@@ -0,0 +1,86 @@
+package prunepolicy
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "prune_policies"
+)
+
+// Service represents a service for managing prune policy data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// PrunePolicies returns an array containing all the prune policies.
+func (service *Service) PrunePolicies() ([]portainer.PrunePolicy, error) {
+	var policies = make([]portainer.PrunePolicy, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var policy portainer.PrunePolicy
+		err := internal.UnmarshalObject(data, &policy)
+		if err != nil {
+			return err
+		}
+		policies = append(policies, policy)
+
+		return nil
+	})
+
+	return policies, err
+}
+
+// PrunePolicy returns a prune policy by ID.
+func (service *Service) PrunePolicy(ID portainer.PrunePolicyID) (*portainer.PrunePolicy, error) {
+	var policy portainer.PrunePolicy
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// CreatePrunePolicy assigns an ID to a new prune policy and saves it.
+func (service *Service) CreatePrunePolicy(policy *portainer.PrunePolicy) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		policy.ID = portainer.PrunePolicyID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(policy.ID)), policy)
+	})
+}
+
+// UpdatePrunePolicy updates a prune policy.
+func (service *Service) UpdatePrunePolicy(ID portainer.PrunePolicyID, policy *portainer.PrunePolicy) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, policy)
+}
+
+// DeletePrunePolicy deletes a prune policy.
+func (service *Service) DeletePrunePolicy(ID portainer.PrunePolicyID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
@@ -0,0 +1,89 @@
+package prunepolicyrun
+
+import (
+	"database/sql"
+	"math"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "prune_policy_runs"
+)
+
+// Service represents a service for managing prune policy run data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// runKey returns the key of a run, made of the prune policy and endpoint identifiers followed by the run
+// identifier so that the runs of a prune policy on an endpoint are stored next to each other, in creation order.
+func runKey(policyID portainer.PrunePolicyID, endpointID portainer.EndpointID, ID int) []byte {
+	key := append(internal.Itob(int(policyID)), internal.Itob(int(endpointID))...)
+	return append(key, internal.Itob(ID)...)
+}
+
+// PrunePolicyRuns returns the runs of a prune policy on every endpoint, ordered by endpoint and creation.
+func (service *Service) PrunePolicyRuns(policyID portainer.PrunePolicyID) ([]portainer.PrunePolicyRun, error) {
+	return service.runsInRange(runKey(policyID, 0, 0), runKey(policyID, math.MaxInt64, math.MaxInt64))
+}
+
+// PrunePolicyEndpointRuns returns the runs of a prune policy on an endpoint, ordered by creation.
+func (service *Service) PrunePolicyEndpointRuns(policyID portainer.PrunePolicyID, endpointID portainer.EndpointID) ([]portainer.PrunePolicyRun, error) {
+	return service.runsInRange(runKey(policyID, endpointID, 0), runKey(policyID, endpointID, math.MaxInt64))
+}
+
+func (service *Service) runsInRange(min, max []byte) ([]portainer.PrunePolicyRun, error) {
+	var runs = make([]portainer.PrunePolicyRun, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, TableName, min, max, func(data []byte) error {
+		var run portainer.PrunePolicyRun
+		err := internal.UnmarshalObject(data, &run)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+
+		return nil
+	})
+
+	return runs, err
+}
+
+// CreatePrunePolicyRun assigns an identifier to a run and saves it.
+func (service *Service) CreatePrunePolicyRun(run *portainer.PrunePolicyRun) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		run.ID = id
+
+		return internal.PutObject(tx, TableName, runKey(run.PrunePolicyID, run.EndpointID, run.ID), run)
+	})
+}
+
+// DeletePrunePolicyEndpointRuns deletes the runs of a prune policy on an endpoint
+// up to the run identified by maxID (inclusive).
+func (service *Service) DeletePrunePolicyEndpointRuns(policyID portainer.PrunePolicyID, endpointID portainer.EndpointID, maxID int) error {
+	return internal.DeleteObjectsInRange(service.connection, TableName, runKey(policyID, endpointID, 0), runKey(policyID, endpointID, maxID))
+}
+
+// DeletePrunePolicyRuns deletes the runs of a prune policy on every endpoint.
+func (service *Service) DeletePrunePolicyRuns(policyID portainer.PrunePolicyID) error {
+	return internal.DeleteObjectsInRange(service.connection, TableName, runKey(policyID, 0, 0), runKey(policyID, math.MaxInt64, math.MaxInt64))
+}
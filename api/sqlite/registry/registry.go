@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "registries"
+)
+
+// Service represents a service for managing registry data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// Registries return an array containing all the registries.
+func (service *Service) Registries() ([]portainer.Registry, error) {
+	var registries = make([]portainer.Registry, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var registry portainer.Registry
+		err := internal.UnmarshalObject(data, &registry)
+		if err != nil {
+			return err
+		}
+		registries = append(registries, registry)
+
+		return nil
+	})
+
+	return registries, err
+}
+
+// Registry returns a registry by ID.
+func (service *Service) Registry(ID portainer.RegistryID) (*portainer.Registry, error) {
+	var registry portainer.Registry
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &registry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &registry, nil
+}
+
+// CreateRegistry assigns an ID to a new registry and saves it.
+func (service *Service) CreateRegistry(registry *portainer.Registry) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		registry.ID = portainer.RegistryID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(registry.ID)), registry)
+	})
+}
+
+// UpdateRegistry updates a registry.
+func (service *Service) UpdateRegistry(ID portainer.RegistryID, registry *portainer.Registry) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, registry)
+}
+
+// DeleteRegistry deletes a registry.
+func (service *Service) DeleteRegistry(ID portainer.RegistryID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
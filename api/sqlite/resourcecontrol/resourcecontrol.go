@@ -0,0 +1,162 @@
+package resourcecontrol
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/bolt/errors"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "resource_control"
+
+	resourceIDColumn        = "resource_id"
+	typeColumn              = "type"
+	hasSubResourceIDsColumn = "has_sub_resource_ids"
+)
+
+// Service represents a service for managing resource control data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName, resourceIDColumn, typeColumn, hasSubResourceIDsColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// ResourceControl returns a ResourceControl object by ID
+func (service *Service) ResourceControl(ID portainer.ResourceControlID) (*portainer.ResourceControl, error) {
+	var resourceControl portainer.ResourceControl
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &resourceControl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resourceControl, nil
+}
+
+// ResourceControlByResourceIDAndType returns a ResourceControl object by checking if the resourceID is equal
+// to the main ResourceID or in SubResourceIDs. It also performs a check on the resource type. Return nil
+// if no ResourceControl was found.
+func (service *Service) ResourceControlByResourceIDAndType(resourceID string, resourceType portainer.ResourceControlType) (*portainer.ResourceControl, error) {
+	var resourceControl portainer.ResourceControl
+
+	err := internal.FindObject(service.connection, TableName, &resourceControl,
+		internal.Index{Column: resourceIDColumn, Value: resourceID},
+		internal.Index{Column: typeColumn, Value: int(resourceType)},
+	)
+	if err == nil {
+		return &resourceControl, nil
+	} else if err != errors.ErrObjectNotFound {
+		return nil, err
+	}
+
+	// Sub resource identifiers are not indexed, only the resource controls holding some are scanned
+	var subResourceControl *portainer.ResourceControl
+	err = internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		if subResourceControl != nil {
+			return nil
+		}
+
+		var rc portainer.ResourceControl
+		err := internal.UnmarshalObject(data, &rc)
+		if err != nil {
+			return err
+		}
+
+		for _, subResourceID := range rc.SubResourceIDs {
+			if subResourceID == resourceID {
+				subResourceControl = &rc
+				break
+			}
+		}
+
+		return nil
+	}, internal.Index{Column: hasSubResourceIDsColumn, Value: true})
+
+	return subResourceControl, err
+}
+
+// ResourceControls returns all the ResourceControl objects
+func (service *Service) ResourceControls() ([]portainer.ResourceControl, error) {
+	var rcs = make([]portainer.ResourceControl, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var resourceControl portainer.ResourceControl
+		err := internal.UnmarshalObject(data, &resourceControl)
+		if err != nil {
+			return err
+		}
+		rcs = append(rcs, resourceControl)
+
+		return nil
+	})
+
+	return rcs, err
+}
+
+// CreateResourceControl creates a new ResourceControl object
+func (service *Service) CreateResourceControl(resourceControl *portainer.ResourceControl) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		resourceControl.ID = portainer.ResourceControlID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(resourceControl.ID)), resourceControl, indexes(resourceControl)...)
+	})
+}
+
+// UpdateResourceControl saves a ResourceControl object.
+func (service *Service) UpdateResourceControl(ID portainer.ResourceControlID, resourceControl *portainer.ResourceControl) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, resourceControl, indexes(resourceControl)...)
+}
+
+// RebuildIndexes saves every resource control again to populate the index columns,
+// it is used after data was copied into the table without going through the service.
+func (service *Service) RebuildIndexes() error {
+	resourceControls, err := service.ResourceControls()
+	if err != nil {
+		return err
+	}
+
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for i := range resourceControls {
+			resourceControl := &resourceControls[i]
+			err := internal.PutObject(tx, TableName, internal.Itob(int(resourceControl.ID)), resourceControl, indexes(resourceControl)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteResourceControl deletes a ResourceControl object by ID
+func (service *Service) DeleteResourceControl(ID portainer.ResourceControlID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+func indexes(resourceControl *portainer.ResourceControl) []internal.Index {
+	return []internal.Index{
+		{Column: resourceIDColumn, Value: resourceControl.ResourceID},
+		{Column: typeColumn, Value: int(resourceControl.Type)},
+		{Column: hasSubResourceIDsColumn, Value: len(resourceControl.SubResourceIDs) > 0},
+	}
+}
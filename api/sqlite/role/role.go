@@ -0,0 +1,80 @@
+package role
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "roles"
+)
+
+// Service represents a service for managing role data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// Roles return an array containing all the roles.
+func (service *Service) Roles() ([]portainer.Role, error) {
+	var roles = make([]portainer.Role, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var role portainer.Role
+		err := internal.UnmarshalObject(data, &role)
+		if err != nil {
+			return err
+		}
+		roles = append(roles, role)
+
+		return nil
+	})
+
+	return roles, err
+}
+
+// Role returns a role by ID.
+func (service *Service) Role(ID portainer.RoleID) (*portainer.Role, error) {
+	var role portainer.Role
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// CreateRole assigns an ID to a new role and saves it.
+func (service *Service) CreateRole(role *portainer.Role) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		role.ID = portainer.RoleID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(role.ID)), role)
+	})
+}
+
+// UpdateRole updates a role.
+func (service *Service) UpdateRole(ID portainer.RoleID, role *portainer.Role) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, role)
+}
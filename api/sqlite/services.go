@@ -0,0 +1,345 @@
+package sqlite
+
+import (
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/customtemplate"
+	"github.com/portainer/portainer/api/sqlite/dockerpolicy"
+	"github.com/portainer/portainer/api/sqlite/edgeasynccommand"
+	"github.com/portainer/portainer/api/sqlite/edgedevice"
+	"github.com/portainer/portainer/api/sqlite/edgegroup"
+	"github.com/portainer/portainer/api/sqlite/edgejob"
+	"github.com/portainer/portainer/api/sqlite/edgejobrun"
+	"github.com/portainer/portainer/api/sqlite/edgestack"
+	"github.com/portainer/portainer/api/sqlite/edgestackstatushistory"
+	"github.com/portainer/portainer/api/sqlite/endpoint"
+	"github.com/portainer/portainer/api/sqlite/endpointgroup"
+	"github.com/portainer/portainer/api/sqlite/endpointrelation"
+	"github.com/portainer/portainer/api/sqlite/prunepolicy"
+	"github.com/portainer/portainer/api/sqlite/prunepolicyrun"
+	"github.com/portainer/portainer/api/sqlite/registry"
+	"github.com/portainer/portainer/api/sqlite/resourcecontrol"
+	"github.com/portainer/portainer/api/sqlite/role"
+	"github.com/portainer/portainer/api/sqlite/settings"
+	"github.com/portainer/portainer/api/sqlite/snapshothistory"
+	"github.com/portainer/portainer/api/sqlite/stack"
+	"github.com/portainer/portainer/api/sqlite/tag"
+	"github.com/portainer/portainer/api/sqlite/team"
+	"github.com/portainer/portainer/api/sqlite/teammembership"
+	"github.com/portainer/portainer/api/sqlite/tunnelevent"
+	"github.com/portainer/portainer/api/sqlite/tunnelserver"
+	"github.com/portainer/portainer/api/sqlite/user"
+	"github.com/portainer/portainer/api/sqlite/version"
+	"github.com/portainer/portainer/api/sqlite/webhook"
+)
+
+func (store *Store) initServices() error {
+	authorizationsetService, err := role.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.RoleService = authorizationsetService
+
+	customTemplateService, err := customtemplate.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.CustomTemplateService = customTemplateService
+
+	dockerPolicyService, err := dockerpolicy.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.DockerPolicyService = dockerPolicyService
+
+	edgeStackService, err := edgestack.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeStackService = edgeStackService
+
+	edgeStackStatusHistoryService, err := edgestackstatushistory.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeStackStatusHistoryService = edgeStackStatusHistoryService
+
+	edgeAsyncCommandService, err := edgeasynccommand.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeAsyncCommandService = edgeAsyncCommandService
+
+	edgeDeviceService, err := edgedevice.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeDeviceService = edgeDeviceService
+
+	edgeGroupService, err := edgegroup.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeGroupService = edgeGroupService
+
+	edgeJobService, err := edgejob.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeJobService = edgeJobService
+
+	edgeJobRunService, err := edgejobrun.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EdgeJobRunService = edgeJobRunService
+
+	endpointgroupService, err := endpointgroup.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EndpointGroupService = endpointgroupService
+
+	endpointService, err := endpoint.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EndpointService = endpointService
+
+	endpointRelationService, err := endpointrelation.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.EndpointRelationService = endpointRelationService
+
+	prunePolicyService, err := prunepolicy.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.PrunePolicyService = prunePolicyService
+
+	prunePolicyRunService, err := prunepolicyrun.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.PrunePolicyRunService = prunePolicyRunService
+
+	registryService, err := registry.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.RegistryService = registryService
+
+	resourcecontrolService, err := resourcecontrol.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.ResourceControlService = resourcecontrolService
+
+	settingsService, err := settings.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.SettingsService = settingsService
+
+	snapshotHistoryService, err := snapshothistory.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.SnapshotHistoryService = snapshotHistoryService
+
+	stackService, err := stack.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.StackService = stackService
+
+	tagService, err := tag.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.TagService = tagService
+
+	teammembershipService, err := teammembership.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.TeamMembershipService = teammembershipService
+
+	teamService, err := team.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.TeamService = teamService
+
+	tunnelServerService, err := tunnelserver.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.TunnelServerService = tunnelServerService
+
+	tunnelEventService, err := tunnelevent.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.TunnelEventService = tunnelEventService
+
+	userService, err := user.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.UserService = userService
+
+	versionService, err := version.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.VersionService = versionService
+
+	webhookService, err := webhook.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.WebhookService = webhookService
+
+	return nil
+}
+
+// CustomTemplate gives access to the CustomTemplate data management layer
+func (store *Store) CustomTemplate() portainer.CustomTemplateService {
+	return store.CustomTemplateService
+}
+
+// DockerPolicy gives access to the DockerPolicy data management layer
+func (store *Store) DockerPolicy() portainer.DockerPolicyService {
+	return store.DockerPolicyService
+}
+
+// EdgeAsyncCommand gives access to the EdgeAsyncCommand data management layer
+func (store *Store) EdgeAsyncCommand() portainer.EdgeAsyncCommandService {
+	return store.EdgeAsyncCommandService
+}
+
+// EdgeDevice gives access to the EdgeDevice data management layer
+func (store *Store) EdgeDevice() portainer.EdgeDeviceService {
+	return store.EdgeDeviceService
+}
+
+// EdgeGroup gives access to the EdgeGroup data management layer
+func (store *Store) EdgeGroup() portainer.EdgeGroupService {
+	return store.EdgeGroupService
+}
+
+// EdgeJob gives access to the EdgeJob data management layer
+func (store *Store) EdgeJob() portainer.EdgeJobService {
+	return store.EdgeJobService
+}
+
+// EdgeJobRun gives access to the EdgeJobRun data management layer
+func (store *Store) EdgeJobRun() portainer.EdgeJobRunService {
+	return store.EdgeJobRunService
+}
+
+// EdgeStack gives access to the EdgeStack data management layer
+func (store *Store) EdgeStack() portainer.EdgeStackService {
+	return store.EdgeStackService
+}
+
+// EdgeStackStatusHistory gives access to the EdgeStackStatusHistory data management layer
+func (store *Store) EdgeStackStatusHistory() portainer.EdgeStackStatusHistoryService {
+	return store.EdgeStackStatusHistoryService
+}
+
+// Endpoint gives access to the Endpoint data management layer
+func (store *Store) Endpoint() portainer.EndpointService {
+	return store.EndpointService
+}
+
+// EndpointGroup gives access to the EndpointGroup data management layer
+func (store *Store) EndpointGroup() portainer.EndpointGroupService {
+	return store.EndpointGroupService
+}
+
+// EndpointRelation gives access to the EndpointRelation data management layer
+func (store *Store) EndpointRelation() portainer.EndpointRelationService {
+	return store.EndpointRelationService
+}
+
+// PrunePolicy gives access to the PrunePolicy data management layer
+func (store *Store) PrunePolicy() portainer.PrunePolicyService {
+	return store.PrunePolicyService
+}
+
+// PrunePolicyRun gives access to the PrunePolicyRun data management layer
+func (store *Store) PrunePolicyRun() portainer.PrunePolicyRunService {
+	return store.PrunePolicyRunService
+}
+
+// Registry gives access to the Registry data management layer
+func (store *Store) Registry() portainer.RegistryService {
+	return store.RegistryService
+}
+
+// ResourceControl gives access to the ResourceControl data management layer
+func (store *Store) ResourceControl() portainer.ResourceControlService {
+	return store.ResourceControlService
+}
+
+// Role gives access to the Role data management layer
+func (store *Store) Role() portainer.RoleService {
+	return store.RoleService
+}
+
+// Settings gives access to the Settings data management layer
+func (store *Store) Settings() portainer.SettingsService {
+	return store.SettingsService
+}
+
+// SnapshotHistory gives access to the SnapshotHistory data management layer
+func (store *Store) SnapshotHistory() portainer.SnapshotHistoryService {
+	return store.SnapshotHistoryService
+}
+
+// Stack gives access to the Stack data management layer
+func (store *Store) Stack() portainer.StackService {
+	return store.StackService
+}
+
+// Tag gives access to the Tag data management layer
+func (store *Store) Tag() portainer.TagService {
+	return store.TagService
+}
+
+// TeamMembership gives access to the TeamMembership data management layer
+func (store *Store) TeamMembership() portainer.TeamMembershipService {
+	return store.TeamMembershipService
+}
+
+// Team gives access to the Team data management layer
+func (store *Store) Team() portainer.TeamService {
+	return store.TeamService
+}
+
+// TunnelServer gives access to the TunnelServer data management layer
+func (store *Store) TunnelServer() portainer.TunnelServerService {
+	return store.TunnelServerService
+}
+
+// TunnelEvent gives access to the TunnelEvent data management layer
+func (store *Store) TunnelEvent() portainer.TunnelEventService {
+	return store.TunnelEventService
+}
+
+// User gives access to the User data management layer
+func (store *Store) User() portainer.UserService {
+	return store.UserService
+}
+
+// Version gives access to the Version data management layer
+func (store *Store) Version() portainer.VersionService {
+	return store.VersionService
+}
+
+// Webhook gives access to the Webhook data management layer
+func (store *Store) Webhook() portainer.WebhookService {
+	return store.WebhookService
+}
@@ -0,0 +1,74 @@
+package snapshothistory
+
+import (
+	"database/sql"
+	"math"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "snapshot_history"
+)
+
+// Service represents a service for managing endpoint snapshot history data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// entryKey returns the key of an entry, made of the endpoint identifier followed by the entry time
+// so that the entries of an endpoint are stored next to each other, ordered by time.
+// The key ends with a sequence number as several entries of an endpoint can be recorded within the same second.
+func entryKey(endpointID portainer.EndpointID, time int64, sequence int) []byte {
+	key := append(internal.Itob(int(endpointID)), internal.Itob(int(time))...)
+	return append(key, internal.Itob(sequence)...)
+}
+
+// SnapshotHistory returns the history entries of an endpoint between from and to (inclusive), ordered by time.
+func (service *Service) SnapshotHistory(endpointID portainer.EndpointID, from, to int64) ([]portainer.SnapshotHistoryEntry, error) {
+	var entries = make([]portainer.SnapshotHistoryEntry, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, TableName, entryKey(endpointID, from, 0), entryKey(endpointID, to, math.MaxInt64), func(data []byte) error {
+		var entry portainer.SnapshotHistoryEntry
+		err := internal.UnmarshalObject(data, &entry)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// CreateSnapshotHistoryEntry saves a history entry.
+func (service *Service) CreateSnapshotHistoryEntry(entry *portainer.SnapshotHistoryEntry) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		sequence, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+
+		return internal.PutObject(tx, TableName, entryKey(entry.EndpointID, entry.Time, sequence), entry)
+	})
+}
+
+// DeleteSnapshotHistory deletes the history entries of an endpoint between from and to (inclusive).
+func (service *Service) DeleteSnapshotHistory(endpointID portainer.EndpointID, from, to int64) error {
+	return internal.DeleteObjectsInRange(service.connection, TableName, entryKey(endpointID, from, 0), entryKey(endpointID, to, math.MaxInt64))
+}
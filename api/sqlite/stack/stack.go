@@ -0,0 +1,132 @@
+package stack
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "stacks"
+
+	nameColumn = "name"
+)
+
+// Service represents a service for managing stack data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName, nameColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// Stack returns a stack object by ID.
+func (service *Service) Stack(ID portainer.StackID) (*portainer.Stack, error) {
+	var stack portainer.Stack
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &stack)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stack, nil
+}
+
+// StackByName returns a stack object by name.
+func (service *Service) StackByName(name string) (*portainer.Stack, error) {
+	var stack portainer.Stack
+
+	err := internal.FindObject(service.connection, TableName, &stack, internal.Index{Column: nameColumn, Value: name})
+	if err != nil {
+		return nil, err
+	}
+
+	return &stack, nil
+}
+
+// Stacks returns an array containing all the stacks.
+func (service *Service) Stacks() ([]portainer.Stack, error) {
+	var stacks = make([]portainer.Stack, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var stack portainer.Stack
+		err := internal.UnmarshalObject(data, &stack)
+		if err != nil {
+			return err
+		}
+		stacks = append(stacks, stack)
+
+		return nil
+	})
+
+	return stacks, err
+}
+
+// GetNextIdentifier returns the next identifier for a stack.
+func (service *Service) GetNextIdentifier() int {
+	return internal.GetNextIdentifier(service.connection, TableName)
+}
+
+// CreateStack saves a new stack using the identifier it already holds.
+func (service *Service) CreateStack(stack *portainer.Stack) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		// We manually manage sequences for stacks
+		err := internal.SetSequence(tx, TableName, int(stack.ID))
+		if err != nil {
+			return err
+		}
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(stack.ID)), stack, indexes(stack)...)
+	})
+}
+
+// UpdateStack updates a stack.
+func (service *Service) UpdateStack(ID portainer.StackID, stack *portainer.Stack) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, stack, indexes(stack)...)
+}
+
+// RebuildIndexes saves every stack again to populate the index columns,
+// it is used after data was copied into the table without going through the service.
+func (service *Service) RebuildIndexes() error {
+	stacks, err := service.Stacks()
+	if err != nil {
+		return err
+	}
+
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for i := range stacks {
+			stack := &stacks[i]
+			err := internal.PutObject(tx, TableName, internal.Itob(int(stack.ID)), stack, indexes(stack)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteStack deletes a stack.
+func (service *Service) DeleteStack(ID portainer.StackID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+func indexes(stack *portainer.Stack) []internal.Index {
+	return []internal.Index{
+		{Column: nameColumn, Value: stack.Name},
+	}
+}
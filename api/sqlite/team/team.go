@@ -0,0 +1,129 @@
+package team
+
+import (
+	"database/sql"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "teams"
+
+	nameColumn = "name"
+)
+
+// Service represents a service for managing team data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName, nameColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// Team returns a Team by ID
+func (service *Service) Team(ID portainer.TeamID) (*portainer.Team, error) {
+	var team portainer.Team
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &team)
+	if err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// TeamByName returns a team by name.
+func (service *Service) TeamByName(name string) (*portainer.Team, error) {
+	var team portainer.Team
+
+	err := internal.FindObject(service.connection, TableName, &team, internal.Index{Column: nameColumn, Value: strings.ToLower(name)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+// Teams return an array containing all the teams.
+func (service *Service) Teams() ([]portainer.Team, error) {
+	var teams = make([]portainer.Team, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var team portainer.Team
+		err := internal.UnmarshalObject(data, &team)
+		if err != nil {
+			return err
+		}
+		teams = append(teams, team)
+
+		return nil
+	})
+
+	return teams, err
+}
+
+// UpdateTeam saves a Team.
+func (service *Service) UpdateTeam(ID portainer.TeamID, team *portainer.Team) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, team, indexes(team)...)
+}
+
+// CreateTeam creates a new Team.
+func (service *Service) CreateTeam(team *portainer.Team) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		team.ID = portainer.TeamID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(team.ID)), team, indexes(team)...)
+	})
+}
+
+// DeleteTeam deletes a Team.
+func (service *Service) DeleteTeam(ID portainer.TeamID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+// RebuildIndexes saves every team again to populate the index columns,
+// it is used after data was copied into the table without going through the service.
+func (service *Service) RebuildIndexes() error {
+	teams, err := service.Teams()
+	if err != nil {
+		return err
+	}
+
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for i := range teams {
+			team := &teams[i]
+			err := internal.PutObject(tx, TableName, internal.Itob(int(team.ID)), team, indexes(team)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// team names are matched case-insensitively, the index stores the lowercase version.
+func indexes(team *portainer.Team) []internal.Index {
+	return []internal.Index{
+		{Column: nameColumn, Value: strings.ToLower(team.Name)},
+	}
+}
@@ -0,0 +1,141 @@
+package teammembership
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "team_membership"
+
+	userIDColumn = "user_id"
+	teamIDColumn = "team_id"
+)
+
+// Service represents a service for managing team membership data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName, userIDColumn, teamIDColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// TeamMembership returns a TeamMembership object by ID
+func (service *Service) TeamMembership(ID portainer.TeamMembershipID) (*portainer.TeamMembership, error) {
+	var membership portainer.TeamMembership
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &membership)
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// TeamMemberships return an array containing all the TeamMembership objects.
+func (service *Service) TeamMemberships() ([]portainer.TeamMembership, error) {
+	return service.teamMemberships()
+}
+
+// TeamMembershipsByUserID return an array containing all the TeamMembership objects where the specified userID is present.
+func (service *Service) TeamMembershipsByUserID(userID portainer.UserID) ([]portainer.TeamMembership, error) {
+	return service.teamMemberships(internal.Index{Column: userIDColumn, Value: int(userID)})
+}
+
+// TeamMembershipsByTeamID return an array containing all the TeamMembership objects where the specified teamID is present.
+func (service *Service) TeamMembershipsByTeamID(teamID portainer.TeamID) ([]portainer.TeamMembership, error) {
+	return service.teamMemberships(internal.Index{Column: teamIDColumn, Value: int(teamID)})
+}
+
+func (service *Service) teamMemberships(conditions ...internal.Index) ([]portainer.TeamMembership, error) {
+	var memberships = make([]portainer.TeamMembership, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var membership portainer.TeamMembership
+		err := internal.UnmarshalObject(data, &membership)
+		if err != nil {
+			return err
+		}
+		memberships = append(memberships, membership)
+
+		return nil
+	}, conditions...)
+
+	return memberships, err
+}
+
+// UpdateTeamMembership saves a TeamMembership object.
+func (service *Service) UpdateTeamMembership(ID portainer.TeamMembershipID, membership *portainer.TeamMembership) error {
+	identifier := internal.Itob(int(ID))
+	return internal.UpdateObject(service.connection, TableName, identifier, membership, indexes(membership)...)
+}
+
+// CreateTeamMembership creates a new TeamMembership object.
+func (service *Service) CreateTeamMembership(membership *portainer.TeamMembership) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		membership.ID = portainer.TeamMembershipID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(membership.ID)), membership, indexes(membership)...)
+	})
+}
+
+// DeleteTeamMembership deletes a TeamMembership object.
+func (service *Service) DeleteTeamMembership(ID portainer.TeamMembershipID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+// DeleteTeamMembershipByUserID deletes all the TeamMembership object associated to a UserID.
+func (service *Service) DeleteTeamMembershipByUserID(userID portainer.UserID) error {
+	return internal.DeleteObjects(service.connection, TableName, internal.Index{Column: userIDColumn, Value: int(userID)})
+}
+
+// RebuildIndexes saves every team membership again to populate the index columns,
+// it is used after data was copied into the table without going through the service.
+func (service *Service) RebuildIndexes() error {
+	memberships, err := service.TeamMemberships()
+	if err != nil {
+		return err
+	}
+
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for i := range memberships {
+			membership := &memberships[i]
+			err := internal.PutObject(tx, TableName, internal.Itob(int(membership.ID)), membership, indexes(membership)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteTeamMembershipByTeamID deletes all the TeamMembership object associated to a TeamID.
+func (service *Service) DeleteTeamMembershipByTeamID(teamID portainer.TeamID) error {
+	return internal.DeleteObjects(service.connection, TableName, internal.Index{Column: teamIDColumn, Value: int(teamID)})
+}
+
+func indexes(membership *portainer.TeamMembership) []internal.Index {
+	return []internal.Index{
+		{Column: userIDColumn, Value: int(membership.UserID)},
+		{Column: teamIDColumn, Value: int(membership.TeamID)},
+	}
+}
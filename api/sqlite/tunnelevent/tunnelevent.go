@@ -0,0 +1,73 @@
+package tunnelevent
+
+import (
+	"database/sql"
+	"math"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "tunnel_events"
+)
+
+// Service represents a service for managing tunnel event data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// eventKey returns the key of an event, made of the endpoint identifier followed by the event
+// identifier so that the events of an endpoint are stored next to each other, in creation order.
+func eventKey(endpointID portainer.EndpointID, ID int) []byte {
+	return append(internal.Itob(int(endpointID)), internal.Itob(ID)...)
+}
+
+// TunnelEvents returns the tunnel events of an endpoint, ordered by creation.
+func (service *Service) TunnelEvents(endpointID portainer.EndpointID) ([]portainer.TunnelEvent, error) {
+	var events = make([]portainer.TunnelEvent, 0)
+
+	err := internal.ForEachObjectInRange(service.connection, TableName, eventKey(endpointID, 0), eventKey(endpointID, math.MaxInt64), func(data []byte) error {
+		var event portainer.TunnelEvent
+		err := internal.UnmarshalObject(data, &event)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+
+		return nil
+	})
+
+	return events, err
+}
+
+// CreateTunnelEvent assigns an identifier to a tunnel event and saves it.
+func (service *Service) CreateTunnelEvent(event *portainer.TunnelEvent) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		event.ID = id
+
+		return internal.PutObject(tx, TableName, eventKey(event.EndpointID, event.ID), event)
+	})
+}
+
+// DeleteTunnelEvents deletes the tunnel events of an endpoint up to the event identified by maxID (inclusive).
+func (service *Service) DeleteTunnelEvents(endpointID portainer.EndpointID, maxID int) error {
+	return internal.DeleteObjectsInRange(service.connection, TableName, eventKey(endpointID, 0), eventKey(endpointID, maxID))
+}
@@ -0,0 +1,141 @@
+package user
+
+import (
+	"database/sql"
+	"strings"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "users"
+
+	usernameColumn = "username"
+	roleColumn     = "role"
+)
+
+// Service represents a service for managing user data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName, usernameColumn, roleColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// User returns a user by ID
+func (service *Service) User(ID portainer.UserID) (*portainer.User, error) {
+	var user portainer.User
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// UserByUsername returns a user by username.
+func (service *Service) UserByUsername(username string) (*portainer.User, error) {
+	var user portainer.User
+
+	err := internal.FindObject(service.connection, TableName, &user, internal.Index{Column: usernameColumn, Value: strings.ToLower(username)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Users return an array containing all the users.
+func (service *Service) Users() ([]portainer.User, error) {
+	return service.users()
+}
+
+// UsersByRole return an array containing all the users with the specified role.
+func (service *Service) UsersByRole(role portainer.UserRole) ([]portainer.User, error) {
+	return service.users(internal.Index{Column: roleColumn, Value: int(role)})
+}
+
+func (service *Service) users(conditions ...internal.Index) ([]portainer.User, error) {
+	var users = make([]portainer.User, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var user portainer.User
+		err := internal.UnmarshalObject(data, &user)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+
+		return nil
+	}, conditions...)
+
+	return users, err
+}
+
+// UpdateUser saves a user.
+func (service *Service) UpdateUser(ID portainer.UserID, user *portainer.User) error {
+	identifier := internal.Itob(int(ID))
+	user.Username = strings.ToLower(user.Username)
+	return internal.UpdateObject(service.connection, TableName, identifier, user, indexes(user)...)
+}
+
+// CreateUser creates a new user.
+func (service *Service) CreateUser(user *portainer.User) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		user.ID = portainer.UserID(id)
+		user.Username = strings.ToLower(user.Username)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(user.ID)), user, indexes(user)...)
+	})
+}
+
+// RebuildIndexes saves every user again to populate the index columns,
+// it is used after data was copied into the table without going through the service.
+func (service *Service) RebuildIndexes() error {
+	users, err := service.Users()
+	if err != nil {
+		return err
+	}
+
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for i := range users {
+			user := &users[i]
+			err := internal.PutObject(tx, TableName, internal.Itob(int(user.ID)), user, indexes(user)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteUser deletes a user.
+func (service *Service) DeleteUser(ID portainer.UserID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+func indexes(user *portainer.User) []internal.Index {
+	return []internal.Index{
+		{Column: usernameColumn, Value: strings.ToLower(user.Username)},
+		{Column: roleColumn, Value: int(user.Role)},
+	}
+}
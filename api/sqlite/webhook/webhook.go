@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"database/sql"
+
+	portainer "github.com/portainer/portainer/api"
+	"github.com/portainer/portainer/api/sqlite/internal"
+)
+
+const (
+	// TableName represents the name of the table where this service stores data.
+	TableName = "webhooks"
+
+	resourceIDColumn = "resource_id"
+	tokenColumn      = "token"
+)
+
+// Service represents a service for managing webhook data.
+type Service struct {
+	connection *internal.DbConnection
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection *internal.DbConnection) (*Service, error) {
+	err := internal.CreateTable(connection, TableName, resourceIDColumn, tokenColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// Webhooks returns an array of all webhooks
+func (service *Service) Webhooks() ([]portainer.Webhook, error) {
+	var webhooks = make([]portainer.Webhook, 0)
+
+	err := internal.ForEachObject(service.connection, TableName, func(data []byte) error {
+		var webhook portainer.Webhook
+		err := internal.UnmarshalObject(data, &webhook)
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, webhook)
+
+		return nil
+	})
+
+	return webhooks, err
+}
+
+// Webhook returns a webhook by ID.
+func (service *Service) Webhook(ID portainer.WebhookID) (*portainer.Webhook, error) {
+	var webhook portainer.Webhook
+	identifier := internal.Itob(int(ID))
+
+	err := internal.GetObject(service.connection, TableName, identifier, &webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// WebhookByResourceID returns a webhook by the ResourceID it is associated with.
+func (service *Service) WebhookByResourceID(ID string) (*portainer.Webhook, error) {
+	var webhook portainer.Webhook
+
+	err := internal.FindObject(service.connection, TableName, &webhook, internal.Index{Column: resourceIDColumn, Value: ID})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// WebhookByToken returns a webhook by the random token it is associated with.
+func (service *Service) WebhookByToken(token string) (*portainer.Webhook, error) {
+	var webhook portainer.Webhook
+
+	err := internal.FindObject(service.connection, TableName, &webhook, internal.Index{Column: tokenColumn, Value: token})
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// DeleteWebhook deletes a webhook.
+func (service *Service) DeleteWebhook(ID portainer.WebhookID) error {
+	identifier := internal.Itob(int(ID))
+	return internal.DeleteObject(service.connection, TableName, identifier)
+}
+
+// CreateWebhook assign an ID to a new webhook and saves it.
+func (service *Service) CreateWebhook(webhook *portainer.Webhook) error {
+	return service.connection.Update(func(tx *sql.Tx) error {
+		id, err := internal.NextSequence(tx, TableName)
+		if err != nil {
+			return err
+		}
+		webhook.ID = portainer.WebhookID(id)
+
+		return internal.PutObject(tx, TableName, internal.Itob(int(webhook.ID)), webhook, indexes(webhook)...)
+	})
+}
+
+// RebuildIndexes saves every webhook again to populate the index columns,
+// it is used after data was copied into the table without going through the service.
+func (service *Service) RebuildIndexes() error {
+	webhooks, err := service.Webhooks()
+	if err != nil {
+		return err
+	}
+
+	return service.connection.Update(func(tx *sql.Tx) error {
+		for i := range webhooks {
+			webhook := &webhooks[i]
+			err := internal.PutObject(tx, TableName, internal.Itob(int(webhook.ID)), webhook, indexes(webhook)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func indexes(webhook *portainer.Webhook) []internal.Index {
+	return []internal.Index{
+		{Column: resourceIDColumn, Value: webhook.ResourceID},
+		{Column: tokenColumn, Value: webhook.Token},
+	}
+}
@@ -5,7 +5,7 @@ function EdgeJobResultsFactory($resource, API_ENDPOINT_EDGE_JOBS) {
     API_ENDPOINT_EDGE_JOBS + '/:id/tasks/:taskId/:action',
     {},
     {
-      query: { method: 'GET', isArray: true, params: { id: '@id' } },
+      query: { method: 'GET', params: { id: '@id' } },
       logFile: { method: 'GET', params: { id: '@id', taskId: '@taskId', action: 'logs' } },
       clearLogs: { method: 'DELETE', params: { id: '@id', taskId: '@taskId', action: 'logs' } },
       collectLogs: { method: 'POST', params: { id: '@id', taskId: '@taskId', action: 'logs' } },
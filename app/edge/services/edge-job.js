@@ -26,7 +26,8 @@ function EdgeJobService(EdgeJobs, EdgeJobResults, FileUploadService) {
   service.jobResults = jobResults;
   async function jobResults(edgeJobId) {
     try {
-      return await EdgeJobResults.query({ id: edgeJobId }).$promise;
+      const { Tasks } = await EdgeJobResults.query({ id: edgeJobId }).$promise;
+      return Tasks;
     } catch (err) {
       throw { msg: 'Unable to retrieve results associated to the edgeJob', err: err };
     }
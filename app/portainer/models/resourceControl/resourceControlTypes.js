@@ -8,6 +8,7 @@ export const ResourceControlTypeString = Object.freeze({
   VOLUME: 'volume',
   CUSTOM_TEMPLATE: 'custom-template',
   CONTAINER_GROUP: 'container-group',
+  IMAGE: 'image',
 });
 
 /**
@@ -23,4 +24,5 @@ export const ResourceControlTypeInt = Object.freeze({
   CONFIG: 7,
   CUSTOM_TEMPLATE: 8,
   CONTAINER_GROUP: 9,
+  IMAGE: 10,
 });